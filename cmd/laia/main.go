@@ -2,21 +2,27 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/lojasmm/laia/internal/admin"
 	"github.com/lojasmm/laia/internal/ai"
 	aitools "github.com/lojasmm/laia/internal/ai/tools"
 	"github.com/lojasmm/laia/internal/auth"
 	"github.com/lojasmm/laia/internal/bot"
 	"github.com/lojasmm/laia/internal/config"
 	"github.com/lojasmm/laia/internal/glpi"
+	"github.com/lojasmm/laia/internal/logging"
+	"github.com/lojasmm/laia/internal/reminder"
 	"github.com/lojasmm/laia/internal/session"
 	"github.com/lojasmm/laia/internal/store"
 	"github.com/lojasmm/laia/internal/whatsapp"
@@ -28,16 +34,105 @@ func main() {
 		log.Fatalf("config: %v", err)
 	}
 
-	db, err := store.NewBoltStore(cfg.DataDir + "/laia.db")
+	logging.Setup(cfg.LogFormat, cfg.LogPhoneKey)
+
+	db, err := store.NewBoltStore(filepath.Join(cfg.DataDir, "laia.db"))
 	if err != nil {
 		log.Fatalf("store: %v", err)
 	}
 	defer db.Close()
+	db.SetHistoryLimits(store.HistoryLimits{MaxTurns: cfg.HistoryMaxTurns, MaxTokens: cfg.HistoryMaxTokens, KeepRecent: cfg.HistoryKeepRecent})
+	if cfg.TokenEncryptionKey != nil {
+		if err := db.SetEncryptionKey(cfg.TokenEncryptionKey); err != nil {
+			log.Fatalf("store: %v", err)
+		}
+	}
 
-	glpiClient := glpi.NewClient(cfg.NexusBaseURL, cfg.NexusAppToken, cfg.NexusAdminToken, cfg.NexusAdminProfile)
+	glpiClient := glpi.NewClient(cfg.NexusBaseURL, cfg.NexusAppToken, cfg.NexusAdminToken, cfg.NexusAdminProfile,
+		glpi.Timeouts{Default: cfg.NexusHTTPTimeout, Search: cfg.NexusSearchTimeout})
+	glpiClient.SetAdminCredential(glpi.AdminReadReference, glpi.AdminCredential{Token: cfg.NexusAdminReadToken, Profile: cfg.NexusAdminReadProfile})
+	glpiClient.SetAdminCredential(glpi.AdminCreateTicket, glpi.AdminCredential{Token: cfg.NexusAdminCreateToken, Profile: cfg.NexusAdminCreateProfile})
+	if cfg.NexusOAuthClientID != "" {
+		glpiClient.EnableOAuth(glpi.OAuthConfig{
+			ClientID:     cfg.NexusOAuthClientID,
+			ClientSecret: cfg.NexusOAuthClientSecret,
+			RedirectURL:  cfg.NexusOAuthRedirectURL,
+		})
+	}
 	waClient := whatsapp.NewClient(cfg.WAPhoneNumberID, cfg.WAAccessToken)
 
-	agent := ai.NewAgent(cfg.OpenAIAPIKey, glpiClient, db, aitools.BuildRegistry)
+	routing, err := aitools.LoadRoutingRules(cfg.RoutingHintsFile)
+	if err != nil {
+		log.Fatalf("routing hints: %v", err)
+	}
+	urgencyRules, err := aitools.LoadUrgencyRules(cfg.UrgencyHintsFile)
+	if err != nil {
+		log.Fatalf("urgency hints: %v", err)
+	}
+	branches, err := aitools.LoadBranches(cfg.BranchesFile)
+	if err != nil {
+		log.Fatalf("branches: %v", err)
+	}
+	managers, err := aitools.LoadManagers(cfg.ManagersFile)
+	if err != nil {
+		log.Fatalf("managers: %v", err)
+	}
+	customFields, err := aitools.LoadCustomFields(cfg.CustomFieldsFile)
+	if err != nil {
+		log.Fatalf("custom fields: %v", err)
+	}
+	handoffHours, err := aitools.ParseBusinessHours(cfg.HandoffHours)
+	if err != nil {
+		log.Fatalf("config: HANDOFF_HOURS: %v", err)
+	}
+	statusWorkflow, err := aitools.ParseStatusWorkflow(cfg.StatusWorkflow)
+	if err != nil {
+		log.Fatalf("config: TICKET_STATUS_WORKFLOW: %v", err)
+	}
+
+	var completer *ai.Completer
+	if cfg.TranslateTickets {
+		completer = ai.NewCompleter(cfg.OpenAIAPIKey)
+	}
+
+	var adminAlert aitools.AdminAlert
+	if cfg.AdminAlertPhone != "" {
+		adminAlert = func(message string) {
+			if err := waClient.SendText(cfg.AdminAlertPhone, message); err != nil {
+				slog.Warn("laia: failed to send admin alert", "error", err)
+			}
+		}
+	}
+
+	agent := ai.NewAgent(cfg.OpenAIAPIKey, glpiClient, db, aitools.NewRegistryBuilder(aitools.Options{
+		AttachTranscript: cfg.AttachTranscript,
+		Store:            db,
+		Routing:          routing,
+		Branches:         branches,
+		Completer:        completer,
+		CustomFields:     customFields,
+		Handoff:          aitools.HandoffConfig{CategoryID: cfg.HandoffCategoryID, GroupID: cfg.HandoffGroupID, Hours: handoffHours},
+		AdminAlert:       adminAlert,
+		StatusWorkflow:   statusWorkflow,
+		Urgency:          aitools.UrgencyPolicy{Default: cfg.DefaultUrgency, SkipCategories: cfg.UrgencySkipCategories},
+		UrgencyRules:     urgencyRules,
+		Managers:         managers,
+		NotifyManager:    waClient.SendText,
+	}))
+	agent.SetHistoryLimits(db.HistoryLimits())
+	agent.SetToolRetryPolicy(ai.ToolRetryPolicy{MaxRetries: cfg.ToolMaxRetries, Backoff: cfg.ToolRetryBackoff, NonRetryable: cfg.ToolNonRetryableErrors})
+	agent.SetMaxParallelTools(cfg.MaxParallelTools)
+	agent.SetDailyTokenBudget(cfg.DailyTokenBudget)
+	confirmLevel, err := ai.ParseConfirmLevel(cfg.ConfirmLevel)
+	if err != nil {
+		log.Fatalf("config: CONFIRM_LEVEL: %v", err)
+	}
+	agent.SetConfirmLevel(confirmLevel)
+	agent.SetDryRun(cfg.DryRun)
+	if cfg.DryRun {
+		slog.Warn("laia: AGENT_DRY_RUN enabled, mutating tools will not run")
+	}
+	agent.SetDoomLoopPolicy(ai.DoomLoopPolicy{ExactThreshold: cfg.DoomLoopExactThreshold, NameThreshold: cfg.DoomLoopNameThreshold})
 	sessionMgr := session.NewManager()
 
 	// Periodic cleanup of stale per-user locks to prevent memory leaks
@@ -49,8 +144,39 @@ func main() {
 		}
 	}()
 
+	// An expired WhatsApp token makes every reply fail silently; check it at
+	// startup and hourly. Alerts can't go over WhatsApp here, so it's logged.
+	go func() {
+		check := func() {
+			if err := waClient.VerifyToken(); errors.Is(err, whatsapp.ErrInvalidToken) {
+				slog.Error("laia: WhatsApp access token rejected, no message will be delivered until WA_ACCESS_TOKEN is renewed", "error", err)
+			} else if err != nil {
+				slog.Warn("laia: could not verify WhatsApp access token", "error", err)
+			}
+		}
+		check()
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			check()
+		}
+	}()
+
+	reminders := reminder.NewScheduler(db, waClient, cfg.WAReminderTemplate)
+	remindersCtx, stopReminders := context.WithCancel(context.Background())
+	defer stopReminders()
+	go reminders.Run(remindersCtx, time.Minute)
+
 	botHandler := bot.NewHandler(waClient, db, cfg.BaseURL, agent, sessionMgr)
+	botHandler.SetMaxInboundChars(cfg.MaxInboundChars)
+	botHandler.SetMaxInboundLogChars(cfg.MaxInboundLogChars)
+	botHandler.SetInterimMessages(cfg.InterimMessages)
 	authHandler := auth.NewHandler(glpiClient, db, waClient)
+	onboarding, err := auth.LoadOnboarding(cfg.OnboardingFile)
+	if err != nil {
+		log.Fatalf("onboarding: %v", err)
+	}
+	authHandler.SetOnboarding(onboarding)
 	webhookHandler := whatsapp.NewWebhookHandler(cfg.WAVerifyToken, botHandler.HandleMessage)
 
 	r := chi.NewRouter()
@@ -67,6 +193,13 @@ func main() {
 
 	r.Get("/auth/verify", authHandler.HandleVerifyPage)
 	r.Post("/auth/verify", authHandler.HandleVerifySubmit)
+	r.Get("/auth/oauth/start", authHandler.HandleOAuthStart)
+	r.Get("/auth/oauth/callback", authHandler.HandleOAuthCallback)
+
+	if cfg.AdminAPIToken != "" {
+		adminHandler := admin.NewHandler(cfg.AdminAPIToken, db, agent, sessionMgr)
+		r.Post("/admin/impersonate", adminHandler.HandleImpersonate)
+	}
 
 	srv := &http.Server{
 		Addr:         ":" + cfg.Port,
@@ -77,8 +210,8 @@ func main() {
 	}
 
 	go func() {
-		log.Printf("laia: listening on :%s", cfg.Port)
-		log.Printf("laia: webhook verify token = %s", cfg.WAVerifyToken)
+		slog.Info("laia: listening", "port", cfg.Port)
+		slog.Info("laia: webhook verify token", "token", cfg.WAVerifyToken)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("server: %v", err)
 		}
@@ -87,12 +220,12 @@ func main() {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
-	log.Println("laia: shutting down...")
+	slog.Info("laia: shutting down")
 
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		log.Fatalf("shutdown: %v", err)
 	}
-	log.Println("laia: stopped")
+	slog.Info("laia: stopped")
 }
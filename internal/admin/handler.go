@@ -0,0 +1,105 @@
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lojasmm/laia/internal/ai"
+	"github.com/lojasmm/laia/internal/logging"
+	"github.com/lojasmm/laia/internal/session"
+	"github.com/lojasmm/laia/internal/store"
+)
+
+// impersonateTimeout bounds one impersonation: a full agent turn runs several
+// model round-trips and tool calls, well past the server's WriteTimeout, so
+// the route extends its own write deadline to match.
+const impersonateTimeout = 3 * time.Minute
+
+// Handler serves support-only endpoints, gated by a static bearer token
+// (ADMIN_API_TOKEN). Routes are only mounted when the token is configured.
+type Handler struct {
+	token      string
+	store      store.Store
+	agent      *ai.Agent
+	sessionMgr *session.Manager
+}
+
+func NewHandler(token string, s store.Store, agent *ai.Agent, sm *session.Manager) *Handler {
+	return &Handler{token: token, store: s, agent: agent, sessionMgr: sm}
+}
+
+type impersonateRequest struct {
+	Phone  string `json:"phone"`
+	Prompt string `json:"prompt"`
+}
+
+type impersonateResponse struct {
+	Response  *ai.Response    `json:"response,omitempty"`
+	ToolCalls []ai.TracedCall `json:"tool_calls"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// HandleImpersonate runs one prompt through the agent as the user linked to
+// phone and returns the reply with the full tool trace. It is a dry run:
+// nothing is sent over WhatsApp, the user's history is not modified and
+// mutating or side-effecting tools are previewed instead of executed. It holds
+// the user's session lock so it never interleaves with a real message.
+func (h *Handler) HandleImpersonate(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req impersonateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Phone == "" || req.Prompt == "" {
+		http.Error(w, "phone and prompt are required", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.store.GetUser(req.Phone)
+	if err != nil {
+		http.Error(w, "store error", http.StatusInternalServerError)
+		return
+	}
+	if user == nil {
+		http.Error(w, "phone not linked", http.StatusNotFound)
+		return
+	}
+
+	logger := logging.ForRequest(req.Phone)
+	logger.Info("admin: impersonate dry run", "glpi_user_id", user.GLPIUserID)
+
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(impersonateTimeout + 5*time.Second)); err != nil {
+		logger.Warn("admin: could not extend write deadline", "error", err)
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), impersonateTimeout)
+	defer cancel()
+
+	trace := &ai.ToolTrace{}
+	ctx = ai.WithDryRun(logging.WithLogger(ctx, logger), trace)
+	var resp *ai.Response
+	err = h.sessionMgr.WithLock(store.NormalizePhone(req.Phone), func() error {
+		var err error
+		resp, err = h.agent.Handle(ctx, user, req.Phone, req.Prompt, false)
+		return err
+	})
+
+	out := impersonateResponse{Response: resp, ToolCalls: trace.Calls}
+	if err != nil {
+		out.Error = err.Error()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		slog.Warn("admin: failed to write response", "error", err)
+	}
+}
+
+func (h *Handler) authorized(r *http.Request) bool {
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(got), []byte(h.token)) == 1
+}
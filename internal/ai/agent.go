@@ -4,15 +4,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/lojasmm/laia/internal/glpi"
+	"github.com/lojasmm/laia/internal/logging"
 	"github.com/lojasmm/laia/internal/store"
 )
 
@@ -29,33 +31,115 @@ const (
 	retryInitialDelay = 2 * time.Second
 	retryMaxDelay     = 30 * time.Second
 
-	// History pruning: tool responses older than this many turns get compressed
-	pruneKeepRecent = 4
+	// Tool retry defaults: retryable tool errors are retried this many times,
+	// waiting toolRetryBackoff before the first retry and doubling after.
+	defaultToolMaxRetries = 1
+	toolRetryBackoff      = 2 * time.Second
 
-	// Doom loop: exact-match threshold and per-tool-name threshold
-	doomLoopExactThreshold = 2
-	doomLoopNameThreshold  = 4
+	// Read-only tools of one model response run at most this many at a time.
+	defaultMaxParallelTools = 4
+
+	// Doom loop defaults: exact-match threshold (aborts) and per-tool-name
+	// threshold (nudges), see DoomLoopPolicy
+	defaultDoomLoopExactThreshold = 2
+	defaultDoomLoopNameThreshold  = 4
 
 	// Incremental history pruning: max attempts before full clear
 	maxPruneAttempts = 3
 
-	// Proactive token budget for messages before sending to OpenAI.
-	// Leaves room for system prompt (~1500 tokens) + output (maxTokens).
-	maxMessageTokenBudget = 6000
+	// Proactive token budget for messages before sending to OpenAI is the
+	// stored history budget plus this headroom for the current turn's tool
+	// results. Leaves room for system prompt (~1500 tokens) + output (maxTokens).
+	messageBudgetHeadroom = 2500
+
+	// Past this fraction of either history limit, suggest a fresh topic
+	// (at most once per longConversationWarnEvery) before pruning kicks in.
+	longConversationRatio     = 0.8
+	longConversationWarnEvery = 30 * time.Minute
 )
 
+// NewTopicReplyID is the button that clears the conversation history.
+const NewTopicReplyID = "conversation:new_topic"
+
 // RegistryBuilder creates a tool registry for a given GLPI session.
-type RegistryBuilder func(g *glpi.Client, sessionToken string, userID int) *Registry
+type RegistryBuilder func(g *glpi.Client, sessionToken string, userID int, conv *Conversation) *Registry
+
+// Conversation gives tools read access to the chat being handled, for tools
+// that need more than the GLPI session (e.g. attaching the transcript to a ticket).
+type Conversation struct {
+	Phone string
+	turns *[]store.ConversationTurn
+
+	mu        sync.Mutex
+	images    []Image
+	documents []Document
+}
+
+// NewConversation wraps a turns slice owned by the caller; later appends to
+// *turns are visible through Turns.
+func NewConversation(phone string, turns *[]store.ConversationTurn) *Conversation {
+	return &Conversation{Phone: phone, turns: turns}
+}
+
+// Turns returns the conversation so far, including the current user message.
+func (c *Conversation) Turns() []store.ConversationTurn {
+	if c == nil || c.turns == nil {
+		return nil
+	}
+	return *c.turns
+}
+
+// AttachImage queues img to be sent with the reply to this message. Tools run
+// in parallel, so it is safe for concurrent use.
+func (c *Conversation) AttachImage(img Image) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.images = append(c.images, img)
+}
+
+func (c *Conversation) attachedImages() []Image {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.images
+}
+
+// AttachDocument queues doc to be sent with the reply, like AttachImage.
+func (c *Conversation) AttachDocument(doc Document) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.documents = append(c.documents, doc)
+}
+
+func (c *Conversation) attachedDocuments() []Document {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.documents
+}
 
 type Agent struct {
-	apiKey   string
+	llm      *openAIClient
 	glpi     *glpi.Client
 	store    store.Store
 	buildReg RegistryBuilder
-	http     *http.Client
+
+	limits   store.HistoryLimits
+	retry    ToolRetryPolicy
+	doomLoop DoomLoopPolicy
+	confirm  ConfirmLevel
+	dryRun   bool
+	// maxParallel caps concurrent read-only tool calls so one response
+	// can't hit GLPI with a burst of requests.
+	maxParallel int
+	// dailyTokens is the per-phone daily token budget; 0 means unlimited.
+	dailyTokens int
 
 	mu       sync.Mutex
 	counters map[string]*rateBucket
+	warned   map[string]time.Time // last "conversation too long" hint per phone
+	// lastActions is the latest mutating tool call per phone, for retry_last_action.
+	lastActions map[string]lastAction
+	// unsaved holds history that couldn't be written to the store, see saveHistory.
+	unsaved map[string][]store.ConversationTurn
 }
 
 type rateBucket struct {
@@ -65,13 +149,136 @@ type rateBucket struct {
 
 func NewAgent(apiKey string, g *glpi.Client, s store.Store, buildReg RegistryBuilder) *Agent {
 	return &Agent{
-		apiKey:   apiKey,
+		llm:      newOpenAIClient(apiKey),
 		glpi:     g,
 		store:    s,
 		buildReg: buildReg,
-		http:     &http.Client{Timeout: 60 * time.Second},
+		limits:   store.DefaultHistoryLimits,
+		retry:    ToolRetryPolicy{MaxRetries: defaultToolMaxRetries, Backoff: toolRetryBackoff},
+		doomLoop: DoomLoopPolicy{}.withDefaults(),
+		confirm:  ConfirmNone,
 		counters: make(map[string]*rateBucket),
+		warned:   make(map[string]time.Time),
+
+		maxParallel: defaultMaxParallelTools,
+		lastActions: make(map[string]lastAction),
+		unsaved:     make(map[string][]store.ConversationTurn),
+	}
+}
+
+// SetHistoryLimits must match the store's, so the prompt budget and the
+// "conversation too long" hint track what is actually persisted.
+func (a *Agent) SetHistoryLimits(l store.HistoryLimits) {
+	a.limits = l
+}
+
+// SetMaxParallelTools caps how many read-only tools run concurrently; n < 1
+// keeps the default.
+func (a *Agent) SetMaxParallelTools(n int) {
+	if n < 1 {
+		n = defaultMaxParallelTools
+	}
+	a.maxParallel = n
+}
+
+// ToolRetryPolicy controls how retryable tool errors (timeouts, 5xx, 429) are retried.
+type ToolRetryPolicy struct {
+	MaxRetries int
+	Backoff    time.Duration // delay before the first retry, doubled on each one
+	// NonRetryable lists error substrings that are never retried, even as a
+	// 5xx: some GLPI 500s are bad input (often a plugin's SQL error) and fail
+	// the same way every time.
+	NonRetryable []string
+}
+
+// classify is ClassifyError with NonRetryable applied.
+func (p ToolRetryPolicy) classify(err error) *ToolError {
+	te := ClassifyError(err)
+	if te.Retryable && containsAny(te.RawError, p.NonRetryable...) {
+		cp := *te
+		cp.Retryable = false
+		return &cp
+	}
+	return te
+}
+
+func (a *Agent) SetToolRetryPolicy(p ToolRetryPolicy) {
+	a.retry = p
+}
+
+// executeWithRetry runs a tool, retrying while the classified error is
+// retryable. Mutating tools are only retried on 429: after a timeout or a 5xx
+// the write may have been applied, in part or whole, and GLPI has no
+// idempotency key to make a repeat safe. It returns a nil *ToolError on success.
+func (a *Agent) executeWithRetry(ctx context.Context, registry *Registry, name string, args map[string]any) (map[string]any, *ToolError) {
+	logger := logging.FromContext(ctx)
+	delay := a.retry.Backoff
+	readOnly := registry.IsReadOnly(name)
+	for attempt := 0; ; attempt++ {
+		result, err := registry.ExecuteTool(ctx, name, args)
+		if err == nil {
+			return result, nil
+		}
+		te := a.retry.classify(err)
+		if te.Retryable && !readOnly && te.Type != ErrRateLimit {
+			return nil, mayHaveApplied(te)
+		}
+		if !te.Retryable || attempt >= a.retry.MaxRetries {
+			return nil, te
+		}
+
+		logger.Warn("agent: retrying tool", "tool", name, "attempt", attempt+1, "error", te.RawError)
+		select {
+		case <-ctx.Done():
+			return nil, ClassifyError(ctx.Err())
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// mayHaveApplied rewords a failed write whose outcome is unknown, so neither
+// the model nor the user assumes it didn't happen.
+func mayHaveApplied(te *ToolError) *ToolError {
+	cp := *te
+	cp.Message = "O Nexus falhou no meio da operação e ela pode ter sido aplicada. Confira antes de tentar de novo."
+	return &cp
+}
+
+func toolErrorResult(te *ToolError) map[string]any {
+	return map[string]any{
+		"status": "error",
+		"error":  map[string]any{"type": string(te.Type), "message": te.Message},
+	}
+}
+
+func (a *Agent) messageTokenBudget() int {
+	return a.limits.MaxTokens + messageBudgetHeadroom
+}
+
+// shouldWarnLongConversation reports whether history is close to the limits
+// and the user hasn't been warned recently.
+func (a *Agent) shouldWarnLongConversation(phone string, history []store.ConversationTurn) bool {
+	near := float64(len(history)) >= longConversationRatio*float64(a.limits.MaxTurns) ||
+		float64(store.EstimateTokens(history)) >= longConversationRatio*float64(a.limits.MaxTokens)
+	if !near {
+		return false
 	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := time.Now()
+	key := store.NormalizePhone(phone)
+	if now.Sub(a.warned[key]) < longConversationWarnEvery {
+		return false
+	}
+	// Past longConversationWarnEvery an entry no longer suppresses anything.
+	for p, at := range a.warned {
+		if now.Sub(at) >= longConversationWarnEvery {
+			delete(a.warned, p)
+		}
+	}
+	a.warned[key] = now
+	return true
 }
 
 // --- OpenAI API types ---
@@ -117,31 +324,25 @@ type chatChoice struct {
 	Message chatMessage `json:"message"`
 }
 
-// Handle processes one user message through the AI agent loop.
-func (a *Agent) Handle(ctx context.Context, user *store.User, phone, text string) (*Response, error) {
+// Handle processes one user message through the AI agent loop. tapped is set
+// when text is the title of a tapped button or list row rather than typed.
+func (a *Agent) Handle(ctx context.Context, user *store.User, phone, text string, tapped bool) (*Response, error) {
 	if !a.allowRequest(phone) {
 		return &Response{Text: "Você está enviando mensagens muito rápido. Aguarde um minuto e tente novamente."}, nil
 	}
-
-	history, err := a.store.GetHistory(phone)
-	if err != nil {
-		log.Printf("agent: failed to load history for %s: %v", phone, err)
+	if a.overBudget(ctx, phone) {
+		return budgetExceededResponse(), nil
 	}
 
-	sessionToken, err := a.glpi.InitSession(user.UserToken)
-	if err != nil {
-		return nil, fmt.Errorf("initSession: %w", err)
-	}
-	defer a.glpi.KillSession(sessionToken)
+	logger := logging.FromContext(ctx)
 
-	registry := a.buildReg(a.glpi, sessionToken, user.GLPIUserID)
+	history := a.loadHistory(ctx, phone)
 
-	messages := []chatMessage{{
-		Role:    "system",
-		Content: BuildSystemPrompt(user.Name, user.GLPIUserID),
-	}}
-	messages = append(messages, toOpenAIMessages(history)...)
-	messages = append(messages, chatMessage{Role: "user", Content: text})
+	sessionToken, err := a.initUserSession(user)
+	if err != nil {
+		return nil, err
+	}
+	defer a.killSession(ctx, sessionToken)
 
 	var allTurns []store.ConversationTurn
 	allTurns = append(allTurns, history...)
@@ -150,30 +351,53 @@ func (a *Agent) Handle(ctx context.Context, user *store.User, phone, text string
 		Parts: []store.TurnPart{{Text: text}},
 	})
 
+	conv := NewConversation(phone, &allTurns)
+	registry := a.buildReg(a.glpi, sessionToken, user.GLPIUserID, conv)
+	registry.Register(&retryLastAction{agent: a, phone: phone, registry: registry})
 	tools := registry.OpenAITools()
 
+	messages := []chatMessage{{
+		Role:    "system",
+		Content: BuildSystemPrompt(user.Name, user.GLPIUserID, registry),
+	}}
+	messages = append(messages, toOpenAIMessages(history, a.limits.KeepRecent)...)
+	messages = append(messages, chatMessage{Role: "user", Content: text})
+	if registry.check(handoffToolName) == nil {
+		if hint := handoffHint(history, text); hint != "" {
+			messages = append(messages, chatMessage{Role: "system", Content: hint})
+		}
+	}
+	if hint := reofferHint(history, text, tapped); hint != "" {
+		messages = append(messages, chatMessage{Role: "system", Content: hint})
+	}
+
 	// Convert to []any for JSON serialization
 	toolsAny := make([]any, len(tools))
 	for i, t := range tools {
 		toolsAny[i] = t
 	}
 
-	// Doom loop detection state
-	var lastToolSig string
-	var sameExactCount int
-	toolNameCounts := map[string]int{} // track per-tool-name call count
+	loops := newLoopDetector(a.doomLoop)
+	confirmed := userConfirmed(history, text)
 	var pruneAttempt int
+	// Offer status chips when the answer is a ticket listing
+	var listedTickets bool
 
 	for range maxToolIterations {
 		// Proactive token budget check: drop oldest non-system turns if too large
 		estimated := estimateMessagesTokens(messages)
-		if estimated > maxMessageTokenBudget {
-			log.Printf("agent: proactive prune for %s (estimated %d tokens > %d budget)", phone, estimated, maxMessageTokenBudget)
-			messages = pruneMessages(messages, user.Name, user.GLPIUserID)
+		if budget := a.messageTokenBudget(); estimated > budget {
+			logger.Info("agent: proactive prune", "estimated_tokens", estimated, "budget", budget)
+			messages = pruneMessages(messages, budget)
 			allTurns = rebuildTurns(messages)
 		}
 
-		resp, err := a.chatCompletion(ctx, messages, toolsAny)
+		var repaired int
+		if messages, repaired = repairToolCalls(messages); repaired > 0 {
+			logger.Warn("agent: repaired orphaned tool calls", "count", repaired)
+		}
+
+		resp, err := a.llm.chatCompletion(ctx, messages, toolsAny)
 		if err != nil {
 			errMsg := err.Error()
 			isContextOverflow := strings.Contains(errMsg, "context_length_exceeded") ||
@@ -187,8 +411,8 @@ func (a *Agent) Handle(ctx context.Context, user *store.User, phone, text string
 				if isContextOverflow {
 					dropCount = pruneAttempt * 2
 				}
-				log.Printf("agent: format error for %s, pruning attempt %d/%d (dropping %d turns, overflow=%v)",
-					phone, pruneAttempt, maxPruneAttempts, dropCount, isContextOverflow)
+				logger.Warn("agent: format error, pruning history",
+					"attempt", pruneAttempt, "max_attempts", maxPruneAttempts, "dropped_turns", dropCount, "overflow", isContextOverflow)
 				for range dropCount {
 					if len(allTurns) > 1 {
 						allTurns = allTurns[1:]
@@ -196,17 +420,19 @@ func (a *Agent) Handle(ctx context.Context, user *store.User, phone, text string
 				}
 				messages = []chatMessage{{
 					Role:    "system",
-					Content: BuildSystemPrompt(user.Name, user.GLPIUserID),
+					Content: BuildSystemPrompt(user.Name, user.GLPIUserID, registry),
 				}}
-				messages = append(messages, toOpenAIMessages(allTurns)...)
+				messages = append(messages, toOpenAIMessages(allTurns, a.limits.KeepRecent)...)
 				continue
 			}
 			// Last resort: clear everything
 			if is400 || isContextOverflow {
-				log.Printf("agent: incremental prune failed for %s, clearing history", phone)
-				a.store.ClearHistory(phone)
+				logger.Warn("agent: incremental prune failed, clearing history")
+				if dryRunTrace(ctx) == nil {
+					a.ClearHistory(phone)
+				}
 				messages = []chatMessage{
-					{Role: "system", Content: BuildSystemPrompt(user.Name, user.GLPIUserID)},
+					{Role: "system", Content: BuildSystemPrompt(user.Name, user.GLPIUserID, registry)},
 					{Role: "user", Content: text},
 				}
 				allTurns = []store.ConversationTurn{
@@ -220,9 +446,10 @@ func (a *Agent) Handle(ctx context.Context, user *store.User, phone, text string
 
 		// Log actual token usage from API response
 		if resp.Usage != nil {
-			log.Printf("agent: tokens for %s — prompt=%d completion=%d total=%d",
-				phone, resp.Usage.PromptTokens, resp.Usage.CompletionTokens, resp.Usage.TotalTokens)
+			logger.Info("agent: token usage",
+				"prompt_tokens", resp.Usage.PromptTokens, "completion_tokens", resp.Usage.CompletionTokens, "tokens", resp.Usage.TotalTokens)
 		}
+		a.recordUsage(ctx, phone, resp.Usage)
 
 		if len(resp.Choices) == 0 {
 			return &Response{Text: "Não recebi resposta do sistema de IA. Tente novamente em alguns segundos."}, nil
@@ -237,8 +464,18 @@ func (a *Agent) Handle(ctx context.Context, user *store.User, phone, text string
 			if responseText == "" {
 				responseText = "Não consegui formular uma resposta. Pode repetir ou reformular sua pergunta?"
 			}
-			a.saveHistory(phone, allTurns)
-			return &Response{Text: responseText}, nil
+			a.saveHistory(ctx, phone, allTurns)
+			r := &Response{Text: responseText, Images: conv.attachedImages(), Documents: conv.attachedDocuments()}
+			if listedTickets {
+				r.Buttons = ticketFilterChips
+			}
+			if a.shouldWarnLongConversation(phone, history) {
+				r.Text += "\n\n_💡 Nossa conversa está ficando longa. Se for um assunto novo, toque em *Novo assunto* ou envie \"novo assunto\" para começarmos do zero._"
+				if len(r.Buttons) == 0 {
+					r.Buttons = []ButtonOption{{ID: NewTopicReplyID, Title: "Novo assunto"}}
+				}
+			}
+			return r, nil
 		}
 
 		// Check for respond_interactive first (returns immediately)
@@ -246,7 +483,7 @@ func (a *Agent) Handle(ctx context.Context, user *store.User, phone, text string
 			if tc.Function.Name == "respond_interactive" {
 				var args map[string]any
 				if err := json.Unmarshal([]byte(tc.Function.Arguments), &args); err != nil {
-					log.Printf("agent: invalid JSON from respond_interactive for %s: %v", phone, err)
+					logger.Warn("agent: invalid JSON from respond_interactive", "error", err)
 					args = map[string]any{"text": "Desculpe, houve um erro ao montar a resposta. Tente novamente."}
 				}
 				r := parseInteractiveResponse(args)
@@ -260,30 +497,34 @@ func (a *Agent) Handle(ctx context.Context, user *store.User, phone, text string
 						},
 					}},
 				})
-				a.saveHistory(phone, allTurns)
+				a.saveHistory(ctx, phone, allTurns)
+				r.Images = conv.attachedImages()
+				r.Documents = conv.attachedDocuments()
 				return r, nil
 			}
 		}
 
 		// Doom loop checks before executing tools
+		var nudges []string
 		for _, tc := range msg.ToolCalls {
-			sig := tc.Function.Name + ":" + tc.Function.Arguments
-			if sig == lastToolSig {
-				sameExactCount++
-			} else {
-				lastToolSig = sig
-				sameExactCount = 1
+			if tc.Function.Name == "list_my_tickets" {
+				listedTickets = true
 			}
-			toolNameCounts[tc.Function.Name]++
 
-			if sameExactCount > doomLoopExactThreshold || toolNameCounts[tc.Function.Name] > doomLoopNameThreshold {
-				log.Printf("agent: doom loop detected for tool %s (exact=%d, name=%d) (%s)",
-					tc.Function.Name, sameExactCount, toolNameCounts[tc.Function.Name], phone)
-				a.saveHistory(phone, allTurns)
+			abort, nudge := loops.observe(tc.Function.Name, tc.Function.Arguments)
+			if abort {
+				logger.Warn("agent: doom loop detected", "tool", tc.Function.Name, "exact_count", loops.exactCount)
+				a.saveHistory(ctx, phone, allTurns)
 				return &Response{Text: fmt.Sprintf("A ferramenta %s travou em um loop. Tente reformular seu pedido ou dividir em perguntas menores.", tc.Function.Name)}, nil
 			}
+			if nudge != "" {
+				logger.Info("agent: repeated tool, nudging model", "tool", tc.Function.Name, "name_count", loops.nameCounts[tc.Function.Name])
+				nudges = append(nudges, nudge)
+			}
 		}
 
+		sendInterim(ctx, msg)
+
 		// Execute tools — parallel if all are read-only, sequential otherwise
 		allReadOnly := true
 		for _, tc := range msg.ToolCalls {
@@ -302,39 +543,39 @@ func (a *Agent) Handle(ctx context.Context, user *store.User, phone, text string
 			}
 			results := make([]toolResult, len(msg.ToolCalls))
 			var wg sync.WaitGroup
+			sem := make(chan struct{}, a.maxParallel)
 			for i, tc := range msg.ToolCalls {
 				wg.Add(1)
 				go func(i int, tc toolCall) {
 					defer wg.Done()
+					sem <- struct{}{}
+					defer func() { <-sem }()
 					var args map[string]any
 					if err := json.Unmarshal([]byte(tc.Function.Arguments), &args); err != nil {
-						log.Printf("agent: invalid JSON args for %s: %v", tc.Function.Name, err)
+						logger.Warn("agent: invalid JSON args", "tool", tc.Function.Name, "error", err)
 						results[i] = toolResult{idx: i, tc: tc, result: map[string]any{
 							"status": "error",
 							"error":  map[string]any{"type": string(ErrValidation), "message": fmt.Sprintf("Argumentos inválidos para %s. Verifique e tente novamente.", tc.Function.Name)},
 						}}
 						return
 					}
-					log.Printf("agent: calling tool %s (parallel) for %s", tc.Function.Name, phone)
-					result, toolErr := registry.ExecuteTool(ctx, tc.Function.Name, args)
-					if toolErr != nil {
-						te := ClassifyError(toolErr)
-						// Retry once if retryable
-						if te.Retryable {
-							log.Printf("agent: retrying tool %s for %s after error: %v", tc.Function.Name, phone, te.RawError)
-							time.Sleep(2 * time.Second)
-							result, toolErr = registry.ExecuteTool(ctx, tc.Function.Name, args)
-							if toolErr != nil {
-								te = ClassifyError(toolErr)
-							}
-						}
-						if toolErr != nil {
-							result = map[string]any{
-								"status": "error",
-								"error":  map[string]any{"type": string(te.Type), "message": te.Message},
-							}
+					var result map[string]any
+					if a.preview(ctx, registry, tc.Function.Name) {
+						logger.Info("agent: dry run, skipping side-effecting tool", "tool", tc.Function.Name)
+						result = dryRunPreview(tc.Function.Name, args)
+					} else {
+						logger.Info("agent: calling tool", "tool", tc.Function.Name, "parallel", true)
+						var te *ToolError
+						result, te = a.executeWithRetry(ctx, registry, tc.Function.Name, args)
+						if te != nil {
+							result = toolErrorResult(te)
+						} else {
+							a.trackTicket(ctx, phone, tc.Function.Name, args, result)
 						}
 					}
+					if trace := dryRunTrace(ctx); trace != nil {
+						trace.record(tc.Function.Name, args, result)
+					}
 					results[i] = toolResult{idx: i, tc: tc, result: result}
 				}(i, tc)
 			}
@@ -344,8 +585,8 @@ func (a *Agent) Handle(ctx context.Context, user *store.User, phone, text string
 			for _, r := range results {
 				if errMap, ok := r.result["error"].(map[string]any); ok {
 					if errMap["type"] == string(ErrAuth) {
-						log.Printf("agent: auth error in parallel tool %s for %s", r.tc.Function.Name, phone)
-						a.saveHistory(phone, allTurns)
+						logger.Warn("agent: auth error in tool", "tool", r.tc.Function.Name)
+						a.saveHistory(ctx, phone, allTurns)
 						return nil, fmt.Errorf("auth_error: %v", errMap["message"])
 					}
 				}
@@ -370,7 +611,7 @@ func (a *Agent) Handle(ctx context.Context, user *store.User, phone, text string
 			for _, tc := range msg.ToolCalls {
 				var args map[string]any
 				if err := json.Unmarshal([]byte(tc.Function.Arguments), &args); err != nil {
-					log.Printf("agent: invalid JSON args for %s: %v", tc.Function.Name, err)
+					logger.Warn("agent: invalid JSON args", "tool", tc.Function.Name, "error", err)
 					errResult := map[string]any{
 						"status": "error",
 						"error":  map[string]any{"type": string(ErrValidation), "message": fmt.Sprintf("Argumentos inválidos para %s. Verifique e tente novamente.", tc.Function.Name)},
@@ -390,30 +631,40 @@ func (a *Agent) Handle(ctx context.Context, user *store.User, phone, text string
 					continue
 				}
 
-				log.Printf("agent: calling tool %s for %s", tc.Function.Name, phone)
-				result, toolErr := registry.ExecuteTool(ctx, tc.Function.Name, args)
-				if toolErr != nil {
-					te := ClassifyError(toolErr)
-					// Retry once if retryable
-					if te.Retryable {
-						log.Printf("agent: retrying tool %s for %s after error: %v", tc.Function.Name, phone, te.RawError)
-						time.Sleep(2 * time.Second)
-						result, toolErr = registry.ExecuteTool(ctx, tc.Function.Name, args)
-						if toolErr != nil {
-							te = ClassifyError(toolErr)
-						}
+				var result map[string]any
+				// Checked first so confirmation and dry run don't ask about a
+				// call that can't run: unknown, or not allowed for the profile.
+				te := registry.check(tc.Function.Name)
+				readOnly := registry.IsReadOnly(tc.Function.Name)
+				switch {
+				case te != nil:
+					logger.Warn("agent: tool unavailable", "tool", tc.Function.Name, "error_type", string(te.Type))
+				case a.confirm.requires(tc.Function.Name, readOnly) && !confirmed.covers(tc.Function.Name, args):
+					logger.Warn("agent: blocked unconfirmed tool", "tool", tc.Function.Name, "confirm_level", string(a.confirm))
+					result = confirmationRequiredResult(tc.Function.Name, args)
+				case a.preview(ctx, registry, tc.Function.Name):
+					logger.Info("agent: dry run, skipping tool", "tool", tc.Function.Name)
+					result = dryRunPreview(tc.Function.Name, args)
+				default:
+					logger.Info("agent: calling tool", "tool", tc.Function.Name)
+					result, te = a.executeWithRetry(ctx, registry, tc.Function.Name, args)
+					if !readOnly && tc.Function.Name != retryLastActionName {
+						a.recordAction(phone, tc.Function.Name, args, te)
 					}
-					if toolErr != nil {
-						if te.Type == ErrAuth {
-							log.Printf("agent: auth error in tool %s for %s", tc.Function.Name, phone)
-							a.saveHistory(phone, allTurns)
-							return nil, fmt.Errorf("auth_error: %s", te.RawError)
-						}
-						result = map[string]any{
-							"status": "error",
-							"error":  map[string]any{"type": string(te.Type), "message": te.Message},
-						}
+					if te == nil {
+						a.trackTicket(ctx, phone, tc.Function.Name, args, result)
+					}
+				}
+				if te != nil {
+					if te.Type == ErrAuth {
+						logger.Warn("agent: auth error in tool", "tool", tc.Function.Name)
+						a.saveHistory(ctx, phone, allTurns)
+						return nil, fmt.Errorf("auth_error: %s", te.RawError)
 					}
+					result = toolErrorResult(te)
+				}
+				if trace := dryRunTrace(ctx); trace != nil {
+					trace.record(tc.Function.Name, args, result)
 				}
 
 				resultJSON, _ := json.Marshal(result)
@@ -430,9 +681,15 @@ func (a *Agent) Handle(ctx context.Context, user *store.User, phone, text string
 				})
 			}
 		}
+
+		// Nudges go after the tool results (tool messages must directly follow
+		// their call) and are never persisted: they only steer the next iteration.
+		for _, n := range nudges {
+			messages = append(messages, chatMessage{Role: "system", Content: n})
+		}
 	}
 
-	a.saveHistory(phone, allTurns)
+	a.saveHistory(ctx, phone, allTurns)
 	return &Response{Text: "Sua solicitação precisou de muitas etapas. Tente dividir em perguntas menores."}, nil
 }
 
@@ -502,7 +759,68 @@ func retryableStatus(code int) bool {
 	return code == 429 || code == 500 || code == 502 || code == 503
 }
 
-func (a *Agent) chatCompletion(ctx context.Context, messages []chatMessage, tools []any) (*chatResponse, error) {
+// initUserSession opens a GLPI session with whichever credential the user linked.
+// OAuth users get their (possibly rotated) refresh token persisted.
+func (a *Agent) initUserSession(user *store.User) (string, error) {
+	if user.OAuthRefreshToken == "" {
+		sessionToken, err := a.glpi.InitSession(user.UserToken)
+		if errors.Is(err, glpi.ErrInvalidCredentials) {
+			return "", fmt.Errorf("auth_error: %w", err)
+		}
+		if err != nil {
+			return "", fmt.Errorf("initSession: %w", err)
+		}
+		a.resetAuthFailures(user.Phone)
+		return sessionToken, nil
+	}
+
+	sessionToken, refreshToken, err := a.glpi.InitSessionOAuth(user.OAuthRefreshToken)
+	if err != nil {
+		if errors.Is(err, glpi.ErrOAuthGrant) || errors.Is(err, glpi.ErrInvalidCredentials) {
+			return "", fmt.Errorf("auth_error: %w", err)
+		}
+		return "", fmt.Errorf("initSession: %w", err)
+	}
+	if refreshToken != user.OAuthRefreshToken {
+		user.OAuthRefreshToken = refreshToken
+		if err := a.store.SaveUser(*user); err != nil {
+			slog.Error("agent: failed to save rotated refresh token", "error", err)
+		}
+	}
+	a.resetAuthFailures(user.Phone)
+	return sessionToken, nil
+}
+
+// resetAuthFailures clears the failure count once a linked credential has
+// actually opened a session. Linking alone proves nothing: a disabled account
+// can still be linked and then fail on its first message.
+func (a *Agent) resetAuthFailures(phone string) {
+	if err := a.store.ResetAuthFailures(phone); err != nil {
+		slog.Warn("agent: failed to reset auth failures", "error", err)
+	}
+}
+
+// killSession ends the user's session at the end of a message. KillSession
+// already ignores sessions GLPI dropped (e.g. after an auth error), so what is
+// logged here is a real failure, such as GLPI being unreachable.
+func (a *Agent) killSession(ctx context.Context, sessionToken string) {
+	if err := a.glpi.KillSession(sessionToken); err != nil {
+		logging.FromContext(ctx).Warn("agent: failed to kill GLPI session", "error", err)
+	}
+}
+
+// openAIClient calls the chat completions API, retrying transient failures.
+type openAIClient struct {
+	apiKey   string
+	endpoint string
+	http     *http.Client
+}
+
+func newOpenAIClient(apiKey string) *openAIClient {
+	return &openAIClient{apiKey: apiKey, endpoint: openAIEndpoint, http: &http.Client{Timeout: 60 * time.Second}}
+}
+
+func (c *openAIClient) chatCompletion(ctx context.Context, messages []chatMessage, tools []any) (*chatResponse, error) {
 	reqBody := chatRequest{
 		Model:       openAIModel,
 		Messages:    messages,
@@ -522,18 +840,18 @@ func (a *Agent) chatCompletion(ctx context.Context, messages []chatMessage, tool
 	var lastErr error
 
 	for attempt := range retryMaxAttempts {
-		req, err := http.NewRequestWithContext(ctx, "POST", openAIEndpoint, bytes.NewReader(body))
+		req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint, bytes.NewReader(body))
 		if err != nil {
 			return nil, err
 		}
 		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Authorization", "Bearer "+a.apiKey)
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
 
-		resp, err := a.http.Do(req)
+		resp, err := c.http.Do(req)
 		if err != nil {
 			lastErr = err
 			if attempt < retryMaxAttempts-1 {
-				log.Printf("agent: request error (attempt %d/%d): %v", attempt+1, retryMaxAttempts, err)
+				logging.FromContext(ctx).Warn("agent: openai request error", "attempt", attempt+1, "max_attempts", retryMaxAttempts, "error", err)
 				time.Sleep(delay)
 				delay = min(delay*2, retryMaxDelay)
 				continue
@@ -549,7 +867,7 @@ func (a *Agent) chatCompletion(ctx context.Context, messages []chatMessage, tool
 
 		if retryableStatus(resp.StatusCode) && attempt < retryMaxAttempts-1 {
 			lastErr = fmt.Errorf("openai: status %d: %s", resp.StatusCode, string(respBody))
-			log.Printf("agent: retryable error (attempt %d/%d): %v", attempt+1, retryMaxAttempts, lastErr)
+			logging.FromContext(ctx).Warn("agent: openai retryable error", "attempt", attempt+1, "max_attempts", retryMaxAttempts, "error", lastErr)
 			time.Sleep(delay)
 			delay = min(delay*2, retryMaxDelay)
 			continue
@@ -570,14 +888,18 @@ func (a *Agent) chatCompletion(ctx context.Context, messages []chatMessage, tool
 	return nil, fmt.Errorf("openai: max retries exceeded: %w", lastErr)
 }
 
+// allowRequest counts a request against phone's rate limit. Like the other
+// per-phone maps it's keyed by the normalized number, so both spellings Meta
+// may send share one bucket.
 func (a *Agent) allowRequest(phone string) bool {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	key := store.NormalizePhone(phone)
 	now := time.Now()
-	b, ok := a.counters[phone]
+	b, ok := a.counters[key]
 	if !ok || now.Sub(b.window) > rateLimitWindow {
-		a.counters[phone] = &rateBucket{count: 1, window: now}
+		a.counters[key] = &rateBucket{count: 1, window: now}
 		return true
 	}
 	if b.count >= rateLimitMax {
@@ -587,18 +909,13 @@ func (a *Agent) allowRequest(phone string) bool {
 	return true
 }
 
-func (a *Agent) saveHistory(phone string, turns []store.ConversationTurn) {
-	if err := a.store.SaveHistory(phone, turns); err != nil {
-		log.Printf("agent: failed to save history for %s: %v", phone, err)
-	}
-}
-
 // --- conversion helpers ---
 
 // toOpenAIMessages converts stored conversation turns to OpenAI chat messages.
 // Drops incompatible old Gemini-format history (role "model").
-// Compresses old tool responses to save tokens (keeps only recent ones full).
-func toOpenAIMessages(turns []store.ConversationTurn) []chatMessage {
+// Compresses old tool responses to save tokens (keeps only recent ones and an
+// unfinished create flow full, see store.KeepFullFrom).
+func toOpenAIMessages(turns []store.ConversationTurn, keepRecent int) []chatMessage {
 	for _, t := range turns {
 		if t.Role == "model" {
 			return nil
@@ -611,9 +928,10 @@ func toOpenAIMessages(turns []store.ConversationTurn) []chatMessage {
 		start++
 	}
 
+	keepFrom := store.KeepFullFrom(turns, keepRecent)
 	var messages []chatMessage
 	for i, t := range turns[start:] {
-		turnsFromEnd := len(turns) - start - i
+		keepFull := start+i >= keepFrom
 
 		switch t.Role {
 		case "user":
@@ -647,7 +965,7 @@ func toOpenAIMessages(turns []store.ConversationTurn) []chatMessage {
 					continue
 				}
 				content := ""
-				if turnsFromEnd <= pruneKeepRecent {
+				if keepFull {
 					// Recent: keep full response
 					resultJSON, _ := json.Marshal(p.FunctionResponse.Response)
 					content = string(resultJSON)
@@ -741,7 +1059,7 @@ func messageToTurn(msg chatMessage) store.ConversationTurn {
 	for _, tc := range msg.ToolCalls {
 		var args map[string]any
 		if err := json.Unmarshal([]byte(tc.Function.Arguments), &args); err != nil {
-			log.Printf("agent: invalid JSON in tool call %s args: %v", tc.Function.Name, err)
+			slog.Warn("agent: invalid JSON in tool call args", "tool", tc.Function.Name, "error", err)
 			args = map[string]any{}
 		}
 		turn.Parts = append(turn.Parts, store.TurnPart{
@@ -768,8 +1086,8 @@ func estimateMessagesTokens(messages []chatMessage) int {
 }
 
 // pruneMessages drops oldest non-system turns until under budget.
-func pruneMessages(messages []chatMessage, userName string, userID int) []chatMessage {
-	for estimateMessagesTokens(messages) > maxMessageTokenBudget && len(messages) > 2 {
+func pruneMessages(messages []chatMessage, budget int) []chatMessage {
+	for estimateMessagesTokens(messages) > budget && len(messages) > 2 {
 		// Drop the first non-system message
 		if len(messages) > 1 {
 			messages = append(messages[:1], messages[2:]...)
@@ -782,6 +1100,49 @@ func pruneMessages(messages []chatMessage, userName string, userID int) []chatMe
 	return messages
 }
 
+// repairToolCalls makes the history acceptable to OpenAI, which rejects with 400
+// any assistant tool_calls not followed by a tool message for each ID, and any
+// tool message that doesn't answer a preceding call. History trimming and
+// pruning can produce both. Missing results get a {"status":"dropped"}
+// placeholder; stray results are removed. Returns how many fixes were made.
+func repairToolCalls(messages []chatMessage) ([]chatMessage, int) {
+	repaired := 0
+	out := make([]chatMessage, 0, len(messages))
+	for i := 0; i < len(messages); i++ {
+		m := messages[i]
+		if m.Role == "tool" {
+			// Tool results are consumed right after their assistant message below
+			repaired++
+			continue
+		}
+		out = append(out, m)
+		if m.Role != "assistant" || len(m.ToolCalls) == 0 {
+			continue
+		}
+
+		pending := make(map[string]bool, len(m.ToolCalls))
+		for _, tc := range m.ToolCalls {
+			pending[tc.ID] = true
+		}
+		for i+1 < len(messages) && messages[i+1].Role == "tool" {
+			i++
+			if !pending[messages[i].ToolCallID] {
+				repaired++
+				continue
+			}
+			delete(pending, messages[i].ToolCallID)
+			out = append(out, messages[i])
+		}
+		for _, tc := range m.ToolCalls {
+			if pending[tc.ID] {
+				out = append(out, chatMessage{Role: "tool", Content: `{"status":"dropped"}`, ToolCallID: tc.ID})
+				repaired++
+			}
+		}
+	}
+	return out, repaired
+}
+
 // rebuildTurns converts pruned messages back to conversation turns (drops system message).
 func rebuildTurns(messages []chatMessage) []store.ConversationTurn {
 	var turns []store.ConversationTurn
@@ -0,0 +1,215 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lojasmm/laia/internal/glpi"
+	"github.com/lojasmm/laia/internal/store"
+)
+
+func TestInitUserSessionResetsAuthFailures(t *testing.T) {
+	valid := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if !valid {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`["ERROR_GLPI_LOGIN_USER_TOKEN", "parâmetro user_token inválido"]`))
+			return
+		}
+		w.Write([]byte(`{"session_token": "s1"}`))
+	}))
+	defer srv.Close()
+
+	s, err := store.NewBoltStore(filepath.Join(t.TempDir(), "laia.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	a := NewAgent("key", glpi.NewClient(srv.URL, "app", "", 0, glpi.Timeouts{}), s, nil)
+	user := &store.User{Phone: "5511987654321", UserToken: "revoked"}
+
+	for range 2 {
+		if _, err := s.RecordAuthFailure(user.Phone); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A rejected token is an auth error and leaves the count alone, so the
+	// bot can escalate on the next failure.
+	if _, err := a.initUserSession(user); err == nil || !strings.Contains(err.Error(), "auth_error") {
+		t.Fatalf("initUserSession = %v, want auth_error", err)
+	}
+	if n, _ := s.RecordAuthFailure(user.Phone); n != 3 {
+		t.Errorf("failures after rejected session = %d, want 3", n)
+	}
+
+	valid = true
+	if _, err := a.initUserSession(user); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := s.RecordAuthFailure(user.Phone); n != 1 {
+		t.Errorf("failures after working session = %d, want the count restarted at 1", n)
+	}
+}
+
+func TestRepairToolCalls(t *testing.T) {
+	call := func(ids ...string) chatMessage {
+		m := chatMessage{Role: "assistant"}
+		for _, id := range ids {
+			m.ToolCalls = append(m.ToolCalls, toolCall{ID: id, Type: "function", Function: functionCall{Name: "get_ticket"}})
+		}
+		return m
+	}
+	result := func(id string) chatMessage { return chatMessage{Role: "tool", Content: `{"id":1}`, ToolCallID: id} }
+	sys := chatMessage{Role: "system", Content: "prompt"}
+	user := chatMessage{Role: "user", Content: "oi"}
+	dropped := func(id string) chatMessage {
+		return chatMessage{Role: "tool", Content: `{"status":"dropped"}`, ToolCallID: id}
+	}
+
+	tests := []struct {
+		name      string
+		in        []chatMessage
+		want      []chatMessage
+		wantFixes int
+	}{
+		{
+			name: "complete history untouched",
+			in:   []chatMessage{sys, user, call("a", "b"), result("a"), result("b"), {Role: "assistant", Content: "pronto"}},
+			want: []chatMessage{sys, user, call("a", "b"), result("a"), result("b"), {Role: "assistant", Content: "pronto"}},
+		},
+		{
+			name:      "call whose results were pruned",
+			in:        []chatMessage{sys, user, call("a"), user},
+			want:      []chatMessage{sys, user, call("a"), dropped("a"), user},
+			wantFixes: 1,
+		},
+		{
+			name:      "one of two results missing",
+			in:        []chatMessage{sys, call("a", "b"), result("b")},
+			want:      []chatMessage{sys, call("a", "b"), result("b"), dropped("a")},
+			wantFixes: 1,
+		},
+		{
+			name:      "result whose call was pruned",
+			in:        []chatMessage{sys, result("x"), user},
+			want:      []chatMessage{sys, user},
+			wantFixes: 1,
+		},
+		{
+			name:      "result answering another call",
+			in:        []chatMessage{sys, call("a"), result("x")},
+			want:      []chatMessage{sys, call("a"), dropped("a")},
+			wantFixes: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, fixes := repairToolCalls(tt.in)
+			if fixes != tt.wantFixes {
+				t.Errorf("fixes = %d, want %d", fixes, tt.wantFixes)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d messages, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i := range got {
+				if got[i].Role != tt.want[i].Role || got[i].ToolCallID != tt.want[i].ToolCallID || got[i].Content != tt.want[i].Content {
+					t.Errorf("message %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// flakyTool fails with errs, one per call, then succeeds.
+type flakyTool struct {
+	fakeTool
+	errs  []error
+	calls int
+}
+
+func (t *flakyTool) Execute(context.Context, map[string]any) (map[string]any, error) {
+	t.calls++
+	if t.calls <= len(t.errs) {
+		return nil, t.errs[t.calls-1]
+	}
+	return map[string]any{"ok": true}, nil
+}
+
+func TestExecuteWithRetry(t *testing.T) {
+	timeout := errors.New("context deadline exceeded")
+	server := errors.New("getTicket status 502: bad gateway")
+	tests := []struct {
+		name      string
+		readOnly  bool
+		errs      []error
+		policy    ToolRetryPolicy
+		wantCalls int
+		wantType  ErrorType // "" for success
+	}{
+		{"success", true, nil, ToolRetryPolicy{MaxRetries: 1}, 1, ""},
+		{"read retried once", true, []error{timeout}, ToolRetryPolicy{MaxRetries: 1}, 2, ""},
+		{"read out of retries", true, []error{timeout, timeout, timeout}, ToolRetryPolicy{MaxRetries: 2}, 3, ErrTimeout},
+		{"retries disabled", true, []error{timeout}, ToolRetryPolicy{}, 1, ErrTimeout},
+		{"not found not retried", true, []error{errors.New("status 404: item not found")}, ToolRetryPolicy{MaxRetries: 3}, 1, ErrNotFound},
+		{"auth error aborts", true, []error{errors.New("status 401: ERROR_SESSION_TOKEN_INVALID")}, ToolRetryPolicy{MaxRetries: 3}, 1, ErrAuth},
+		{"non-retryable substring", true, []error{errors.New("status 500: SQL syntax error")}, ToolRetryPolicy{MaxRetries: 3, NonRetryable: []string{"SQL"}}, 1, ErrServer},
+		{"write not retried on 5xx", false, []error{server}, ToolRetryPolicy{MaxRetries: 3}, 1, ErrServer},
+		{"write retried on rate limit", false, []error{errors.New("status 429: too many requests")}, ToolRetryPolicy{MaxRetries: 1}, 2, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := &flakyTool{fakeTool: fakeTool{name: "tool", readOnly: tt.readOnly}, errs: tt.errs}
+			r := NewRegistry()
+			r.Register(tool)
+			a := &Agent{retry: tt.policy}
+
+			_, te := a.executeWithRetry(context.Background(), r, "tool", nil)
+			if tool.calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", tool.calls, tt.wantCalls)
+			}
+			var got ErrorType
+			if te != nil {
+				got = te.Type
+			}
+			if got != tt.wantType {
+				t.Errorf("error type = %q, want %q", got, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestExecuteWithRetryWriteMayHaveApplied(t *testing.T) {
+	tool := &flakyTool{fakeTool: fakeTool{name: "update_ticket"}, errs: []error{errors.New("status 503: unavailable")}}
+	r := NewRegistry()
+	r.Register(tool)
+	a := &Agent{retry: ToolRetryPolicy{MaxRetries: 2}}
+
+	_, te := a.executeWithRetry(context.Background(), r, "update_ticket", nil)
+	if te == nil || !strings.Contains(te.Message, "pode ter sido aplicada") {
+		t.Errorf("error = %+v, want the may-have-applied message", te)
+	}
+}
+
+func TestExecuteWithRetryStopsOnCancel(t *testing.T) {
+	tool := &flakyTool{fakeTool: fakeTool{name: "tool", readOnly: true}, errs: []error{errors.New("context deadline exceeded")}}
+	r := NewRegistry()
+	r.Register(tool)
+	a := &Agent{retry: ToolRetryPolicy{MaxRetries: 1, Backoff: time.Hour}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, te := a.executeWithRetry(ctx, r, "tool", nil); te == nil {
+		t.Error("no error after cancellation")
+	}
+	if tool.calls != 1 {
+		t.Errorf("calls = %d, want 1: the backoff must not outlive the request", tool.calls)
+	}
+}
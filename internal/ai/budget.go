@@ -0,0 +1,65 @@
+package ai
+
+import (
+	"context"
+	"time"
+
+	"github.com/lojasmm/laia/internal/logging"
+)
+
+// budgetLocation sets when the daily token budget resets: midnight in
+// Brasília, where the users are, rather than the server's UTC midnight.
+var budgetLocation = time.FixedZone("BRT", -3*60*60)
+
+const budgetExceededText = "Você atingiu o limite diário de uso do assistente, que volta ao normal amanhã. " +
+	"Enquanto isso, ainda consigo mostrar seus chamados pelos botões abaixo. " +
+	"Se for urgente, abra o chamado direto no Nexus."
+
+// SetDailyTokenBudget caps the model tokens (prompt + completion) one phone
+// may use per day; 0 disables the cap. A message that starts under the budget
+// always runs to the end, so the cap may be overrun by one message.
+func (a *Agent) SetDailyTokenBudget(tokens int) {
+	a.dailyTokens = tokens
+}
+
+func budgetDay(now time.Time) string {
+	return now.In(budgetLocation).Format(time.DateOnly)
+}
+
+// overBudget reports whether phone used up today's token budget. A store
+// error lets the message through: the budget caps spend, it isn't worth
+// failing a user's message over.
+func (a *Agent) overBudget(ctx context.Context, phone string) bool {
+	if a.dailyTokens <= 0 || dryRunTrace(ctx) != nil {
+		return false
+	}
+	used, err := a.store.TokenUsage(phone, budgetDay(time.Now()))
+	if err != nil {
+		logging.FromContext(ctx).Warn("agent: failed to read token usage", "error", err)
+		return false
+	}
+	return used >= a.dailyTokens
+}
+
+// recordUsage adds a completion's tokens to phone's count for today. It runs
+// even without a budget, so one set mid-day already counts the day's usage.
+func (a *Agent) recordUsage(ctx context.Context, phone string, usage *usageInfo) {
+	if usage == nil || dryRunTrace(ctx) != nil {
+		return
+	}
+	logger := logging.FromContext(ctx)
+	total, err := a.store.AddTokenUsage(phone, budgetDay(time.Now()), usage.TotalTokens)
+	if err != nil {
+		logger.Warn("agent: failed to record token usage", "error", err)
+		return
+	}
+	if a.dailyTokens > 0 && total >= a.dailyTokens && total-usage.TotalTokens < a.dailyTokens {
+		logger.Warn("agent: daily token budget reached", "tokens_today", total, "budget", a.dailyTokens)
+	}
+}
+
+// budgetExceededResponse is the fallback once the budget is spent: the status
+// chips still work, since HandleTicketFilter doesn't call the model.
+func budgetExceededResponse() *Response {
+	return &Response{Text: budgetExceededText, Buttons: ticketFilterChips}
+}
@@ -0,0 +1,99 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lojasmm/laia/internal/store"
+)
+
+// ticketFilterPrefix marks button replies that refine "meus chamados" by status.
+// The suffix is the list_my_tickets status value.
+const ticketFilterPrefix = "tickets_status:"
+
+// maxChipListedTickets keeps the deterministic listing within a WhatsApp message.
+const maxChipListedTickets = 10
+
+var ticketFilterChips = []ButtonOption{
+	{ID: ticketFilterPrefix + "aberto", Title: "Abertos"},
+	{ID: ticketFilterPrefix + "pendente", Title: "Pendentes"},
+	{ID: ticketFilterPrefix + "todos", Title: "Todos"},
+}
+
+// TicketFilterFromReply returns the status filter carried by a chip's reply ID.
+func TicketFilterFromReply(replyID string) (string, bool) {
+	status, ok := strings.CutPrefix(replyID, ticketFilterPrefix)
+	if !ok {
+		return "", false
+	}
+	for _, c := range ticketFilterChips {
+		if c.ID == replyID {
+			return status, true
+		}
+	}
+	return "", false
+}
+
+// HandleTicketFilter answers a status chip by re-running list_my_tickets
+// directly — the choice is unambiguous, so there's no need to go through the model.
+// Both sides are recorded in history so follow-up questions have context.
+func (a *Agent) HandleTicketFilter(ctx context.Context, user *store.User, phone, status string) (*Response, error) {
+	sessionToken, err := a.initUserSession(user)
+	if err != nil {
+		return nil, err
+	}
+	defer a.killSession(ctx, sessionToken)
+
+	history := a.loadHistory(ctx, phone)
+	registry := a.buildReg(a.glpi, sessionToken, user.GLPIUserID, NewConversation(phone, &history))
+
+	result, err := registry.ExecuteTool(ctx, "list_my_tickets", map[string]any{"status": status})
+	if err != nil {
+		return nil, fmt.Errorf("list_my_tickets: %w", err)
+	}
+
+	text := formatTicketList(status, result)
+	history = append(history,
+		store.ConversationTurn{Role: "user", Parts: []store.TurnPart{{Text: "Mostrar meus chamados: " + status}}},
+		store.ConversationTurn{Role: "assistant", Parts: []store.TurnPart{{Text: text}}},
+	)
+	a.saveHistory(ctx, phone, history)
+
+	return &Response{Text: text, Buttons: ticketFilterChips}, nil
+}
+
+func formatTicketList(status string, result map[string]any) string {
+	tickets, _ := result["chamados"].([]map[string]any)
+	label := "seus chamados"
+	switch status {
+	case "aberto":
+		label = "seus chamados abertos"
+	case "pendente":
+		label = "seus chamados pendentes"
+	}
+	if len(tickets) == 0 {
+		return fmt.Sprintf("Não encontrei %s.", label)
+	}
+
+	// total counts every match, even when the registry truncated the list
+	total, ok := result["total"].(int)
+	if !ok {
+		total = len(tickets)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "📋 *%s* (%d):\n", strings.ToUpper(label[:1])+label[1:], total)
+	shown := 0
+	for _, tk := range tickets {
+		if shown == maxChipListedTickets {
+			break
+		}
+		fmt.Fprintf(&b, "\n• *#%v* — %v (_%v_)", tk["id"], tk["nome"], tk["status"])
+		shown++
+	}
+	if total > shown {
+		fmt.Fprintf(&b, "\n\n_…e mais %d._", total-shown)
+	}
+	return b.String()
+}
@@ -0,0 +1,32 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+)
+
+// Completer runs one-off prompts outside the conversation loop, for tools that
+// need the model themselves (e.g. translating a ticket). Each call is billed
+// like a regular turn, so tools using it should be opt-in.
+type Completer struct {
+	llm *openAIClient
+}
+
+func NewCompleter(apiKey string) *Completer {
+	return &Completer{llm: newOpenAIClient(apiKey)}
+}
+
+// Complete sends a system instruction and a user prompt and returns the reply text.
+func (c *Completer) Complete(ctx context.Context, system, prompt string) (string, error) {
+	resp, err := c.llm.chatCompletion(ctx, []chatMessage{
+		{Role: "system", Content: system},
+		{Role: "user", Content: prompt},
+	}, nil)
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 || resp.Choices[0].Message.Content == "" {
+		return "", fmt.Errorf("openai: empty completion")
+	}
+	return resp.Choices[0].Message.Content, nil
+}
@@ -0,0 +1,141 @@
+package ai
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/lojasmm/laia/internal/store"
+)
+
+// ConfirmLevel selects which mutating tools the agent refuses to run until the
+// user has confirmed. The prompt already asks for confirmation; this enforces
+// it in code for deployments that can't rely on the model alone. The tools in
+// alwaysConfirm are enforced at every level, including none.
+type ConfirmLevel string
+
+const (
+	ConfirmNone   ConfirmLevel = "none"   // trust the prompt
+	ConfirmCreate ConfirmLevel = "create" // only create_ticket
+	ConfirmAll    ConfirmLevel = "all"    // every mutating tool
+)
+
+// ParseConfirmLevel accepts the CONFIRM_LEVEL values; empty means none.
+func ParseConfirmLevel(s string) (ConfirmLevel, error) {
+	switch l := ConfirmLevel(strings.ToLower(strings.TrimSpace(s))); l {
+	case "":
+		return ConfirmNone, nil
+	case ConfirmNone, ConfirmCreate, ConfirmAll:
+		return l, nil
+	default:
+		return "", fmt.Errorf("invalid confirm level %q (use none, create or all)", s)
+	}
+}
+
+func (a *Agent) SetConfirmLevel(l ConfirmLevel) {
+	a.confirm = l
+}
+
+// alwaysConfirm are confirmed regardless of CONFIRM_LEVEL: bulk_approve
+// answers many approvals at once, self_resolve_ticket solves a ticket without
+// the technician and close_and_rate closes it for good, so they are enforced
+// even when the prompt is otherwise trusted.
+var alwaysConfirm = map[string]bool{
+	"bulk_approve":        true,
+	"self_resolve_ticket": true,
+	"close_and_rate":      true,
+}
+
+// requires reports whether tool name needs a confirmed user turn at this level.
+func (l ConfirmLevel) requires(name string, readOnly bool) bool {
+	if alwaysConfirm[name] {
+		return true
+	}
+	switch l {
+	case ConfirmCreate:
+		return name == "create_ticket"
+	case ConfirmAll:
+		// retry_last_action only repeats a call that already passed this check.
+		return !readOnly && name != "respond_interactive" && name != retryLastActionName
+	default:
+		return false
+	}
+}
+
+// affirmativeWords start replies that confirm an action, typed or tapped
+// ("Confirmar" button). Matched against the first word only, so "não pode" fails.
+var affirmativeWords = map[string]bool{
+	"confirmar": true, "confirmo": true, "confirma": true, "confirmado": true,
+	"sim": true, "ok": true, "pode": true, "isso": true, "correto": true,
+	"certo": true, "perfeito": true, "beleza": true, "s": true,
+}
+
+// confirmation is the action a user agreed to: the confirm_tool and a hash of
+// the confirm_args of the respond_interactive prompt they answered.
+type confirmation struct {
+	tool     string
+	argsHash string
+}
+
+// covers reports whether the user confirmed exactly this call; a nil
+// confirmation covers nothing.
+func (c *confirmation) covers(name string, args map[string]any) bool {
+	return c != nil && c.tool == name && c.argsHash == hashArgs(args)
+}
+
+// hashArgs fingerprints tool arguments. json.Marshal sorts map keys, so the
+// same arguments hash alike however the model ordered them.
+func hashArgs(args map[string]any) string {
+	if args == nil {
+		args = map[string]any{}
+	}
+	data, _ := json.Marshal(args)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// userConfirmed returns what text confirms: the last assistant turn in history
+// must have been a respond_interactive prompt naming the action in
+// confirm_tool/confirm_args, and text must be affirmative. An answer to any
+// other menu confirms nothing.
+func userConfirmed(history []store.ConversationTurn, text string) *confirmation {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return r == ' ' || r == ',' || r == '.' || r == '!' || r == '\n'
+	})
+	if len(words) == 0 || !affirmativeWords[words[0]] {
+		return nil
+	}
+	for i := len(history) - 1; i >= 0; i-- {
+		turn := history[i]
+		if turn.Role != "assistant" {
+			continue
+		}
+		for _, p := range turn.Parts {
+			if p.FunctionCall == nil || p.FunctionCall.Name != "respond_interactive" {
+				continue
+			}
+			tool, _ := p.FunctionCall.Args["confirm_tool"].(string)
+			if tool == "" {
+				return nil
+			}
+			args, _ := p.FunctionCall.Args["confirm_args"].(map[string]any)
+			return &confirmation{tool: tool, argsHash: hashArgs(args)}
+		}
+		return nil
+	}
+	return nil
+}
+
+func confirmationRequiredResult(name string, args map[string]any) map[string]any {
+	return map[string]any{
+		"status": "error",
+		"error": map[string]any{
+			"type": string(ErrValidation),
+			"message": fmt.Sprintf("%s exige confirmação. Apresente o resumo com respond_interactive (botões Confirmar/Cancelar), "+
+				"com confirm_tool=%q e confirm_args iguais a confirm_args abaixo, e só execute após o usuário confirmar.", name, name),
+		},
+		"confirm_args": args,
+	}
+}
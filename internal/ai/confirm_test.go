@@ -0,0 +1,73 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/lojasmm/laia/internal/store"
+)
+
+func confirmTurn(tool string, args map[string]any) store.ConversationTurn {
+	call := map[string]any{"text": "Confirma?", "message_type": "buttons"}
+	if tool != "" {
+		call["confirm_tool"] = tool
+		call["confirm_args"] = args
+	}
+	return interactiveTurn(call)
+}
+
+func TestConfirmLevelRequires(t *testing.T) {
+	tests := []struct {
+		level    ConfirmLevel
+		tool     string
+		readOnly bool
+		want     bool
+	}{
+		{ConfirmNone, "create_ticket", false, false},
+		{ConfirmNone, "bulk_approve", false, true},
+		{ConfirmNone, "self_resolve_ticket", false, true},
+		{ConfirmNone, "close_and_rate", false, true},
+		{ConfirmCreate, "close_and_rate", false, true},
+		{ConfirmCreate, "create_ticket", false, true},
+		{ConfirmCreate, "update_ticket", false, false},
+		{ConfirmAll, "update_ticket", false, true},
+		{ConfirmAll, "get_ticket", true, false},
+		{ConfirmAll, "respond_interactive", false, false},
+		{ConfirmAll, retryLastActionName, false, false},
+	}
+	for _, tt := range tests {
+		if got := tt.level.requires(tt.tool, tt.readOnly); got != tt.want {
+			t.Errorf("%s.requires(%s) = %v, want %v", tt.level, tt.tool, got, tt.want)
+		}
+	}
+}
+
+func TestUserConfirmed(t *testing.T) {
+	// Args come back from stored history as decoded JSON, so numbers are float64.
+	args := map[string]any{"ticket_id": float64(123), "note": "resolvi"}
+	user := store.ConversationTurn{Role: "user", Parts: []store.TurnPart{{Text: "fecha o 123"}}}
+	text := store.ConversationTurn{Role: "assistant", Parts: []store.TurnPart{{Text: "Pronto"}}}
+
+	tests := []struct {
+		name    string
+		history []store.ConversationTurn
+		reply   string
+		tool    string
+		args    map[string]any
+		want    bool
+	}{
+		{"confirms the prompted call", []store.ConversationTurn{user, confirmTurn("self_resolve_ticket", args)}, "Confirmar", "self_resolve_ticket", args, true},
+		{"key order doesn't matter", []store.ConversationTurn{user, confirmTurn("self_resolve_ticket", args)}, "sim, pode", "self_resolve_ticket", map[string]any{"note": "resolvi", "ticket_id": float64(123)}, true},
+		{"different arguments", []store.ConversationTurn{user, confirmTurn("self_resolve_ticket", args)}, "sim", "self_resolve_ticket", map[string]any{"ticket_id": float64(124), "note": "resolvi"}, false},
+		{"different tool", []store.ConversationTurn{user, confirmTurn("self_resolve_ticket", args)}, "sim", "bulk_approve", args, false},
+		{"menu without action", []store.ConversationTurn{user, confirmTurn("", nil)}, "sim", "self_resolve_ticket", args, false},
+		{"negative reply", []store.ConversationTurn{user, confirmTurn("self_resolve_ticket", args)}, "não pode", "self_resolve_ticket", args, false},
+		{"prompt is not the last assistant turn", []store.ConversationTurn{confirmTurn("self_resolve_ticket", args), text}, "sim", "self_resolve_ticket", args, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := userConfirmed(tt.history, tt.reply).covers(tt.tool, tt.args); got != tt.want {
+				t.Errorf("confirmed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
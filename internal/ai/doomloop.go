@@ -0,0 +1,64 @@
+package ai
+
+import "fmt"
+
+// DoomLoopPolicy bounds repeated tool calls within one user message.
+// Repeating the exact same call (name + arguments) more than ExactThreshold
+// times in a row aborts the turn; calling the same tool more than
+// NameThreshold times only nudges the model, since fetching several tickets
+// one by one is legitimate.
+type DoomLoopPolicy struct {
+	ExactThreshold int
+	NameThreshold  int
+}
+
+func (p DoomLoopPolicy) withDefaults() DoomLoopPolicy {
+	if p.ExactThreshold <= 0 {
+		p.ExactThreshold = defaultDoomLoopExactThreshold
+	}
+	if p.NameThreshold <= 0 {
+		p.NameThreshold = defaultDoomLoopNameThreshold
+	}
+	return p
+}
+
+func (a *Agent) SetDoomLoopPolicy(p DoomLoopPolicy) {
+	a.doomLoop = p.withDefaults()
+}
+
+// loopDetector tracks tool calls across the iterations of one Handle call.
+type loopDetector struct {
+	policy     DoomLoopPolicy
+	lastSig    string
+	exactCount int
+	nameCounts map[string]int
+	nudged     map[string]bool
+}
+
+func newLoopDetector(p DoomLoopPolicy) *loopDetector {
+	return &loopDetector{policy: p, nameCounts: map[string]int{}, nudged: map[string]bool{}}
+}
+
+// observe records a call. abort is set for exact-duplicate loops; nudge is a
+// one-time system hint (per tool) once the tool has been called too often.
+func (d *loopDetector) observe(name, arguments string) (abort bool, nudge string) {
+	sig := name + ":" + arguments
+	if sig == d.lastSig {
+		d.exactCount++
+	} else {
+		d.lastSig = sig
+		d.exactCount = 1
+	}
+	d.nameCounts[name]++
+
+	if d.exactCount > d.policy.ExactThreshold {
+		return true, ""
+	}
+	if d.nameCounts[name] > d.policy.NameThreshold && !d.nudged[name] {
+		d.nudged[name] = true
+		return false, fmt.Sprintf("Você já chamou %s %d vezes nesta mensagem. Não repita a mesma abordagem: "+
+			"use uma ferramenta que traga tudo de uma vez (ex: get_tickets_batch), responda com o que já tem "+
+			"ou pergunte ao usuário.", name, d.nameCounts[name])
+	}
+	return false, ""
+}
@@ -0,0 +1,77 @@
+package ai
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLoopDetector(t *testing.T) {
+	type call struct{ name, args string }
+	tests := []struct {
+		name      string
+		calls     []call
+		wantAbort int // index of the aborting call, -1 for none
+		wantNudge []int
+	}{
+		{
+			name:      "exact repetition aborts",
+			calls:     []call{{"get_ticket", `{"id":1}`}, {"get_ticket", `{"id":1}`}, {"get_ticket", `{"id":1}`}},
+			wantAbort: 2,
+		},
+		{
+			name:      "fetching different tickets only nudges",
+			calls:     []call{{"get_ticket", `{"id":1}`}, {"get_ticket", `{"id":2}`}, {"get_ticket", `{"id":3}`}, {"get_ticket", `{"id":4}`}},
+			wantAbort: -1,
+			wantNudge: []int{3},
+		},
+		{
+			name:      "interleaved repetition is not exact",
+			calls:     []call{{"get_ticket", `{"id":1}`}, {"get_followups", `{"id":1}`}, {"get_ticket", `{"id":1}`}, {"get_followups", `{"id":1}`}},
+			wantAbort: -1,
+		},
+		{
+			name: "nudge fires once per tool",
+			calls: []call{
+				{"get_ticket", `{"id":1}`}, {"get_ticket", `{"id":2}`}, {"get_ticket", `{"id":3}`},
+				{"get_ticket", `{"id":4}`}, {"get_ticket", `{"id":5}`},
+				{"search_kb", `{"q":"a"}`}, {"search_kb", `{"q":"b"}`}, {"search_kb", `{"q":"c"}`}, {"search_kb", `{"q":"d"}`},
+			},
+			wantAbort: -1,
+			wantNudge: []int{3, 8},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newLoopDetector(DoomLoopPolicy{ExactThreshold: 2, NameThreshold: 3})
+			gotAbort := -1
+			var gotNudge []int
+			for i, c := range tt.calls {
+				abort, nudge := d.observe(c.name, c.args)
+				if abort {
+					gotAbort = i
+					break
+				}
+				if nudge != "" {
+					gotNudge = append(gotNudge, i)
+				}
+			}
+			if gotAbort != tt.wantAbort {
+				t.Errorf("aborted at call %d, want %d", gotAbort, tt.wantAbort)
+			}
+			if !slices.Equal(gotNudge, tt.wantNudge) {
+				t.Errorf("nudged at calls %v, want %v", gotNudge, tt.wantNudge)
+			}
+		})
+	}
+}
+
+func TestDoomLoopPolicyDefaults(t *testing.T) {
+	p := DoomLoopPolicy{}.withDefaults()
+	if p.ExactThreshold != defaultDoomLoopExactThreshold || p.NameThreshold != defaultDoomLoopNameThreshold {
+		t.Errorf("withDefaults() = %+v, want the package defaults", p)
+	}
+	p = DoomLoopPolicy{ExactThreshold: 5, NameThreshold: 9}.withDefaults()
+	if p.ExactThreshold != 5 || p.NameThreshold != 9 {
+		t.Errorf("withDefaults() = %+v, want explicit values kept", p)
+	}
+}
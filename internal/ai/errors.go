@@ -1,6 +1,11 @@
 package ai
 
-import "strings"
+import (
+	"errors"
+	"strings"
+
+	"github.com/lojasmm/laia/internal/glpi"
+)
 
 // ErrorType categorizes tool errors for structured handling by the agent loop.
 type ErrorType string
@@ -13,6 +18,7 @@ const (
 	ErrValidation ErrorType = "validation"    // Bad arguments, missing params
 	ErrSession    ErrorType = "session_error" // GLPI session expired mid-request
 	ErrTimeout    ErrorType = "timeout"       // Context deadline exceeded
+	ErrPermission ErrorType = "permission"    // 403, GLPI profile/entity lacks the right
 )
 
 // ToolError wraps a tool execution error with type classification.
@@ -28,9 +34,29 @@ func (e *ToolError) Error() string { return e.RawError }
 // ClassifyError inspects an error string and returns a typed ToolError
 // with user-friendly messages in PT-BR.
 func ClassifyError(err error) *ToolError {
+	// Errors the registry already typed (validation, permission) pass through.
+	var te *ToolError
+	if errors.As(err, &te) {
+		return te
+	}
+
 	raw := err.Error()
 
+	if errors.Is(err, glpi.ErrMaintenance) {
+		return maintenanceError(raw)
+	}
+
+	var apiErr *glpi.APIError
+	if errors.As(err, &apiErr) {
+		if te := classifyAPIError(apiErr, raw); te != nil {
+			return te
+		}
+	}
+
 	switch {
+	case containsAny(raw, "manutenção"):
+		// A tool that formatted the error with %v instead of %w.
+		return maintenanceError(raw)
 	case containsAny(raw, "context deadline exceeded", "timeout"):
 		return &ToolError{
 			Type: ErrTimeout, Retryable: true,
@@ -82,6 +108,47 @@ func ClassifyError(err error) *ToolError {
 	}
 }
 
+// classifyAPIError maps GLPI error codes to specific reasons, so failures like
+// creating a ticket outside the user's entities don't surface as a generic
+// error. It returns nil for codes the string matching in ClassifyError covers.
+// Reference: nexus_apirest.md — Errors
+func classifyAPIError(e *glpi.APIError, raw string) *ToolError {
+	msg := e.Message
+	switch {
+	case e.Code == "ERROR_RIGHT_MISSING" || e.Status == 403:
+		text := "Você não tem permissão para esta ação no Nexus."
+		if containsAny(msg, "entit", "entidade") {
+			text = "Você não tem permissão nesta entidade do Nexus."
+		}
+		return &ToolError{Type: ErrPermission, Retryable: false, Message: text, RawError: raw}
+	case e.Code == "ERROR_GLPI_ADD" || e.Code == "ERROR_GLPI_UPDATE" ||
+		e.Code == "ERROR_BAD_ARRAY" || e.Code == "ERROR_JSON_PAYLOAD_INVALID":
+		switch {
+		case containsAny(msg, "categor"):
+			return &ToolError{Type: ErrValidation, Retryable: false,
+				Message:  "Categoria inválida para este chamado. Escolha outra com get_department_categories.",
+				RawError: raw}
+		case containsAny(msg, "entit", "entidade"):
+			return &ToolError{Type: ErrPermission, Retryable: false,
+				Message:  "Você não tem permissão nesta entidade do Nexus.",
+				RawError: raw}
+		case containsAny(msg, "permiss", "right", "direito"):
+			return &ToolError{Type: ErrPermission, Retryable: false,
+				Message:  "Você não tem permissão para esta ação no Nexus.",
+				RawError: raw}
+		case msg != "":
+			// GLPI messages are localized to the session language (pt_BR).
+			return &ToolError{Type: ErrValidation, Retryable: false,
+				Message:  "O Nexus recusou os dados: " + msg,
+				RawError: raw}
+		}
+		return &ToolError{Type: ErrValidation, Retryable: false,
+			Message:  "O Nexus recusou os dados enviados.",
+			RawError: raw}
+	}
+	return nil
+}
+
 func containsAny(s string, patterns ...string) bool {
 	lower := strings.ToLower(s)
 	for _, p := range patterns {
@@ -91,3 +158,13 @@ func containsAny(s string, patterns ...string) bool {
 	}
 	return false
 }
+
+// maintenanceError is not retryable: maintenance windows last longer than a
+// retry, so don't bother.
+func maintenanceError(raw string) *ToolError {
+	return &ToolError{
+		Type: ErrServer, Retryable: false,
+		Message:  "O Nexus está em manutenção no momento. Tente novamente mais tarde.",
+		RawError: raw,
+	}
+}
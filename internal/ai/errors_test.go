@@ -0,0 +1,32 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lojasmm/laia/internal/glpi"
+)
+
+func TestClassifyMaintenance(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"sentinel wrapped by the client and a tool", fmt.Errorf("erro ao buscar chamado: %w", fmt.Errorf("getTicket request: %w (status 503)", glpi.ErrMaintenance))},
+		{"message only, wrapped with %v", fmt.Errorf("erro: %v", glpi.ErrMaintenance)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			te := ClassifyError(tt.err)
+			if te.Type != ErrServer || te.Retryable {
+				t.Errorf("ClassifyError = %s retryable=%v, want non-retryable %s", te.Type, te.Retryable, ErrServer)
+			}
+		})
+	}
+
+	// A timeout that mentions nothing about maintenance stays retryable.
+	if te := ClassifyError(errors.New("context deadline exceeded")); te.Type != ErrTimeout || !te.Retryable {
+		t.Errorf("timeout classified as %s retryable=%v", te.Type, te.Retryable)
+	}
+}
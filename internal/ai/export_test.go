@@ -0,0 +1,11 @@
+package ai
+
+// CapabilityTools lists the tool behind each help menu row, for tests
+// outside the package.
+func CapabilityTools() []string {
+	names := make([]string, len(capabilities))
+	for i, c := range capabilities {
+		names[i] = c.Tool
+	}
+	return names
+}
@@ -0,0 +1,179 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lojasmm/laia/internal/glpi"
+	"github.com/lojasmm/laia/internal/store"
+)
+
+// scriptedLLM answers chat completions with replies, in order, and keeps the
+// requests so tests can inspect what the model was sent.
+type scriptedLLM struct {
+	mu       sync.Mutex
+	replies  []chatMessage
+	requests []chatRequest
+}
+
+func (l *scriptedLLM) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var req chatRequest
+	json.NewDecoder(r.Body).Decode(&req)
+	l.requests = append(l.requests, req)
+	reply := chatMessage{Role: "assistant", Content: "fim do roteiro"}
+	if len(l.replies) > 0 {
+		reply, l.replies = l.replies[0], l.replies[1:]
+	}
+	json.NewEncoder(w).Encode(chatResponse{Choices: []chatChoice{{Message: reply}}})
+}
+
+func callTool(id, name, args string) toolCall {
+	return toolCall{ID: id, Type: "function", Function: functionCall{Name: name, Arguments: args}}
+}
+
+// countingTool counts its executions and how many ran at once.
+type countingTool struct {
+	fakeTool
+	delay                        time.Duration
+	calls, inflight, maxInflight atomic.Int32
+}
+
+func (t *countingTool) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	t.calls.Add(1)
+	n := t.inflight.Add(1)
+	defer t.inflight.Add(-1)
+	for {
+		m := t.maxInflight.Load()
+		if n <= m || t.maxInflight.CompareAndSwap(m, n) {
+			break
+		}
+	}
+	time.Sleep(t.delay)
+	return map[string]any{"n": args["n"]}, nil
+}
+
+func TestHandleDryRunSkipsMutatingTools(t *testing.T) {
+	var glpiCalls []string
+	glpiSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		glpiCalls = append(glpiCalls, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"session_token": "s1"}`))
+	}))
+	defer glpiSrv.Close()
+	llm := &scriptedLLM{replies: []chatMessage{
+		{Role: "assistant", ToolCalls: []toolCall{callTool("c1", "update_ticket", `{"id": 1, "status": 5}`)}},
+		{Role: "assistant", Content: "O chamado seria fechado."},
+	}}
+	llmSrv := httptest.NewServer(llm)
+	defer llmSrv.Close()
+
+	s, err := store.NewBoltStore(filepath.Join(t.TempDir(), "laia.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	tool := &countingTool{fakeTool: fakeTool{name: "update_ticket"}}
+	a := NewAgent("key", glpi.NewClient(glpiSrv.URL, "app", "", 0, glpi.Timeouts{}), s,
+		func(*glpi.Client, string, int, *Conversation) *Registry {
+			r := NewRegistry()
+			r.Register(tool)
+			return r
+		})
+	a.llm.endpoint = llmSrv.URL
+
+	trace := &ToolTrace{}
+	user := &store.User{Phone: "5511987654321", UserToken: "token", GLPIUserID: 7}
+	resp, err := a.Handle(WithDryRun(context.Background(), trace), user, user.Phone, "fecha o chamado 1", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := tool.calls.Load(); n != 0 {
+		t.Errorf("mutating tool ran %d times in a dry run", n)
+	}
+	for _, c := range glpiCalls {
+		if c != "GET /apirest.php/initSession" && c != "GET /apirest.php/killSession" {
+			t.Errorf("unexpected GLPI call %s", c)
+		}
+	}
+	if len(trace.Calls) != 1 || trace.Calls[0].Result["status"] != "dry_run" {
+		t.Errorf("trace = %+v, want one dry_run preview", trace.Calls)
+	}
+	if resp.Text != "O chamado seria fechado." {
+		t.Errorf("reply = %q", resp.Text)
+	}
+	if h, _ := s.GetHistory(user.Phone); len(h) != 0 {
+		t.Errorf("dry run saved %d history turns", len(h))
+	}
+}
+
+func TestHandleParallelToolsCapAndOrder(t *testing.T) {
+	glpiSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"session_token": "s1"}`))
+	}))
+	defer glpiSrv.Close()
+	const n = 6
+	var calls []toolCall
+	for i := range n {
+		calls = append(calls, callTool(fmt.Sprintf("c%d", i), "get_ticket", fmt.Sprintf(`{"n": %d}`, i)))
+	}
+	llm := &scriptedLLM{replies: []chatMessage{
+		{Role: "assistant", ToolCalls: calls},
+		{Role: "assistant", Content: "Aqui estão os chamados."},
+	}}
+	llmSrv := httptest.NewServer(llm)
+	defer llmSrv.Close()
+
+	s, err := store.NewBoltStore(filepath.Join(t.TempDir(), "laia.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	tool := &countingTool{fakeTool: fakeTool{name: "get_ticket", readOnly: true}, delay: 20 * time.Millisecond}
+	a := NewAgent("key", glpi.NewClient(glpiSrv.URL, "app", "", 0, glpi.Timeouts{}), s,
+		func(*glpi.Client, string, int, *Conversation) *Registry {
+			r := NewRegistry()
+			r.Register(tool)
+			return r
+		})
+	a.llm.endpoint = llmSrv.URL
+	a.SetMaxParallelTools(2)
+
+	user := &store.User{Phone: "5511987654321", UserToken: "token", GLPIUserID: 7}
+	if _, err := a.Handle(context.Background(), user, user.Phone, "mostra meus chamados", false); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := tool.calls.Load(); got != n {
+		t.Errorf("tool ran %d times, want %d", got, n)
+	}
+	if got := tool.maxInflight.Load(); got > 2 {
+		t.Errorf("%d tools ran at once, want at most 2", got)
+	}
+	// Results reach the model in call order, whatever order they finished in.
+	var results []chatMessage
+	for _, m := range llm.requests[1].Messages {
+		if m.Role == "tool" {
+			results = append(results, m)
+		}
+	}
+	if len(results) != n {
+		t.Fatalf("model got %d tool results, want %d", len(results), n)
+	}
+	for i, m := range results {
+		if want := fmt.Sprintf("c%d", i); m.ToolCallID != want || m.Content != fmt.Sprintf(`{"n":%d}`, i) {
+			t.Errorf("result %d = %s %s, want %s with n=%d", i, m.ToolCallID, m.Content, want, i)
+		}
+	}
+}
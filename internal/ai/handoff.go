@@ -0,0 +1,69 @@
+package ai
+
+import (
+	"strings"
+
+	"github.com/lojasmm/laia/internal/store"
+)
+
+const handoffToolName = "human_handoff"
+
+// handoffPhrases signal the user wants a person. They are whole phrases, not
+// single words: "humano" alone would match "Recursos Humanos" and every HR
+// ticket would get a handoff offer.
+var handoffPhrases = []string{
+	"falar com um humano", "falar com humano", "atendente humano", "atendimento humano",
+	"falar com um atendente", "falar com atendente", "quero um atendente",
+	"falar com uma pessoa", "pessoa de verdade", "falar com alguem", "falar com alguém",
+}
+
+// handoffWords are the same request typed as a single word.
+var handoffWords = map[string]bool{"humano": true, "atendente": true, "pessoa": true}
+
+// handoffClarifications is how many clarification rounds in the recent
+// history mean the agent is going in circles and should offer a person.
+const (
+	handoffClarifications = 3
+	handoffLookback       = 12
+)
+
+func wantsHuman(text string) bool {
+	lower := strings.ToLower(strings.TrimSpace(text))
+	if handoffWords[strings.Trim(lower, "?!. ")] {
+		return true
+	}
+	for _, p := range handoffPhrases {
+		if strings.Contains(lower, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// recentClarifications counts tool results asking for clarification in the
+// last handoffLookback turns.
+func recentClarifications(history []store.ConversationTurn) int {
+	n := 0
+	for _, t := range history[max(len(history)-handoffLookback, 0):] {
+		for _, p := range t.Parts {
+			if p.FunctionResponse != nil && p.FunctionResponse.Response["need_clarification"] == true {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// handoffHint returns a system message steering the model towards
+// human_handoff, or "" when nothing suggests it.
+func handoffHint(history []store.ConversationTurn, text string) string {
+	switch {
+	case wantsHuman(text):
+		return "O usuário parece pedir atendimento humano. Se for isso, confirme com respond_interactive " +
+			"(botões \"Falar com pessoa\"/\"Continuar aqui\") e, se ele aceitar, chame human_handoff com reason=pedido_usuario."
+	case recentClarifications(history) >= handoffClarifications:
+		return "Você já pediu esclarecimentos várias vezes nesta conversa. Se ainda não conseguir ajudar, " +
+			"ofereça com respond_interactive falar com uma pessoa e, se o usuário aceitar, chame human_handoff com reason=nao_resolvido."
+	}
+	return ""
+}
@@ -0,0 +1,29 @@
+package ai
+
+import "testing"
+
+func TestWantsHuman(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"quero falar com um humano", true},
+		{"Quero falar com um atendente, por favor", true},
+		{"preciso de atendimento humano", true},
+		{"dá pra falar com alguém?", true},
+		{"tem uma pessoa de verdade aí?", true},
+		{"Humano!", true},
+		{"atendente", true},
+
+		{"chamado para Recursos Humanos", false},
+		{"o sistema do RH humano está fora", false},
+		{"o atendente da loja não consegue logar", false},
+		{"uma pessoa do setor perdeu o crachá", false},
+		{"abrir chamado para o setor de Recursos Humanos sobre férias", false},
+	}
+	for _, tt := range tests {
+		if got := wantsHuman(tt.text); got != tt.want {
+			t.Errorf("wantsHuman(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package ai
+
+import (
+	"context"
+	"strings"
+
+	"github.com/lojasmm/laia/internal/store"
+)
+
+const helpReplyPrefix = "help:"
+
+// capability is one entry of the help menu. It's shown only when its tool is
+// registered for the user, so disabled features (human_handoff without
+// HANDOFF_CATEGORY_ID, restricted tools) don't show up; choosing it sends
+// Prompt to the agent as if the user had typed it.
+type capability struct {
+	Tool        string
+	Title       string // Max 24 chars
+	Description string // Max 72 chars
+	Prompt      string
+}
+
+// capabilities is the help menu, most used first. WhatsApp lists take at
+// most 10 rows, so entries past that are dropped.
+var capabilities = []capability{
+	{"list_my_tickets", "Meus chamados", "Ver seus chamados e o status de cada um", "Quero ver meus chamados"},
+	{"create_ticket", "Abrir chamado", "Relatar um problema ou fazer um pedido", "Quero abrir um chamado"},
+	{"my_dashboard", "Meu resumo", "Chamados abertos, aprovações e avaliações pendentes", "Mostre meu resumo"},
+	{"my_deadlines", "Prazos", "Prazo de SLA dos seus chamados abertos", "Quais são os prazos dos meus chamados?"},
+	{"add_followup", "Comentar chamado", "Mandar uma informação para o técnico", "Quero adicionar um comentário em um chamado"},
+	{"list_pending_approvals", "Aprovações", "Chamados esperando a sua aprovação", "Tenho aprovações pendentes?"},
+	{"search_knowledge_base", "Base de conhecimento", "Tutoriais e soluções para problemas comuns", "Quero buscar na base de conhecimento"},
+	{"search_assets", "Equipamentos", "Consultar computadores, impressoras e outros ativos", "Quero consultar um equipamento"},
+	{"reserve_asset", "Reservar equipamento", "Reservar projetor, notebook e outros itens", "Quero reservar um equipamento"},
+	{"set_reminder", "Lembretes", "Ser lembrado de um chamado mais tarde", "Quero criar um lembrete"},
+	{"human_handoff", "Falar com atendente", "Ser atendido por uma pessoa da equipe", "Quero falar com um atendente"},
+}
+
+// IsHelpRequest reports whether text asks what Laia can do. Only whole
+// messages match, so "ajuda com a impressora" still goes to the model.
+func IsHelpRequest(text string) bool {
+	t := strings.ToLower(strings.TrimRight(strings.TrimSpace(text), "?!. "))
+	switch t {
+	case "/ajuda", "ajuda", "/help", "help", "menu",
+		"o que você faz", "o que voce faz", "o que você pode fazer", "o que voce pode fazer":
+		return true
+	}
+	return false
+}
+
+// HelpPrompt returns what a help menu row stands for.
+func HelpPrompt(replyID string) (string, bool) {
+	tool, ok := strings.CutPrefix(replyID, helpReplyPrefix)
+	if !ok {
+		return "", false
+	}
+	for _, c := range capabilities {
+		if c.Tool == tool {
+			return c.Prompt, true
+		}
+	}
+	return "", false
+}
+
+// HandleHelp answers a help request with the capability menu, without a model
+// call. The registry is built for the user's session so the menu matches the
+// tools the model would actually have. The menu isn't recorded in history;
+// the choice that follows carries the intent.
+func (a *Agent) HandleHelp(ctx context.Context, user *store.User, phone string) (*Response, error) {
+	sessionToken, err := a.initUserSession(user)
+	if err != nil {
+		return nil, err
+	}
+	defer a.killSession(ctx, sessionToken)
+
+	history := a.loadHistory(ctx, phone)
+	registry := a.buildReg(a.glpi, sessionToken, user.GLPIUserID, NewConversation(phone, &history))
+
+	var rows []ListRow
+	for _, c := range capabilities {
+		if len(rows) == 10 {
+			break
+		}
+		if t, err := registry.Get(c.Tool); err == nil && registry.permitted(t) {
+			rows = append(rows, ListRow{ID: helpReplyPrefix + c.Tool, Title: c.Title, Description: c.Description})
+		}
+	}
+	return &Response{
+		Text: "Posso ajudar com estas coisas pelo WhatsApp. Escolha uma opção ou me conte o que precisa com suas palavras.",
+		List: &ListOption{ButtonText: "Ver opções", Sections: []ListSection{{Title: "O que posso fazer", Rows: rows}}},
+	}, nil
+}
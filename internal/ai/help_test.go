@@ -0,0 +1,41 @@
+package ai_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/lojasmm/laia/internal/ai"
+	"github.com/lojasmm/laia/internal/ai/tools"
+	"github.com/lojasmm/laia/internal/glpi"
+	"github.com/lojasmm/laia/internal/store"
+)
+
+// TestCapabilitiesResolve guards against help rows keyed by a tool name that
+// doesn't exist, which the registry filter would silently drop.
+func TestCapabilitiesResolve(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	g := glpi.NewClient(srv.URL, "app", "", 0, glpi.Timeouts{})
+
+	s, err := store.NewBoltStore(filepath.Join(t.TempDir(), "laia.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// Every optional tool a capability can point to is enabled.
+	build := tools.NewRegistryBuilder(tools.Options{
+		Store:   s,
+		Handoff: tools.HandoffConfig{CategoryID: 1},
+	})
+	var history []store.ConversationTurn
+	registry := build(g, "session", 1, ai.NewConversation("5511987654321", &history))
+
+	for _, name := range ai.CapabilityTools() {
+		if !registry.Has(name) {
+			t.Errorf("capability %q has no registered tool", name)
+		}
+	}
+}
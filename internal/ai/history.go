@@ -0,0 +1,75 @@
+package ai
+
+import (
+	"context"
+	"time"
+
+	"github.com/lojasmm/laia/internal/logging"
+	"github.com/lojasmm/laia/internal/store"
+)
+
+// saveHistoryAttempts bounds the retries of a failed history write; Bolt
+// failures are usually a brief lock or disk hiccup.
+const (
+	saveHistoryAttempts = 3
+	saveHistoryBackoff  = 100 * time.Millisecond
+)
+
+// saveHistory persists turns, retrying briefly. When every attempt fails the
+// turns are kept in memory (a.unsaved) and loadHistory serves them on the
+// next message, so the user doesn't lose the conversation to a stale copy.
+func (a *Agent) saveHistory(ctx context.Context, phone string, turns []store.ConversationTurn) {
+	if dryRunTrace(ctx) != nil {
+		return
+	}
+	logger := logging.FromContext(ctx)
+
+	var err error
+	for attempt := range saveHistoryAttempts {
+		if attempt > 0 {
+			time.Sleep(saveHistoryBackoff * time.Duration(attempt))
+		}
+		if err = a.store.SaveHistory(phone, turns); err == nil {
+			a.mu.Lock()
+			delete(a.unsaved, phone)
+			a.mu.Unlock()
+			return
+		}
+		logger.Warn("agent: failed to save history", "attempt", attempt+1, "error", err)
+	}
+
+	logger.Error("agent: history not saved, keeping it in memory", "attempts", saveHistoryAttempts, "error", err)
+	if len(turns) > a.limits.MaxTurns {
+		turns = turns[len(turns)-a.limits.MaxTurns:]
+	}
+	a.mu.Lock()
+	a.unsaved[phone] = turns
+	a.mu.Unlock()
+}
+
+// loadHistory returns the phone's history, preferring turns a failed
+// saveHistory left in memory since they are newer than the stored copy.
+func (a *Agent) loadHistory(ctx context.Context, phone string) []store.ConversationTurn {
+	a.mu.Lock()
+	pending, ok := a.unsaved[phone]
+	a.mu.Unlock()
+	if ok {
+		logging.FromContext(ctx).Warn("agent: using unsaved in-memory history", "turns", len(pending))
+		return append([]store.ConversationTurn(nil), pending...)
+	}
+
+	history, err := a.store.GetHistory(phone)
+	if err != nil {
+		logging.FromContext(ctx).Error("agent: failed to load history", "error", err)
+	}
+	return history
+}
+
+// ClearHistory forgets the phone's conversation, including any copy a failed
+// save left in memory. Use it instead of store.ClearHistory.
+func (a *Agent) ClearHistory(phone string) error {
+	a.mu.Lock()
+	delete(a.unsaved, phone)
+	a.mu.Unlock()
+	return a.store.ClearHistory(phone)
+}
@@ -0,0 +1,109 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/lojasmm/laia/internal/store"
+)
+
+// failingStore fails the next failures SaveHistory calls.
+type failingStore struct {
+	*store.BoltStore
+	failures int
+	saves    int
+}
+
+func (s *failingStore) SaveHistory(phone string, turns []store.ConversationTurn) error {
+	s.saves++
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("timeout")
+	}
+	return s.BoltStore.SaveHistory(phone, turns)
+}
+
+func TestSaveHistoryRetries(t *testing.T) {
+	const phone = "5511987654321"
+	turn := func(text string) store.ConversationTurn {
+		return store.ConversationTurn{Role: "user", Parts: []store.TurnPart{{Text: text}}}
+	}
+	tests := []struct {
+		name       string
+		failures   int
+		wantSaves  int
+		wantStored int
+		wantMemory bool
+	}{
+		{"first attempt", 0, 1, 1, false},
+		{"recovers within attempts", saveHistoryAttempts - 1, saveHistoryAttempts, 1, false},
+		{"kept in memory after all attempts", saveHistoryAttempts, saveHistoryAttempts, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bolt, err := store.NewBoltStore(filepath.Join(t.TempDir(), "laia.db"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer bolt.Close()
+			s := &failingStore{BoltStore: bolt, failures: tt.failures}
+			a := NewAgent("key", nil, s, nil)
+			ctx := context.Background()
+
+			a.saveHistory(ctx, phone, []store.ConversationTurn{turn("oi")})
+
+			if s.saves != tt.wantSaves {
+				t.Errorf("saves = %d, want %d", s.saves, tt.wantSaves)
+			}
+			if h, _ := bolt.GetHistory(phone); len(h) != tt.wantStored {
+				t.Errorf("stored turns = %d, want %d", len(h), tt.wantStored)
+			}
+			if _, ok := a.unsaved[phone]; ok != tt.wantMemory {
+				t.Errorf("kept in memory = %v, want %v", ok, tt.wantMemory)
+			}
+			if h := a.loadHistory(ctx, phone); len(h) != 1 || h[0].Parts[0].Text != "oi" {
+				t.Errorf("loadHistory = %+v, want the saved turn", h)
+			}
+		})
+	}
+}
+
+func TestUnsavedHistoryIsCleared(t *testing.T) {
+	const phone = "5511987654321"
+	bolt, err := store.NewBoltStore(filepath.Join(t.TempDir(), "laia.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bolt.Close()
+	s := &failingStore{BoltStore: bolt, failures: saveHistoryAttempts}
+	a := NewAgent("key", nil, s, nil)
+	a.SetHistoryLimits(store.HistoryLimits{MaxTurns: 2, MaxTokens: 3500, KeepRecent: 1})
+	ctx := context.Background()
+
+	turns := []store.ConversationTurn{
+		{Role: "user", Parts: []store.TurnPart{{Text: "1"}}},
+		{Role: "assistant", Parts: []store.TurnPart{{Text: "2"}}},
+		{Role: "user", Parts: []store.TurnPart{{Text: "3"}}},
+	}
+	a.saveHistory(ctx, phone, turns)
+	if got := a.unsaved[phone]; len(got) != 2 || got[0].Parts[0].Text != "2" {
+		t.Errorf("unsaved = %+v, want the last MaxTurns turns", got)
+	}
+
+	// The next successful save replaces the in-memory copy.
+	a.saveHistory(ctx, phone, turns[:1])
+	if _, ok := a.unsaved[phone]; ok {
+		t.Error("in-memory history kept after a successful save")
+	}
+
+	s.failures = saveHistoryAttempts
+	a.saveHistory(ctx, phone, turns)
+	if err := a.ClearHistory(phone); err != nil {
+		t.Fatal(err)
+	}
+	if h := a.loadHistory(ctx, phone); len(h) != 0 {
+		t.Errorf("loadHistory after ClearHistory = %+v, want empty", h)
+	}
+}
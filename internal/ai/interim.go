@@ -0,0 +1,28 @@
+package ai
+
+import (
+	"context"
+	"strings"
+)
+
+type interimKey struct{}
+
+// WithInterim delivers the text the model writes alongside tool calls ("Vou
+// verificar seu chamado...") through send as soon as it arrives, instead of
+// dropping it. Tools can take several seconds, and without it the user sees
+// nothing until the final answer.
+func WithInterim(ctx context.Context, send func(text string)) context.Context {
+	return context.WithValue(ctx, interimKey{}, send)
+}
+
+// sendInterim delivers msg's text if it has tool calls and ctx asked for
+// interim messages.
+func sendInterim(ctx context.Context, msg chatMessage) {
+	send, _ := ctx.Value(interimKey{}).(func(string))
+	if send == nil || len(msg.ToolCalls) == 0 {
+		return
+	}
+	if text := strings.TrimSpace(msg.Content); text != "" {
+		send(text)
+	}
+}
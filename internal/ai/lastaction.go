@@ -0,0 +1,133 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lojasmm/laia/internal/store"
+)
+
+// lastActionTTL bounds how long "tenta de novo" refers to the last mutating
+// call; after that the user has most likely moved on to something else.
+const lastActionTTL = 30 * time.Minute
+
+const retryLastActionName = "retry_last_action"
+
+// actionOutcome decides whether repeating a mutating call is safe. There is no
+// idempotency key in the GLPI API, so only failures known not to have reached
+// Nexus are retried.
+type actionOutcome int
+
+const (
+	actionApplied   actionOutcome = iota // succeeded; repeating would duplicate it
+	actionUncertain                      // timed out or 5xx; Nexus may have applied it
+	actionRetryable                      // transient failure known not to apply (rate limit, session)
+	actionRejected                       // Nexus refused the data; the same args fail again
+)
+
+type lastAction struct {
+	tool    string
+	args    map[string]any
+	outcome actionOutcome
+	reason  string // user-facing error message, for failed outcomes
+	at      time.Time
+}
+
+func outcomeOf(te *ToolError) actionOutcome {
+	switch {
+	case te == nil:
+		return actionApplied
+	case te.Type == ErrTimeout, te.Type == ErrServer && te.Retryable:
+		// A 5xx can come after GLPI wrote part of the change (e.g. the
+		// ticket but not its actors).
+		return actionUncertain
+	case te.Retryable, te.Type == ErrAuth, te.Type == ErrSession:
+		return actionRetryable
+	default:
+		return actionRejected
+	}
+}
+
+// recordAction remembers the latest mutating call for phone.
+func (a *Agent) recordAction(phone, tool string, args map[string]any, te *ToolError) {
+	act := lastAction{tool: tool, args: args, outcome: outcomeOf(te), at: time.Now()}
+	if te != nil {
+		act.reason = te.Message
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for p, old := range a.lastActions {
+		if act.at.Sub(old.at) > lastActionTTL {
+			delete(a.lastActions, p)
+		}
+	}
+	a.lastActions[store.NormalizePhone(phone)] = act
+}
+
+func (a *Agent) lastAction(phone string) (lastAction, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	key := store.NormalizePhone(phone)
+	act, ok := a.lastActions[key]
+	if ok && time.Since(act.at) > lastActionTTL {
+		delete(a.lastActions, key)
+		return lastAction{}, false
+	}
+	return act, ok
+}
+
+// retryLastAction re-runs the phone's last mutating call when it failed
+// before reaching Nexus. It is registered by Handle rather than in the tools
+// package because the state lives on the Agent.
+type retryLastAction struct {
+	agent    *Agent
+	phone    string
+	registry *Registry
+}
+
+func (t *retryLastAction) Name() string   { return retryLastActionName }
+func (t *retryLastAction) ReadOnly() bool { return false }
+func (t *retryLastAction) Description() string {
+	return `Repete a ultima acao que alterava dados (criar chamado, comentar, atualizar...) se ela falhou por erro temporario.
+Quando usar: quando o usuario pedir para tentar de novo apos uma falha. Ex: "tenta de novo", "tenta novamente".
+NAO usar: para repetir uma acao que deu certo ou quando o usuario quiser mudar os dados — chame a ferramenta original.
+Recusa repetir acoes ja concluidas, recusadas pelo Nexus ou que podem ter sido aplicadas (timeout, erro no servidor).
+Retorna: o resultado da ferramenta repetida, ou {status, mensagem} explicando por que nao repetiu.`
+}
+func (t *retryLastAction) Parameters() *ParamSchema { return nil }
+
+func (t *retryLastAction) Execute(ctx context.Context, _ map[string]any) (map[string]any, error) {
+	last, ok := t.agent.lastAction(t.phone)
+	if !ok {
+		return map[string]any{"status": "nothing_to_retry", "mensagem": "Não há nenhuma ação recente para repetir."}, nil
+	}
+	switch last.outcome {
+	case actionApplied:
+		return map[string]any{
+			"status":   "already_applied",
+			"acao":     last.tool,
+			"mensagem": fmt.Sprintf("A última ação (%s) já foi concluída com sucesso; repetir criaria uma duplicata.", last.tool),
+		}, nil
+	case actionUncertain:
+		return map[string]any{
+			"status":   "uncertain",
+			"acao":     last.tool,
+			"mensagem": fmt.Sprintf("A última tentativa de %s falhou sem resposta clara do Nexus e pode ter sido aplicada. Confira antes (ex: list_my_tickets ou get_ticket) em vez de repetir.", last.tool),
+		}, nil
+	case actionRejected:
+		return map[string]any{
+			"status":   "not_retryable",
+			"acao":     last.tool,
+			"mensagem": fmt.Sprintf("%s foi recusada pelo Nexus (%s). Repetir com os mesmos dados falharia de novo; corrija e chame %s.", last.tool, last.reason, last.tool),
+		}, nil
+	}
+
+	result, err := t.registry.ExecuteTool(ctx, last.tool, last.args)
+	var te *ToolError
+	if err != nil {
+		te = t.agent.retry.classify(err)
+	}
+	t.agent.recordAction(t.phone, last.tool, last.args, te)
+	return result, err
+}
@@ -0,0 +1,55 @@
+package ai
+
+import (
+	"context"
+	"time"
+
+	"github.com/lojasmm/laia/internal/logging"
+	"github.com/lojasmm/laia/internal/store"
+)
+
+// trackTicket records the ticket a successful tool call was about, for the
+// recent_tickets tool. Title and status are only taken from results that
+// describe the ticket itself (get_ticket and other ticketDetailResult tools),
+// since "status" means other things in other results.
+func (a *Agent) trackTicket(ctx context.Context, phone, tool string, args, result map[string]any) {
+	if dryRunTrace(ctx) != nil || result == nil {
+		return
+	}
+	if _, failed := result["error"]; failed {
+		return
+	}
+
+	var rt store.RecentTicket
+	switch {
+	case tool == "create_ticket":
+		rt.ID = anyInt(result["id"])
+		rt.Title, _ = args["title"].(string)
+		rt.Status = "Novo"
+	case anyInt(args["ticket_id"]) > 0:
+		rt.ID = anyInt(args["ticket_id"])
+		if anyInt(result["id"]) == rt.ID {
+			rt.Title, _ = result["titulo"].(string)
+			rt.Status, _ = result["status"].(string)
+		}
+	}
+	if rt.ID <= 0 {
+		return
+	}
+	rt.At = time.Now()
+	if err := a.store.RecordRecentTicket(phone, rt); err != nil {
+		logging.FromContext(ctx).Warn("agent: failed to record recent ticket", "ticket_id", rt.ID, "error", err)
+	}
+}
+
+// anyInt reads a JSON number (float64) or int, returning 0 otherwise.
+func anyInt(v any) int {
+	switch n := v.(type) {
+	case float64:
+		return int(n)
+	case int:
+		return n
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,97 @@
+package ai
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/lojasmm/laia/internal/store"
+)
+
+// lastOfferedOptions returns the titles of the buttons or list rows of the
+// last assistant turn, when it was a respond_interactive message. History is
+// stored per phone and keeps the tool call arguments, so the options offered
+// last are in it already. The titles are the ones the user saw: the bot drops
+// buttons past MaxButtons and cuts titles to WhatsApp's limits.
+func lastOfferedOptions(history []store.ConversationTurn) []string {
+	for i := len(history) - 1; i >= 0; i-- {
+		turn := history[i]
+		if turn.Role != "assistant" {
+			continue
+		}
+		for _, p := range turn.Parts {
+			if p.FunctionCall == nil || p.FunctionCall.Name != "respond_interactive" {
+				continue
+			}
+			r := parseInteractiveResponse(p.FunctionCall.Args)
+			var options []string
+			buttons := r.Buttons
+			if len(buttons) > MaxButtons {
+				buttons = buttons[:MaxButtons]
+			}
+			for _, b := range buttons {
+				options = append(options, truncateRunes(b.Title, MaxButtonTitleLen))
+			}
+			if r.List != nil && len(buttons) == 0 {
+				for _, s := range r.List.Sections {
+					for _, row := range s.Rows {
+						options = append(options, truncateRunes(row.Title, MaxRowTitleLen))
+					}
+				}
+			}
+			return options
+		}
+		return nil
+	}
+	return nil
+}
+
+func truncateRunes(s string, maxLen int) string {
+	runes := []rune(s)
+	if len(runes) <= maxLen {
+		return s
+	}
+	return string(runes[:maxLen])
+}
+
+func normalizeReply(s string) string {
+	return strings.Trim(strings.ToLower(strings.TrimSpace(s)), ".!?,;:")
+}
+
+// matchesOption reports whether text picks one of options: a tapped button
+// arrives as its title, and a typed reply may add words after it
+// ("Confirmar, pode abrir") or give its position ("2").
+func matchesOption(text string, options []string) bool {
+	reply := normalizeReply(text)
+	if n, err := strconv.Atoi(reply); err == nil {
+		return n >= 1 && n <= len(options)
+	}
+	for _, o := range options {
+		o = normalizeReply(o)
+		if o == "" {
+			continue
+		}
+		if reply == o || strings.HasPrefix(reply, o+" ") || strings.HasPrefix(reply, o+",") {
+			return true
+		}
+	}
+	return false
+}
+
+// reofferHint returns a system message asking the model to offer the options
+// again when the user typed something that isn't one of them, or "" when the
+// reply was a tap or picks an option (or confirms, see userConfirmed). The model decides
+// whether the text still answers the question: "só o meu computador" to "Só
+// eu / Meu setor / Loja inteira" does, and re-asking would only annoy.
+func reofferHint(history []store.ConversationTurn, text string, tapped bool) string {
+	if tapped {
+		return ""
+	}
+	options := lastOfferedOptions(history)
+	if len(options) == 0 || matchesOption(text, options) || userConfirmed(history, text) != nil {
+		return ""
+	}
+	return "O usuário respondeu com texto em vez de tocar em uma das opções oferecidas (" +
+		strings.Join(options, ", ") + "). Se a resposta corresponder claramente a uma opção, siga com ela; " +
+		"se for outro assunto, atenda normalmente. Se não der para saber, reapresente as mesmas opções com " +
+		"respond_interactive e um lembrete gentil (ex: \"Pode tocar em uma das opções abaixo 🙂\"), sem repetir a explicação inteira."
+}
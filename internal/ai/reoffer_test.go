@@ -0,0 +1,100 @@
+package ai
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/lojasmm/laia/internal/store"
+)
+
+func interactiveTurn(args map[string]any) store.ConversationTurn {
+	return store.ConversationTurn{
+		Role:  "assistant",
+		Parts: []store.TurnPart{{FunctionCall: &store.FunctionCallPart{Name: "respond_interactive", Args: args}}},
+	}
+}
+
+func buttonsTurn(titles ...string) store.ConversationTurn {
+	var buttons []any
+	for i, t := range titles {
+		buttons = append(buttons, map[string]any{"id": string(rune('a' + i)), "title": t})
+	}
+	return interactiveTurn(map[string]any{"text": "Escolha", "message_type": "buttons", "buttons": buttons})
+}
+
+func TestLastOfferedOptions(t *testing.T) {
+	list := interactiveTurn(map[string]any{
+		"text":         "Escolha",
+		"message_type": "list",
+		"sections": []any{map[string]any{
+			"title": "Lojas",
+			"rows": []any{
+				map[string]any{"id": "1", "title": "Loja Centro"},
+				map[string]any{"id": "2", "title": "Loja Shopping Estação Curitiba"},
+			},
+		}},
+	})
+	userTurn := store.ConversationTurn{Role: "user", Parts: []store.TurnPart{{Text: "oi"}}}
+	textTurn := store.ConversationTurn{Role: "assistant", Parts: []store.TurnPart{{Text: "Olá!"}}}
+
+	tests := []struct {
+		name    string
+		history []store.ConversationTurn
+		want    []string
+	}{
+		{"no history", nil, nil},
+		{"buttons", []store.ConversationTurn{buttonsTurn("Sim", "Não"), userTurn}, []string{"Sim", "Não"}},
+		{"buttons past the third dropped", []store.ConversationTurn{buttonsTurn("A", "B", "C", "D")}, []string{"A", "B", "C"}},
+		{"button title cut to 20", []store.ConversationTurn{buttonsTurn("Confirmar abertura do chamado")}, []string{"Confirmar abertura d"}},
+		{"list row title cut to 24", []store.ConversationTurn{list}, []string{"Loja Centro", "Loja Shopping Estação Cu"}},
+		{"last assistant turn is text", []store.ConversationTurn{buttonsTurn("Sim", "Não"), userTurn, textTurn}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lastOfferedOptions(tt.history); !slices.Equal(got, tt.want) {
+				t.Errorf("lastOfferedOptions = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesOption(t *testing.T) {
+	options := []string{"Só eu", "Meu setor", "Loja inteira"}
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"Meu setor", true},
+		{"meu setor.", true},
+		{"Loja inteira, desde cedo", true},
+		{"só eu mesmo", true},
+		{"2", true},
+		{"3", true},
+		{"0", false},
+		{"4", false},
+		{"meu setorzinho", false},
+		{"o computador não liga", false},
+	}
+	for _, tt := range tests {
+		if got := matchesOption(tt.text, options); got != tt.want {
+			t.Errorf("matchesOption(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestReofferHint(t *testing.T) {
+	history := []store.ConversationTurn{buttonsTurn("Abrir chamado agora", "Falar com o suporte", "Cancelar", "Ver meus chamados")}
+
+	if hint := reofferHint(history, "Abrir chamado agora", true); hint != "" {
+		t.Errorf("tap got hint %q", hint)
+	}
+	if hint := reofferHint(history, "Abrir chamado agora", false); hint != "" {
+		t.Errorf("typed option got hint %q", hint)
+	}
+	if hint := reofferHint(history, "4", false); hint == "" {
+		t.Error("position of a button that was never sent got no hint")
+	}
+	if hint := reofferHint(history, "qual o horário do suporte?", false); hint == "" {
+		t.Error("unrelated text got no hint")
+	}
+}
@@ -6,8 +6,38 @@ type Response struct {
 	Text    string
 	Buttons []ButtonOption
 	List    *ListOption
+	// Images are sent after the reply, in order (see Conversation.AttachImage).
+	Images []Image
+	// Documents are sent after the images (see Conversation.AttachDocument).
+	Documents []Document
 }
 
+// Image is a file a tool wants delivered to the user as a WhatsApp image.
+type Image struct {
+	Data     []byte
+	MIMEType string // image/jpeg or image/png
+	Filename string
+	Caption  string
+}
+
+// Document is a file a tool wants delivered to the user as a WhatsApp
+// document, for files WhatsApp can't show as an image (PDF, spreadsheets...).
+type Document struct {
+	Data     []byte
+	MIMEType string
+	Filename string // shown to the user; WhatsApp uses it to pick the icon
+	Caption  string
+}
+
+// WhatsApp limits on interactive messages. The bot sends at most MaxButtons
+// buttons and cuts titles to these lengths, and a tap comes back with the
+// title as sent.
+const (
+	MaxButtons        = 3
+	MaxButtonTitleLen = 20
+	MaxRowTitleLen    = 24
+)
+
 type ButtonOption struct {
 	ID    string // Unique identifier for callback
 	Title string // Max 20 chars (WhatsApp limit)
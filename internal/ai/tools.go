@@ -4,14 +4,19 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
+	"sort"
+	"strings"
 	"time"
+
+	"github.com/lojasmm/laia/internal/glpi"
+	"github.com/lojasmm/laia/internal/logging"
 )
 
 const (
 	// Max JSON output length before truncation (~8KB, keeps token usage low)
 	maxOutputLen = 8192
-	// Per-tool execution timeout
+	// Per-tool execution timeout, unless the tool sets its own (TimeLimited)
 	toolTimeout = 30 * time.Second
 	// Max items in a list before truncation
 	maxListItems = 10
@@ -37,9 +42,61 @@ type Tool interface {
 	ReadOnly() bool
 }
 
+// ProfileRestricted is implemented by tools only some GLPI profiles may call
+// (e.g. technician actions). The registry checks it against the session's
+// active profile, so the prompt isn't the only thing keeping them out.
+type ProfileRestricted interface {
+	AllowsProfile(p glpi.ActiveProfile) bool
+}
+
+// SideEffecting is implemented by read-only tools that still act outside the
+// conversation: persisting state, paying for a model call. A support dry run
+// previews them like mutating tools so reproducing a conversation leaves no
+// trace.
+type SideEffecting interface {
+	HasSideEffects() bool
+}
+
+// OutputLimited is implemented by tools whose results need different
+// truncation than the defaults (maxListItems, maxOutputLen): detailed views
+// like a ticket's history need more room, short lists less.
+type OutputLimited interface {
+	OutputLimits() OutputLimits
+}
+
+// TimeLimited is implemented by tools that need a deadline other than
+// toolTimeout: longer for a model call on a long ticket, shorter for lookups
+// that should fail fast. The deadline bounds what the tool does with its ctx;
+// GLPI requests are bounded by the client's own glpi.Timeouts.
+type TimeLimited interface {
+	Timeout() time.Duration
+}
+
+// OutputLimits overrides truncation for one tool; zero fields keep the default.
+type OutputLimits struct {
+	MaxItems int
+	MaxBytes int
+}
+
+func (l OutputLimits) withDefaults() OutputLimits {
+	if l.MaxItems <= 0 {
+		l.MaxItems = maxListItems
+	}
+	if l.MaxBytes <= 0 {
+		l.MaxBytes = maxOutputLen
+	}
+	return l
+}
+
 // Registry holds all registered tools.
 type Registry struct {
 	tools map[string]Tool
+	// order is the registration order, which the prompt's tool list and the
+	// tool definitions follow so they read in a stable, grouped order.
+	order []string
+	// profile is the session's active profile; nil when unknown, which denies
+	// every ProfileRestricted tool.
+	profile *glpi.ActiveProfile
 }
 
 func NewRegistry() *Registry {
@@ -47,9 +104,36 @@ func NewRegistry() *Registry {
 }
 
 func (r *Registry) Register(t Tool) {
+	if _, ok := r.tools[t.Name()]; !ok {
+		r.order = append(r.order, t.Name())
+	}
 	r.tools[t.Name()] = t
 }
 
+// SetProfile records the session's active profile for ProfileRestricted tools.
+func (r *Registry) SetProfile(p glpi.ActiveProfile) {
+	r.profile = &p
+}
+
+// HasRestricted reports whether any registered tool needs the session profile,
+// so builders can skip reading it when nothing depends on it.
+func (r *Registry) HasRestricted() bool {
+	for _, t := range r.tools {
+		if _, ok := t.(ProfileRestricted); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Registry) permitted(t Tool) bool {
+	pr, ok := t.(ProfileRestricted)
+	if !ok {
+		return true
+	}
+	return r.profile != nil && pr.AllowsProfile(*r.profile)
+}
+
 func (r *Registry) Get(name string) (Tool, error) {
 	t, ok := r.tools[name]
 	if !ok {
@@ -58,40 +142,82 @@ func (r *Registry) Get(name string) (Tool, error) {
 	return t, nil
 }
 
+// Has reports whether a tool with that name is registered.
+func (r *Registry) Has(name string) bool {
+	_, ok := r.tools[name]
+	return ok
+}
+
+// unknownToolError answers a call to a tool that doesn't exist (usually a
+// hallucinated or misspelled name) with the tools the model can actually use,
+// so it can correct itself on the next iteration.
+func (r *Registry) unknownToolError(name string) *ToolError {
+	var names []string
+	for n, t := range r.tools {
+		if r.permitted(t) {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+	msg := fmt.Sprintf("a ferramenta %q não existe. Use uma destas: %s", name, strings.Join(names, ", "))
+	return &ToolError{Type: ErrValidation, Message: msg, RawError: "unknown tool: " + name}
+}
+
+// check returns why name can't be called in this session: it doesn't exist or
+// the active profile may not use it. nil means ExecuteTool will run it.
+func (r *Registry) check(name string) *ToolError {
+	t, err := r.Get(name)
+	if err != nil {
+		return r.unknownToolError(name)
+	}
+	if !r.permitted(t) {
+		msg := fmt.Sprintf("%s não está disponível para o seu perfil no Nexus.", name)
+		return &ToolError{Type: ErrPermission, Message: msg, RawError: msg}
+	}
+	return nil
+}
+
 // ExecuteTool validates args, applies a timeout, runs the tool, truncates output,
 // and logs execution duration.
 func (r *Registry) ExecuteTool(ctx context.Context, name string, args map[string]any) (map[string]any, error) {
-	t, err := r.Get(name)
-	if err != nil {
-		return nil, err
+	if te := r.check(name); te != nil {
+		return nil, te
 	}
+	t, _ := r.Get(name)
 
 	// Validate required parameters before execution
 	if schema := t.Parameters(); schema != nil {
 		if err := validateArgs(schema, args); err != nil {
-			return nil, &ToolError{
-				Type:    ErrValidation,
-				Message: fmt.Sprintf("argumentos inválidos para %s: %v", name, err),
-			}
+			msg := fmt.Sprintf("argumentos inválidos para %s: %v", name, err)
+			return nil, &ToolError{Type: ErrValidation, Message: msg, RawError: msg}
 		}
 	}
 
 	// Apply per-tool timeout
-	toolCtx, cancel := context.WithTimeout(ctx, toolTimeout)
+	timeout := toolTimeout
+	if tl, ok := t.(TimeLimited); ok && tl.Timeout() > 0 {
+		timeout = tl.Timeout()
+	}
+	toolCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	start := time.Now()
 	result, err := t.Execute(toolCtx, args)
 	elapsed := time.Since(start)
 
-	log.Printf("tool: %s completed in %dms", name, elapsed.Milliseconds())
-
+	logger := logging.FromContext(ctx).With("tool", name, "latency_ms", elapsed.Milliseconds())
 	if err != nil {
+		logger.Warn("tool: failed", "error", err)
 		return nil, err
 	}
+	logger.Info("tool: completed")
 
 	// Truncate large outputs to save tokens
-	return truncateOutput(result), nil
+	var limits OutputLimits
+	if ol, ok := t.(OutputLimited); ok {
+		limits = ol.OutputLimits()
+	}
+	return truncateOutput(result, limits.withDefaults()), nil
 }
 
 // IsReadOnly checks if a tool is safe for parallel execution.
@@ -103,6 +229,17 @@ func (r *Registry) IsReadOnly(name string) bool {
 	return t.ReadOnly()
 }
 
+// HasSideEffects reports whether a read-only tool still acts outside the
+// conversation (see SideEffecting).
+func (r *Registry) HasSideEffects(name string) bool {
+	t, err := r.Get(name)
+	if err != nil {
+		return false
+	}
+	se, ok := t.(SideEffecting)
+	return ok && se.HasSideEffects()
+}
+
 // validateArgs checks that all required parameters are present and have correct types.
 func validateArgs(schema *ParamSchema, args map[string]any) error {
 	if args == nil {
@@ -153,13 +290,13 @@ func validateArgs(schema *ParamSchema, args map[string]any) error {
 }
 
 // truncateOutput detects large list fields and truncates them, then checks total size.
-func truncateOutput(result map[string]any) map[string]any {
-	// First pass: truncate known list fields to maxListItems
+func truncateOutput(result map[string]any, limits OutputLimits) map[string]any {
+	// First pass: truncate known list fields to limits.MaxItems
 	for key, val := range result {
-		if items, ok := val.([]map[string]any); ok && len(items) > maxListItems {
+		if items, ok := val.([]map[string]any); ok && len(items) > limits.MaxItems {
 			originalCount := len(items)
-			truncated := make([]map[string]any, maxListItems)
-			for i := range maxListItems {
+			truncated := make([]map[string]any, limits.MaxItems)
+			for i := range limits.MaxItems {
 				// Copy item, strip verbose fields to save tokens
 				item := make(map[string]any, len(items[i]))
 				for k, v := range items[i] {
@@ -174,28 +311,33 @@ func truncateOutput(result map[string]any) map[string]any {
 			result["_truncated"] = true
 			result["_truncated_field"] = key
 			result["_original_count"] = originalCount
-			result["_nota"] = fmt.Sprintf("Mostrando %d de %d resultados. Sugira ao usuário refinar a busca.", maxListItems, originalCount)
+			result["_nota"] = fmt.Sprintf("Mostrando %d de %d resultados. Sugira ao usuário refinar a busca.", limits.MaxItems, originalCount)
 			return result
 		}
 	}
 
 	// Second pass: check total size
 	data, err := json.Marshal(result)
-	if err != nil || len(data) <= maxOutputLen {
+	if err != nil || len(data) <= limits.MaxBytes {
 		return result
 	}
 
-	log.Printf("tool: output truncated from %d to %d bytes", len(data), maxOutputLen)
+	slog.Info("tool: output truncated", "bytes", len(data), "max_bytes", limits.MaxBytes)
 	return map[string]any{
 		"_truncated": true,
-		"_summary":   string(data[:maxOutputLen]),
+		"_summary":   string(data[:limits.MaxBytes]),
 	}
 }
 
-// OpenAITools returns tool definitions for the OpenAI chat completion API.
+// OpenAITools returns tool definitions for the OpenAI chat completion API,
+// leaving out tools the session's profile may not call.
 func (r *Registry) OpenAITools() []map[string]any {
 	tools := make([]map[string]any, 0, len(r.tools))
-	for _, t := range r.tools {
+	for _, name := range r.order {
+		t := r.tools[name]
+		if !r.permitted(t) {
+			continue
+		}
 		fn := map[string]any{
 			"name":        t.Name(),
 			"description": t.Description(),
@@ -0,0 +1,184 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/lojasmm/laia/internal/ai"
+	"github.com/lojasmm/laia/internal/glpi"
+)
+
+// maxBulkApprovals caps one bulk_approve call and the titles fetched by
+// list_pending_approvals; each ticket costs its own GLPI requests.
+const maxBulkApprovals = 20
+
+// --- ListPendingApprovals ---
+
+type ListPendingApprovals struct {
+	glpi         *glpi.Client
+	sessionToken string
+	userID       int
+}
+
+func NewListPendingApprovals(g *glpi.Client, token string, userID int) *ListPendingApprovals {
+	return &ListPendingApprovals{glpi: g, sessionToken: token, userID: userID}
+}
+
+func (t *ListPendingApprovals) Name() string   { return "list_pending_approvals" }
+func (t *ListPendingApprovals) ReadOnly() bool { return true }
+func (t *ListPendingApprovals) Description() string {
+	return `Lista as aprovacoes (validacoes) que estao aguardando a decisao do usuario, em todos os chamados.
+Quando usar: quando o usuario perguntar o que tem para aprovar. Ex: "tenho aprovacoes pendentes?", "o que falta eu aprovar?".
+Use antes de bulk_approve para mostrar ao usuario quais chamados serao aprovados/recusados.
+Retorna: {total, aprovacoes: [{ticket_id, titulo, solicitado_em, comentario_solicitacao}]}.`
+}
+func (t *ListPendingApprovals) Parameters() *ai.ParamSchema { return nil }
+
+func (t *ListPendingApprovals) Execute(_ context.Context, _ map[string]any) (map[string]any, error) {
+	pending, err := t.glpi.GetMyValidations(t.sessionToken, t.userID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar aprovações: %w", err)
+	}
+	if len(pending) == 0 {
+		return map[string]any{
+			"total":      0,
+			"aprovacoes": []map[string]any{},
+			"mensagem":   "Nenhuma aprovação aguardando você.",
+		}, nil
+	}
+
+	shown := pending
+	if len(shown) > maxBulkApprovals {
+		shown = shown[:maxBulkApprovals]
+	}
+	items := make([]map[string]any, len(shown))
+	var wg sync.WaitGroup
+	for i, v := range shown {
+		items[i] = map[string]any{
+			"ticket_id":              v.TicketsID,
+			"solicitado_em":          v.DateCreated,
+			"comentario_solicitacao": truncateText(htmlToPlainText(v.CommentSubmission), 150),
+		}
+		wg.Add(1)
+		go func(item map[string]any, ticketID int) {
+			defer wg.Done()
+			if ticket, err := t.glpi.GetTicket(t.sessionToken, ticketID); err == nil {
+				item["titulo"] = ticket.Name
+			}
+		}(items[i], v.TicketsID)
+	}
+	wg.Wait()
+
+	result := map[string]any{"total": len(pending), "aprovacoes": items}
+	if len(pending) > len(shown) {
+		result["mensagem"] = fmt.Sprintf("Mostrando as %d mais recentes de %d.", len(shown), len(pending))
+	}
+	return result, nil
+}
+
+// --- BulkApprove ---
+
+type BulkApprove struct {
+	glpi         *glpi.Client
+	sessionToken string
+	userID       int
+}
+
+func NewBulkApprove(g *glpi.Client, token string, userID int) *BulkApprove {
+	return &BulkApprove{glpi: g, sessionToken: token, userID: userID}
+}
+
+func (t *BulkApprove) Name() string   { return "bulk_approve" }
+func (t *BulkApprove) ReadOnly() bool { return false }
+func (t *BulkApprove) Description() string {
+	return `Aprova ou recusa, de uma vez, as aprovacoes pendentes do usuario em varios chamados, com o mesmo comentario.
+Quando usar: quando o usuario quiser aprovar/recusar varios chamados juntos. Ex: "aprova todos", "recusa os chamados 12, 15 e 20". Para um unico chamado use approve_ticket.
+Obtenha os IDs com list_pending_approvals e SEMPRE confirme a lista com o usuario via respond_interactive antes de executar.
+Cada chamado e tratado separadamente: uma falha nao impede os demais.
+Retorna: {total, sucesso, falhas, resultados: [{ticket_id, status, erro}]}.`
+}
+func (t *BulkApprove) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"ticket_ids": {
+				Type:        "array",
+				Description: fmt.Sprintf("IDs dos chamados (máx %d)", maxBulkApprovals),
+				Items:       &ai.ParamSchema{Type: "integer"},
+			},
+			"approve": {Type: "string", Description: "Aprovar ou recusar", Enum: []string{"sim", "nao"}},
+			"comment": {Type: "string", Description: "Comentário aplicado a todas as aprovações/recusas (opcional)"},
+		},
+		Required: []string{"ticket_ids", "approve"},
+	}
+}
+
+func (t *BulkApprove) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	ids, err := intSliceArg(args, "ticket_ids")
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("informe ao menos um chamado em ticket_ids")
+	}
+	if len(ids) > maxBulkApprovals {
+		return nil, fmt.Errorf("máximo de %d chamados por vez (recebidos %d)", maxBulkApprovals, len(ids))
+	}
+	approveStr, _ := stringArg(args, "approve")
+	approve := approveStr == "sim"
+	comment := strings.TrimSpace(optionalStringArg(args, "comment"))
+
+	pending, err := t.glpi.GetMyValidations(t.sessionToken, t.userID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar aprovações: %w", err)
+	}
+	validationByTicket := make(map[int]int, len(pending))
+	for _, v := range pending {
+		if _, seen := validationByTicket[v.TicketsID]; !seen {
+			validationByTicket[v.TicketsID] = v.ID
+		}
+	}
+
+	action := "aprovado"
+	if !approve {
+		action = "recusado"
+	}
+	// Sequential on purpose: these are writes, and the volume is already capped.
+	results := make([]map[string]any, len(ids))
+	succeeded := 0
+	for i, id := range ids {
+		item := map[string]any{"ticket_id": id}
+		results[i] = item
+		validationID, ok := validationByTicket[id]
+		if !ok {
+			item["status"] = "erro"
+			item["erro"] = "nenhuma aprovação pendente para você neste chamado"
+			continue
+		}
+		if err := t.glpi.RespondTicketValidation(t.sessionToken, validationID, approve, comment); err != nil {
+			item["status"] = "erro"
+			item["erro"] = err.Error()
+			continue
+		}
+		item["status"] = action
+		succeeded++
+	}
+
+	result := map[string]any{
+		"total":      len(ids),
+		"sucesso":    succeeded,
+		"falhas":     len(ids) - succeeded,
+		"resultados": results,
+	}
+	if succeeded == 0 {
+		result["mensagem"] = "Nenhuma aprovação foi respondida; veja o erro de cada chamado."
+	}
+	return result, nil
+}
+
+var (
+	_ ai.Tool = (*ListPendingApprovals)(nil)
+	_ ai.Tool = (*BulkApprove)(nil)
+)
@@ -3,6 +3,9 @@ package tools
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/lojasmm/laia/internal/ai"
 	"github.com/lojasmm/laia/internal/glpi"
@@ -83,4 +86,408 @@ func (t *SearchAssets) Execute(_ context.Context, args map[string]any) (map[stri
 	return map[string]any{"total": result.TotalCount, "ativos": items}, nil
 }
 
+// --- TicketAssets ---
+
+// assetTypeLabels translates GLPI itemtypes for the user.
+var assetTypeLabels = map[string]string{
+	"Computer":         "Computador",
+	"Monitor":          "Monitor",
+	"Printer":          "Impressora",
+	"Phone":            "Telefone",
+	"NetworkEquipment": "Equipamento de rede",
+	"Peripheral":       "Periférico",
+	"Software":         "Software",
+}
+
+type TicketAssets struct {
+	glpi         *glpi.Client
+	sessionToken string
+}
+
+func NewTicketAssets(g *glpi.Client, token string) *TicketAssets {
+	return &TicketAssets{glpi: g, sessionToken: token}
+}
+
+func (t *TicketAssets) Name() string   { return "get_ticket_assets" }
+func (t *TicketAssets) ReadOnly() bool { return true }
+func (t *TicketAssets) Description() string {
+	return `Lista os ativos (computador, impressora, etc.) vinculados a um chamado, com tipo, nome, serie e patrimonio.
+Quando usar: quando o usuario perguntar qual equipamento esta no chamado ou quiser os dados do computador do chamado. Ex: "qual computador esta no chamado 123?".
+NAO usar: para buscar ativos sem chamado — use search_assets.
+Retorna: {total, ativos: [{tipo, id, nome, serie, patrimonio, status, localizacao}]}.`
+}
+func (t *TicketAssets) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"ticket_id": {Type: "integer", Description: "ID do chamado"},
+		},
+		Required: []string{"ticket_id"},
+	}
+}
+
+func (t *TicketAssets) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	ticketID, err := intArg(args, "ticket_id")
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := t.glpi.GetTicketItems(t.sessionToken, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar itens do chamado: %w", err)
+	}
+	if len(links) == 0 {
+		return map[string]any{
+			"total":    0,
+			"ativos":   []map[string]any{},
+			"mensagem": fmt.Sprintf("O chamado #%d não tem ativos vinculados.", ticketID),
+		}, nil
+	}
+
+	items := make([]map[string]any, 0, len(links))
+	for _, l := range links {
+		tipo := assetTypeLabels[l.ItemType]
+		if tipo == "" {
+			tipo = l.ItemType
+		}
+		item := map[string]any{"tipo": tipo, "id": l.ItemsID}
+		// A missing asset (deleted, or not visible to the profile) still shows
+		// up as a link, so list it without details rather than failing.
+		if asset, err := t.glpi.GetAsset(t.sessionToken, l.ItemType, l.ItemsID); err == nil {
+			item["nome"] = asset.Name
+			item["serie"] = asset.Serial
+			item["patrimonio"] = asset.OtherSerial
+			item["status"] = dropdownName(asset.States)
+			item["localizacao"] = dropdownName(asset.Locations)
+		} else {
+			item["erro"] = ai.ClassifyError(err).Message
+		}
+		items = append(items, item)
+	}
+	return map[string]any{"total": len(items), "ativos": items}, nil
+}
+
+// --- TicketsForAsset ---
+
+// maxAssetTickets bounds the search; links are sorted newest first.
+const maxAssetTickets = 20
+
+type TicketsForAsset struct {
+	glpi         *glpi.Client
+	sessionToken string
+}
+
+func NewTicketsForAsset(g *glpi.Client, token string) *TicketsForAsset {
+	return &TicketsForAsset{glpi: g, sessionToken: token}
+}
+
+func (t *TicketsForAsset) Name() string   { return "tickets_for_asset" }
+func (t *TicketsForAsset) ReadOnly() bool { return true }
+func (t *TicketsForAsset) Description() string {
+	return `Lista os chamados vinculados a um ativo (computador, impressora, etc.), do mais recente para o mais antigo.
+Quando usar: quando o usuario perguntar o historico de chamados de um equipamento. Ex: "quais chamados ja abri sobre esse computador?", "essa impressora ja deu problema antes?".
+Obtenha asset_type e asset_id com search_assets ou get_ticket_assets antes.
+Mostra apenas chamados que o usuario pode ver; os demais sao so contados em 'nao_visiveis'.
+Retorna: {total, chamados: [{id, titulo, status, data_abertura, data_fechamento, categoria, solicitante}], nao_visiveis}.`
+}
+func (t *TicketsForAsset) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"asset_type": {Type: "string", Description: "Tipo do ativo (em ingles)", Enum: reservableTypes},
+			"asset_id":   {Type: "integer", Description: "ID do ativo (de search_assets)"},
+		},
+		Required: []string{"asset_type", "asset_id"},
+	}
+}
+
+func (t *TicketsForAsset) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	assetType, err := stringArg(args, "asset_type")
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(reservableTypes, assetType) {
+		return nil, fmt.Errorf("tipo de ativo inválido: %s", assetType)
+	}
+	assetID, err := intArg(args, "asset_id")
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := t.glpi.GetAssetTickets(t.sessionToken, assetType, assetID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar chamados do ativo: %w", err)
+	}
+	ids, linked := assetTicketIDs(links)
+	if len(ids) == 0 {
+		return map[string]any{
+			"total":    0,
+			"chamados": []map[string]any{},
+			"mensagem": "Nenhum chamado vinculado a este equipamento.",
+		}, nil
+	}
+
+	// Searching with the user's session drops the tickets they can't see,
+	// which the link list doesn't.
+	criteria := map[string]string{
+		"sort":  "2",
+		"order": "DESC",
+		"range": fmt.Sprintf("0-%d", len(ids)-1),
+	}
+	for i, id := range ids {
+		prefix := fmt.Sprintf("criteria[%d]", i)
+		if i > 0 {
+			criteria[prefix+"[link]"] = "OR"
+		}
+		criteria[prefix+"[field]"] = "2"
+		criteria[prefix+"[searchtype]"] = "equals"
+		criteria[prefix+"[value]"] = strconv.Itoa(id)
+	}
+	result, err := t.glpi.AdvancedSearchTickets(t.sessionToken, criteria)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar chamados: %w", err)
+	}
+
+	items := make([]map[string]any, len(result.Data))
+	for i, d := range result.Data {
+		items[i] = map[string]any{
+			"id":              d["2"],
+			"titulo":          d["1"],
+			"status":          searchLabel(d["12"], ticketStatusLabel),
+			"data_abertura":   d["15"],
+			"data_fechamento": d["16"],
+			"categoria":       d["7"],
+			"solicitante":     d["4"],
+		}
+	}
+	out := map[string]any{"total": len(items), "chamados": items}
+	if hidden := len(ids) - len(items); hidden > 0 {
+		out["nao_visiveis"] = hidden
+	}
+	if linked > len(ids) {
+		out["mensagem"] = fmt.Sprintf("Mostrando os %d chamados mais recentes do equipamento.", maxAssetTickets)
+	}
+	return out, nil
+}
+
+// assetTicketIDs returns the distinct tickets of an asset's links, newest
+// (highest ID) first, at most maxAssetTickets, and how many there are.
+func assetTicketIDs(links []glpi.ItemTicket) ([]int, int) {
+	ids := make([]int, 0, len(links))
+	for _, l := range links {
+		if l.TicketsID > 0 && !slices.Contains(ids, l.TicketsID) {
+			ids = append(ids, l.TicketsID)
+		}
+	}
+	slices.SortFunc(ids, func(a, b int) int { return b - a })
+	return ids[:min(len(ids), maxAssetTickets)], len(ids)
+}
+
+// reservableTypes are the asset itemtypes accepted by the reservation tools.
+var reservableTypes = []string{"Computer", "Monitor", "Printer", "Phone", "NetworkEquipment", "Peripheral"}
+
+// glpiDateTime is the datetime layout GLPI uses in its API.
+const glpiDateTime = "2006-01-02 15:04:05"
+
+// --- ListAssetReservations ---
+
+type ListAssetReservations struct {
+	glpi         *glpi.Client
+	sessionToken string
+}
+
+func NewListAssetReservations(g *glpi.Client, token string) *ListAssetReservations {
+	return &ListAssetReservations{glpi: g, sessionToken: token}
+}
+
+func (t *ListAssetReservations) Name() string   { return "list_asset_reservations" }
+func (t *ListAssetReservations) ReadOnly() bool { return true }
+func (t *ListAssetReservations) Description() string {
+	return `Lista as reservas futuras de um equipamento compartilhado (ex: projetor, notebook de emprestimo).
+Quando usar: quando o usuario perguntar se um equipamento esta livre ou quem reservou. Ex: "o projetor esta reservado amanha?".
+Obtenha asset_id com search_assets antes.
+Retorna: {total, reservas: [{id, inicio, fim, usuario_id, comentario}]}. Se o ativo nao for reservavel, retorna reservavel=false.`
+}
+func (t *ListAssetReservations) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"asset_type": {Type: "string", Description: "Tipo do ativo (em ingles)", Enum: reservableTypes},
+			"asset_id":   {Type: "integer", Description: "ID do ativo (de search_assets)"},
+		},
+		Required: []string{"asset_type", "asset_id"},
+	}
+}
+
+func (t *ListAssetReservations) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	assetType, _ := stringArg(args, "asset_type")
+	assetID, err := intArg(args, "asset_id")
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := t.glpi.GetReservationItem(t.sessionToken, assetType, assetID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar equipamento reservável: %w", err)
+	}
+	if item == nil || item.IsActive == 0 {
+		return map[string]any{"reservavel": false, "mensagem": "Este equipamento não está disponível para reserva."}, nil
+	}
+
+	// Past reservations aren't useful.
+	now := time.Now().In(brLocation).Format(glpiDateTime)
+	reservations, err := t.glpi.GetReservations(t.sessionToken, item.ID, now)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar reservas: %w", err)
+	}
+
+	items := []map[string]any{}
+	for _, r := range reservations {
+		items = append(items, reservationItem(r))
+	}
+	return map[string]any{"reservavel": true, "total": len(items), "reservas": items}, nil
+}
+
+// --- ReserveAsset ---
+
+type ReserveAsset struct {
+	glpi         *glpi.Client
+	sessionToken string
+	userID       int
+}
+
+func NewReserveAsset(g *glpi.Client, token string, userID int) *ReserveAsset {
+	return &ReserveAsset{glpi: g, sessionToken: token, userID: userID}
+}
+
+func (t *ReserveAsset) Name() string   { return "reserve_asset" }
+func (t *ReserveAsset) ReadOnly() bool { return false }
+func (t *ReserveAsset) Description() string {
+	return `Reserva um equipamento compartilhado para um periodo.
+Quando usar: quando o usuario quiser reservar um equipamento. Ex: "reservar o projetor amanha das 14h as 16h".
+Obtenha asset_id com search_assets antes. SEMPRE confirme periodo e equipamento via respond_interactive antes de reservar.
+Verifica a disponibilidade antes: se houver conflito, nao reserva e retorna os conflitos — sugira outro horario.
+start/end aceitam "YYYY-MM-DD HH:MM" ou linguagem natural ("amanha 14h").
+Retorna: {id, mensagem} ou {disponivel: false, conflitos: [...]}.`
+}
+func (t *ReserveAsset) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"asset_type": {Type: "string", Description: "Tipo do ativo (em ingles)", Enum: reservableTypes},
+			"asset_id":   {Type: "integer", Description: "ID do ativo (de search_assets)"},
+			"start":      {Type: "string", Description: "Início da reserva (YYYY-MM-DD HH:MM ou 'amanhã 14h')"},
+			"end":        {Type: "string", Description: "Fim da reserva (YYYY-MM-DD HH:MM ou 'amanhã 16h')"},
+			"comment":    {Type: "string", Description: "Motivo da reserva (opcional)"},
+		},
+		Required: []string{"asset_type", "asset_id", "start", "end"},
+	}
+}
+
+func (t *ReserveAsset) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	assetType, _ := stringArg(args, "asset_type")
+	assetID, err := intArg(args, "asset_id")
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	startArg, _ := stringArg(args, "start")
+	endArg, _ := stringArg(args, "end")
+	begin, err := parseDateTimeArg(startArg, now)
+	if err != nil {
+		return nil, fmt.Errorf("início inválido %q: %v", startArg, err)
+	}
+	end, err := parseDateTimeArg(endArg, now)
+	if err != nil {
+		return nil, fmt.Errorf("fim inválido %q: %v", endArg, err)
+	}
+	if !end.After(begin) {
+		return nil, fmt.Errorf("o fim da reserva deve ser depois do início")
+	}
+
+	item, err := t.glpi.GetReservationItem(t.sessionToken, assetType, assetID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar equipamento reservável: %w", err)
+	}
+	if item == nil || item.IsActive == 0 {
+		return nil, fmt.Errorf("este equipamento não está disponível para reserva")
+	}
+
+	// Only reservations ending after the new one starts can overlap it.
+	existing, err := t.glpi.GetReservations(t.sessionToken, item.ID, begin.In(brLocation).Format(glpiDateTime))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao verificar disponibilidade: %w", err)
+	}
+	if conflicts := reservationConflicts(existing, begin, end); len(conflicts) > 0 {
+		items := make([]map[string]any, len(conflicts))
+		for i, r := range conflicts {
+			items[i] = reservationItem(r)
+		}
+		return map[string]any{
+			"disponivel": false,
+			"conflitos":  items,
+			"mensagem":   "O equipamento já está reservado em parte desse período.",
+		}, nil
+	}
+
+	id, err := t.glpi.CreateReservation(t.sessionToken, glpi.CreateReservationInput{
+		ReservationItemsID: item.ID,
+		Begin:              begin.In(brLocation).Format(glpiDateTime),
+		End:                end.In(brLocation).Format(glpiDateTime),
+		UsersID:            t.userID,
+		Comment:            optionalStringArg(args, "comment"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar reserva: %w", err)
+	}
+	return map[string]any{
+		"id": id,
+		"mensagem": fmt.Sprintf("Reserva #%d criada de %s até %s", id,
+			begin.In(brLocation).Format("02/01 15:04"), end.In(brLocation).Format("02/01 15:04")),
+	}, nil
+}
+
+// reservationConflicts returns the reservations overlapping [begin, end).
+// Back-to-back bookings (one ends exactly when the other starts) don't conflict.
+func reservationConflicts(existing []glpi.Reservation, begin, end time.Time) []glpi.Reservation {
+	var conflicts []glpi.Reservation
+	for _, r := range existing {
+		rBegin, err1 := time.ParseInLocation(glpiDateTime, r.Begin, brLocation)
+		rEnd, err2 := time.ParseInLocation(glpiDateTime, r.End, brLocation)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		if rBegin.Before(end) && begin.Before(rEnd) {
+			conflicts = append(conflicts, r)
+		}
+	}
+	return conflicts
+}
+
+// parseDateTimeArg accepts "YYYY-MM-DD HH:MM" or the natural expressions
+// understood by parseReminderTime.
+func parseDateTimeArg(s string, now time.Time) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02 15:04", glpiDateTime} {
+		if t, err := time.ParseInLocation(layout, s, brLocation); err == nil {
+			return t, nil
+		}
+	}
+	return parseReminderTime(s, now)
+}
+
+func reservationItem(r glpi.Reservation) map[string]any {
+	return map[string]any{
+		"id":         r.ID,
+		"inicio":     r.Begin,
+		"fim":        r.End,
+		"usuario_id": r.UsersID,
+		"comentario": r.Comment,
+	}
+}
+
 var _ ai.Tool = (*SearchAssets)(nil)
+var _ ai.Tool = (*ListAssetReservations)(nil)
+var _ ai.Tool = (*ReserveAsset)(nil)
+var _ ai.Tool = (*TicketAssets)(nil)
+var _ ai.Tool = (*TicketsForAsset)(nil)
@@ -0,0 +1,121 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/lojasmm/laia/internal/ai"
+	"github.com/lojasmm/laia/internal/glpi"
+)
+
+// maxAwaitingChecked caps how many open tickets have their followups read;
+// each one is a request, and the recently updated ones are where a reply is due.
+const maxAwaitingChecked = 15
+
+// awaitingReply reports whether the requester owes a reply on a ticket: the
+// last followup came from someone else (normally the technician), not from
+// the requester or an automatic action. A pending ticket whose last word is
+// the technician's is almost always waiting on the requester; an open one
+// may simply have a question in it, so the two are told apart.
+func awaitingReply(status int, followups []glpi.Followup, userID int) (glpi.Followup, string, bool) {
+	var last glpi.Followup
+	for _, f := range followups {
+		// GLPI dates sort as strings; ties keep the later ID.
+		if f.DateCreated > last.DateCreated || (f.DateCreated == last.DateCreated && f.ID > last.ID) {
+			last = f
+		}
+	}
+	if last.ID == 0 || last.UsersID == 0 || last.UsersID == userID {
+		return glpi.Followup{}, "", false
+	}
+	if status == 4 {
+		return last, "O chamado está pendente e o último comentário é do técnico: ele aguarda sua resposta.", true
+	}
+	return last, "O técnico comentou e você ainda não respondeu.", true
+}
+
+// --- AwaitingMe ---
+
+type AwaitingMe struct {
+	glpi         *glpi.Client
+	sessionToken string
+	userID       int
+}
+
+func NewAwaitingMe(g *glpi.Client, token string, userID int) *AwaitingMe {
+	return &AwaitingMe{glpi: g, sessionToken: token, userID: userID}
+}
+
+func (t *AwaitingMe) Name() string   { return "tickets_awaiting_me" }
+func (t *AwaitingMe) ReadOnly() bool { return true }
+func (t *AwaitingMe) Description() string {
+	return `Lista os chamados abertos do usuario em que o tecnico esta esperando uma resposta dele (o ultimo comentario e do tecnico).
+Quando usar: quando o usuario perguntar se tem algo para responder. Ex: "tem chamado esperando resposta minha?", "o tecnico me perguntou alguma coisa?", "o que esta parado por minha causa?".
+Depois, ofereca responder com add_followup.
+Retorna: {total, chamados: [{id, titulo, status, motivo, ultimo_comentario, data_comentario, autor}]}.`
+}
+func (t *AwaitingMe) Parameters() *ai.ParamSchema { return nil }
+
+func (t *AwaitingMe) Execute(_ context.Context, _ map[string]any) (map[string]any, error) {
+	criteria := actorTicketsCriteria("4", t.userID, "")
+	criteria["range"] = fmt.Sprintf("0-%d", maxAwaitingChecked-1)
+	criteria["sort"] = "19" // last update
+	criteria["order"] = "DESC"
+	result, err := t.glpi.AdvancedSearchTickets(t.sessionToken, criteria)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar chamados: %w", err)
+	}
+
+	type check struct {
+		last   glpi.Followup
+		reason string
+		ok     bool
+	}
+	checks := make([]check, len(result.Data))
+	var wg sync.WaitGroup
+	for i, d := range result.Data {
+		id := searchInt(d["2"])
+		if id == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(i, id, status int) {
+			defer wg.Done()
+			// A ticket whose followups can't be read is left out rather
+			// than failing the whole list.
+			followups, err := t.glpi.GetFollowups(t.sessionToken, id)
+			if err != nil {
+				return
+			}
+			last, reason, ok := awaitingReply(status, followups, t.userID)
+			checks[i] = check{last, reason, ok}
+		}(i, id, searchInt(d["12"]))
+	}
+	wg.Wait()
+
+	userName := userNamer(t.glpi, t.sessionToken)
+	items := []map[string]any{}
+	for i, d := range result.Data {
+		c := checks[i]
+		if !c.ok {
+			continue
+		}
+		items = append(items, map[string]any{
+			"id":                d["2"],
+			"titulo":            d["1"],
+			"status":            searchLabel(d["12"], ticketStatusLabel),
+			"motivo":            c.reason,
+			"ultimo_comentario": truncateText(c.last.Content, 200),
+			"data_comentario":   c.last.DateCreated,
+			"autor":             keyName(c.last.KeysNames, "users_id", c.last.UsersID, userName),
+		})
+	}
+	out := map[string]any{"total": len(items), "chamados": items}
+	if len(items) == 0 {
+		out["mensagem"] = "Nenhum chamado aberto está esperando uma resposta sua."
+	}
+	return out, nil
+}
+
+var _ ai.Tool = (*AwaitingMe)(nil)
@@ -0,0 +1,176 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/lojasmm/laia/internal/ai"
+	"github.com/lojasmm/laia/internal/glpi"
+)
+
+// Branch is a store/branch and the GLPI Location that represents it. Loaded
+// from BRANCHES_FILE:
+//
+//	[{"number": 12, "name": "Centro Joinville", "location_id": 57}]
+type Branch struct {
+	Number     int    `json:"number"`
+	Name       string `json:"name"`
+	LocationID int    `json:"location_id"`
+}
+
+func (b Branch) label() string {
+	return fmt.Sprintf("Loja %d - %s", b.Number, b.Name)
+}
+
+// LoadBranches reads the branch list. An empty path disables set_branch.
+func LoadBranches(path string) ([]Branch, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var branches []Branch
+	if err := json.Unmarshal(data, &branches); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	for i, b := range branches {
+		if b.Number == 0 || b.LocationID == 0 {
+			return nil, fmt.Errorf("%s: branch %d needs number and location_id", path, i)
+		}
+	}
+	return branches, nil
+}
+
+// branchFillers are words users add around the branch name ("loja do centro").
+var branchFillers = map[string]bool{"loja": true, "filial": true, "unidade": true, "de": true, "da": true, "do": true}
+
+// resolveBranch finds the branch the user meant. A number anywhere in the
+// input must match a branch number exactly; otherwise every word of the input
+// must appear in the branch name. It returns the match, or the candidates when
+// the input is ambiguous.
+func resolveBranch(branches []Branch, input string) (*Branch, []Branch) {
+	var words []string
+	for _, w := range routingWords(input) {
+		if n, err := strconv.Atoi(w); err == nil {
+			for i := range branches {
+				if branches[i].Number == n {
+					return &branches[i], nil
+				}
+			}
+			return nil, nil
+		}
+		if !branchFillers[w] {
+			words = append(words, w)
+		}
+	}
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	var candidates []Branch
+	for i := range branches {
+		name := routingWords(branches[i].Name)
+		if strings.Join(name, " ") == strings.Join(words, " ") {
+			return &branches[i], nil
+		}
+		if containsAll(name, words) {
+			candidates = append(candidates, branches[i])
+		}
+	}
+	if len(candidates) == 1 {
+		return &candidates[0], nil
+	}
+	return nil, candidates
+}
+
+func containsAll(haystack, needles []string) bool {
+	for _, n := range needles {
+		found := false
+		for _, h := range haystack {
+			if h == n {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// --- SetBranch ---
+
+type SetBranch struct {
+	glpi         *glpi.Client
+	sessionToken string
+	branches     []Branch
+}
+
+func NewSetBranch(g *glpi.Client, token string, branches []Branch) *SetBranch {
+	return &SetBranch{glpi: g, sessionToken: token, branches: branches}
+}
+
+func (t *SetBranch) Name() string   { return "set_branch" }
+func (t *SetBranch) ReadOnly() bool { return false }
+func (t *SetBranch) Description() string {
+	return `Identifica a loja/filial pelo numero ou nome e a associa ao chamado (localizacao no Nexus).
+Quando usar: quando o usuario informar a loja do problema. Ex: "sou da loja 12", "filial centro".
+Com ticket_id: grava a localizacao no chamado existente. Sem ticket_id (chamado ainda nao criado): retorna location_id para passar ao create_ticket.
+Se a loja for ambigua, retorna need_clarification com as opcoes.
+Retorna: {loja, location_id} e, com ticket_id, {mensagem}.`
+}
+func (t *SetBranch) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"branch":    {Type: "string", Description: "Numero ou nome da loja/filial informado pelo usuario"},
+			"ticket_id": {Type: "integer", Description: "ID do chamado (omitir se o chamado ainda nao foi criado)"},
+		},
+		Required: []string{"branch"},
+	}
+}
+
+func (t *SetBranch) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	input, err := stringArg(args, "branch")
+	if err != nil {
+		return nil, err
+	}
+
+	branch, candidates := resolveBranch(t.branches, input)
+	if branch == nil {
+		if len(candidates) == 0 {
+			return clarification(
+				fmt.Sprintf("Não encontrei a loja \"%s\". Qual o número da loja?", input),
+				nil, "Peça o número da loja ao usuário.",
+			), nil
+		}
+		options := make([]string, 0, len(candidates))
+		for _, c := range candidates {
+			if len(options) == 10 {
+				break
+			}
+			options = append(options, c.label())
+		}
+		return clarification("Qual destas lojas?", options, "Use respond_interactive com as opções."), nil
+	}
+
+	result := map[string]any{"loja": branch.label(), "location_id": branch.LocationID}
+	ticketID := optionalIntArg(args, "ticket_id")
+	if ticketID <= 0 {
+		return result, nil
+	}
+	if err := t.glpi.UpdateTicket(t.sessionToken, ticketID, glpi.UpdateTicketInput{LocationsID: branch.LocationID}); err != nil {
+		return nil, fmt.Errorf("erro ao definir loja do chamado: %w", err)
+	}
+	result["mensagem"] = fmt.Sprintf("Chamado #%d associado à %s", ticketID, branch.label())
+	return result, nil
+}
+
+var _ ai.Tool = (*SetBranch)(nil)
@@ -0,0 +1,184 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/lojasmm/laia/internal/ai"
+	"github.com/lojasmm/laia/internal/glpi"
+)
+
+// serviceCatalogTTL is how long forms and form categories are reused; both
+// only change when an admin edits FormCreator.
+const serviceCatalogTTL = time.Hour
+
+// uncategorizedForms names the group of forms without a FormCreator category.
+const uncategorizedForms = "Outros"
+
+// serviceCatalogCache is shared by every registry, like kbCategoryCache. It
+// holds every active form; access rights are checked per user afterwards.
+type serviceCatalogCache struct {
+	mu         sync.Mutex
+	forms      []glpi.Form
+	categories []glpi.FormCategory
+	loadedAt   time.Time
+}
+
+func newServiceCatalogCache() *serviceCatalogCache {
+	return &serviceCatalogCache{}
+}
+
+func (c *serviceCatalogCache) get(g *glpi.Client, sessionToken string) ([]glpi.Form, []glpi.FormCategory, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.loadedAt.IsZero() && time.Since(c.loadedAt) < serviceCatalogTTL {
+		return c.forms, c.categories, nil
+	}
+	forms, err := g.GetForms(sessionToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	categories, err := g.GetFormCategories(sessionToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.forms, c.categories, c.loadedAt = forms, categories, time.Now()
+	return forms, categories, nil
+}
+
+type catalogGroup struct {
+	Category string
+	Forms    []glpi.Form
+}
+
+// groupFormsByCategory groups forms under their category's full name, groups
+// and forms sorted by name. Forms without a category, or with one that no
+// longer exists, go to a last "Outros" group.
+func groupFormsByCategory(forms []glpi.Form, categories []glpi.FormCategory) []catalogGroup {
+	names := make(map[int]string, len(categories))
+	for _, c := range categories {
+		name := c.Completename
+		if name == "" {
+			name = c.Name
+		}
+		names[c.ID] = name
+	}
+
+	byName := map[string]*catalogGroup{}
+	var groups []*catalogGroup
+	for _, f := range forms {
+		name, ok := names[f.CategoryID]
+		if !ok {
+			name = uncategorizedForms
+		}
+		g := byName[name]
+		if g == nil {
+			g = &catalogGroup{Category: name}
+			byName[name] = g
+			groups = append(groups, g)
+		}
+		g.Forms = append(g.Forms, f)
+	}
+
+	slices.SortFunc(groups, func(a, b *catalogGroup) int {
+		if (a.Category == uncategorizedForms) != (b.Category == uncategorizedForms) {
+			if a.Category == uncategorizedForms {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Compare(a.Category, b.Category)
+	})
+	out := make([]catalogGroup, len(groups))
+	for i, g := range groups {
+		slices.SortFunc(g.Forms, func(a, b glpi.Form) int { return cmp.Compare(a.Name, b.Name) })
+		out[i] = *g
+	}
+	return out
+}
+
+// --- ServiceCatalog ---
+
+type ServiceCatalog struct {
+	glpi         *glpi.Client
+	sessionToken string
+	userID       int
+	cache        *serviceCatalogCache
+}
+
+func NewServiceCatalog(g *glpi.Client, token string, userID int, cache *serviceCatalogCache) *ServiceCatalog {
+	return &ServiceCatalog{glpi: g, sessionToken: token, userID: userID, cache: cache}
+}
+
+func (t *ServiceCatalog) Name() string   { return "get_service_catalog" }
+func (t *ServiceCatalog) ReadOnly() bool { return true }
+func (t *ServiceCatalog) Description() string {
+	return `Mostra o catalogo de servicos: os formularios que o usuario pode solicitar, agrupados por categoria.
+Quando usar: quando o usuario perguntar o que pode pedir. Ex: "o que posso solicitar?", "quais servicos a TI oferece?", "tem formulario para pedir acesso?".
+NAO usar: no fluxo de criacao de chamado — la use get_departments em silencio.
+Apresente com respond_interactive (list): primeiro as categorias, depois os formularios da categoria escolhida. Informe category para ver so uma categoria.
+O id de cada formulario e o department_id do fluxo de criacao de chamado.
+Retorna: {total_formularios, categorias: [{categoria, formularios: [{id, nome, descricao}]}]}.`
+}
+func (t *ServiceCatalog) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"category": {Type: "string", Description: "Nome (ou parte do nome) da categoria para mostrar apenas os formularios dela. Ex: 'Acessos'"},
+		},
+	}
+}
+
+func (t *ServiceCatalog) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	forms, categories, err := t.cache.get(t.glpi, t.sessionToken)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar catálogo de serviços: %w", err)
+	}
+
+	access := newFormAccessChecker(t.glpi, t.sessionToken, t.userID)
+	defer access.close()
+	var allowed []glpi.Form
+	for _, f := range forms {
+		if !internalForm(f) && access.allowed(f) {
+			allowed = append(allowed, f)
+		}
+	}
+
+	groups := groupFormsByCategory(allowed, categories)
+	if filter := optionalStringArg(args, "category"); filter != "" {
+		words := routingWords(filter)
+		matched := groups[:0]
+		for _, g := range groups {
+			if containsAll(routingWords(g.Category), words) {
+				matched = append(matched, g)
+			}
+		}
+		groups = matched
+	}
+
+	total := 0
+	items := make([]map[string]any, len(groups))
+	for i, g := range groups {
+		formItems := make([]map[string]any, len(g.Forms))
+		for j, f := range g.Forms {
+			formItems[j] = map[string]any{
+				"id":        f.ID,
+				"nome":      f.Name,
+				"descricao": truncateText(htmlToPlainText(f.Description), 100),
+			}
+		}
+		total += len(g.Forms)
+		items[i] = map[string]any{"categoria": g.Category, "formularios": formItems}
+	}
+	result := map[string]any{"total_formularios": total, "categorias": items}
+	if total == 0 {
+		result["mensagem"] = "Nenhum formulário disponível encontrado; sugira descrever o problema para abrir um chamado."
+	}
+	return result, nil
+}
+
+var _ ai.Tool = (*ServiceCatalog)(nil)
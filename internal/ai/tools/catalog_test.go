@@ -0,0 +1,54 @@
+package tools
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lojasmm/laia/internal/glpi"
+)
+
+func TestGroupFormsByCategory(t *testing.T) {
+	categories := []glpi.FormCategory{
+		{ID: 1, Name: "Acessos", Completename: "TI > Acessos"},
+		{ID: 2, Name: "Equipamentos", Completename: "TI > Equipamentos"},
+		{ID: 3, Name: "Benefícios"}, // no completename: falls back to the name
+	}
+	forms := []glpi.Form{
+		{ID: 10, Name: "Pedir notebook", CategoryID: 2},
+		{ID: 11, Name: "Resetar senha", CategoryID: 1},
+		{ID: 12, Name: "Acesso à pasta", CategoryID: 1},
+		{ID: 13, Name: "Sugestão", CategoryID: 0},
+		{ID: 14, Name: "Vale transporte", CategoryID: 3},
+		{ID: 15, Name: "Antigo", CategoryID: 99}, // deleted category
+	}
+
+	got := groupFormsByCategory(forms, categories)
+
+	type group struct {
+		category string
+		forms    []int
+	}
+	want := []group{
+		{"Benefícios", []int{14}},
+		{"TI > Acessos", []int{12, 11}},
+		{"TI > Equipamentos", []int{10}},
+		{uncategorizedForms, []int{15, 13}},
+	}
+	gotGroups := make([]group, len(got))
+	for i, g := range got {
+		ids := make([]int, len(g.Forms))
+		for j, f := range g.Forms {
+			ids[j] = f.ID
+		}
+		gotGroups[i] = group{g.Category, ids}
+	}
+	if !reflect.DeepEqual(gotGroups, want) {
+		t.Errorf("groupFormsByCategory = %+v, want %+v", gotGroups, want)
+	}
+}
+
+func TestGroupFormsByCategoryEmpty(t *testing.T) {
+	if got := groupFormsByCategory(nil, []glpi.FormCategory{{ID: 1, Name: "Acessos"}}); len(got) != 0 {
+		t.Errorf("groupFormsByCategory(nil) = %+v, want no groups", got)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 
 	"github.com/lojasmm/laia/internal/ai"
 	"github.com/lojasmm/laia/internal/glpi"
@@ -14,10 +15,11 @@ import (
 type GetDepartments struct {
 	glpi         *glpi.Client
 	sessionToken string
+	userID       int
 }
 
-func NewGetDepartments(g *glpi.Client, token string) *GetDepartments {
-	return &GetDepartments{glpi: g, sessionToken: token}
+func NewGetDepartments(g *glpi.Client, token string, userID int) *GetDepartments {
+	return &GetDepartments{glpi: g, sessionToken: token, userID: userID}
 }
 
 func (t *GetDepartments) Name() string     { return "get_departments" }
@@ -26,6 +28,7 @@ func (t *GetDepartments) Description() string {
 	return `Lista os departamentos/setores disponiveis para abertura de chamados.
 Quando usar: no fluxo de criacao de chamado (Etapa 2) para determinar o setor correto.
 NAO mostre a lista completa ao usuario — use para decidir internamente e confirmar.
+Inclui apenas formularios que o perfil do usuario pode usar.
 Retorna: lista com id e nome de cada departamento/formulario.`
 }
 func (t *GetDepartments) Parameters() *ai.ParamSchema { return nil }
@@ -36,9 +39,15 @@ func (t *GetDepartments) Execute(_ context.Context, _ map[string]any) (map[strin
 		return nil, fmt.Errorf("erro ao buscar departamentos: %w", err)
 	}
 
+	access := newFormAccessChecker(t.glpi, t.sessionToken, t.userID)
+	defer access.close()
+
 	items := make([]map[string]any, 0, len(forms))
 	for _, f := range forms {
-		if f.Name == "Abro chamado a quem? GUIA" || f.Name == "Abrir Chamado Loja" {
+		if internalForm(f) {
+			continue
+		}
+		if !access.allowed(f) {
 			continue
 		}
 		items = append(items, map[string]any{
@@ -49,15 +58,106 @@ func (t *GetDepartments) Execute(_ context.Context, _ map[string]any) (map[strin
 	return map[string]any{"total": len(items), "departamentos": items}, nil
 }
 
+// formAccessChecker decides whether the session user may submit a form.
+// Allow-lists are read with the admin session because self-service profiles
+// can't read PluginFormcreatorForm_Profile/_User; it's opened on the first
+// restricted form only.
+type formAccessChecker struct {
+	glpi         *glpi.Client
+	sessionToken string
+	userID       int
+
+	loaded       bool
+	adminSession string
+	profileID    int
+}
+
+func newFormAccessChecker(g *glpi.Client, token string, userID int) *formAccessChecker {
+	return &formAccessChecker{glpi: g, sessionToken: token, userID: userID}
+}
+
+// internalForm reports the forms never offered to users through the bot: the
+// routing guide and the store form.
+func internalForm(f glpi.Form) bool {
+	return f.Name == "Abro chamado a quem? GUIA" || f.Name == "Abrir Chamado Loja"
+}
+
+func (c *formAccessChecker) load() {
+	c.loaded = true
+	if p, err := c.glpi.ActiveProfile(c.sessionToken); err == nil {
+		c.profileID = p.ID
+	} else {
+		slog.Warn("tools: get_departments could not read active profile", "tool", "get_departments", "error", err)
+	}
+	if session, err := c.glpi.AdminSession(glpi.AdminReadReference); err == nil {
+		c.adminSession = session
+	} else {
+		slog.Warn("tools: get_departments could not open admin session", "tool", "get_departments", "error", err)
+	}
+}
+
+func (c *formAccessChecker) close() {
+	if c.adminSession != "" {
+		c.glpi.KillSession(c.adminSession)
+	}
+}
+
+// allowed keeps public/private forms and restricted forms listing the user's
+// profile or the user. If the allow-list can't be read the form is kept —
+// hiding every restricted department on a lookup failure would be worse.
+func (c *formAccessChecker) allowed(f glpi.Form) bool {
+	if f.AccessRights != glpi.FormAccessRestricted {
+		return true
+	}
+	if !c.loaded {
+		c.load()
+	}
+	if c.adminSession == "" {
+		return true
+	}
+
+	profiles, err := c.glpi.GetFormAccessList(c.adminSession, "PluginFormcreatorForm_Profile", f.ID)
+	if err != nil {
+		slog.Warn("tools: form access lookup failed", "tool", "get_departments", "form_id", f.ID, "error", err)
+		return true
+	}
+	users, err := c.glpi.GetFormAccessList(c.adminSession, "PluginFormcreatorForm_User", f.ID)
+	if err != nil {
+		// Older FormCreator versions only restrict by profile
+		users = nil
+	}
+	return formAllowsUser(profiles, users, c.profileID, c.userID)
+}
+
+// formAllowsUser reports whether a restricted form's allow-lists include the profile or user.
+// TODO: FormCreator also supports group restrictions (PluginFormcreatorForm_Group);
+// needs the user's groups from Group_User to be checked here.
+func formAllowsUser(profiles, users []glpi.FormAccess, profileID, userID int) bool {
+	for _, p := range profiles {
+		if profileID > 0 && p.ProfilesID == profileID {
+			return true
+		}
+	}
+	for _, u := range users {
+		if u.UsersID == userID {
+			return true
+		}
+	}
+	return false
+}
+
 // --- GetDepartmentCategories ---
 
 type GetDepartmentCategories struct {
 	glpi         *glpi.Client
 	sessionToken string
+	alerts       *configAlerts
+	slas         *categorySLACache
+	urgency      UrgencyPolicy
 }
 
-func NewGetDepartmentCategories(g *glpi.Client, token string) *GetDepartmentCategories {
-	return &GetDepartmentCategories{glpi: g, sessionToken: token}
+func NewGetDepartmentCategories(g *glpi.Client, token string, alerts *configAlerts, slas *categorySLACache, urgency UrgencyPolicy) *GetDepartmentCategories {
+	return &GetDepartmentCategories{glpi: g, sessionToken: token, alerts: alerts, slas: slas, urgency: urgency}
 }
 
 func (t *GetDepartmentCategories) Name() string     { return "get_department_categories" }
@@ -67,8 +167,10 @@ func (t *GetDepartmentCategories) Description() string {
 Quando usar: no fluxo de criacao de chamado (Etapa 3) apos determinar o departamento.
 NAO mostre a lista completa ao usuario — analise e use respond_interactive com opcoes filtradas.
 O campo 'id' (category_id) e o que deve ser passado para create_ticket.
-Se retornar total=0, o departamento pode nao ter categorias configuradas — informe o campo 'erro'.
-Retorna: {total, categorias: [{id, nome}]}.`
+Se retornar total=0, o setor esta com problema de configuracao no Nexus: informe o campo 'erro' e ofereca outro setor ou human_handoff. Nao tente create_ticket sem categoria.
+'sla_resolucao' (quando existir) e o prazo de solucao previsto da categoria: mencione-o ao oferecer as opcoes se ajudar o usuario a escolher, sem prometer o prazo.
+Se a categoria escolhida vier com perguntar_urgencia=false, NAO pergunte a urgencia (o padrao e aplicado), a menos que o usuario tenha dito que e urgente.
+Retorna: {total, categorias: [{id, nome, sla_resolucao?, perguntar_urgencia?}]} ou {total: 0, problema: sem_pergunta_categoria|categorias_vazias, erro}.`
 }
 func (t *GetDepartmentCategories) Parameters() *ai.ParamSchema {
 	return &ai.ParamSchema{
@@ -80,7 +182,7 @@ func (t *GetDepartmentCategories) Parameters() *ai.ParamSchema {
 	}
 }
 
-func (t *GetDepartmentCategories) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+func (t *GetDepartmentCategories) Execute(ctx context.Context, args map[string]any) (map[string]any, error) {
 	formID, err := intArg(args, "department_id")
 	if err != nil {
 		return nil, err
@@ -91,9 +193,11 @@ func (t *GetDepartmentCategories) Execute(_ context.Context, args map[string]any
 		return nil, fmt.Errorf("erro ao buscar seções do formulário: %w", err)
 	}
 
+	var sectionErr error
 	for _, s := range sections {
 		questions, err := t.glpi.GetSectionQuestions(t.sessionToken, s.ID)
 		if err != nil {
+			sectionErr = err
 			continue
 		}
 
@@ -112,7 +216,7 @@ func (t *GetDepartmentCategories) Execute(_ context.Context, args map[string]any
 				fmt.Sscanf(vals.ShowTreeRoot, "%d", &rootID)
 			}
 
-			adminSession, err := t.glpi.AdminSession()
+			adminSession, err := t.glpi.AdminSession(glpi.AdminReadReference)
 			if err != nil {
 				return nil, fmt.Errorf("erro ao criar sessão admin: %w", err)
 			}
@@ -123,27 +227,64 @@ func (t *GetDepartmentCategories) Execute(_ context.Context, args map[string]any
 				return nil, fmt.Errorf("erro ao buscar categorias: %w", err)
 			}
 
-			items := make([]map[string]any, len(categories))
-			for i, c := range categories {
-				items[i] = map[string]any{
-					"id":   c.ID,
-					"nome": c.Name,
-				}
+			if len(categories) == 0 {
+				t.alerts.report(ctx, fmt.Sprintf("form:%d:empty_categories", formID),
+					fmt.Sprintf("⚠️ Laia: o formulário #%d tem a pergunta de categoria, mas a categoria raiz #%d não tem subcategorias. "+
+						"Usuários não conseguem abrir chamados nesse setor pelo WhatsApp.", formID, rootID),
+					"form_id", formID, "root_category_id", rootID)
+				return map[string]any{
+					"total":      0,
+					"categorias": []map[string]any{},
+					"problema":   "categorias_vazias",
+					"erro":       "este setor está sem categorias cadastradas no Nexus; a equipe responsável foi avisada",
+				}, nil
 			}
+
 			return map[string]any{
 				"total":      len(categories),
-				"categorias": items,
+				"categorias": categoryItems(categories, t.slas.get(t.glpi, adminSession), t.urgency),
 			}, nil
 		}
 	}
 
+	// A section we couldn't read may hold the question, so this is not
+	// (yet) a misconfiguration.
+	if sectionErr != nil {
+		return nil, fmt.Errorf("erro ao buscar perguntas do formulário: %w", sectionErr)
+	}
+	t.alerts.report(ctx, fmt.Sprintf("form:%d:no_category_question", formID),
+		fmt.Sprintf("⚠️ Laia: o formulário #%d não tem pergunta do tipo lista suspensa de Categoria ITIL. "+
+			"Usuários não conseguem abrir chamados nesse setor pelo WhatsApp até a pergunta ser adicionada.", formID),
+		"form_id", formID, "sections", len(sections))
 	return map[string]any{
 		"total":      0,
 		"categorias": []map[string]any{},
-		"erro":       "nenhuma categoria encontrada para este formulário",
+		"problema":   "sem_pergunta_categoria",
+		"erro":       "este setor não está configurado para abertura de chamados pelo WhatsApp; a equipe responsável foi avisada",
 	}, nil
 }
 
+// categoryItems lists categories for the LLM, with the resolution SLA of
+// those that have one and a flag on those that skip the urgency question.
+func categoryItems(categories []glpi.ITILCategory, slas map[int]glpi.SLA, urgency UrgencyPolicy) []map[string]any {
+	items := make([]map[string]any, len(categories))
+	for i, c := range categories {
+		items[i] = map[string]any{
+			"id":   c.ID,
+			"nome": c.Name,
+		}
+		if sla, ok := slas[c.ID]; ok {
+			if label := slaTargetLabel(sla); label != "" {
+				items[i]["sla_resolucao"] = label
+			}
+		}
+		if !urgency.asks(c.ID, c.ITILCategoriesID) {
+			items[i]["perguntar_urgencia"] = false
+		}
+	}
+	return items
+}
+
 // dropdownValues extracts the tree root config from FormCreator question values.
 type dropdownValues struct {
 	ShowTreeRoot string `json:"show_tree_root"`
@@ -152,11 +293,13 @@ type dropdownValues struct {
 // --- GetSubCategories ---
 
 type GetSubCategories struct {
-	glpi *glpi.Client
+	glpi    *glpi.Client
+	slas    *categorySLACache
+	urgency UrgencyPolicy
 }
 
-func NewGetSubCategories(g *glpi.Client) *GetSubCategories {
-	return &GetSubCategories{glpi: g}
+func NewGetSubCategories(g *glpi.Client, slas *categorySLACache, urgency UrgencyPolicy) *GetSubCategories {
+	return &GetSubCategories{glpi: g, slas: slas, urgency: urgency}
 }
 
 func (t *GetSubCategories) Name() string     { return "get_subcategories" }
@@ -165,7 +308,7 @@ func (t *GetSubCategories) Description() string {
 	return `Lista as sub-categorias de uma categoria ITIL.
 Quando usar: no fluxo de criacao de chamado (Etapa 3) quando uma categoria tem sub-niveis.
 Se retornar total=0, a categoria nao possui sub-categorias — use o ID da propria categoria no create_ticket.
-Retorna: {total, categorias: [{id, nome}]}.`
+Retorna: {total, categorias: [{id, nome, sla_resolucao?, perguntar_urgencia?}]}.`
 }
 func (t *GetSubCategories) Parameters() *ai.ParamSchema {
 	return &ai.ParamSchema{
@@ -183,7 +326,7 @@ func (t *GetSubCategories) Execute(_ context.Context, args map[string]any) (map[
 		return nil, err
 	}
 
-	adminSession, err := t.glpi.AdminSession()
+	adminSession, err := t.glpi.AdminSession(glpi.AdminReadReference)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao criar sessão admin: %w", err)
 	}
@@ -194,14 +337,7 @@ func (t *GetSubCategories) Execute(_ context.Context, args map[string]any) (map[
 		return nil, fmt.Errorf("erro ao buscar sub-categorias: %w", err)
 	}
 
-	items := make([]map[string]any, len(categories))
-	for i, c := range categories {
-		items[i] = map[string]any{
-			"id":   c.ID,
-			"nome": c.Name,
-		}
-	}
-	return map[string]any{"total": len(categories), "categorias": items}, nil
+	return map[string]any{"total": len(categories), "categorias": categoryItems(categories, t.slas.get(t.glpi, adminSession), t.urgency)}, nil
 }
 
 var _ ai.Tool = (*GetDepartments)(nil)
@@ -0,0 +1,140 @@
+package tools
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lojasmm/laia/internal/glpi"
+)
+
+// categorySLATTL is how long the category→SLA map is reused; it comes from
+// business rules, which change even less often than categories.
+const categorySLATTL = time.Hour
+
+// categorySLACache maps ITIL categories to the resolution SLA that ticket
+// business rules assign them. GLPI has no SLA field on categories; the usual
+// setup is one rule per category ("Categoria é X" → "SLA TTR = Y"). It is
+// shared by every registry, like kbCategoryCache.
+type categorySLACache struct {
+	mu         sync.Mutex
+	byCategory map[int]glpi.SLA
+	loadedAt   time.Time
+}
+
+func newCategorySLACache() *categorySLACache {
+	return &categorySLACache{}
+}
+
+// get returns the SLA of each category that has one. Errors only leave the
+// SLA out of the category list, so they're logged and cached like a result:
+// a profile without access to rules would otherwise pay four requests per call.
+func (c *categorySLACache) get(g *glpi.Client, sessionToken string) map[int]glpi.SLA {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.loadedAt.IsZero() && time.Since(c.loadedAt) < categorySLATTL {
+		return c.byCategory
+	}
+	m, err := loadCategorySLAs(g, sessionToken)
+	if err != nil {
+		slog.Warn("tools: could not resolve category SLAs", "error", err)
+	}
+	c.byCategory, c.loadedAt = m, time.Now()
+	return m
+}
+
+func loadCategorySLAs(g *glpi.Client, sessionToken string) (map[int]glpi.SLA, error) {
+	slas, err := g.GetSLAs(sessionToken)
+	if err != nil {
+		return nil, fmt.Errorf("slas: %w", err)
+	}
+	ttr := make(map[int]glpi.SLA, len(slas))
+	for _, s := range slas {
+		if s.Type == glpi.SLATypeTTR {
+			ttr[s.ID] = s
+		}
+	}
+	if len(ttr) == 0 {
+		return nil, nil
+	}
+
+	rules, err := g.GetTicketRules(sessionToken)
+	if err != nil {
+		return nil, fmt.Errorf("rules: %w", err)
+	}
+	actions, err := g.GetRuleActions(sessionToken, "slas_id_ttr")
+	if err != nil {
+		return nil, fmt.Errorf("rule actions: %w", err)
+	}
+	criteria, err := g.GetRuleCriteria(sessionToken, "itilcategories_id")
+	if err != nil {
+		return nil, fmt.Errorf("rule criteria: %w", err)
+	}
+
+	ruleSLA := make(map[int]int, len(actions))
+	for _, a := range actions {
+		if id, err := strconv.Atoi(a.Value); err == nil && a.ActionType == "assign" {
+			ruleSLA[a.RulesID] = id
+		}
+	}
+	// Rules run by ranking and a later match overwrites the SLA, so the
+	// highest-ranked rule wins when several name the same category.
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Ranking < rules[j].Ranking })
+	rank := make(map[int]int, len(rules))
+	for i, r := range rules {
+		if r.IsActive == 1 {
+			rank[r.ID] = i + 1
+		}
+	}
+
+	out := map[int]glpi.SLA{}
+	winner := map[int]int{} // category → rank of the rule that set it
+	for _, cr := range criteria {
+		slaID, ok := ruleSLA[cr.RulesID]
+		sla, known := ttr[slaID]
+		if !ok || !known || rank[cr.RulesID] == 0 || cr.Condition != glpi.RulePatternIs {
+			continue
+		}
+		categoryID, err := strconv.Atoi(cr.Pattern)
+		if err != nil {
+			continue
+		}
+		if rank[cr.RulesID] > winner[categoryID] {
+			out[categoryID], winner[categoryID] = sla, rank[cr.RulesID]
+		}
+	}
+	return out, nil
+}
+
+// slaTargetLabel renders an SLA target, e.g. "4 horas" or "2 dias úteis".
+// SLAs with a calendar only count business hours.
+func slaTargetLabel(s glpi.SLA) string {
+	units := map[string][2]string{
+		"minute": {"minuto", "minutos"},
+		"hour":   {"hora", "horas"},
+		"day":    {"dia", "dias"},
+		"month":  {"mês", "meses"},
+	}
+	unit, ok := units[s.DefinitionTime]
+	if !ok || s.NumberTime <= 0 {
+		return ""
+	}
+	label := fmt.Sprintf("%d %s", s.NumberTime, unit[1])
+	if s.NumberTime == 1 {
+		label = "1 " + unit[0]
+	}
+	if s.CalendarsID > 0 {
+		if s.NumberTime == 1 {
+			label += " útil"
+		} else {
+			label += " úteis"
+		}
+	}
+	return label
+}
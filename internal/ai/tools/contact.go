@@ -0,0 +1,148 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"github.com/lojasmm/laia/internal/ai"
+	"github.com/lojasmm/laia/internal/glpi"
+	"github.com/lojasmm/laia/internal/store"
+)
+
+// validContactEmail accepts a bare address with a dotted domain; GLPI sends
+// notifications to whatever is stored, so typos must stop here.
+func validContactEmail(s string) (string, bool) {
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Name != "" {
+		return "", false
+	}
+	_, domain, _ := strings.Cut(addr.Address, "@")
+	if !strings.Contains(domain, ".") || strings.HasSuffix(domain, ".") {
+		return "", false
+	}
+	return strings.ToLower(addr.Address), true
+}
+
+// validContactPhone normalizes a phone to "+<digits>" (see store.NormalizePhone).
+// Users type local numbers, so a 10-digit one is taken as a Brazilian
+// landline with area code. E.164 allows 11 (NANP) to 15 digits here.
+func validContactPhone(s string) (string, bool) {
+	digits := store.NormalizePhone(s)
+	if len(digits) == 10 {
+		digits = "55" + digits
+	}
+	if len(digits) < 11 || len(digits) > 15 {
+		return "", false
+	}
+	return "+" + digits, true
+}
+
+// --- UpdateContactInfo ---
+
+type UpdateContactInfo struct {
+	glpi         *glpi.Client
+	sessionToken string
+	userID       int
+}
+
+func NewUpdateContactInfo(g *glpi.Client, token string, userID int) *UpdateContactInfo {
+	return &UpdateContactInfo{glpi: g, sessionToken: token, userID: userID}
+}
+
+func (t *UpdateContactInfo) Name() string   { return "update_contact_info" }
+func (t *UpdateContactInfo) ReadOnly() bool { return false }
+func (t *UpdateContactInfo) Description() string {
+	return `Atualiza o contato do usuario para o tecnico falar com ele: e-mail alternativo do chamado e/ou telefone celular do cadastro.
+Quando usar: quando o usuario disser que o e-mail/telefone do chamado esta errado ou quiser ser contatado em outro. Ex: "meu celular mudou, e 11 98765-4321", "manda as atualizacoes do chamado 123 para joao@lojasmm.com.br".
+Confirme o contato com o usuario antes. So funciona para chamados em que ele e solicitante.
+Se o cadastro nao puder ser alterado, o contato e registrado como comentario no chamado.
+Retorna: {email_atualizado, telefone_atualizado, registrado_em_comentario, mensagem}.`
+}
+func (t *UpdateContactInfo) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"ticket_id": {Type: "integer", Description: "ID do chamado"},
+			"email":     {Type: "string", Description: "E-mail para receber as notificações deste chamado"},
+			"phone":     {Type: "string", Description: "Telefone celular com DDD. Ex: '11 98765-4321'"},
+		},
+		Required: []string{"ticket_id"},
+	}
+}
+
+func (t *UpdateContactInfo) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	ticketID, err := intArg(args, "ticket_id")
+	if err != nil {
+		return nil, err
+	}
+	rawEmail := strings.TrimSpace(optionalStringArg(args, "email"))
+	rawPhone := strings.TrimSpace(optionalStringArg(args, "phone"))
+	if rawEmail == "" && rawPhone == "" {
+		return nil, fmt.Errorf("informe email ou phone")
+	}
+	var email, phone string
+	if rawEmail != "" {
+		var ok bool
+		if email, ok = validContactEmail(rawEmail); !ok {
+			return nil, fmt.Errorf("e-mail inválido: %q", rawEmail)
+		}
+	}
+	if rawPhone != "" {
+		var ok bool
+		if phone, ok = validContactPhone(rawPhone); !ok {
+			return nil, fmt.Errorf("telefone inválido: %q (informe com DDD, ex: 11 98765-4321)", rawPhone)
+		}
+	}
+
+	actors, err := t.glpi.GetTicketUsers(t.sessionToken, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar solicitantes do chamado: %w", err)
+	}
+	var link *glpi.TicketUser
+	for i, a := range actors {
+		if a.Type == glpi.ActorRequester && a.UsersID == t.userID {
+			link = &actors[i]
+			break
+		}
+	}
+	if link == nil {
+		return map[string]any{
+			"email_atualizado":    false,
+			"telefone_atualizado": false,
+			"mensagem":            fmt.Sprintf("Você não é solicitante do chamado #%d, então não dá para alterar o contato nele.", ticketID),
+		}, nil
+	}
+
+	result := map[string]any{"email_atualizado": false, "telefone_atualizado": false, "registrado_em_comentario": false}
+	var pending []string // what couldn't be saved in place, for the followup
+	if email != "" {
+		if err := t.glpi.UpdateTicketUser(t.sessionToken, link.ID, glpi.UpdateTicketUserInput{UseNotification: 1, AlternativeEmail: email}); err != nil {
+			pending = append(pending, "e-mail "+email)
+		} else {
+			result["email_atualizado"] = true
+		}
+	}
+	if phone != "" {
+		if err := t.glpi.UpdateUser(t.sessionToken, t.userID, glpi.UpdateUserInput{Mobile: phone}); err != nil {
+			pending = append(pending, "telefone "+phone)
+		} else {
+			result["telefone_atualizado"] = true
+		}
+	}
+
+	if len(pending) > 0 {
+		note := "Contato atualizado pelo solicitante: " + strings.Join(pending, ", ") + "."
+		if _, err := t.glpi.AddFollowup(t.sessionToken, ticketID, note); err != nil {
+			return nil, fmt.Errorf("erro ao registrar contato no chamado: %w", err)
+		}
+		result["registrado_em_comentario"] = true
+		result["mensagem"] = fmt.Sprintf("Não foi possível alterar o cadastro, então o novo contato foi registrado como comentário no chamado #%d.", ticketID)
+		return result, nil
+	}
+	result["mensagem"] = fmt.Sprintf("Contato atualizado no chamado #%d.", ticketID)
+	return result, nil
+}
+
+var _ ai.Tool = (*UpdateContactInfo)(nil)
@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/lojasmm/laia/internal/ai"
+)
+
+// CustomField maps an answer collected by create_ticket to a GLPI plugin Fields
+// field on the ticket. Loaded from CUSTOM_FIELDS_FILE:
+//
+//	[{"key": "matricula", "field": "matriculafield", "description": "Matricula do funcionario afetado"}]
+//
+// Field is the plugin's internal field name, shown under Setup > Additional
+// fields > (container) > Fields.
+// Reference: https://github.com/pluginsGLPI/fields
+type CustomField struct {
+	Key         string `json:"key"`
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+// LoadCustomFields reads the custom field mapping. An empty path disables the
+// custom_fields parameter of create_ticket.
+func LoadCustomFields(path string) ([]CustomField, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fields []CustomField
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	seen := make(map[string]bool, len(fields))
+	for i, f := range fields {
+		if f.Key == "" || f.Field == "" {
+			return nil, fmt.Errorf("%s: custom field %d needs key and field", path, i)
+		}
+		if seen[f.Key] {
+			return nil, fmt.Errorf("%s: duplicate custom field key %q", path, f.Key)
+		}
+		seen[f.Key] = true
+	}
+	return fields, nil
+}
+
+// customFieldsSchema describes the custom_fields object of create_ticket, one
+// property per configured key.
+func customFieldsSchema(fields []CustomField) *ai.ParamSchema {
+	props := make(map[string]*ai.ParamSchema, len(fields))
+	for _, f := range fields {
+		props[f.Key] = &ai.ParamSchema{Type: "string", Description: f.Description}
+	}
+	return &ai.ParamSchema{
+		Type:        "object",
+		Description: "Campos adicionais do formulario, quando o usuario informar",
+		Properties:  props,
+	}
+}
+
+// mapCustomFields translates the model's answers to plugin field names,
+// dropping blank values. Unknown keys are an error so the model learns the
+// valid ones instead of having them silently discarded.
+func mapCustomFields(fields []CustomField, raw any) (map[string]any, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	answers, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("custom_fields deve ser um objeto")
+	}
+	byKey := make(map[string]string, len(fields))
+	for _, f := range fields {
+		byKey[f.Key] = f.Field
+	}
+
+	out := make(map[string]any, len(answers))
+	var unknown []string
+	for k, v := range answers {
+		field, ok := byKey[k]
+		if !ok {
+			unknown = append(unknown, k)
+			continue
+		}
+		if s, isStr := v.(string); isStr && strings.TrimSpace(s) == "" {
+			continue
+		}
+		out[field] = v
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		valid := make([]string, 0, len(fields))
+		for _, f := range fields {
+			valid = append(valid, f.Key)
+		}
+		return nil, fmt.Errorf("custom_fields desconhecidos: %s (validos: %s)", strings.Join(unknown, ", "), strings.Join(valid, ", "))
+	}
+	return out, nil
+}
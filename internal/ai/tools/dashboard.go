@@ -0,0 +1,157 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/lojasmm/laia/internal/ai"
+	"github.com/lojasmm/laia/internal/glpi"
+)
+
+// dashboardRatingChecks is how many of the newest closed tickets are checked
+// for an unanswered survey; each check is one request.
+const dashboardRatingChecks = 5
+
+// --- Dashboard ---
+
+type Dashboard struct {
+	glpi         *glpi.Client
+	sessionToken string
+	userID       int
+}
+
+func NewDashboard(g *glpi.Client, token string, userID int) *Dashboard {
+	return &Dashboard{glpi: g, sessionToken: token, userID: userID}
+}
+
+func (t *Dashboard) Name() string   { return "my_dashboard" }
+func (t *Dashboard) ReadOnly() bool { return true }
+func (t *Dashboard) Description() string {
+	return `Painel resumido do usuario em uma chamada: chamados em aberto, aprovacoes aguardando ele, chamados fechados sem avaliacao e a ultima atualizacao.
+Quando usar: quando o usuario pedir um resumo geral. Ex: "meu resumo", "meu painel", "como estao minhas coisas?", "o que tem pra mim?".
+NAO usar: para listar os chamados em detalhe — use list_my_tickets.
+Se alguma parte falhar, ela vem em indisponivel; mostre o resto normalmente.
+Retorna: {chamados_abertos, chamados_pendentes, aprovacoes_pendentes, avaliacoes_pendentes: [ids], ultima_atualizacao: {id, titulo, status, data}, indisponivel}.`
+}
+func (t *Dashboard) Parameters() *ai.ParamSchema { return nil }
+
+func (t *Dashboard) Execute(_ context.Context, _ map[string]any) (map[string]any, error) {
+	var (
+		wg                      sync.WaitGroup
+		tickets                 []glpi.Ticket
+		validations             []glpi.TicketValidation
+		ticketsErr, approvalErr error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		tickets, ticketsErr = t.glpi.GetMyTickets(t.sessionToken)
+	}()
+	go func() {
+		defer wg.Done()
+		validations, approvalErr = t.glpi.GetMyValidations(t.sessionToken, t.userID)
+	}()
+	wg.Wait()
+
+	if ticketsErr != nil && approvalErr != nil {
+		return nil, fmt.Errorf("erro ao montar painel: %w", ticketsErr)
+	}
+
+	result := map[string]any{}
+	var unavailable []string
+	if approvalErr != nil {
+		unavailable = append(unavailable, "aprovacoes")
+	} else {
+		result["aprovacoes_pendentes"] = len(validations)
+	}
+	if ticketsErr != nil {
+		unavailable = append(unavailable, "chamados", "avaliacoes")
+		result["indisponivel"] = unavailable
+		return result, nil
+	}
+
+	summary := summarizeTickets(tickets)
+	result["chamados_abertos"] = summary.open
+	result["chamados_pendentes"] = summary.pending
+	if summary.latest != nil {
+		result["ultima_atualizacao"] = map[string]any{
+			"id":     summary.latest.ID,
+			"titulo": summary.latest.Name,
+			"status": ticketStatusLabel(summary.latest.Status),
+			"data":   summary.latest.DateMod,
+		}
+	}
+
+	unrated, err := t.unratedTickets(summary.closed)
+	if err != nil {
+		unavailable = append(unavailable, "avaliacoes")
+	}
+	result["avaliacoes_pendentes"] = unrated
+	if len(unavailable) > 0 {
+		result["indisponivel"] = unavailable
+	}
+	return result, nil
+}
+
+type ticketSummary struct {
+	open, pending int
+	latest        *glpi.Ticket
+	closed        []glpi.Ticket // newest first
+}
+
+// summarizeTickets counts open (new/processing) and pending tickets and picks
+// the most recently modified one. GLPI dates sort as strings.
+func summarizeTickets(tickets []glpi.Ticket) ticketSummary {
+	var s ticketSummary
+	for i, tk := range tickets {
+		switch {
+		case tk.Status <= 3:
+			s.open++
+		case tk.Status == 4:
+			s.pending++
+		case tk.Status == 6:
+			s.closed = append(s.closed, tk)
+		}
+		if s.latest == nil || tk.DateMod > s.latest.DateMod {
+			s.latest = &tickets[i]
+		}
+	}
+	sort.Slice(s.closed, func(i, j int) bool { return s.closed[i].DateMod > s.closed[j].DateMod })
+	return s
+}
+
+// unratedTickets returns the IDs among the newest closed tickets whose
+// satisfaction survey is still unanswered. Tickets without a survey are not
+// counted. The error is the first failed check; the other results still count.
+func (t *Dashboard) unratedTickets(closed []glpi.Ticket) ([]int, error) {
+	closed = closed[:min(len(closed), dashboardRatingChecks)]
+	unrated := make([]bool, len(closed))
+	errs := make([]error, len(closed))
+	var wg sync.WaitGroup
+	for i, tk := range closed {
+		wg.Add(1)
+		go func(i, ticketID int) {
+			defer wg.Done()
+			survey, err := t.glpi.GetTicketSatisfaction(t.sessionToken, ticketID)
+			errs[i] = err
+			unrated[i] = err == nil && survey != nil && survey.DateAnswered == ""
+		}(i, tk.ID)
+	}
+	wg.Wait()
+
+	ids := []int{}
+	var firstErr error
+	for i, tk := range closed {
+		if unrated[i] {
+			ids = append(ids, tk.ID)
+		}
+		if errs[i] != nil && firstErr == nil {
+			firstErr = errs[i]
+		}
+	}
+	return ids, firstErr
+}
+
+var _ ai.Tool = (*Dashboard)(nil)
@@ -0,0 +1,240 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/lojasmm/laia/internal/ai"
+	"github.com/lojasmm/laia/internal/glpi"
+)
+
+const (
+	// maxListedDocuments bounds the documents read for one ticket; each one
+	// is a GLPI request.
+	maxListedDocuments = 10
+	// maxDocumentBytes is well under WhatsApp's 100 MB document limit: the
+	// file is held in memory until it is uploaded to Meta.
+	// Reference: https://developers.facebook.com/docs/whatsapp/cloud-api/reference/media#supported-media-types
+	maxDocumentBytes = 16 << 20
+)
+
+// whatsAppImageExt returns the file extension for MIME types WhatsApp can send
+// as an image, or "" for anything else: WhatsApp images only accept JPEG and PNG.
+func whatsAppImageExt(mimeType string) string {
+	mimeType, _, _ = strings.Cut(mimeType, ";")
+	return map[string]string{"image/jpeg": "jpg", "image/png": "png"}[strings.TrimSpace(mimeType)]
+}
+
+// documentIsImage reports whether a document can be shown as a WhatsApp
+// image. GLPI leaves mime empty on some documents, so the filename extension
+// is the fallback.
+func documentIsImage(doc glpi.Document) bool {
+	if doc.Mime != "" {
+		return whatsAppImageExt(doc.Mime) != ""
+	}
+	switch strings.ToLower(path.Ext(doc.Filename)) {
+	case ".jpg", ".jpeg", ".png":
+		return true
+	}
+	return false
+}
+
+// downloadImage downloads an image document and checks it really is one,
+// since GLPI's mime isn't always right. ok is false for anything WhatsApp
+// can't send as an image.
+func downloadImage(g *glpi.Client, session string, docID int) (data []byte, mimeType, ext string, ok bool) {
+	data, mimeType, err := g.DownloadDocument(session, docID, maxInlineImageBytes)
+	if err != nil {
+		return nil, "", "", false
+	}
+	if !strings.HasPrefix(mimeType, "image/") {
+		mimeType = http.DetectContentType(data)
+	}
+	mimeType, _, _ = strings.Cut(mimeType, ";")
+	ext = whatsAppImageExt(mimeType)
+	return data, mimeType, ext, ext != ""
+}
+
+// --- ListTicketDocuments ---
+
+type ListTicketDocuments struct {
+	glpi         *glpi.Client
+	sessionToken string
+	conv         *ai.Conversation
+}
+
+func NewListTicketDocuments(g *glpi.Client, token string, conv *ai.Conversation) *ListTicketDocuments {
+	return &ListTicketDocuments{glpi: g, sessionToken: token, conv: conv}
+}
+
+func (t *ListTicketDocuments) Name() string   { return "list_ticket_documents" }
+func (t *ListTicketDocuments) ReadOnly() bool { return true }
+func (t *ListTicketDocuments) Description() string {
+	return `Lista os anexos (documentos) de um chamado. As imagens sao enviadas ao usuario como previa no WhatsApp.
+Quando usar: quando o usuario quiser ver os anexos de um chamado. Ex: "quais arquivos tem no chamado 123?", "me manda os anexos do chamado".
+NAO usar: para os prints colados na descricao — use get_ticket_description.
+As previas sao enviadas automaticamente apos sua resposta; nao tente descreve-las. Para os anexos que nao sao imagem, mostre o nome e ofereca enviar com respond_interactive (botao "Enviar"); se o usuario aceitar, use send_ticket_document.
+Retorna: {total, documentos: [{id, nome, arquivo, tipo, imagem, previa_enviada}], previas_enviadas}.`
+}
+func (t *ListTicketDocuments) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"ticket_id": {Type: "integer", Description: "ID do chamado"},
+		},
+		Required: []string{"ticket_id"},
+	}
+}
+
+func (t *ListTicketDocuments) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	ticketID, err := intArg(args, "ticket_id")
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := t.glpi.GetTicketDocumentIDs(t.sessionToken, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar anexos: %w", err)
+	}
+	if len(ids) == 0 {
+		return map[string]any{
+			"total":      0,
+			"documentos": []map[string]any{},
+			"mensagem":   fmt.Sprintf("O chamado #%d não tem anexos.", ticketID),
+		}, nil
+	}
+
+	docs := []map[string]any{}
+	previews := 0
+	for _, id := range ids[:min(len(ids), maxListedDocuments)] {
+		doc, err := t.glpi.GetDocument(t.sessionToken, id)
+		if err != nil {
+			continue // no read right on this document
+		}
+		item := map[string]any{
+			"id":      doc.ID,
+			"nome":    doc.Name,
+			"arquivo": doc.Filename,
+			"tipo":    doc.Mime,
+			"imagem":  documentIsImage(*doc),
+		}
+		if documentIsImage(*doc) && previews < maxInlineImages {
+			data, mimeType, ext, ok := downloadImage(t.glpi, t.sessionToken, doc.ID)
+			if ok {
+				previews++
+				t.conv.AttachImage(ai.Image{
+					Data:     data,
+					MIMEType: mimeType,
+					Filename: fmt.Sprintf("chamado-%d-anexo-%d.%s", ticketID, doc.ID, ext),
+					Caption:  fmt.Sprintf("Chamado #%d — %s", ticketID, doc.Name),
+				})
+			}
+			item["previa_enviada"] = ok
+		}
+		docs = append(docs, item)
+	}
+
+	result := map[string]any{"total": len(ids), "documentos": docs, "previas_enviadas": previews}
+	if len(ids) > maxListedDocuments {
+		result["mensagem"] = fmt.Sprintf("Mostrando os primeiros %d de %d anexos; os demais estão no Nexus.", maxListedDocuments, len(ids))
+	}
+	return result, nil
+}
+
+var _ ai.Tool = (*ListTicketDocuments)(nil)
+
+// --- SendTicketDocument ---
+
+type SendTicketDocument struct {
+	glpi         *glpi.Client
+	sessionToken string
+	conv         *ai.Conversation
+}
+
+func NewSendTicketDocument(g *glpi.Client, token string, conv *ai.Conversation) *SendTicketDocument {
+	return &SendTicketDocument{glpi: g, sessionToken: token, conv: conv}
+}
+
+func (t *SendTicketDocument) Name() string   { return "send_ticket_document" }
+func (t *SendTicketDocument) ReadOnly() bool { return true }
+func (t *SendTicketDocument) Description() string {
+	return `Envia ao usuario, pelo WhatsApp, um anexo de um chamado (PDF, planilha, imagem...).
+Quando usar: depois de list_ticket_documents, quando o usuario pedir um anexo. Ex: clicou "Enviar", "me manda o PDF do chamado 123".
+Use o id do documento retornado por list_ticket_documents; nao invente IDs. O arquivo e enviado automaticamente apos sua resposta.
+Retorna: {enviado, arquivo, mensagem}.`
+}
+func (t *SendTicketDocument) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"ticket_id":   {Type: "integer", Description: "ID do chamado"},
+			"document_id": {Type: "integer", Description: "ID do documento (campo id de list_ticket_documents)"},
+		},
+		Required: []string{"ticket_id", "document_id"},
+	}
+}
+
+func (t *SendTicketDocument) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	ticketID, err := intArg(args, "ticket_id")
+	if err != nil {
+		return nil, err
+	}
+	docID, err := intArg(args, "document_id")
+	if err != nil {
+		return nil, err
+	}
+
+	// Only documents linked to the ticket: the model must not be able to
+	// send any document the session happens to read by guessing an ID.
+	ids, err := t.glpi.GetTicketDocumentIDs(t.sessionToken, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar anexos: %w", err)
+	}
+	if !slices.Contains(ids, docID) {
+		return nil, fmt.Errorf("o documento %d não é anexo do chamado #%d", docID, ticketID)
+	}
+	doc, err := t.glpi.GetDocument(t.sessionToken, docID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar documento: %w", err)
+	}
+
+	caption := fmt.Sprintf("Chamado #%d — %s", ticketID, doc.Name)
+	if documentIsImage(*doc) {
+		if data, mimeType, ext, ok := downloadImage(t.glpi, t.sessionToken, docID); ok {
+			t.conv.AttachImage(ai.Image{
+				Data:     data,
+				MIMEType: mimeType,
+				Filename: fmt.Sprintf("chamado-%d-anexo-%d.%s", ticketID, docID, ext),
+				Caption:  caption,
+			})
+			return map[string]any{"enviado": true, "arquivo": doc.Filename, "mensagem": "Imagem enviada."}, nil
+		}
+	}
+
+	data, mimeType, err := t.glpi.DownloadDocument(t.sessionToken, docID, maxDocumentBytes)
+	if err != nil {
+		return map[string]any{
+			"enviado":  false,
+			"arquivo":  doc.Filename,
+			"mensagem": fmt.Sprintf("Não consegui baixar o arquivo (pode ser maior que %d MB); o usuário pode abri-lo no Nexus.", maxDocumentBytes>>20),
+		}, nil
+	}
+	if doc.Mime != "" {
+		mimeType = doc.Mime
+	}
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	filename := doc.Filename
+	if filename == "" {
+		filename = fmt.Sprintf("chamado-%d-anexo-%d", ticketID, docID)
+	}
+	t.conv.AttachDocument(ai.Document{Data: data, MIMEType: mimeType, Filename: filename, Caption: caption})
+	return map[string]any{"enviado": true, "arquivo": filename, "mensagem": "Arquivo enviado."}, nil
+}
+
+var _ ai.Tool = (*SendTicketDocument)(nil)
@@ -0,0 +1,159 @@
+package tools
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/lojasmm/laia/internal/ai"
+)
+
+const (
+	// maxLogTitleLen keeps suggested titles readable in ticket lists.
+	maxLogTitleLen = 80
+	// maxInlineLogLen is how much of a log goes into the description; longer
+	// logs are cut there and attached whole as a .txt document.
+	maxInlineLogLen = 3000
+)
+
+var (
+	// logErrorPattern marks the lines that say what went wrong.
+	logErrorPattern = regexp.MustCompile(`(?i)(exception|error|erro\b|fatal|failed|falha|falhou|traceback|panic|denied|negad[oa]|refused|recusad[oa]|timed? ?out|not found|n[aã]o encontrad[oa]|c[oó]digo \d+|code \d+)`)
+	// logNoisePattern matches the prefixes that make every log line look the
+	// same: timestamps, levels, thread/process ids in brackets.
+	logNoisePattern = regexp.MustCompile(`^(\s*(\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}(:\d{2})?([.,]\d+)?(Z|[+-]\d{2}:?\d{2})?|\d{2}/\d{2}/\d{4}( \d{2}:\d{2}(:\d{2})?)?|\d{2}:\d{2}:\d{2}([.,]\d+)?|\[[^\]]{0,40}\]|(TRACE|DEBUG|INFO|WARN|WARNING|ERROR|FATAL|CRITICAL|SEVERE)\b:?|-)\s*)+`)
+	// logStackPattern matches stack frames and trace headers, which never make
+	// a good title. Python prints the exception after the frames, Java before.
+	logStackPattern = regexp.MustCompile(`^\s*(at |File "|#\d+ |\.\.\. \d+ more|goroutine \d+|Traceback \(most recent call last\))`)
+)
+
+// LooksLikeLog reports whether text is a pasted log or stack trace rather than
+// a description: several lines, most of them timestamped, stack frames or
+// error lines. The bot uses it to let logs past the inbound message cap
+// through, since create_ticket attaches them whole.
+func LooksLikeLog(text string) bool {
+	lines := nonEmptyLines(text)
+	if len(lines) < 3 {
+		return false
+	}
+	technical := 0
+	for _, l := range lines {
+		if logNoisePattern.MatchString(l) || logStackPattern.MatchString(l) || logErrorPattern.MatchString(l) {
+			technical++
+		}
+	}
+	return technical*2 >= len(lines)
+}
+
+// logErrorLines returns the error lines of a log without their prefixes,
+// skipping stack frames and repeats.
+func logErrorLines(text string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, l := range nonEmptyLines(text) {
+		if logStackPattern.MatchString(l) || !logErrorPattern.MatchString(l) {
+			continue
+		}
+		clean := strings.TrimSpace(logNoisePattern.ReplaceAllString(l, ""))
+		if clean == "" || seen[clean] {
+			continue
+		}
+		seen[clean] = true
+		out = append(out, clean)
+	}
+	return out
+}
+
+// logTitle proposes a ticket title from a log: its first error line, cleaned
+// of prefixes. Exceptions usually read "pkg.SomeException: message", so the
+// message is preferred when the class name alone would eat the title.
+func logTitle(text string) string {
+	lines := logErrorLines(text)
+	if len(lines) == 0 {
+		return ""
+	}
+	title := lines[0]
+	if head, msg, ok := strings.Cut(title, ": "); ok && strings.TrimSpace(msg) != "" && !strings.Contains(head, " ") {
+		if i := strings.LastIndexAny(head, ".\\/"); i >= 0 {
+			head = head[i+1:]
+		}
+		title = head + ": " + strings.TrimSpace(msg)
+	}
+	return truncateText("Erro: "+strings.TrimPrefix(strings.TrimPrefix(title, "Erro: "), "Error: "), maxLogTitleLen)
+}
+
+// logDescription appends the log to the user's description, cut at
+// maxInlineLogLen. The cut keeps whole lines so stack frames aren't garbled.
+func logDescription(description, log string) string {
+	excerpt := strings.TrimSpace(log)
+	cut := len(excerpt) > maxInlineLogLen
+	if cut {
+		excerpt = strings.ToValidUTF8(excerpt[:maxInlineLogLen], "")
+		if i := strings.LastIndexByte(excerpt, '\n'); i > 0 {
+			excerpt = excerpt[:i]
+		}
+		excerpt += "\n[... log completo no anexo .txt]"
+	}
+	return description + "\n\n----- Log do erro -----\n" + excerpt + "\n----- Fim do log -----"
+}
+
+func nonEmptyLines(text string) []string {
+	var out []string
+	for _, l := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(l) != "" {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// --- AnalyzeErrorLog ---
+
+type AnalyzeErrorLog struct{}
+
+func NewAnalyzeErrorLog() *AnalyzeErrorLog { return &AnalyzeErrorLog{} }
+
+func (t *AnalyzeErrorLog) Name() string   { return "analyze_error_log" }
+func (t *AnalyzeErrorLog) ReadOnly() bool { return true }
+func (t *AnalyzeErrorLog) Description() string {
+	return `Analisa um log/mensagem de erro colado pelo usuario: diz se e um log, sugere um titulo curto e destaca as linhas de erro.
+Quando usar: quando o usuario colar um log, stack trace ou varias linhas de erro, antes de abrir o chamado. Passe o texto exatamente como veio.
+Depois, no create_ticket, use o titulo sugerido, escreva na description um resumo em portugues do problema e passe o texto original em error_log (nao copie o log na description).
+Retorna: {eh_log, titulo_sugerido, linhas_erro, anexo}.`
+}
+func (t *AnalyzeErrorLog) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"text": {Type: "string", Description: "Texto colado pelo usuário, sem alterações"},
+		},
+		Required: []string{"text"},
+	}
+}
+
+func (t *AnalyzeErrorLog) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	text, err := stringArg(args, "text")
+	if err != nil {
+		return nil, err
+	}
+	if !LooksLikeLog(text) {
+		return map[string]any{
+			"eh_log":   false,
+			"mensagem": "O texto não parece um log; siga o fluxo normal de criação.",
+		}, nil
+	}
+	errLines := logErrorLines(text)
+	result := map[string]any{
+		"eh_log":          true,
+		"titulo_sugerido": logTitle(text),
+		"linhas_erro":     errLines[:min(len(errLines), 3)],
+		// Tells the model the full log survives even though the description is cut.
+		"anexo": len(strings.TrimSpace(text)) > maxInlineLogLen,
+	}
+	if result["titulo_sugerido"] == "" {
+		result["titulo_sugerido"] = "Erro reportado pelo usuário"
+	}
+	return result, nil
+}
+
+var _ ai.Tool = (*AnalyzeErrorLog)(nil)
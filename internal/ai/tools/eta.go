@@ -0,0 +1,154 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lojasmm/laia/internal/ai"
+	"github.com/lojasmm/laia/internal/glpi"
+)
+
+// minResolutionSamples is the fewest solved tickets a category needs before we
+// risk an estimate; below that a single outlier dominates the average.
+const minResolutionSamples = 5
+
+// --- EstimateResolution ---
+
+type EstimateResolution struct {
+	glpi         *glpi.Client
+	sessionToken string
+	now          func() time.Time
+}
+
+func NewEstimateResolution(g *glpi.Client, token string) *EstimateResolution {
+	return &EstimateResolution{glpi: g, sessionToken: token, now: time.Now}
+}
+
+func (t *EstimateResolution) Name() string   { return "estimate_resolution" }
+func (t *EstimateResolution) ReadOnly() bool { return true }
+func (t *EstimateResolution) Description() string {
+	return `Estima quando um chamado deve ser resolvido, com base no tempo medio de solucao dos ultimos chamados da mesma categoria.
+Quando usar: quando o usuario perguntar "quando vai ser resolvido?", "quanto tempo demora?". Para prazo de SLA use get_ticket_sla.
+E uma estimativa: SEMPRE repasse o aviso ao usuario. Se estimativa_disponivel=false, explique que nao ha historico suficiente.
+Retorna: {id, categoria, estimativa_disponivel, amostras, tempo_medio, previsao, aviso}.`
+}
+func (t *EstimateResolution) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"ticket_id": {Type: "integer", Description: "ID do chamado"},
+		},
+		Required: []string{"ticket_id"},
+	}
+}
+
+func (t *EstimateResolution) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	ticketID, err := intArg(args, "ticket_id")
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, err := t.glpi.GetTicket(t.sessionToken, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar chamado: %w", err)
+	}
+	if ticket.Status >= 5 {
+		return map[string]any{
+			"id":       ticket.ID,
+			"mensagem": fmt.Sprintf("O chamado já está %s.", ticketStatusLabel(ticket.Status)),
+		}, nil
+	}
+	category := dropdownName(ticket.ITILCategoriesID)
+	if category == "" {
+		return map[string]any{
+			"id":                    ticket.ID,
+			"estimativa_disponivel": false,
+			"mensagem":              "O chamado não tem categoria, então não há histórico para comparar.",
+		}, nil
+	}
+
+	// Self-service profiles only see their own tickets, which is too few to
+	// average; only durations leave this function, so the admin session is safe.
+	adminSession, err := t.glpi.AdminSession(glpi.AdminReadReference)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar sessão admin: %w", err)
+	}
+	defer t.glpi.KillSession(adminSession)
+
+	result, err := t.glpi.AdvancedSearchTickets(adminSession, solvedInCategoryCriteria(category))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar histórico da categoria: %w", err)
+	}
+
+	avg, samples := averageResolution(result.Data)
+	out := map[string]any{
+		"id":                    ticket.ID,
+		"categoria":             category,
+		"amostras":              samples,
+		"estimativa_disponivel": samples >= minResolutionSamples,
+	}
+	if samples < minResolutionSamples {
+		out["mensagem"] = fmt.Sprintf("Há poucos chamados solucionados nesta categoria (%d) para estimar um prazo.", samples)
+		return out, nil
+	}
+
+	out["tempo_medio"] = formatDuration(avg)
+	out["aviso"] = "Estimativa baseada na média dos últimos chamados da categoria; não é um prazo garantido."
+	if opened, err := time.ParseInLocation(glpiDateTime, ticket.DateCreated, brLocation); err == nil {
+		eta := opened.Add(avg)
+		if eta.Before(t.now()) {
+			out["previsao"] = "já passou da média da categoria"
+		} else {
+			out["previsao"] = eta.Format("02/01/2006 15:04")
+		}
+	}
+	return out, nil
+}
+
+// solvedInCategoryCriteria searches the 50 most recently solved or closed
+// tickets of the category. Field 7 holds the category's complete name, so the
+// value is anchored (^...$) to skip sub-categories.
+// Reference: nexus_apirest.md — search criteria, searchtype "contains"
+func solvedInCategoryCriteria(category string) map[string]string {
+	return map[string]string{
+		"criteria[0][field]":                   "7",
+		"criteria[0][searchtype]":              "contains",
+		"criteria[0][value]":                   "^" + category + "$",
+		"criteria[1][link]":                    "AND",
+		"criteria[1][criteria][0][field]":      "12",
+		"criteria[1][criteria][0][searchtype]": "equals",
+		"criteria[1][criteria][0][value]":      "5",
+		"criteria[1][criteria][1][link]":       "OR",
+		"criteria[1][criteria][1][field]":      "12",
+		"criteria[1][criteria][1][searchtype]": "equals",
+		"criteria[1][criteria][1][value]":      "6",
+		"sort":                                 "17",
+		"order":                                "DESC",
+		"range":                                "0-49",
+	}
+}
+
+// averageResolution averages resolution date (17) minus opening date (15)
+// over the rows, skipping rows with missing or inconsistent dates.
+func averageResolution(rows []glpi.SearchResultItem) (time.Duration, int) {
+	var total time.Duration
+	n := 0
+	for _, row := range rows {
+		opened, _ := row["15"].(string)
+		solved, _ := row["17"].(string)
+		o, err1 := time.ParseInLocation(glpiDateTime, opened, brLocation)
+		s, err2 := time.ParseInLocation(glpiDateTime, solved, brLocation)
+		if err1 != nil || err2 != nil || s.Before(o) {
+			continue
+		}
+		total += s.Sub(o)
+		n++
+	}
+	if n == 0 {
+		return 0, 0
+	}
+	return total / time.Duration(n), n
+}
+
+var _ ai.Tool = (*EstimateResolution)(nil)
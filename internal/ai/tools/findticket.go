@@ -0,0 +1,154 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lojasmm/laia/internal/ai"
+	"github.com/lojasmm/laia/internal/glpi"
+)
+
+const (
+	// minTicketMatchScore is the share of the user's keywords a title must
+	// contain to count as a match at all.
+	minTicketMatchScore = 0.5
+	maxTicketCandidates = 5
+)
+
+// ticketQueryFillers are words users wrap around the subject ("o chamado da
+// impressora") that never help tell tickets apart.
+var ticketQueryFillers = map[string]bool{
+	"o": true, "a": true, "os": true, "as": true, "de": true, "da": true, "do": true, "das": true, "dos": true,
+	"um": true, "uma": true, "no": true, "na": true, "em": true, "com": true, "sobre": true, "que": true,
+	"meu": true, "minha": true, "aquele": true, "aquela": true, "esse": true, "essa": true,
+	"chamado": true, "chamados": true, "ticket": true, "problema": true, "abri": true,
+}
+
+// ticketMatchScore is the share of query words found in title. Words match
+// when one is a prefix of the other with at least 4 letters in common, so
+// "impressora" finds "Impressoras do 2o andar".
+func ticketMatchScore(query []string, title string) float64 {
+	if len(query) == 0 {
+		return 0
+	}
+	titleWords := routingWords(title)
+	hits := 0
+	for _, q := range query {
+		for _, w := range titleWords {
+			if q == w || len(q) >= 4 && len(w) >= 4 && (strings.HasPrefix(w, q) || strings.HasPrefix(q, w)) {
+				hits++
+				break
+			}
+		}
+	}
+	return float64(hits) / float64(len(query))
+}
+
+type ticketMatch struct {
+	ticket glpi.Ticket
+	score  float64
+}
+
+// matchTickets ranks tickets by ticketMatchScore, best first and newest first
+// on ties, dropping those below minTicketMatchScore.
+func matchTickets(tickets []glpi.Ticket, description string) []ticketMatch {
+	var query []string
+	for _, w := range routingWords(description) {
+		if !ticketQueryFillers[w] {
+			query = append(query, w)
+		}
+	}
+	var matches []ticketMatch
+	for _, tk := range tickets {
+		if s := ticketMatchScore(query, tk.Name); s >= minTicketMatchScore {
+			matches = append(matches, ticketMatch{ticket: tk, score: s})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].ticket.DateCreated > matches[j].ticket.DateCreated
+	})
+	return matches
+}
+
+// --- FindTicketByDescription ---
+
+type FindTicketByDescription struct {
+	glpi         *glpi.Client
+	sessionToken string
+}
+
+func NewFindTicketByDescription(g *glpi.Client, token string) *FindTicketByDescription {
+	return &FindTicketByDescription{glpi: g, sessionToken: token}
+}
+
+func (t *FindTicketByDescription) Name() string   { return "find_ticket" }
+func (t *FindTicketByDescription) ReadOnly() bool { return true }
+func (t *FindTicketByDescription) Description() string {
+	return `Encontra um chamado do usuario pelo assunto quando ele nao informa o numero. Compara as palavras com os titulos dos chamados dele.
+Quando usar: SEMPRE que o usuario se referir a um chamado sem numero. Ex: "o chamado da impressora", "aquele do e-mail". NUNCA invente um ID.
+NAO usar: para listar varios chamados por filtro — use search_tickets_advanced.
+Se houver mais de um candidato, retorna need_clarification com as opcoes: pergunte ao usuario qual e.
+Retorna: {encontrado, chamado: {id, titulo, status, data}} ou {encontrado: false, mensagem}.`
+}
+func (t *FindTicketByDescription) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"description": {Type: "string", Description: "Como o usuário se referiu ao chamado. Ex: 'impressora do financeiro'"},
+		},
+		Required: []string{"description"},
+	}
+}
+
+func (t *FindTicketByDescription) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	description, err := stringArg(args, "description")
+	if err != nil {
+		return nil, err
+	}
+
+	tickets, err := t.glpi.GetMyTickets(t.sessionToken)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar chamados: %w", err)
+	}
+
+	matches := matchTickets(tickets, description)
+	switch {
+	case len(matches) == 0:
+		return map[string]any{
+			"encontrado": false,
+			"mensagem":   fmt.Sprintf("Nenhum chamado seu com título parecido com '%s'. Peça o número ou ofereça list_my_tickets.", description),
+		}, nil
+	// One match, or one clearly ahead of the rest.
+	case len(matches) == 1 || matches[0].score > matches[1].score:
+		tk := matches[0].ticket
+		return map[string]any{
+			"encontrado": true,
+			"chamado": map[string]any{
+				"id":     tk.ID,
+				"titulo": tk.Name,
+				"status": ticketStatusLabel(tk.Status),
+				"data":   tk.DateCreated,
+			},
+		}, nil
+	}
+
+	var options []string
+	for _, m := range matches[:min(len(matches), maxTicketCandidates)] {
+		if m.score < matches[0].score {
+			break
+		}
+		options = append(options, fmt.Sprintf("#%d %s (%s)", m.ticket.ID, m.ticket.Name, ticketStatusLabel(m.ticket.Status)))
+	}
+	return clarification(
+		"Encontrei mais de um chamado parecido. Qual deles?",
+		options,
+		"Mostre as opcoes com respond_interactive e use o numero do chamado escolhido.",
+	), nil
+}
+
+var _ ai.Tool = (*FindTicketByDescription)(nil)
@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lojasmm/laia/internal/ai"
+	"github.com/lojasmm/laia/internal/glpi"
+)
+
+// HandoffConfig enables human_handoff: tickets go to CategoryID and, when set,
+// are assigned to GroupID. Hours only changes what the user is promised.
+type HandoffConfig struct {
+	CategoryID int
+	GroupID    int
+	Hours      BusinessHours
+}
+
+// BusinessHours is the support team's weekday shift, [Open, Close) in
+// America/Sao_Paulo. The zero value means always staffed.
+type BusinessHours struct {
+	Open, Close int
+}
+
+// ParseBusinessHours reads HANDOFF_HOURS ("8-18"); empty means always staffed.
+func ParseBusinessHours(s string) (BusinessHours, error) {
+	if strings.TrimSpace(s) == "" {
+		return BusinessHours{}, nil
+	}
+	from, to, ok := strings.Cut(s, "-")
+	open, err1 := strconv.Atoi(strings.TrimSpace(from))
+	closeH, err2 := strconv.Atoi(strings.TrimSpace(to))
+	if !ok || err1 != nil || err2 != nil || open < 0 || closeH > 24 || open >= closeH {
+		return BusinessHours{}, fmt.Errorf("invalid business hours %q (use e.g. 8-18)", s)
+	}
+	return BusinessHours{Open: open, Close: closeH}, nil
+}
+
+// staffed reports whether someone is on shift at t (weekdays only).
+func (h BusinessHours) staffed(t time.Time) bool {
+	if h == (BusinessHours{}) {
+		return true
+	}
+	t = t.In(brLocation)
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return false
+	}
+	return t.Hour() >= h.Open && t.Hour() < h.Close
+}
+
+// --- HumanHandoff ---
+
+type HumanHandoff struct {
+	glpi   *glpi.Client
+	userID int
+	conv   *ai.Conversation
+	cfg    HandoffConfig
+	now    func() time.Time
+}
+
+func NewHumanHandoff(g *glpi.Client, userID int, conv *ai.Conversation, cfg HandoffConfig) *HumanHandoff {
+	return &HumanHandoff{glpi: g, userID: userID, conv: conv, cfg: cfg, now: time.Now}
+}
+
+func (t *HumanHandoff) Name() string   { return "human_handoff" }
+func (t *HumanHandoff) ReadOnly() bool { return false }
+func (t *HumanHandoff) Description() string {
+	return `Encaminha o usuario para uma pessoa da equipe: abre um chamado de alta urgencia na fila de atendimento humano, com o resumo e a conversa.
+Quando usar: quando o usuario pedir para falar com uma pessoa/atendente/humano, ou quando voce nao conseguir ajudar apos varias tentativas (ofereca antes via respond_interactive).
+NAO usar: para abrir chamados comuns — use o fluxo de create_ticket.
+Repasse a mensagem retornada ao usuario; ela diz quando alguem vai responder.
+Retorna: {id, fora_do_horario, mensagem}.`
+}
+func (t *HumanHandoff) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"summary": {Type: "string", Description: "Resumo do que o usuário precisa, em uma ou duas frases"},
+			"reason":  {Type: "string", Description: "Por que encaminhar", Enum: []string{"pedido_usuario", "nao_resolvido"}},
+		},
+		Required: []string{"summary", "reason"},
+	}
+}
+
+func (t *HumanHandoff) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	summary, _ := stringArg(args, "summary")
+	summary = strings.TrimSpace(summary)
+	if summary == "" {
+		return nil, fmt.Errorf("parâmetro obrigatório ausente: summary")
+	}
+	reason := "O usuário pediu para falar com uma pessoa."
+	if r, _ := stringArg(args, "reason"); r == "nao_resolvido" {
+		reason = "A Laia não conseguiu resolver pelo WhatsApp."
+	}
+
+	content := "Motivo: " + reason + "\n\nResumo: " + summary
+	if transcript := buildTranscript(t.conv.Turns()); transcript != "" {
+		content += "\n\n" + transcript
+	}
+	input := glpi.CreateTicketInput{
+		Name:             "Atendimento humano: " + truncateText(summary, 60),
+		Content:          content,
+		Type:             2, // Requisição
+		ITILCategoriesID: t.cfg.CategoryID,
+		Urgency:          4, // Alta
+		UsersIDRequester: t.userID,
+	}
+	if t.cfg.GroupID > 0 {
+		input.GroupsIDAssign = []int{t.cfg.GroupID}
+	}
+
+	// Same as create_ticket: self-service profiles can't create through the API.
+	adminSession, err := t.glpi.AdminSession(glpi.AdminCreateTicket)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar sessão admin: %w", err)
+	}
+	defer t.glpi.KillSession(adminSession)
+
+	id, err := t.glpi.CreateTicket(adminSession, input)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir atendimento humano: %w", err)
+	}
+
+	staffed := t.cfg.Hours.staffed(t.now())
+	msg := fmt.Sprintf("Chamado #%d aberto para atendimento humano. Uma pessoa da equipe vai falar com você pelo chamado em breve.", id)
+	if !staffed {
+		msg = fmt.Sprintf("Chamado #%d aberto para atendimento humano. Agora estamos fora do horário da equipe (%dh às %dh, de segunda a sexta); "+
+			"uma pessoa vai falar com você pelo chamado no próximo horário de atendimento.", id, t.cfg.Hours.Open, t.cfg.Hours.Close)
+	}
+	return map[string]any{"id": id, "fora_do_horario": !staffed, "mensagem": msg}, nil
+}
+
+var _ ai.Tool = (*HumanHandoff)(nil)
@@ -75,6 +75,14 @@ func (t *RespondInteractive) Parameters() *ai.ParamSchema {
 					Required: []string{"title", "rows"},
 				},
 			},
+			"confirm_tool": {
+				Type:        "string",
+				Description: "Ao pedir confirmacao de uma acao: nome da ferramenta que sera executada se o usuario confirmar",
+			},
+			"confirm_args": {
+				Type:        "object",
+				Description: "Ao pedir confirmacao de uma acao: argumentos exatos que serao passados a confirm_tool",
+			},
 		},
 		Required: []string{"message_type", "text"},
 	}
@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lojasmm/laia/internal/ai"
+	"github.com/lojasmm/laia/internal/glpi"
+)
+
+// taskStateDone is TicketTask's "Feito" state; a done task's plan is history.
+const taskStateDone = 3
+
+// nextIntervention picks the planned task that comes first from now on. A
+// task under way (begun, not yet ended) counts: the technician is there now.
+// A task without an end is treated as a point in time at its begin.
+func nextIntervention(tasks []glpi.TicketTask, now time.Time) (glpi.TicketTask, bool) {
+	var (
+		next      glpi.TicketTask
+		nextBegin time.Time
+		found     bool
+	)
+	for _, task := range tasks {
+		if task.Begin == "" || task.State == taskStateDone {
+			continue
+		}
+		begin, err := time.ParseInLocation(glpiDateTime, task.Begin, brLocation)
+		if err != nil {
+			continue
+		}
+		end := begin
+		if e, err := time.ParseInLocation(glpiDateTime, task.End, brLocation); err == nil && e.After(begin) {
+			end = e
+		}
+		if end.Before(now) {
+			continue
+		}
+		if !found || begin.Before(nextBegin) {
+			next, nextBegin, found = task, begin, true
+		}
+	}
+	return next, found
+}
+
+// --- NextIntervention ---
+
+type NextIntervention struct {
+	glpi         *glpi.Client
+	sessionToken string
+}
+
+func NewNextIntervention(g *glpi.Client, token string) *NextIntervention {
+	return &NextIntervention{glpi: g, sessionToken: token}
+}
+
+func (t *NextIntervention) Name() string   { return "next_intervention" }
+func (t *NextIntervention) ReadOnly() bool { return true }
+func (t *NextIntervention) Description() string {
+	return `Informa a proxima visita/intervencao agendada de um chamado, a partir das tarefas planejadas pelo tecnico.
+Quando usar: quando o usuario perguntar quando o atendimento vai acontecer. Ex: "quando o tecnico vem?", "tem visita marcada no chamado 123?", "que horas vao mexer no meu computador?".
+NAO usar: para listar todas as tarefas — use get_ticket_tasks.
+Se agendada=false, diga que ainda nao ha horario marcado; nao invente um.
+Retorna: {agendada, em_andamento, inicio, fim, tecnico, tarefa}.`
+}
+func (t *NextIntervention) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"ticket_id": {Type: "integer", Description: "ID do chamado"},
+		},
+		Required: []string{"ticket_id"},
+	}
+}
+
+func (t *NextIntervention) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	ticketID, err := intArg(args, "ticket_id")
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := t.glpi.GetTicketTasks(t.sessionToken, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar tarefas: %w", err)
+	}
+
+	now := time.Now().In(brLocation)
+	task, ok := nextIntervention(tasks, now)
+	if !ok {
+		return map[string]any{
+			"agendada": false,
+			"mensagem": fmt.Sprintf("O chamado #%d não tem intervenção agendada.", ticketID),
+		}, nil
+	}
+
+	begin, _ := time.ParseInLocation(glpiDateTime, task.Begin, brLocation)
+	result := map[string]any{
+		"agendada":     true,
+		"em_andamento": !begin.After(now),
+		"inicio":       task.Begin,
+		"fim":          task.End,
+		"tarefa":       truncateText(task.Content, 200),
+	}
+	if task.UsersIDTech > 0 {
+		result["tecnico"] = userNamer(t.glpi, t.sessionToken)(task.UsersIDTech)
+	}
+	return result, nil
+}
+
+var _ ai.Tool = (*NextIntervention)(nil)
@@ -0,0 +1,247 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lojasmm/laia/internal/ai"
+	"github.com/lojasmm/laia/internal/glpi"
+)
+
+const (
+	// kbGuideTTL is how long a guide is kept without the user moving through
+	// it; past that they have moved on, and "próximo" starts nothing.
+	kbGuideTTL = 30 * time.Minute
+	// maxStepLen keeps a step, with the "Passo N de M" header, inside the
+	// 1024-character body of a WhatsApp interactive message.
+	maxStepLen = 900
+)
+
+var (
+	kbOrderedListPattern = regexp.MustCompile(`(?is)<ol[^>]*>(.*?)</ol>`)
+	kbListItemPattern    = regexp.MustCompile(`(?is)<li[^>]*>(.*?)</li>`)
+	// kbNumberedLinePattern matches "1.", "2)", "3 -" and "Passo 4:" at the
+	// start of a line, however the author typed the numbering.
+	kbNumberedLinePattern = regexp.MustCompile(`(?i)^(?:passo|etapa)?\s*\d{1,2}\s*[.)\-:–]\s*`)
+)
+
+// splitKBSteps splits a KB article (GLPI rich text) into steps. An ordered
+// list is the clearest sign of a procedure, so its items win; otherwise lines
+// numbered by hand start steps and the lines after them belong to the step.
+// It returns nil when the article has fewer than two steps, i.e. isn't a
+// procedure.
+func splitKBSteps(content string) []string {
+	decoded := html.UnescapeString(content)
+
+	var steps []string
+	for _, list := range kbOrderedListPattern.FindAllStringSubmatch(decoded, -1) {
+		for _, item := range kbListItemPattern.FindAllStringSubmatch(list[1], -1) {
+			if text := htmlToPlainText(item[1]); text != "" {
+				steps = append(steps, text)
+			}
+		}
+	}
+	if len(steps) < 2 {
+		steps = nil
+		text := htmlBreakPattern.ReplaceAllString(decoded, "\n")
+		text = html.UnescapeString(htmlTagPattern.ReplaceAllString(text, ""))
+		for _, line := range strings.Split(text, "\n") {
+			line = strings.Join(strings.Fields(line), " ")
+			switch {
+			case line == "":
+			case kbNumberedLinePattern.MatchString(line):
+				steps = append(steps, kbNumberedLinePattern.ReplaceAllString(line, ""))
+			case len(steps) > 0:
+				steps[len(steps)-1] += "\n" + line
+			}
+		}
+	}
+	if len(steps) < 2 {
+		return nil
+	}
+	for i, s := range steps {
+		steps[i] = truncateText(s, maxStepLen)
+	}
+	return steps
+}
+
+// kbGuide is one user's position in an article's steps.
+type kbGuide struct {
+	articleID int
+	title     string
+	steps     []string
+	current   int
+	at        time.Time
+}
+
+// move applies a navigation action, staying within the steps: "next" on the
+// last step and "previous" on the first leave the position unchanged.
+func (g *kbGuide) move(action string) error {
+	switch action {
+	case "next":
+		g.current = min(g.current+1, len(g.steps)-1)
+	case "previous":
+		g.current = max(g.current-1, 0)
+	case "current":
+	default:
+		return fmt.Errorf("ação inválida: %s", action)
+	}
+	return nil
+}
+
+// kbGuides keeps each phone's open guide. It is shared by every registry,
+// like managerNotices, because the registry is rebuilt for every message.
+// It lives in memory: after a restart the user just starts the guide again.
+type kbGuides struct {
+	mu     sync.Mutex
+	guides map[string]*kbGuide
+}
+
+func newKBGuides() *kbGuides {
+	return &kbGuides{guides: make(map[string]*kbGuide)}
+}
+
+func (s *kbGuides) start(phone string, g *kbGuide, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for p, old := range s.guides {
+		if now.Sub(old.at) >= kbGuideTTL {
+			delete(s.guides, p)
+		}
+	}
+	g.at = now
+	s.guides[phone] = g
+}
+
+// navigate moves phone's guide and returns a copy of it, or false when the
+// phone has no guide open.
+func (s *kbGuides) navigate(phone, action string, now time.Time) (kbGuide, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	g, ok := s.guides[phone]
+	if !ok || now.Sub(g.at) >= kbGuideTTL {
+		delete(s.guides, phone)
+		return kbGuide{}, false, nil
+	}
+	if err := g.move(action); err != nil {
+		return kbGuide{}, true, err
+	}
+	g.at = now
+	return *g, true, nil
+}
+
+func (s *kbGuides) stop(phone string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.guides, phone)
+}
+
+// --- KBGuide ---
+
+// KBGuide is ReadOnly even though it moves the guide: the position is bot
+// state, not GLPI data, and a "Próximo" tap must not wait for confirmation
+// under ConfirmAll.
+type KBGuide struct {
+	glpi         *glpi.Client
+	sessionToken string
+	conv         *ai.Conversation
+	guides       *kbGuides
+}
+
+func NewKBGuide(g *glpi.Client, token string, conv *ai.Conversation, guides *kbGuides) *KBGuide {
+	return &KBGuide{glpi: g, sessionToken: token, conv: conv, guides: guides}
+}
+
+func (t *KBGuide) Name() string   { return "kb_guide" }
+func (t *KBGuide) ReadOnly() bool { return true }
+
+// HasSideEffects: the guide's position is stored for the next message.
+func (t *KBGuide) HasSideEffects() bool { return true }
+func (t *KBGuide) Description() string {
+	return `Apresenta um artigo da base de conhecimento como guia passo a passo, um passo por mensagem.
+Quando usar: quando o artigo encontrado for um procedimento (configurar, instalar, acessar...) e o usuario for segui-lo agora. Ex: "me ajuda a configurar a VPN passo a passo". Depois, quando o usuario tocar "Proximo", "Anterior" ou "Parar".
+NAO usar: para artigos informativos — se retornar guia=false, use get_kb_article.
+Comece com action=start e article_id (de search_knowledge_base). Mostre o texto do passo com respond_interactive e botoes "Anterior" (se nao for o primeiro), "Proximo" e "Parar"; no ultimo passo, troque "Proximo" por "Deu certo" e "Nao resolveu" (ofereca abrir um chamado).
+Nao resuma nem pule passos; nao invente passos que nao estao no artigo.
+Retorna: {guia, artigo, titulo, passo, total, texto, primeiro, ultimo}.`
+}
+func (t *KBGuide) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"action": {
+				Type:        "string",
+				Description: "start inicia o guia do artigo; next/previous navegam; current repete o passo atual; stop encerra",
+				Enum:        []string{"start", "next", "previous", "current", "stop"},
+			},
+			"article_id": {Type: "integer", Description: "ID do artigo (obrigatorio com action=start)"},
+		},
+		Required: []string{"action"},
+	}
+}
+
+func (t *KBGuide) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	action, err := stringArg(args, "action")
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+
+	switch action {
+	case "start":
+		articleID, err := intArg(args, "article_id")
+		if err != nil {
+			return nil, err
+		}
+		article, err := t.glpi.GetKBArticle(t.sessionToken, articleID)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao buscar artigo: %w", err)
+		}
+		steps := splitKBSteps(article.Answer)
+		if steps == nil {
+			return map[string]any{
+				"guia":     false,
+				"titulo":   article.Name,
+				"mensagem": "O artigo não está dividido em passos. Use get_kb_article e apresente o conteúdo.",
+			}, nil
+		}
+		g := &kbGuide{articleID: article.ID, title: article.Name, steps: steps}
+		t.guides.start(t.conv.Phone, g, now)
+		return guideStep(*g), nil
+	case "stop":
+		t.guides.stop(t.conv.Phone)
+		return map[string]any{"guia": false, "mensagem": "Guia encerrado."}, nil
+	}
+
+	g, ok, err := t.guides.navigate(t.conv.Phone, action, now)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return map[string]any{
+			"guia":     false,
+			"mensagem": "Não há guia em andamento (ele expira após 30 minutos parado). Pergunte qual artigo o usuário quer seguir e use action=start.",
+		}, nil
+	}
+	return guideStep(g), nil
+}
+
+func guideStep(g kbGuide) map[string]any {
+	return map[string]any{
+		"guia":     true,
+		"artigo":   g.articleID,
+		"titulo":   g.title,
+		"passo":    g.current + 1,
+		"total":    len(g.steps),
+		"texto":    g.steps[g.current],
+		"primeiro": g.current == 0,
+		"ultimo":   g.current == len(g.steps)-1,
+	}
+}
+
+var _ ai.Tool = (*KBGuide)(nil)
@@ -0,0 +1,115 @@
+package tools
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplitKBSteps(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "ordered list",
+			content: "<p>Para configurar a VPN:</p><ol><li>Abra o <b>FortiClient</b></li><li>Clique em Conectar</li><li>Digite sua senha</li></ol>",
+			want:    []string{"Abra o FortiClient", "Clique em Conectar", "Digite sua senha"},
+		},
+		{
+			name:    "escaped ordered list",
+			content: "&lt;ol&gt;&lt;li&gt;Desligue a impressora&lt;/li&gt;&lt;li&gt;Ligue de novo&lt;/li&gt;&lt;/ol&gt;",
+			want:    []string{"Desligue a impressora", "Ligue de novo"},
+		},
+		{
+			name:    "hand-numbered lines",
+			content: "Antes de começar, salve seu trabalho.<br>1. Abra o menu Iniciar<br>2) Procure por Impressoras<br>Ela aparece no topo.<br>Passo 3: Clique em Adicionar",
+			want:    []string{"Abra o menu Iniciar", "Procure por Impressoras\nEla aparece no topo.", "Clique em Adicionar"},
+		},
+		{
+			name:    "ordered list wins over numbered lines",
+			content: "1. Introdução<br>2. Requisitos<ol><li>Primeiro</li><li>Segundo</li></ol>",
+			want:    []string{"Primeiro", "Segundo"},
+		},
+		{
+			name:    "single item list falls back to numbered lines",
+			content: "<ol><li>Único item</li></ol>",
+			want:    nil,
+		},
+		{
+			name:    "one numbered line",
+			content: "1. Reinicie o computador",
+			want:    nil,
+		},
+		{
+			name:    "prose",
+			content: "<p>O horário do suporte é das 8h às 18h.</p>",
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitKBSteps(tt.content); !slices.Equal(got, tt.want) {
+				t.Errorf("splitKBSteps = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitKBStepsTruncatesLongSteps(t *testing.T) {
+	long := strings.Repeat("a", maxStepLen+100)
+	steps := splitKBSteps("<ol><li>" + long + "</li><li>curto</li></ol>")
+	if len(steps) != 2 {
+		t.Fatalf("got %d steps, want 2", len(steps))
+	}
+	if want := strings.Repeat("a", maxStepLen) + "…"; steps[0] != want {
+		t.Errorf("first step has %d runes, want %d plus an ellipsis", len([]rune(steps[0])), maxStepLen)
+	}
+}
+
+func TestKBGuideMove(t *testing.T) {
+	tests := []struct {
+		name    string
+		current int
+		action  string
+		want    int
+		wantErr bool
+	}{
+		{"next", 0, "next", 1, false},
+		{"next on last stays", 2, "next", 2, false},
+		{"previous", 2, "previous", 1, false},
+		{"previous on first stays", 0, "previous", 0, false},
+		{"current", 1, "current", 1, false},
+		{"invalid", 1, "skip", 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &kbGuide{steps: []string{"a", "b", "c"}, current: tt.current}
+			err := g.move(tt.action)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("move(%q) error = %v, wantErr %v", tt.action, err, tt.wantErr)
+			}
+			if g.current != tt.want {
+				t.Errorf("move(%q) from %d = %d, want %d", tt.action, tt.current, g.current, tt.want)
+			}
+		})
+	}
+}
+
+func TestKBGuidesExpire(t *testing.T) {
+	s := newKBGuides()
+	now := time.Now()
+	s.start("a", &kbGuide{steps: []string{"1", "2"}}, now)
+
+	if g, ok, err := s.navigate("a", "next", now.Add(time.Minute)); err != nil || !ok || g.current != 1 {
+		t.Fatalf("navigate = %+v, %v, %v; want step 1", g, ok, err)
+	}
+	if _, ok, _ := s.navigate("a", "next", now.Add(time.Minute+kbGuideTTL)); ok {
+		t.Error("expired guide still navigable")
+	}
+	if _, ok, _ := s.navigate("b", "next", now); ok {
+		t.Error("navigate without a guide reported one")
+	}
+}
@@ -3,20 +3,79 @@ package tools
 import (
 	"context"
 	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/lojasmm/laia/internal/ai"
 	"github.com/lojasmm/laia/internal/glpi"
 )
 
+// kbCategoryTTL is how long the KB category list is reused; categories are
+// edited rarely and a stale list only affects name resolution.
+const kbCategoryTTL = time.Hour
+
+// kbCategoryCache is shared by every registry, like translationCache, so the
+// category list is read once per TTL instead of on every scoped search.
+type kbCategoryCache struct {
+	mu       sync.Mutex
+	list     []glpi.KBCategory
+	loadedAt time.Time
+}
+
+func newKBCategoryCache() *kbCategoryCache {
+	return &kbCategoryCache{}
+}
+
+func (c *kbCategoryCache) get(g *glpi.Client, sessionToken string) ([]glpi.KBCategory, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.list != nil && time.Since(c.loadedAt) < kbCategoryTTL {
+		return c.list, nil
+	}
+	list, err := g.GetKBCategories(sessionToken)
+	if err != nil {
+		return nil, err
+	}
+	c.list, c.loadedAt = list, time.Now()
+	return list, nil
+}
+
+// resolveKBCategory matches a category name the way resolveBranch matches
+// stores: an exact name wins, otherwise every word of input must appear in the
+// category's full name. It returns the match, or the candidates when ambiguous.
+func resolveKBCategory(categories []glpi.KBCategory, input string) (*glpi.KBCategory, []glpi.KBCategory) {
+	words := routingWords(input)
+	if len(words) == 0 {
+		return nil, nil
+	}
+	var candidates []glpi.KBCategory
+	for i := range categories {
+		if strings.Join(routingWords(categories[i].Name), " ") == strings.Join(words, " ") {
+			return &categories[i], nil
+		}
+		if containsAll(routingWords(categories[i].Completename), words) {
+			candidates = append(candidates, categories[i])
+		}
+	}
+	if len(candidates) == 1 {
+		return &candidates[0], nil
+	}
+	return nil, candidates
+}
+
 // --- SearchKnowledgeBase ---
 
 type SearchKnowledgeBase struct {
 	glpi         *glpi.Client
 	sessionToken string
+	categories   *kbCategoryCache
 }
 
-func NewSearchKnowledgeBase(g *glpi.Client, token string) *SearchKnowledgeBase {
-	return &SearchKnowledgeBase{glpi: g, sessionToken: token}
+func NewSearchKnowledgeBase(g *glpi.Client, token string, categories *kbCategoryCache) *SearchKnowledgeBase {
+	return &SearchKnowledgeBase{glpi: g, sessionToken: token, categories: categories}
 }
 
 func (t *SearchKnowledgeBase) Name() string     { return "search_knowledge_base" }
@@ -24,15 +83,17 @@ func (t *SearchKnowledgeBase) ReadOnly() bool { return true }
 func (t *SearchKnowledgeBase) Description() string {
 	return `Busca artigos na base de conhecimento do Nexus/GLPI.
 Quando usar: quando o usuario perguntar "como faz...", "tem tutorial de...", "como configurar...", ou buscar solucoes para problemas conhecidos.
+Use 'category' quando o assunto for claro (ex: query="VPN", category="Rede") para evitar artigos de outras areas.
 O preview do conteudo e truncado a 200 caracteres — use get_kb_article para ler o artigo completo.
 Se nenhum artigo for encontrado, sugira ao usuario abrir um chamado para obter ajuda.
-Retorna: {total, artigos: [{id, nome, preview}]}.`
+Retorna: {total, artigos: [{id, nome, preview}], categoria?}.`
 }
 func (t *SearchKnowledgeBase) Parameters() *ai.ParamSchema {
 	return &ai.ParamSchema{
 		Type: "object",
 		Properties: map[string]*ai.ParamSchema{
-			"query": {Type: "string", Description: "Termo de busca (ex: VPN, email, impressora, como configurar)"},
+			"query":    {Type: "string", Description: "Termo de busca (ex: VPN, email, impressora, como configurar)"},
+			"category": {Type: "string", Description: "Nome da categoria da base de conhecimento para restringir a busca (opcional). Ex: 'Rede', 'Impressoras'"},
 		},
 		Required: []string{"query"},
 	}
@@ -44,7 +105,33 @@ func (t *SearchKnowledgeBase) Execute(_ context.Context, args map[string]any) (m
 		return nil, fmt.Errorf("termo de busca é obrigatório")
 	}
 
-	result, err := t.glpi.SearchKnowledgeBase(t.sessionToken, query)
+	var categoryID int
+	var note string
+	if name := strings.TrimSpace(optionalStringArg(args, "category")); name != "" {
+		categories, err := t.categories.get(t.glpi, t.sessionToken)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao buscar categorias da base de conhecimento: %w", err)
+		}
+		match, candidates := resolveKBCategory(categories, name)
+		switch {
+		case match != nil:
+			categoryID = match.ID
+		case len(candidates) > 1:
+			options := make([]string, 0, len(candidates))
+			for _, c := range candidates {
+				options = append(options, c.Completename)
+			}
+			return clarification(
+				fmt.Sprintf("Encontrei mais de uma categoria para '%s'. Qual delas?", name),
+				options,
+				"Chame search_knowledge_base de novo com category igual a uma das opcoes.",
+			), nil
+		default:
+			note = fmt.Sprintf("Categoria '%s' não encontrada; busca feita em toda a base.", name)
+		}
+	}
+
+	result, err := t.glpi.SearchKnowledgeBase(t.sessionToken, query, categoryID)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao buscar na base de conhecimento: %w", err)
 	}
@@ -55,13 +142,20 @@ func (t *SearchKnowledgeBase) Execute(_ context.Context, args map[string]any) (m
 			"id":   item["2"],
 			"nome": item["6"], // Field 6 = Subject/name
 		}
-		// Field 7 = Content/answer; include a truncated preview
+		// Field 7 = Content/answer; include a truncated plain-text preview.
+		// Strip first so the 200-char budget isn't spent on markup.
 		if body, ok := item["7"].(string); ok && body != "" {
-			entry["preview"] = truncateText(body, 200)
+			if text := htmlToPlainText(body); text != "" {
+				entry["preview"] = truncateText(text, 200)
+			}
 		}
 		items[i] = entry
 	}
-	return map[string]any{"total": result.TotalCount, "artigos": items}, nil
+	out := map[string]any{"total": result.TotalCount, "artigos": items}
+	if note != "" {
+		out["nota"] = note
+	}
+	return out, nil
 }
 
 // --- GetKBArticle ---
@@ -111,5 +205,24 @@ func (t *GetKBArticle) Execute(_ context.Context, args map[string]any) (map[stri
 	}, nil
 }
 
+// --- KB formatting ---
+
+var (
+	htmlBreakPattern = regexp.MustCompile(`(?i)<\s*(br|/p|/div|/li|/h[1-6]|/tr)\s*/?>`)
+	htmlTagPattern   = regexp.MustCompile(`<[^>]*>`)
+)
+
+// htmlToPlainText converts KB article HTML to single-line plain text.
+// GLPI stores rich text HTML-encoded (&lt;p&gt;...), so entities are decoded
+// before stripping tags and again after, for the entities inside the text.
+// strings.Fields also folds &nbsp; (U+00A0) into regular spaces.
+func htmlToPlainText(s string) string {
+	s = html.UnescapeString(s)
+	s = htmlBreakPattern.ReplaceAllString(s, " ")
+	s = htmlTagPattern.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
 var _ ai.Tool = (*SearchKnowledgeBase)(nil)
 var _ ai.Tool = (*GetKBArticle)(nil)
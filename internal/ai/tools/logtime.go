@@ -0,0 +1,155 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lojasmm/laia/internal/ai"
+	"github.com/lojasmm/laia/internal/glpi"
+)
+
+// maxLoggedDuration bounds a single entry; more than a day at once is far
+// more likely "1h30" misread or a typo than real work.
+const maxLoggedDuration = 24 * time.Hour
+
+var (
+	// durationPattern matches how people write time spent: "30min", "30 m",
+	// "1h30", "1h 30min", "2h", "1,5h", "2 horas" or a bare number of minutes.
+	durationPattern = regexp.MustCompile(`^(?:(\d+(?:[.,]\d+)?)\s*(?:h|hr|hrs|hora|horas))?\s*(?:(\d+)\s*(?:m|min|mins|minuto|minutos)?)?$`)
+	// clockDurationPattern matches "1:30".
+	clockDurationPattern = regexp.MustCompile(`^(\d+):([0-5]\d)$`)
+)
+
+// parseDuration parses a time spent as written by a technician. It rejects
+// zero, negative (there is no sign in the patterns) and over-long durations.
+func parseDuration(s string) (time.Duration, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	var d time.Duration
+	if m := clockDurationPattern.FindStringSubmatch(s); m != nil {
+		h, _ := strconv.Atoi(m[1])
+		mins, _ := strconv.Atoi(m[2])
+		d = time.Duration(h)*time.Hour + time.Duration(mins)*time.Minute
+	} else if m := durationPattern.FindStringSubmatch(s); m != nil && (m[1] != "" || m[2] != "") {
+		if m[1] != "" {
+			h, err := strconv.ParseFloat(strings.Replace(m[1], ",", ".", 1), 64)
+			if err != nil {
+				return 0, fmt.Errorf("duração inválida: %q", s)
+			}
+			d = time.Duration(math.Round(h*60)) * time.Minute
+		}
+		if m[2] != "" {
+			mins, _ := strconv.Atoi(m[2])
+			d += time.Duration(mins) * time.Minute
+		}
+	} else {
+		return 0, fmt.Errorf("duração inválida: %q (use por exemplo 30min, 1h30 ou 2h)", s)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("a duração precisa ser maior que zero")
+	}
+	if d > maxLoggedDuration {
+		return 0, fmt.Errorf("duração de %s é maior que o limite de %s por registro", formatDuration(d), formatDuration(maxLoggedDuration))
+	}
+	return d, nil
+}
+
+// loggedTime sums the time spent recorded on a ticket's tasks.
+func loggedTime(tasks []glpi.TicketTask) time.Duration {
+	var total time.Duration
+	for _, task := range tasks {
+		total += time.Duration(task.Actiontime) * time.Second
+	}
+	return total
+}
+
+// --- LogTime ---
+
+type LogTime struct {
+	technicianOnly
+	glpi         *glpi.Client
+	sessionToken string
+}
+
+func NewLogTime(g *glpi.Client, token string) *LogTime {
+	return &LogTime{glpi: g, sessionToken: token}
+}
+
+func (t *LogTime) Name() string   { return "log_time" }
+func (t *LogTime) ReadOnly() bool { return false }
+func (t *LogTime) Description() string {
+	return `Registra o tempo gasto em um chamado, como uma tarefa concluida com a duracao, ou corrige a duracao de uma tarefa existente.
+Quando usar: quando o tecnico informar quanto tempo trabalhou. Ex: "lanca 30min no chamado 123", "gastei 1h30 no 456", "a tarefa 78 levou 2h".
+Informe a duracao como o tecnico escreveu (30min, 1h30, 2h, 1:30). Com task_id, a duracao da tarefa e substituida, nao somada.
+SEMPRE confirme chamado, duracao e descricao com o usuario via respond_interactive antes de registrar.
+Retorna: {tarefa_id, tempo_registrado, total_chamado, mensagem}.`
+}
+func (t *LogTime) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"ticket_id":   {Type: "integer", Description: "ID do chamado"},
+			"duration":    {Type: "string", Description: "Tempo gasto. Ex: '30min', '1h30', '2h', '1:30'"},
+			"description": {Type: "string", Description: "O que foi feito, para a nova tarefa. Ex: 'Troca do cabo de rede'"},
+			"task_id":     {Type: "integer", Description: "ID de uma tarefa existente do chamado (get_ticket_tasks) para corrigir sua duracao, em vez de criar uma nova"},
+		},
+		Required: []string{"ticket_id", "duration"},
+	}
+}
+
+func (t *LogTime) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	ticketID, err := intArg(args, "ticket_id")
+	if err != nil {
+		return nil, err
+	}
+	durationText, err := stringArg(args, "duration")
+	if err != nil {
+		return nil, err
+	}
+	d, err := parseDuration(durationText)
+	if err != nil {
+		return nil, err
+	}
+	seconds := int(d.Seconds())
+
+	taskID := optionalIntArg(args, "task_id")
+	if taskID > 0 {
+		tasks, err := t.glpi.GetTicketTasks(t.sessionToken, ticketID)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao buscar tarefas: %w", err)
+		}
+		if !slices.ContainsFunc(tasks, func(task glpi.TicketTask) bool { return task.ID == taskID }) {
+			return nil, fmt.Errorf("a tarefa %d não pertence ao chamado #%d", taskID, ticketID)
+		}
+		if err := t.glpi.SetTicketTaskTime(t.sessionToken, taskID, seconds); err != nil {
+			return nil, fmt.Errorf("erro ao registrar tempo: %w", err)
+		}
+	} else {
+		content := optionalStringArg(args, "description")
+		if content == "" {
+			content = "Tempo registrado via WhatsApp"
+		}
+		taskID, err = t.glpi.LogTicketTaskTime(t.sessionToken, ticketID, content, seconds)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao registrar tempo: %w", err)
+		}
+	}
+
+	result := map[string]any{
+		"tarefa_id":        taskID,
+		"tempo_registrado": formatDuration(d),
+		"mensagem":         fmt.Sprintf("%s registrado(s) no chamado #%d", formatDuration(d), ticketID),
+	}
+	// The time is already saved; a failed re-read only loses the total.
+	if tasks, err := t.glpi.GetTicketTasks(t.sessionToken, ticketID); err == nil {
+		result["total_chamado"] = formatDuration(loggedTime(tasks))
+	}
+	return result, nil
+}
+
+var _ ai.Tool = (*LogTime)(nil)
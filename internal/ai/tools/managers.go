@@ -0,0 +1,208 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lojasmm/laia/internal/ai"
+	"github.com/lojasmm/laia/internal/glpi"
+)
+
+// managerNotifyEvery is how long a ticket can't be sent to its manager again;
+// a worried user would otherwise ping them once per message.
+const managerNotifyEvery = 4 * time.Hour
+
+// Manager is who hears about urgent tickets of a department. The file lists
+// one entry per department (the department_id from get_departments), and
+// may narrow an entry to some categories; an entry without department_id
+// or category_ids is the default.
+//
+//	[{"department_id": 3, "name": "Ana (TI)", "phone": "5547999990000"},
+//	 {"category_ids": [42], "name": "Bruno (Redes)", "phone": "5547999990001"}]
+type Manager struct {
+	DepartmentID int    `json:"department_id"`
+	CategoryIDs  []int  `json:"category_ids"`
+	Name         string `json:"name"`
+	Phone        string `json:"phone"`
+}
+
+// LoadManagers reads the manager list. An empty path disables notify_manager.
+func LoadManagers(path string) ([]Manager, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var managers []Manager
+	if err := json.Unmarshal(data, &managers); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	for i, m := range managers {
+		if m.Phone == "" || m.Name == "" {
+			return nil, fmt.Errorf("%s: manager %d needs name and phone", path, i)
+		}
+	}
+	return managers, nil
+}
+
+// ManagerNotifier sends a WhatsApp message to a manager's phone.
+type ManagerNotifier func(phone, message string) error
+
+// resolveManager picks the manager for a ticket: one naming its category
+// first, since it's the most specific, then its department, then the default.
+func resolveManager(managers []Manager, departmentID, categoryID int) (Manager, bool) {
+	var byDepartment, fallback *Manager
+	for i := range managers {
+		m := &managers[i]
+		switch {
+		case categoryID > 0 && slices.Contains(m.CategoryIDs, categoryID):
+			return *m, true
+		case departmentID > 0 && m.DepartmentID == departmentID && len(m.CategoryIDs) == 0 && byDepartment == nil:
+			byDepartment = m
+		case m.DepartmentID == 0 && len(m.CategoryIDs) == 0 && fallback == nil:
+			fallback = m
+		}
+	}
+	if byDepartment != nil {
+		return *byDepartment, true
+	}
+	if fallback != nil {
+		return *fallback, true
+	}
+	return Manager{}, false
+}
+
+// managerNotices remembers which tickets were sent to a manager recently. It
+// is shared by every registry, like configAlerts.
+type managerNotices struct {
+	mu   sync.Mutex
+	sent map[int]time.Time
+}
+
+func newManagerNotices() *managerNotices {
+	return &managerNotices{sent: make(map[int]time.Time)}
+}
+
+// claim reports whether ticketID may be sent now and, if so, records it.
+func (n *managerNotices) claim(ticketID int, now time.Time) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if last, ok := n.sent[ticketID]; ok && now.Sub(last) < managerNotifyEvery {
+		return false
+	}
+	for id, at := range n.sent {
+		if now.Sub(at) >= managerNotifyEvery {
+			delete(n.sent, id)
+		}
+	}
+	n.sent[ticketID] = now
+	return true
+}
+
+func (n *managerNotices) release(ticketID int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.sent, ticketID)
+}
+
+// --- NotifyManager ---
+
+type NotifyManager struct {
+	glpi         *glpi.Client
+	sessionToken string
+	userID       int
+	managers     []Manager
+	notify       ManagerNotifier
+	notices      *managerNotices
+}
+
+func NewNotifyManager(g *glpi.Client, token string, userID int, managers []Manager, notify ManagerNotifier, notices *managerNotices) *NotifyManager {
+	return &NotifyManager{glpi: g, sessionToken: token, userID: userID, managers: managers, notify: notify, notices: notices}
+}
+
+func (t *NotifyManager) Name() string   { return "notify_manager" }
+func (t *NotifyManager) ReadOnly() bool { return false }
+func (t *NotifyManager) Description() string {
+	return `Avisa o gestor responsavel pelo setor, por WhatsApp, que um chamado e urgente, com um resumo do chamado e da preocupacao do usuario.
+Quando usar: quando o usuario precisar que uma pessoa saiba do chamado agora, alem de aumentar a urgencia. Ex: "avisa o gestor que o chamado 123 e urgente", "preciso que alguem veja isso hoje".
+NAO usar: so para mudar a urgencia (use update_ticket) ou para ser atendido por alguem (use human_handoff).
+SEMPRE confirme com o usuario via respond_interactive antes de avisar. Informe department_id se souber o setor do chamado.
+Cada chamado so pode ser enviado ao gestor uma vez a cada 4 horas.
+Retorna: {enviado, gestor, mensagem}.`
+}
+func (t *NotifyManager) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"ticket_id":     {Type: "integer", Description: "ID do chamado"},
+			"concern":       {Type: "string", Description: "Por que e urgente, nas palavras do usuario. Ex: 'loja sem sistema desde as 9h, caixa parado'"},
+			"department_id": {Type: "integer", Description: "ID do departamento/formulario do chamado (get_departments), se conhecido"},
+		},
+		Required: []string{"ticket_id", "concern"},
+	}
+}
+
+func (t *NotifyManager) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	ticketID, err := intArg(args, "ticket_id")
+	if err != nil {
+		return nil, err
+	}
+	concern, err := stringArg(args, "concern")
+	if err != nil {
+		return nil, err
+	}
+
+	// Reading the ticket with the user's session also checks they can see it.
+	ticket, err := t.glpi.GetTicket(t.sessionToken, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar chamado: %w", err)
+	}
+	categoryID, err := t.glpi.GetTicketCategoryID(t.sessionToken, ticketID)
+	if err != nil {
+		slog.Warn("tools: notify_manager could not read ticket category", "ticket_id", ticketID, "error", err)
+	}
+	manager, ok := resolveManager(t.managers, optionalIntArg(args, "department_id"), categoryID)
+	if !ok {
+		return map[string]any{
+			"enviado":  false,
+			"mensagem": "Não há gestor cadastrado para o setor deste chamado. Sugira aumentar a urgência ou falar com um atendente.",
+		}, nil
+	}
+
+	if !t.notices.claim(ticketID, time.Now()) {
+		return map[string]any{
+			"enviado":  false,
+			"gestor":   manager.Name,
+			"mensagem": fmt.Sprintf("O gestor já foi avisado sobre o chamado #%d há pouco tempo. Ele será avisado de novo só daqui a algumas horas.", ticketID),
+		}, nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "🔔 *Chamado #%d marcado como urgente*\n", ticketID)
+	fmt.Fprintf(&b, "%s\n", ticket.Name)
+	fmt.Fprintf(&b, "Status: %s · Urgência: %s\n", ticketStatusLabel(ticket.Status), urgencyLabel(ticket.Urgency))
+	fmt.Fprintf(&b, "Solicitante: %s\n\n", userNamer(t.glpi, t.sessionToken)(t.userID))
+	fmt.Fprintf(&b, "_%s_", truncateText(concern, 500))
+	if err := t.notify(manager.Phone, b.String()); err != nil {
+		t.notices.release(ticketID)
+		return nil, fmt.Errorf("erro ao avisar o gestor: %w", err)
+	}
+
+	slog.Info("tools: manager notified", "ticket_id", ticketID, "manager", manager.Name)
+	return map[string]any{
+		"enviado":  true,
+		"gestor":   manager.Name,
+		"mensagem": fmt.Sprintf("%s foi avisado(a) sobre o chamado #%d", manager.Name, ticketID),
+	}, nil
+}
+
+var _ ai.Tool = (*NotifyManager)(nil)
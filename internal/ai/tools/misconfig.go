@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/lojasmm/laia/internal/ai"
+)
+
+// configAlertEvery bounds how often the same problem is sent to admins; every
+// user reaching a broken form would otherwise trigger one message.
+const configAlertEvery = 6 * time.Hour
+
+// AdminAlert delivers GLPI configuration problems a user ran into to the
+// people who can fix them (e.g. a WhatsApp message to ADMIN_ALERT_PHONE).
+type AdminAlert func(message string)
+
+// configAlerts logs configuration problems found while serving users and
+// forwards them to the AdminAlert, if any, at most once per configAlertEvery.
+type configAlerts struct {
+	mu   sync.Mutex
+	sent map[string]time.Time
+	send AdminAlert
+}
+
+func newConfigAlerts(send AdminAlert) *configAlerts {
+	return &configAlerts{sent: make(map[string]time.Time), send: send}
+}
+
+// report records the problem identified by key. attrs are slog key/values.
+// A support dry run only logs it, so reproducing a conversation doesn't page
+// the admins.
+func (a *configAlerts) report(ctx context.Context, key, message string, attrs ...any) {
+	slog.Warn("tools: glpi misconfiguration", append([]any{"problem", key, "detail", message}, attrs...)...)
+	if a == nil || a.send == nil || ai.IsDryRun(ctx) {
+		return
+	}
+	a.mu.Lock()
+	if last, ok := a.sent[key]; ok && time.Since(last) < configAlertEvery {
+		a.mu.Unlock()
+		return
+	}
+	a.sent[key] = time.Now()
+	a.mu.Unlock()
+	// Sending goes over the network; don't hold up the user's reply.
+	go a.send(message)
+}
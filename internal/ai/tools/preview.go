@@ -0,0 +1,128 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lojasmm/laia/internal/ai"
+	"github.com/lojasmm/laia/internal/glpi"
+)
+
+// --- PreviewTicket ---
+
+// PreviewTicket shows what create_ticket would send for the same arguments,
+// with IDs resolved to names, so the confirmation step matches the result.
+type PreviewTicket struct {
+	create *CreateTicket
+}
+
+func NewPreviewTicket(create *CreateTicket) *PreviewTicket {
+	return &PreviewTicket{create: create}
+}
+
+func (t *PreviewTicket) Name() string   { return "preview_ticket" }
+func (t *PreviewTicket) ReadOnly() bool { return true }
+func (t *PreviewTicket) Description() string {
+	return `Mostra como o chamado vai ficar antes de criar: setor, categoria completa, localizacao, urgencia/impacto e quem sera atribuido/observador pelas regras do formulario. NAO cria nada.
+Quando usar: na Etapa 4 do fluxo de criacao, antes de pedir a confirmacao, com os MESMOS argumentos que voce vai passar a create_ticket. Use o resumo retornado na mensagem de confirmacao.
+Retorna: {titulo, descricao, setor, categoria, tipo, localizacao, urgencia, impacto, atribuido_a, observadores, inclui_conversa}.`
+}
+func (t *PreviewTicket) Parameters() *ai.ParamSchema { return t.create.Parameters() }
+
+func (t *PreviewTicket) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	input, formID, err := t.create.buildInput(args)
+	if err != nil {
+		return nil, err
+	}
+
+	// Same session as create_ticket: form target actors aren't readable by
+	// self-service profiles.
+	g := t.create.glpi
+	adminSession, err := g.AdminSession(glpi.AdminCreateTicket)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar sessão admin: %w", err)
+	}
+	defer g.KillSession(adminSession)
+
+	typ, clarify := resolveTicketType(g, adminSession, input.ITILCategoriesID, input.Type)
+	if clarify != nil {
+		return clarify, nil
+	}
+	input.Type = typ
+	if formID > 0 {
+		applyFormActors(g, adminSession, formID, t.create.userID, &input)
+	}
+
+	itemName := func(itemtype string, id int) string {
+		if name, err := g.GetItemName(adminSession, itemtype, id); err == nil && name != "" {
+			return name
+		}
+		return fmt.Sprintf("#%d", id)
+	}
+	userName := userNamer(g, adminSession)
+
+	// title/description come from the args as typed; the transcript is
+	// only flagged, it would drown the summary.
+	title, _ := stringArg(args, "title")
+	description, _ := stringArg(args, "description")
+	preview := map[string]any{
+		"titulo":          title,
+		"descricao":       truncateText(description, 300),
+		"categoria":       itemName("ITILCategory", input.ITILCategoriesID),
+		"tipo":            ticketTypeLabel(input.Type),
+		"inclui_conversa": len(input.Content) > len(description),
+	}
+	if formID > 0 {
+		preview["setor"] = t.formName(adminSession, formID)
+	}
+	if input.LocationsID > 0 {
+		preview["localizacao"] = itemName("Location", input.LocationsID)
+	}
+	if input.Urgency > 0 {
+		preview["urgencia"] = urgencyLabel(input.Urgency)
+	}
+	if input.Impact > 0 {
+		preview["impacto"] = impactLabel(input.Impact)
+	}
+
+	actorNames := func(groups, users []int) []string {
+		names := []string{}
+		for _, id := range groups {
+			names = append(names, "Grupo "+itemName("Group", id))
+		}
+		for _, id := range users {
+			names = append(names, userName(id))
+		}
+		return names
+	}
+	if assigned := actorNames(input.GroupsIDAssign, input.UsersIDAssign); len(assigned) > 0 {
+		preview["atribuido_a"] = assigned
+	}
+	if observers := actorNames(input.GroupsIDObserver, input.UsersIDObserver); len(observers) > 0 {
+		preview["observadores"] = observers
+	}
+	if errorLog := optionalStringArg(args, "error_log"); errorLog != "" {
+		preview["log_anexado"] = len(strings.TrimSpace(errorLog)) > maxInlineLogLen
+	}
+	if len(input.CustomFields) > 0 {
+		preview["campos_adicionais"] = input.CustomFields
+	}
+	return preview, nil
+}
+
+// formName looks the department's form up in the form list; forms are shown
+// by name everywhere else (get_departments).
+func (t *PreviewTicket) formName(session string, formID int) string {
+	forms, err := t.create.glpi.GetForms(session)
+	if err == nil {
+		for _, f := range forms {
+			if f.ID == formID {
+				return f.Name
+			}
+		}
+	}
+	return fmt.Sprintf("#%d", formID)
+}
+
+var _ ai.Tool = (*PreviewTicket)(nil)
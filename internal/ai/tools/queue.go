@@ -0,0 +1,180 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lojasmm/laia/internal/ai"
+	"github.com/lojasmm/laia/internal/glpi"
+)
+
+// queuedStatuses are the statuses still waiting for a technician: new,
+// processing (assigned) and processing (planned). Pending tickets (4) wait on
+// someone else and don't hold up the queue.
+var queuedStatuses = []int{1, 2, 3}
+
+// --- QueuePosition ---
+
+type QueuePosition struct {
+	glpi         *glpi.Client
+	sessionToken string
+}
+
+func NewQueuePosition(g *glpi.Client, token string) *QueuePosition {
+	return &QueuePosition{glpi: g, sessionToken: token}
+}
+
+func (t *QueuePosition) Name() string   { return "queue_position" }
+func (t *QueuePosition) ReadOnly() bool { return true }
+func (t *QueuePosition) Description() string {
+	return `Estima a posicao de um chamado aberto na fila: quantos chamados mais antigos, ainda em aberto, com prioridade igual ou maior, estao no mesmo grupo atribuido e categoria.
+Quando usar: quando o usuario perguntar "quantos chamados estao na frente do meu?", "qual a posicao do meu chamado na fila?". Para previsao de solucao use estimate_resolution.
+E uma aproximacao: SEMPRE repasse o aviso ao usuario.
+Retorna: {id, grupo, categoria, a_frente, posicao, aviso}.`
+}
+func (t *QueuePosition) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"ticket_id": {Type: "integer", Description: "ID do chamado"},
+		},
+		Required: []string{"ticket_id"},
+	}
+}
+
+func (t *QueuePosition) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	ticketID, err := intArg(args, "ticket_id")
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetched with the user's session first so only visible tickets are ranked.
+	ticket, err := t.glpi.GetTicket(t.sessionToken, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar chamado: %w", err)
+	}
+	if ticket.Status >= 4 {
+		return map[string]any{
+			"id":       ticket.ID,
+			"mensagem": fmt.Sprintf("O chamado está %s, então não está na fila de atendimento.", ticketStatusLabel(ticket.Status)),
+		}, nil
+	}
+
+	// The queue is made of other users' tickets; only the count leaves this
+	// function, so the admin session is safe (same as estimate_resolution).
+	adminSession, err := t.glpi.AdminSession(glpi.AdminReadReference)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar sessão admin: %w", err)
+	}
+	defer t.glpi.KillSession(adminSession)
+
+	group, err := t.assignedGroup(adminSession, ticket.ID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar grupo do chamado: %w", err)
+	}
+	category := dropdownName(ticket.ITILCategoriesID)
+	if group == "" && category == "" {
+		return map[string]any{
+			"id":       ticket.ID,
+			"mensagem": "O chamado ainda não tem grupo nem categoria, então não dá para saber a fila dele.",
+		}, nil
+	}
+
+	result, err := t.glpi.AdvancedSearchTickets(adminSession, queueAheadCriteria(group, category, ticket.DateCreated, ticket.Priority))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao contar chamados na fila: %w", err)
+	}
+
+	out := map[string]any{
+		"id":       ticket.ID,
+		"a_frente": result.TotalCount,
+		"posicao":  result.TotalCount + 1,
+		"aviso": "Posição aproximada: chamados urgentes podem passar na frente e a equipe nem sempre atende em ordem. " +
+			"Não é uma previsão de horário.",
+	}
+	if group != "" {
+		out["grupo"] = group
+	}
+	if category != "" {
+		out["categoria"] = category
+	}
+	return out, nil
+}
+
+// assignedGroup reads the ticket's assigned group (search field 8) from its
+// search row, since the Ticket item itself doesn't carry actors. With several
+// groups only the first is used.
+func (t *QueuePosition) assignedGroup(session string, ticketID int) (string, error) {
+	result, err := t.glpi.AdvancedSearchTickets(session, map[string]string{
+		"criteria[0][field]":      "2",
+		"criteria[0][searchtype]": "equals",
+		"criteria[0][value]":      fmt.Sprintf("%d", ticketID),
+		"forcedisplay[12]":        "8",
+		"range":                   "0-0",
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(result.Data) == 0 {
+		return "", nil
+	}
+	group, _ := result.Data[0]["8"].(string)
+	// Multi-valued search columns are joined with "$#$".
+	group, _, _ = strings.Cut(group, "$#$")
+	return strings.TrimSpace(group), nil
+}
+
+// queueAheadCriteria counts queued tickets opened before openedAt in the same
+// assigned group (8) and category (7), with priority (3) at least priority.
+// Names are anchored (^...$) so parent groups/categories don't match their
+// children. Priority only supports "equals", hence the OR group.
+// Reference: nexus_apirest.md — search criteria
+func queueAheadCriteria(group, category, openedAt string, priority int) map[string]string {
+	criteria := map[string]string{
+		"range":                   "0-0",
+		"criteria[0][field]":      "15",
+		"criteria[0][searchtype]": "lessthan",
+		"criteria[0][value]":      openedAt,
+	}
+	idx := 1
+	addAnd := func(field, value string) {
+		criteria[fmt.Sprintf("criteria[%d][link]", idx)] = "AND"
+		criteria[fmt.Sprintf("criteria[%d][field]", idx)] = field
+		criteria[fmt.Sprintf("criteria[%d][searchtype]", idx)] = "contains"
+		criteria[fmt.Sprintf("criteria[%d][value]", idx)] = "^" + value + "$"
+		idx++
+	}
+	if group != "" {
+		addAnd("8", group)
+	}
+	if category != "" {
+		addAnd("7", category)
+	}
+
+	orGroup := func(field string, values []int) {
+		criteria[fmt.Sprintf("criteria[%d][link]", idx)] = "AND"
+		for j, v := range values {
+			prefix := fmt.Sprintf("criteria[%d][criteria][%d]", idx, j)
+			if j > 0 {
+				criteria[prefix+"[link]"] = "OR"
+			}
+			criteria[prefix+"[field]"] = field
+			criteria[prefix+"[searchtype]"] = "equals"
+			criteria[prefix+"[value]"] = fmt.Sprintf("%d", v)
+		}
+		idx++
+	}
+	orGroup("12", queuedStatuses)
+	if priority < 1 {
+		priority = 1
+	}
+	var priorities []int
+	for p := priority; p <= 6; p++ { // 6 = Major
+		priorities = append(priorities, p)
+	}
+	orGroup("3", priorities)
+	return criteria
+}
+
+var _ ai.Tool = (*QueuePosition)(nil)
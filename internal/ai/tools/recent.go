@@ -0,0 +1,57 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lojasmm/laia/internal/ai"
+	"github.com/lojasmm/laia/internal/store"
+)
+
+// --- RecentTickets ---
+
+// RecentTickets lists the tickets the agent recently handled for this phone
+// (recorded by ai.Agent after each successful tool call). It reads only the
+// local store, so titles and statuses are as of that interaction.
+type RecentTickets struct {
+	store store.Store
+	phone string
+}
+
+func NewRecentTickets(s store.Store, phone string) *RecentTickets {
+	return &RecentTickets{store: s, phone: phone}
+}
+
+func (t *RecentTickets) Name() string   { return "recent_tickets" }
+func (t *RecentTickets) ReadOnly() bool { return true }
+func (t *RecentTickets) Description() string {
+	return `Lista os ultimos chamados com que o usuario interagiu por aqui (consultados, comentados, criados), do mais recente ao mais antigo.
+Quando usar: quando o usuario quiser voltar a um chamado recente sem lembrar o numero. Ex: "aquele chamado de ontem", "o chamado que vimos antes".
+NAO usar: para listar todos os chamados do usuario — use list_my_tickets.
+Titulo e status sao de quando o chamado foi visto; use get_ticket para a situacao atual.
+Retorna: {total, chamados: [{id, titulo, status, visto_em}]}.`
+}
+func (t *RecentTickets) Parameters() *ai.ParamSchema { return nil }
+
+func (t *RecentTickets) Execute(_ context.Context, _ map[string]any) (map[string]any, error) {
+	recent, err := t.store.RecentTickets(t.phone)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler chamados recentes: %w", err)
+	}
+	items := make([]map[string]any, len(recent))
+	for i, r := range recent {
+		items[i] = map[string]any{
+			"id":       r.ID,
+			"titulo":   r.Title,
+			"status":   r.Status,
+			"visto_em": r.At.In(brLocation).Format("02/01 15:04"),
+		}
+	}
+	result := map[string]any{"total": len(items), "chamados": items}
+	if len(items) == 0 {
+		result["mensagem"] = "Nenhum chamado recente por aqui."
+	}
+	return result, nil
+}
+
+var _ ai.Tool = (*RecentTickets)(nil)
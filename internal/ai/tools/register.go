@@ -2,34 +2,173 @@ package tools
 
 import (
 	"fmt"
+	"log/slog"
 	"math"
+	"strconv"
+	"strings"
 
 	"github.com/lojasmm/laia/internal/ai"
 	"github.com/lojasmm/laia/internal/glpi"
+	"github.com/lojasmm/laia/internal/store"
 )
 
-// BuildRegistry creates a Registry with all GLPI tools configured for this session.
-func BuildRegistry(g *glpi.Client, sessionToken string, userID int) *ai.Registry {
+// Options carries startup configuration and shared dependencies for the tools.
+type Options struct {
+	// AttachTranscript appends a condensed WhatsApp transcript to tickets created by create_ticket.
+	AttachTranscript bool
+	// Store persists tool state that outlives a conversation (e.g. reminders).
+	Store store.Store
+	// Routing enables suggest_routing; nil leaves routing entirely to the LLM.
+	Routing []RoutingRule
+	// Branches enables set_branch; nil disables it.
+	Branches []Branch
+	// Completer enables translate_ticket, which costs an extra model call.
+	Completer *ai.Completer
+	// CustomFields enables the custom_fields parameter of create_ticket; nil disables it.
+	CustomFields []CustomField
+	// Handoff enables human_handoff when CategoryID is set.
+	Handoff HandoffConfig
+	// AdminAlert receives GLPI configuration problems users run into (e.g. a
+	// form without a category question); nil only logs them.
+	AdminAlert AdminAlert
+	// StatusWorkflow limits the status changes the ticket tools accept; nil uses
+	// DefaultStatusWorkflow.
+	StatusWorkflow StatusWorkflow
+	// Urgency sets the default urgency and the categories that don't ask for it.
+	Urgency UrgencyPolicy
+	// UrgencyRules enables suggest_urgency; nil disables it.
+	UrgencyRules []UrgencyRule
+	// Managers and NotifyManager enable notify_manager; both are required.
+	Managers      []Manager
+	NotifyManager ManagerNotifier
+
+	translations *translationCache
+	kbCategories *kbCategoryCache
+	categorySLAs *categorySLACache
+	catalog      *serviceCatalogCache
+	configAlerts *configAlerts
+	managerPings *managerNotices
+	kbGuides     *kbGuides
+}
+
+// NewRegistryBuilder returns an ai.RegistryBuilder that builds every GLPI tool with opts applied.
+func NewRegistryBuilder(opts Options) ai.RegistryBuilder {
+	opts.kbCategories = newKBCategoryCache()
+	opts.categorySLAs = newCategorySLACache()
+	opts.catalog = newServiceCatalogCache()
+	opts.configAlerts = newConfigAlerts(opts.AdminAlert)
+	opts.managerPings = newManagerNotices()
+	opts.kbGuides = newKBGuides()
+	if opts.StatusWorkflow == nil {
+		opts.StatusWorkflow = DefaultStatusWorkflow
+	}
+	if opts.Completer != nil {
+		opts.translations = newTranslationCache()
+	}
+	return func(g *glpi.Client, sessionToken string, userID int, conv *ai.Conversation) *ai.Registry {
+		return buildRegistry(g, sessionToken, userID, conv, opts)
+	}
+}
+
+// buildRegistry creates a Registry with all GLPI tools configured for this session.
+func buildRegistry(g *glpi.Client, sessionToken string, userID int, conv *ai.Conversation, opts Options) *ai.Registry {
 	r := ai.NewRegistry()
 	r.Register(NewListMyTickets(g, sessionToken))
+	r.Register(NewDashboard(g, sessionToken, userID))
 	r.Register(NewGetTicket(g, sessionToken, userID))
-	r.Register(NewCreateTicket(g, userID))
-	r.Register(NewUpdateTicket(g, sessionToken, userID))
+	r.Register(NewGetTicketsBatch(g, sessionToken))
+	r.Register(NewFindTicketByDescription(g, sessionToken))
+	if conv != nil {
+		r.Register(NewGetTicketDescription(g, sessionToken, conv))
+		r.Register(NewListTicketDocuments(g, sessionToken, conv))
+		r.Register(NewSendTicketDocument(g, sessionToken, conv))
+	}
+	if opts.Completer != nil {
+		r.Register(NewTranslateTicket(g, sessionToken, opts.Completer, opts.translations))
+	}
+	r.Register(NewSLAStatus(g, sessionToken))
+	r.Register(NewMyDeadlines(g, sessionToken, userID))
+	r.Register(NewEstimateResolution(g, sessionToken))
+	r.Register(NewQueuePosition(g, sessionToken))
+	createTicket := NewCreateTicket(g, userID)
+	if opts.AttachTranscript {
+		createTicket.conv = conv
+	}
+	createTicket.customFields = opts.CustomFields
+	createTicket.urgency = opts.Urgency
+	r.Register(createTicket)
+	r.Register(NewPreviewTicket(createTicket))
+	r.Register(NewAnalyzeErrorLog())
+	if opts.Handoff.CategoryID > 0 && conv != nil {
+		r.Register(NewHumanHandoff(g, userID, conv, opts.Handoff))
+	}
+	r.Register(NewUpdateTicket(g, sessionToken, userID, opts.StatusWorkflow))
+	if len(opts.Managers) > 0 && opts.NotifyManager != nil {
+		r.Register(NewNotifyManager(g, sessionToken, userID, opts.Managers, opts.NotifyManager, opts.managerPings))
+	}
 	r.Register(NewAddFollowup(g, sessionToken, userID))
+	r.Register(NewFollowupAndUpdate(g, sessionToken, opts.StatusWorkflow))
+	r.Register(NewSelfResolve(g, sessionToken, userID))
+	r.Register(NewUpdateContactInfo(g, sessionToken, userID))
 	r.Register(NewGetFollowups(g, sessionToken, userID))
 	r.Register(NewSearchTicketsAdvanced(g, sessionToken))
+	r.Register(NewSearchITIL(g, sessionToken, userID))
+	r.Register(NewSearchDeletedTickets(g, sessionToken))
+	r.Register(NewRestoreTicket(g, sessionToken))
+	r.Register(NewTicketCountByPeriod(g, sessionToken))
+	r.Register(NewMyTicketsByCategory(g, sessionToken, userID))
+	r.Register(NewMyAssignedTickets(g, sessionToken, userID))
+	r.Register(NewAwaitingMe(g, sessionToken, userID))
+	r.Register(NewColleagueTickets(g, sessionToken))
 	r.Register(NewGetTicketTasks(g, sessionToken, userID))
+	r.Register(NewNextIntervention(g, sessionToken))
 	r.Register(NewAddTicketTask(g, sessionToken, userID))
+	r.Register(NewLogTime(g, sessionToken))
 	r.Register(NewApproveTicket(g, sessionToken))
+	r.Register(NewListPendingApprovals(g, sessionToken, userID))
+	r.Register(NewBulkApprove(g, sessionToken, userID))
+	r.Register(NewApprovalHistory(g, sessionToken))
+	r.Register(NewExplainStatus())
 	r.Register(NewRateTicket(g, sessionToken))
+	r.Register(NewCloseAndRate(g, sessionToken, opts.StatusWorkflow))
 	r.Register(NewGetTicketHistory(g, sessionToken, userID))
-	r.Register(NewSearchKnowledgeBase(g, sessionToken))
+	r.Register(NewSearchKnowledgeBase(g, sessionToken, opts.kbCategories))
 	r.Register(NewGetKBArticle(g, sessionToken))
+	if conv != nil {
+		r.Register(NewKBGuide(g, sessionToken, conv, opts.kbGuides))
+	}
 	r.Register(NewSearchAssets(g, sessionToken))
-	r.Register(NewGetDepartments(g, sessionToken))
-	r.Register(NewGetDepartmentCategories(g, sessionToken))
-	r.Register(NewGetSubCategories(g))
+	r.Register(NewTicketAssets(g, sessionToken))
+	r.Register(NewTicketsForAsset(g, sessionToken))
+	r.Register(NewListAssetReservations(g, sessionToken))
+	r.Register(NewReserveAsset(g, sessionToken, userID))
+	if len(opts.Routing) > 0 {
+		r.Register(NewSuggestRouting(opts.Routing))
+	}
+	if len(opts.UrgencyRules) > 0 {
+		r.Register(NewSuggestUrgency(opts.UrgencyRules))
+	}
+	if len(opts.Branches) > 0 {
+		r.Register(NewSetBranch(g, sessionToken, opts.Branches))
+	}
+	r.Register(NewGetDepartments(g, sessionToken, userID))
+	r.Register(NewServiceCatalog(g, sessionToken, userID, opts.catalog))
+	r.Register(NewGetDepartmentCategories(g, sessionToken, opts.configAlerts, opts.categorySLAs, opts.Urgency))
+	r.Register(NewGetSubCategories(g, opts.categorySLAs, opts.Urgency))
+	if opts.Store != nil && conv != nil {
+		r.Register(NewRemindMe(g, sessionToken, userID, opts.Store, conv.Phone))
+		r.Register(NewRecentTickets(opts.Store, conv.Phone))
+	}
 	r.Register(NewRespondInteractive())
+
+	if r.HasRestricted() {
+		if p, err := g.ActiveProfile(sessionToken); err == nil {
+			r.SetProfile(p)
+		} else {
+			// Without a profile the registry denies restricted tools.
+			slog.Warn("tools: could not read active profile", "error", err)
+		}
+	}
 	return r
 }
 
@@ -83,6 +222,31 @@ func optionalIntArg(args map[string]any, key string) int {
 	}
 }
 
+// intSliceArg extracts an array of integers, dropping duplicates.
+func intSliceArg(args map[string]any, key string) ([]int, error) {
+	v, ok := args[key]
+	if !ok {
+		return nil, fmt.Errorf("parâmetro obrigatório ausente: %s", key)
+	}
+	items, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("parâmetro %s deve ser uma lista", key)
+	}
+	seen := make(map[int]bool, len(items))
+	ids := make([]int, 0, len(items))
+	for _, item := range items {
+		n, ok := item.(float64)
+		if !ok || n != math.Trunc(n) {
+			return nil, fmt.Errorf("parâmetro %s deve conter apenas inteiros", key)
+		}
+		if !seen[int(n)] {
+			seen[int(n)] = true
+			ids = append(ids, int(n))
+		}
+	}
+	return ids, nil
+}
+
 // clarification builds a response asking the LLM to clarify with the user.
 func clarification(question string, options []string, context string) map[string]any {
 	result := map[string]any{
@@ -107,6 +271,40 @@ func intInSlice(val int, slice []int) bool {
 	return false
 }
 
+// searchInt reads a numeric search-result value; GLPI returns these as
+// numbers or numeric strings depending on the field.
+func searchInt(v any) int {
+	switch n := v.(type) {
+	case float64:
+		return int(n)
+	case int:
+		return n
+	case string:
+		i, _ := strconv.Atoi(n)
+		return i
+	default:
+		return 0
+	}
+}
+
+// searchLabel renders a search column holding a dropdown code (status,
+// urgency, priority, impact) with label. The search API returns the numeric
+// code, but some GLPI versions and plugins already send the display name,
+// which is passed through unchanged.
+func searchLabel(v any, label func(int) string) any {
+	switch n := v.(type) {
+	case float64, int:
+		return label(searchInt(n))
+	case string:
+		if code, err := strconv.Atoi(strings.TrimSpace(n)); err == nil {
+			return label(code)
+		}
+		return n
+	default:
+		return v
+	}
+}
+
 func truncateText(s string, maxLen int) string {
 	runes := []rune(s)
 	if len(runes) <= maxLen {
@@ -0,0 +1,53 @@
+package tools
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/lojasmm/laia/internal/ai"
+	"github.com/lojasmm/laia/internal/store"
+)
+
+// TestReadOnlyTools guards the parallel path: only read-only tools run
+// concurrently, so a read tool reporting false silently runs one by one, and
+// a write reporting true would run unconfirmed alongside others.
+func TestReadOnlyTools(t *testing.T) {
+	s, err := store.NewBoltStore(filepath.Join(t.TempDir(), "laia.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	build := NewRegistryBuilder(Options{Store: s, Handoff: HandoffConfig{CategoryID: 1}})
+	var history []store.ConversationTurn
+	registry := build(testGLPI(t, http.NotFoundHandler().ServeHTTP), "session", 1, ai.NewConversation("5511987654321", &history))
+
+	reads := []string{
+		"search_assets", "list_my_tickets", "get_ticket", "get_tickets_batch", "search_tickets_advanced",
+		"get_ticket_tasks", "get_followups", "get_ticket_history", "get_ticket_description", "find_ticket",
+		"recent_tickets", "count_tickets_by_period", "my_tickets_by_category", "list_my_assigned_tickets",
+		"list_colleague_tickets", "search_knowledge_base", "get_kb_article", "get_service_catalog",
+		"get_departments", "get_department_categories", "get_subcategories", "list_pending_approvals",
+		"get_approval_history", "get_ticket_sla", "my_deadlines", "my_dashboard", "explain_status",
+		"search_deleted_tickets", "list_ticket_documents", "get_ticket_assets", "tickets_for_asset",
+	}
+	writes := []string{
+		"create_ticket", "update_ticket", "add_followup", "add_followup_and_update", "add_ticket_task",
+		"approve_ticket", "bulk_approve", "rate_ticket", "close_and_rate", "self_resolve_ticket",
+		"restore_ticket", "log_time", "reserve_asset", "update_contact_info", "set_reminder", "human_handoff",
+	}
+	for _, name := range reads {
+		if !registry.Has(name) {
+			t.Errorf("%s is not registered", name)
+		} else if !registry.IsReadOnly(name) {
+			t.Errorf("IsReadOnly(%s) = false, want true", name)
+		}
+	}
+	for _, name := range writes {
+		if !registry.Has(name) {
+			t.Errorf("%s is not registered", name)
+		} else if registry.IsReadOnly(name) {
+			t.Errorf("IsReadOnly(%s) = true, want false", name)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lojasmm/laia/internal/ai"
+	"github.com/lojasmm/laia/internal/glpi"
+	"github.com/lojasmm/laia/internal/store"
+)
+
+// brLocation is Brasília time. Brazil dropped DST in 2019, so a fixed offset is
+// exact and avoids depending on tzdata in the alpine image.
+var brLocation = time.FixedZone("BRT", -3*60*60)
+
+// --- RemindMe ---
+
+type RemindMe struct {
+	glpi         *glpi.Client
+	sessionToken string
+	userID       int
+	store        store.Store
+	phone        string
+	now          func() time.Time
+}
+
+func NewRemindMe(g *glpi.Client, token string, userID int, s store.Store, phone string) *RemindMe {
+	return &RemindMe{glpi: g, sessionToken: token, userID: userID, store: s, phone: phone, now: time.Now}
+}
+
+func (t *RemindMe) Name() string   { return "set_reminder" }
+func (t *RemindMe) ReadOnly() bool { return false }
+func (t *RemindMe) Description() string {
+	return `Agenda um lembrete sobre um chamado para ser enviado via WhatsApp no horario escolhido.
+Quando usar: quando o usuario pedir para ser lembrado de um chamado. Ex: "me lembra desse chamado amanha as 9h", "me avisa do chamado 123 em 2 horas".
+O campo 'when' aceita linguagem natural em PT-BR: "em 30 minutos", "daqui 2 horas", "amanha as 9h", "hoje 15:30", "sexta 10h", "25/12 08:00".
+Sem horario explicito, usa 9h. Horarios sao no fuso de Brasilia.
+So chamados em que o usuario participa (solicitante, tecnico ou observador) podem ter lembrete.
+Retorna: {mensagem, lembrete_em} ou {agendado: false, mensagem}.`
+}
+func (t *RemindMe) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"ticket_id": {Type: "integer", Description: "ID do chamado"},
+			"when":      {Type: "string", Description: "Quando lembrar, em linguagem natural (ex: 'amanhã às 9h', 'em 2 horas')"},
+			"note":      {Type: "string", Description: "Observação curta a incluir no lembrete (opcional)"},
+		},
+		Required: []string{"ticket_id", "when"},
+	}
+}
+
+func (t *RemindMe) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	ticketID, err := intArg(args, "ticket_id")
+	if err != nil {
+		return nil, err
+	}
+	when, _ := stringArg(args, "when")
+
+	// A reminder repeats the ticket to the user later, so it must be one they
+	// could follow anyway.
+	actors, err := t.glpi.GetTicketUsers(t.sessionToken, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar participantes do chamado: %w", err)
+	}
+	if !isTicketActor(actors, t.userID) {
+		return map[string]any{
+			"agendado": false,
+			"mensagem": fmt.Sprintf("Você não participa do chamado #%d, então não posso agendar um lembrete para ele.", ticketID),
+		}, nil
+	}
+
+	now := t.now()
+	fireAt, err := parseReminderTime(when, now)
+	if err != nil {
+		return clarification(
+			"Para quando voce quer o lembrete?",
+			[]string{"Em 1 hora", "Amanhã às 9h", "Segunda às 9h"},
+			fmt.Sprintf("Nao entendi o horario %q: %v", when, err),
+		), nil
+	}
+
+	r := store.Reminder{
+		Phone:     t.phone,
+		TicketID:  ticketID,
+		FireAt:    fireAt,
+		Note:      truncateText(optionalStringArg(args, "note"), 200),
+		CreatedAt: now,
+	}
+	if err := t.store.SaveReminder(r); err != nil {
+		return nil, fmt.Errorf("erro ao salvar lembrete: %w", err)
+	}
+
+	formatted := fireAt.In(brLocation).Format("02/01 às 15:04")
+	return map[string]any{
+		"mensagem":    fmt.Sprintf("Lembrete do chamado #%d agendado para %s", ticketID, formatted),
+		"lembrete_em": formatted,
+	}, nil
+}
+
+// isTicketActor reports whether userID is a requester, technician or
+// observer of the ticket.
+func isTicketActor(actors []glpi.TicketUser, userID int) bool {
+	for _, a := range actors {
+		if a.UsersID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+var _ ai.Tool = (*RemindMe)(nil)
+
+// --- natural time parsing ---
+
+var (
+	relativePattern = regexp.MustCompile(`(?:em|daqui(?: a)?)\s+(\d+)\s*(min|minutos?|h|horas?|dias?)\b`)
+	datePattern     = regexp.MustCompile(`\b(\d{1,2})/(\d{1,2})(?:/(\d{4}))?\b`)
+	clockPattern    = regexp.MustCompile(`\b(\d{1,2})(?::(\d{2})|h(\d{2})?|\s*horas?\b)`)
+	atPattern       = regexp.MustCompile(`(?:^|\s)(?:às|as)\s+(\d{1,2})\b`)
+)
+
+var weekdays = map[string]time.Weekday{
+	"domingo": time.Sunday, "segunda": time.Monday, "terça": time.Tuesday, "terca": time.Tuesday,
+	"quarta": time.Wednesday, "quinta": time.Thursday, "sexta": time.Friday,
+	"sábado": time.Saturday, "sabado": time.Saturday,
+}
+
+// parseReminderTime turns PT-BR expressions like "amanhã às 9h" or "em 2 horas"
+// into an absolute time after now. Days without a time default to 09:00.
+func parseReminderTime(s string, now time.Time) (time.Time, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return time.Time{}, fmt.Errorf("horário vazio")
+	}
+	now = now.In(brLocation)
+
+	if m := relativePattern.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if n <= 0 {
+			return time.Time{}, fmt.Errorf("intervalo deve ser positivo")
+		}
+		switch {
+		case strings.HasPrefix(m[2], "min"):
+			return now.Add(time.Duration(n) * time.Minute), nil
+		case strings.HasPrefix(m[2], "h"):
+			return now.Add(time.Duration(n) * time.Hour), nil
+		default:
+			return now.AddDate(0, 0, n), nil
+		}
+	}
+
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, brLocation)
+	explicitDay := true
+	switch {
+	case strings.Contains(s, "depois de amanhã"), strings.Contains(s, "depois de amanha"):
+		day = day.AddDate(0, 0, 2)
+	case strings.Contains(s, "amanhã"), strings.Contains(s, "amanha"):
+		day = day.AddDate(0, 0, 1)
+	case strings.Contains(s, "hoje"):
+	default:
+		explicitDay = false
+		if m := datePattern.FindStringSubmatch(s); m != nil {
+			d, _ := strconv.Atoi(m[1])
+			mo, _ := strconv.Atoi(m[2])
+			y := now.Year()
+			if m[3] != "" {
+				y, _ = strconv.Atoi(m[3])
+			}
+			if mo < 1 || mo > 12 || d < 1 || d > 31 {
+				return time.Time{}, fmt.Errorf("data inválida")
+			}
+			day = time.Date(y, time.Month(mo), d, 0, 0, 0, 0, brLocation)
+			if m[3] == "" && day.Before(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, brLocation)) {
+				day = day.AddDate(1, 0, 0)
+			}
+			explicitDay = true
+			s = strings.Replace(s, m[0], " ", 1)
+			break
+		}
+		for name, wd := range weekdays {
+			if strings.Contains(s, name) {
+				ahead := (int(wd) - int(now.Weekday()) + 7) % 7
+				if ahead == 0 {
+					ahead = 7
+				}
+				day = day.AddDate(0, 0, ahead)
+				explicitDay = true
+				break
+			}
+		}
+	}
+
+	hour, minute, hasClock := 9, 0, false
+	if m := clockPattern.FindStringSubmatch(s); m != nil {
+		hour, _ = strconv.Atoi(m[1])
+		if m[2] != "" {
+			minute, _ = strconv.Atoi(m[2])
+		} else if m[3] != "" {
+			minute, _ = strconv.Atoi(m[3])
+		}
+		hasClock = true
+	} else if m := atPattern.FindStringSubmatch(s); m != nil {
+		hour, _ = strconv.Atoi(m[1])
+		hasClock = true
+	}
+	if hour > 23 || minute > 59 {
+		return time.Time{}, fmt.Errorf("horário inválido")
+	}
+	if !explicitDay && !hasClock {
+		return time.Time{}, fmt.Errorf("não reconheci data nem horário")
+	}
+
+	fireAt := day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	if !explicitDay && !fireAt.After(now) {
+		fireAt = fireAt.AddDate(0, 0, 1)
+	}
+	if !fireAt.After(now) {
+		return time.Time{}, fmt.Errorf("esse horário já passou")
+	}
+	return fireAt, nil
+}
@@ -0,0 +1,148 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lojasmm/laia/internal/ai"
+)
+
+// RoutingRule maps keywords to a department (form) and ITIL category, so
+// common problems skip the decision tree. Loaded from ROUTING_HINTS_FILE:
+//
+//	[{"keywords": ["vpn", "forticlient"], "department_id": 3, "category_id": 42, "label": "TI - Acessos"}]
+type RoutingRule struct {
+	Keywords     []string `json:"keywords"`
+	DepartmentID int      `json:"department_id"`
+	CategoryID   int      `json:"category_id"`
+	Label        string   `json:"label"`
+}
+
+// LoadRoutingRules reads the routing hint file. An empty path disables hints.
+func LoadRoutingRules(path string) ([]RoutingRule, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rules []RoutingRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	for i, r := range rules {
+		if len(r.Keywords) == 0 || r.DepartmentID == 0 || r.CategoryID == 0 {
+			return nil, fmt.Errorf("%s: rule %d needs keywords, department_id and category_id", path, i)
+		}
+	}
+	return rules, nil
+}
+
+var accentFolder = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+	"é", "e", "ê", "e", "è", "e", "ë", "e",
+	"í", "i", "î", "i", "ì", "i", "ï", "i",
+	"ó", "o", "ô", "o", "õ", "o", "ò", "o", "ö", "o",
+	"ú", "u", "û", "u", "ù", "u", "ü", "u",
+	"ç", "c",
+)
+
+// routingWords lowercases, strips accents and splits on anything that isn't a
+// letter or digit, so "VPN!" and "vpn" compare equal.
+func routingWords(s string) []string {
+	s = accentFolder.Replace(strings.ToLower(s))
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+}
+
+// matchRouting returns the rule with the most keyword hits in text, and the
+// keywords that hit. Keywords may be several words ("impressora travada") and
+// must appear as whole words, so "vpn" doesn't match "vpnx". Ties keep the
+// earlier rule, letting admins order the file by priority.
+func matchRouting(rules []RoutingRule, text string) (*RoutingRule, []string) {
+	words := routingWords(text)
+	var best *RoutingRule
+	var bestHits []string
+	for i := range rules {
+		var hits []string
+		for _, kw := range rules[i].Keywords {
+			if kwWords := routingWords(kw); len(kwWords) > 0 && containsPhrase(words, kwWords) {
+				hits = append(hits, kw)
+			}
+		}
+		if len(hits) > len(bestHits) {
+			best, bestHits = &rules[i], hits
+		}
+	}
+	return best, bestHits
+}
+
+func containsPhrase(words, phrase []string) bool {
+	for i := 0; i+len(phrase) <= len(words); i++ {
+		match := true
+		for j := range phrase {
+			if words[i+j] != phrase[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// --- SuggestRouting ---
+
+type SuggestRouting struct {
+	rules []RoutingRule
+}
+
+func NewSuggestRouting(rules []RoutingRule) *SuggestRouting {
+	return &SuggestRouting{rules: rules}
+}
+
+func (t *SuggestRouting) Name() string   { return "suggest_routing" }
+func (t *SuggestRouting) ReadOnly() bool { return true }
+func (t *SuggestRouting) Description() string {
+	return `Sugere setor e categoria a partir de palavras-chave configuradas pelos administradores.
+Quando usar: no fluxo de criacao de chamado, ANTES das Etapas 2 e 3, passando um resumo do problema.
+Se encontrado=true, use department_id e category_id direto e apenas confirme com o usuario. Se encontrado=false, siga a arvore de decisao normal.
+Retorna: {encontrado, department_id, category_id, rotulo, palavras_chave}.`
+}
+func (t *SuggestRouting) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"problem": {Type: "string", Description: "Resumo do problema relatado pelo usuario"},
+		},
+		Required: []string{"problem"},
+	}
+}
+
+func (t *SuggestRouting) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	problem, err := stringArg(args, "problem")
+	if err != nil {
+		return nil, err
+	}
+
+	rule, hits := matchRouting(t.rules, problem)
+	if rule == nil {
+		return map[string]any{"encontrado": false}, nil
+	}
+	return map[string]any{
+		"encontrado":     true,
+		"department_id":  rule.DepartmentID,
+		"category_id":    rule.CategoryID,
+		"rotulo":         rule.Label,
+		"palavras_chave": hits,
+	}, nil
+}
+
+var _ ai.Tool = (*SuggestRouting)(nil)
@@ -0,0 +1,262 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lojasmm/laia/internal/ai"
+	"github.com/lojasmm/laia/internal/glpi"
+)
+
+// searchCriteria builds GLPI search criteria: top-level criteria linked with
+// AND, sub-criteria inside a group linked with OR. Field IDs are the search
+// options Ticket, Problem and Change share (1=Title, 12=Status, 15=Open date,
+// 21=Content...), so one builder serves all three.
+// Reference: nexus_apirest.md — criteria[N][criteria][M] for sub-groups.
+type searchCriteria struct {
+	params map[string]string
+	n      int
+}
+
+type searchCond struct {
+	field, searchType, value string
+}
+
+func newSearchCriteria() *searchCriteria {
+	return &searchCriteria{params: map[string]string{}}
+}
+
+// add adds a single top-level AND criterion.
+func (c *searchCriteria) add(field, searchType, value string) {
+	c.addAny(searchCond{field, searchType, value})
+}
+
+// addAny adds a top-level AND group matching any of conds.
+func (c *searchCriteria) addAny(conds ...searchCond) {
+	if len(conds) == 0 {
+		return
+	}
+	if c.n > 0 {
+		c.params[fmt.Sprintf("criteria[%d][link]", c.n)] = "AND"
+	}
+	prefix := func(j int) string { return fmt.Sprintf("criteria[%d]", c.n) }
+	if len(conds) > 1 {
+		prefix = func(j int) string { return fmt.Sprintf("criteria[%d][criteria][%d]", c.n, j) }
+	}
+	for j, cond := range conds {
+		p := prefix(j)
+		if j > 0 {
+			c.params[p+"[link]"] = "OR"
+		}
+		c.params[p+"[field]"] = cond.field
+		c.params[p+"[searchtype]"] = cond.searchType
+		c.params[p+"[value]"] = cond.value
+	}
+	c.n++
+}
+
+// addText matches query in the title or the content.
+func (c *searchCriteria) addText(query string) {
+	if query != "" {
+		c.addAny(searchCond{"1", "contains", query}, searchCond{"21", "contains", query})
+	}
+}
+
+func (c *searchCriteria) addStatuses(codes []int) {
+	conds := make([]searchCond, len(codes))
+	for i, code := range codes {
+		conds[i] = searchCond{"12", "equals", fmt.Sprintf("%d", code)}
+	}
+	c.addAny(conds...)
+}
+
+// addPeriod limits the opening date to a parsePeriod range.
+func (c *searchCriteria) addPeriod(period string) {
+	if period == "" {
+		return
+	}
+	dateFrom, dateTo := parsePeriod(period)
+	if dateFrom != "" {
+		c.add("15", "morethan", dateFrom)
+	}
+	if dateTo != "" {
+		c.add("15", "lessthan", dateTo)
+	}
+}
+
+// itilStatuses maps a status filter to the codes of itemtype. Problems and
+// changes have statuses tickets don't (accepted, under observation, the
+// change approval steps); they're open ones, except refused and canceled.
+// Reference: https://github.com/glpi-project/glpi/blob/main/src/CommonITILObject.php (status constants)
+func itilStatuses(itemtype, status string) []int {
+	switch {
+	case itemtype == "ticket":
+		return mapStatusToGLPI(status)
+	case status == "aberto" && itemtype == "problem":
+		return []int{1, 2, 3, 7, 8}
+	case status == "aberto" && itemtype == "change":
+		return []int{1, 2, 3, 7, 8, 9, 10, 11, 12}
+	case status == "fechado" && itemtype == "change":
+		return []int{6, 13, 14}
+	default:
+		return mapStatusToGLPI(status)
+	}
+}
+
+func itilStatusLabel(s int) string {
+	switch s {
+	case 7:
+		return "Aceito"
+	case 8:
+		return "Em observação"
+	case 9:
+		return "Em avaliação"
+	case 10:
+		return "Em aprovação"
+	case 11:
+		return "Em teste"
+	case 12:
+		return "Em qualificação"
+	case 13:
+		return "Recusado"
+	case 14:
+		return "Cancelado"
+	default:
+		return ticketStatusLabel(s)
+	}
+}
+
+// itilSearchType is what differs between searching tickets, problems and
+// changes: the endpoint and the columns worth showing. Tickets are about who
+// asked; problems and changes about their reach.
+type itilSearchType struct {
+	search func(*glpi.Client, string, map[string]string) (*glpi.SearchResponse, error)
+	item   func(glpi.SearchResultItem) map[string]any
+}
+
+var itilSearchTypes = map[string]itilSearchType{
+	"ticket": {
+		search: (*glpi.Client).AdvancedSearchTickets,
+		item: func(d glpi.SearchResultItem) map[string]any {
+			return map[string]any{"urgencia": searchLabel(d["10"], urgencyLabel), "solicitante": d["4"]}
+		},
+	},
+	"problem": {
+		search: (*glpi.Client).SearchProblems,
+		item: func(d glpi.SearchResultItem) map[string]any {
+			return map[string]any{"impacto": searchLabel(d["11"], impactLabel), "data_solucao": d["17"]}
+		},
+	},
+	"change": {
+		search: (*glpi.Client).SearchChanges,
+		item: func(d glpi.SearchResultItem) map[string]any {
+			return map[string]any{"impacto": searchLabel(d["11"], impactLabel), "data_solucao": d["17"]}
+		},
+	},
+}
+
+// --- SearchITIL ---
+
+// SearchITIL searches problems and changes as well as tickets. Self-service
+// profiles have no access to problems and changes and keep
+// search_tickets_advanced for their tickets.
+type SearchITIL struct {
+	technicianOnly
+	glpi         *glpi.Client
+	sessionToken string
+	userID       int
+}
+
+func NewSearchITIL(g *glpi.Client, token string, userID int) *SearchITIL {
+	return &SearchITIL{glpi: g, sessionToken: token, userID: userID}
+}
+
+func (t *SearchITIL) Name() string   { return "search_itil" }
+func (t *SearchITIL) ReadOnly() bool { return true }
+func (t *SearchITIL) Description() string {
+	return `Busca problemas e mudancas do Nexus (e tambem chamados) por palavra-chave, status ou periodo.
+Quando usar: quando o tecnico perguntar por problemas ou mudancas. Ex: "tem problema aberto sobre a VPN?", "mudancas planejadas este mes", "problemas atribuidos a mim".
+NAO usar: para chamados com filtros de urgencia, tecnico ou solicitante — use search_tickets_advanced.
+Informe itemtype e pelo menos um criterio. Resultados limitados a 10 itens.
+Retorna: {tipo, total, itens: [{id, titulo, status, data_abertura, prioridade, categoria, tecnico, ...}]}; chamados trazem urgencia e solicitante, problemas e mudancas trazem impacto e data_solucao.`
+}
+func (t *SearchITIL) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"itemtype": {
+				Type:        "string",
+				Description: "O que buscar: ticket (chamados), problem (problemas), change (mudancas)",
+				Enum:        []string{"ticket", "problem", "change"},
+			},
+			"query": {Type: "string", Description: "Busca no titulo e na descricao. Ex: 'VPN'"},
+			"status": {
+				Type:        "string",
+				Description: "Filtrar por status: aberto, pendente, solucionado, fechado, todos",
+				Enum:        []string{"aberto", "pendente", "solucionado", "fechado", "todos"},
+			},
+			"period":         {Type: "string", Description: "Periodo de abertura: hoje, semana, mes, ano, ou intervalo YYYY-MM-DD..YYYY-MM-DD"},
+			"assigned_to_me": {Type: "boolean", Description: "Se true, apenas itens atribuidos ao usuario como tecnico"},
+		},
+		Required: []string{"itemtype"},
+	}
+}
+
+func (t *SearchITIL) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	itemtype, err := stringArg(args, "itemtype")
+	if err != nil {
+		return nil, err
+	}
+	typ, ok := itilSearchTypes[itemtype]
+	if !ok {
+		return nil, fmt.Errorf("itemtype inválido: %s", itemtype)
+	}
+	query := optionalStringArg(args, "query")
+	status := optionalStringArg(args, "status")
+	period := optionalStringArg(args, "period")
+	mine, _ := args["assigned_to_me"].(bool)
+
+	if query == "" && status == "" && period == "" && !mine {
+		return clarification(
+			"O que você gostaria de buscar? Informe pelo menos um critério.",
+			[]string{"texto (ex: VPN)", "status (aberto/pendente)", "periodo (hoje/semana/mes)", "atribuidos a mim"},
+			"Use search_itil com itemtype e pelo menos um criterio.",
+		), nil
+	}
+
+	criteria := newSearchCriteria()
+	criteria.addText(query)
+	if status != "" && status != "todos" {
+		criteria.addStatuses(itilStatuses(itemtype, status))
+	}
+	criteria.addPeriod(period)
+	if mine {
+		criteria.add("5", "equals", fmt.Sprintf("%d", t.userID))
+	}
+	criteria.params["range"] = "0-9"
+
+	result, err := typ.search(t.glpi, t.sessionToken, criteria.params)
+	if err != nil {
+		return nil, fmt.Errorf("erro na busca: %w", err)
+	}
+
+	items := make([]map[string]any, len(result.Data))
+	for i, d := range result.Data {
+		item := map[string]any{
+			"id":            d["2"],
+			"titulo":        d["1"],
+			"status":        searchLabel(d["12"], itilStatusLabel),
+			"data_abertura": d["15"],
+			"prioridade":    searchLabel(d["3"], priorityLabel),
+			"categoria":     d["7"],
+			"tecnico":       d["5"],
+		}
+		for k, v := range typ.item(d) {
+			item[k] = v
+		}
+		items[i] = item
+	}
+	return map[string]any{"tipo": itemtype, "total": result.TotalCount, "itens": items}, nil
+}
+
+var _ ai.Tool = (*SearchITIL)(nil)
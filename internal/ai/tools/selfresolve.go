@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lojasmm/laia/internal/ai"
+	"github.com/lojasmm/laia/internal/glpi"
+)
+
+// --- SelfResolve ---
+
+type SelfResolve struct {
+	glpi         *glpi.Client
+	sessionToken string
+	userID       int
+}
+
+func NewSelfResolve(g *glpi.Client, token string, userID int) *SelfResolve {
+	return &SelfResolve{glpi: g, sessionToken: token, userID: userID}
+}
+
+func (t *SelfResolve) Name() string   { return "self_resolve_ticket" }
+func (t *SelfResolve) ReadOnly() bool { return false }
+func (t *SelfResolve) Description() string {
+	return `Marca como solucionado um chamado que o proprio usuario resolveu, registrando um comentario com o que ele fez.
+Quando usar: quando o usuario disser que resolveu sozinho. Ex: "consegui resolver, pode fechar o 123", "ja resolvi, era o cabo solto".
+SEMPRE confirme antes com respond_interactive (botoes "Confirmar"/"Cancelar"). So funciona para chamados em que o usuario e solicitante.
+NAO usar: para comentar sem mudar o status — use add_followup.
+Retorna: {comentario_id, status_alterado, mensagem}.`
+}
+func (t *SelfResolve) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"ticket_id": {Type: "integer", Description: "ID do chamado"},
+			"note":      {Type: "string", Description: "O que o usuário fez para resolver. Ex: 'reiniciei o roteador'"},
+		},
+		Required: []string{"ticket_id", "note"},
+	}
+}
+
+func (t *SelfResolve) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	ticketID, err := intArg(args, "ticket_id")
+	if err != nil {
+		return nil, err
+	}
+	note, _ := stringArg(args, "note")
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return nil, fmt.Errorf("parâmetro obrigatório ausente: note")
+	}
+
+	ticket, err := t.glpi.GetTicket(t.sessionToken, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar chamado: %w", err)
+	}
+	if ticket.Status >= 5 {
+		return map[string]any{
+			"status_alterado": false,
+			"mensagem":        fmt.Sprintf("O chamado #%d já está %s.", ticketID, ticketStatusLabel(ticket.Status)),
+		}, nil
+	}
+
+	// Technicians and observers can see the ticket too; only requesters may
+	// close it on their own say-so.
+	actors, err := t.glpi.GetTicketUsers(t.sessionToken, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar solicitantes do chamado: %w", err)
+	}
+	if !isTicketRequester(actors, t.userID) {
+		return map[string]any{
+			"status_alterado": false,
+			"mensagem":        fmt.Sprintf("Só quem solicitou o chamado #%d pode marcá-lo como resolvido. Sugira um comentário com add_followup.", ticketID),
+		}, nil
+	}
+
+	id, err := t.glpi.AddFollowup(t.sessionToken, ticketID, "Resolvido pelo próprio usuário: "+note)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao adicionar comentário: %w", err)
+	}
+	result := map[string]any{"comentario_id": id, "status_alterado": false}
+
+	// Partial success like add_followup_and_update: an error here would make
+	// the agent retry and post the followup twice.
+	if err := t.glpi.UpdateTicket(t.sessionToken, ticketID, glpi.UpdateTicketInput{Status: 5}); err != nil {
+		result["erro_status"] = ai.ClassifyError(err).Message
+		result["mensagem"] = fmt.Sprintf("Comentário registrado no chamado #%d, mas não foi possível marcá-lo como solucionado", ticketID)
+		return result, nil
+	}
+	result["status_alterado"] = true
+	result["mensagem"] = fmt.Sprintf("Chamado #%d marcado como solucionado por você. A equipe de TI ainda pode reabri-lo se necessário.", ticketID)
+	return result, nil
+}
+
+func isTicketRequester(actors []glpi.TicketUser, userID int) bool {
+	for _, a := range actors {
+		if a.Type == glpi.ActorRequester && a.UsersID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+var _ ai.Tool = (*SelfResolve)(nil)
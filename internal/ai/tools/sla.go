@@ -0,0 +1,253 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lojasmm/laia/internal/ai"
+	"github.com/lojasmm/laia/internal/glpi"
+)
+
+// slaRiskThreshold is how close to the due date a ticket is flagged as at risk.
+const slaRiskThreshold = 2 * time.Hour
+
+// --- SLAStatus ---
+
+type SLAStatus struct {
+	glpi         *glpi.Client
+	sessionToken string
+	now          func() time.Time
+}
+
+func NewSLAStatus(g *glpi.Client, token string) *SLAStatus {
+	return &SLAStatus{glpi: g, sessionToken: token, now: time.Now}
+}
+
+func (t *SLAStatus) Name() string   { return "get_ticket_sla" }
+func (t *SLAStatus) ReadOnly() bool { return true }
+func (t *SLAStatus) Description() string {
+	return `Mostra a situacao do SLA de um chamado: dentro do prazo, em risco (menos de 2h) ou violado.
+Quando usar: quando o usuario perguntar sobre prazo, SLA ou se o chamado esta atrasado. Ex: "meu chamado esta atrasado?", "qual o prazo do chamado 123?".
+NAO usar: para detalhes gerais do chamado — use get_ticket.
+Retorna: {id, sla_atendimento: {situacao, indicador, prazo, restante}, sla_solucao: {...}, nivel_escalonamento}.
+Mostre o indicador (🟢/🟡/🔴) junto da situacao na resposta.`
+}
+func (t *SLAStatus) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"ticket_id": {Type: "integer", Description: "ID do chamado"},
+		},
+		Required: []string{"ticket_id"},
+	}
+}
+
+func (t *SLAStatus) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	ticketID, err := intArg(args, "ticket_id")
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, err := t.glpi.GetTicket(t.sessionToken, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar chamado: %w", err)
+	}
+	if ticket.TimeToOwn == "" && ticket.TimeToResolve == "" {
+		return map[string]any{
+			"id":       ticket.ID,
+			"mensagem": "Este chamado não possui SLA definido.",
+		}, nil
+	}
+
+	now := t.now()
+	result := map[string]any{"id": ticket.ID, "status": ticketStatusLabel(ticket.Status)}
+	if ticket.TimeToOwn != "" {
+		// takeintoaccount_delay_stat is only set once a technician picks the ticket up
+		var ownedAt string
+		if ticket.TakeIntoAccountDelay > 0 {
+			ownedAt = addSecondsToGLPIDate(ticket.DateCreated, ticket.TakeIntoAccountDelay)
+		}
+		result["sla_atendimento"] = slaDeadline(ticket.TimeToOwn, ownedAt, now)
+	}
+	if ticket.TimeToResolve != "" {
+		doneAt := ticket.SolveDate
+		if doneAt == "" {
+			doneAt = ticket.CloseDate
+		}
+		result["sla_solucao"] = slaDeadline(ticket.TimeToResolve, doneAt, now)
+	}
+	if level := dropdownName(ticket.SLALevelTTR); level != "" {
+		result["nivel_escalonamento"] = level
+	}
+	return result, nil
+}
+
+var _ ai.Tool = (*SLAStatus)(nil)
+
+// --- MyDeadlines ---
+
+// maxDeadlines bounds the list; the most urgent come first, so the rest are
+// the least interesting ones.
+const maxDeadlines = 10
+
+type MyDeadlines struct {
+	glpi         *glpi.Client
+	sessionToken string
+	userID       int
+	now          func() time.Time
+}
+
+func NewMyDeadlines(g *glpi.Client, token string, userID int) *MyDeadlines {
+	return &MyDeadlines{glpi: g, sessionToken: token, userID: userID, now: time.Now}
+}
+
+func (t *MyDeadlines) Name() string   { return "my_deadlines" }
+func (t *MyDeadlines) ReadOnly() bool { return true }
+func (t *MyDeadlines) Description() string {
+	return `Lista os chamados abertos do usuario com prazo de SLA, do mais urgente para o menos: violados primeiro, depois em risco (menos de 2h) e dentro do prazo.
+Quando usar: quando o usuario perguntar o que esta atrasado ou vencendo. Ex: "o que esta atrasado?", "quais chamados vencem hoje?", "meus prazos".
+NAO usar: para o SLA de um chamado especifico — use get_ticket_sla.
+Retorna: {total, violados, em_risco, chamados: [{id, titulo, status, prazo_solucao: {situacao, indicador, prazo, restante|atraso}, prazo_atendimento}], sem_sla}.
+Mostre o indicador (🟢/🟡/🔴) de cada chamado.`
+}
+func (t *MyDeadlines) Parameters() *ai.ParamSchema { return nil }
+
+func (t *MyDeadlines) Execute(_ context.Context, _ map[string]any) (map[string]any, error) {
+	result, err := t.glpi.AdvancedSearchTickets(t.sessionToken, actorTicketsCriteria("4", t.userID, ""))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar chamados: %w", err)
+	}
+
+	deadlines, withoutSLA := ticketDeadlines(result.Data, t.now())
+	overdue, atRisk := 0, 0
+	for _, d := range deadlines {
+		switch d.item["prazo_solucao"].(map[string]any)["situacao"] {
+		case "violado":
+			overdue++
+		case "em risco":
+			atRisk++
+		}
+	}
+
+	items := make([]map[string]any, 0, min(len(deadlines), maxDeadlines))
+	for _, d := range deadlines[:min(len(deadlines), maxDeadlines)] {
+		items = append(items, d.item)
+	}
+	out := map[string]any{
+		"total":    len(deadlines),
+		"violados": overdue,
+		"em_risco": atRisk,
+		"chamados": items,
+		"sem_sla":  withoutSLA,
+	}
+	if len(deadlines) == 0 {
+		out["mensagem"] = "Nenhum chamado aberto seu tem prazo de SLA."
+	} else if len(deadlines) > len(items) {
+		out["mensagem"] = fmt.Sprintf("Mostrando os %d mais urgentes de %d.", len(items), len(deadlines))
+	}
+	return out, nil
+}
+
+type ticketDeadline struct {
+	due  time.Time
+	item map[string]any
+}
+
+// ticketDeadlines turns search rows into deadline entries ordered by time to
+// resolve (18), soonest (most overdue) first, and counts rows without one.
+// Time to own (155) is only shown while the ticket is new: once a technician
+// takes it, that target no longer applies to what the user is waiting for.
+func ticketDeadlines(rows []glpi.SearchResultItem, now time.Time) ([]ticketDeadline, int) {
+	var deadlines []ticketDeadline
+	withoutSLA := 0
+	for _, row := range rows {
+		ttr, _ := row["18"].(string)
+		due, err := time.ParseInLocation(glpiDateTime, ttr, brLocation)
+		if err != nil {
+			withoutSLA++
+			continue
+		}
+		status := searchInt(row["12"])
+		item := map[string]any{
+			"id":            searchInt(row["2"]),
+			"titulo":        row["1"],
+			"status":        ticketStatusLabel(status),
+			"prazo_solucao": slaDeadline(ttr, "", now),
+		}
+		if tto, _ := row["155"].(string); tto != "" && status == 1 {
+			item["prazo_atendimento"] = slaDeadline(tto, "", now)
+		}
+		deadlines = append(deadlines, ticketDeadline{due: due, item: item})
+	}
+	sort.SliceStable(deadlines, func(i, j int) bool { return deadlines[i].due.Before(deadlines[j].due) })
+	return deadlines, withoutSLA
+}
+
+// slaDeadline classifies a due date. When doneAt is set the SLA target was
+// already reached, so it's judged against that instead of now.
+func slaDeadline(due, doneAt string, now time.Time) map[string]any {
+	dueAt, err := time.ParseInLocation(glpiDateTime, due, brLocation)
+	if err != nil {
+		return map[string]any{"prazo": due, "situacao": "desconhecida"}
+	}
+	out := map[string]any{"prazo": dueAt.Format("02/01/2006 15:04")}
+
+	if doneAt != "" {
+		if at, err := time.ParseInLocation(glpiDateTime, doneAt, brLocation); err == nil {
+			if at.After(dueAt) {
+				out["situacao"], out["indicador"] = "violado", "🔴"
+			} else {
+				out["situacao"], out["indicador"] = "cumprido", "🟢"
+			}
+			return out
+		}
+	}
+
+	remaining := dueAt.Sub(now)
+	switch {
+	case remaining <= 0:
+		out["situacao"], out["indicador"] = "violado", "🔴"
+		out["atraso"] = formatDuration(-remaining)
+	case remaining < slaRiskThreshold:
+		out["situacao"], out["indicador"] = "em risco", "🟡"
+		out["restante"] = formatDuration(remaining)
+	default:
+		out["situacao"], out["indicador"] = "dentro do prazo", "🟢"
+		out["restante"] = formatDuration(remaining)
+	}
+	return out
+}
+
+func addSecondsToGLPIDate(date string, seconds int) string {
+	t, err := time.ParseInLocation(glpiDateTime, date, brLocation)
+	if err != nil {
+		return ""
+	}
+	return t.Add(time.Duration(seconds) * time.Second).Format(glpiDateTime)
+}
+
+// dropdownName returns the label of an expanded dropdown field, or "" when unset.
+func dropdownName(v any) string {
+	if s, ok := v.(string); ok && s != "" && s != "0" {
+		return s
+	}
+	return ""
+}
+
+// formatDuration renders d as "3d 4h", "2h 15min" or "40min".
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dmin", hours, minutes)
+	default:
+		return fmt.Sprintf("%dmin", minutes)
+	}
+}
@@ -0,0 +1,122 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+
+	"github.com/lojasmm/laia/internal/ai"
+	"github.com/lojasmm/laia/internal/glpi"
+)
+
+const (
+	// maxInlineImages bounds the images sent for one description; screenshots
+	// beyond that are rarely worth the extra WhatsApp messages.
+	maxInlineImages = 3
+	// maxInlineImageBytes is WhatsApp's image size limit.
+	// Reference: https://developers.facebook.com/docs/whatsapp/cloud-api/reference/media#supported-media-types
+	maxInlineImageBytes = 5 << 20
+)
+
+// inlineImagePattern matches images pasted into GLPI rich text, which are
+// stored as Documents and referenced through document.send.php?docid=N.
+var inlineImagePattern = regexp.MustCompile(`(?i)<img[^>]*\ssrc\s*=\s*["'][^"']*document\.send\.php\?[^"']*\bdocid=(\d+)`)
+
+// inlineImageDocIDs returns the Document IDs of the images embedded in GLPI
+// content, in order and without repeats. Content is HTML-encoded in the
+// database (&lt;img ...&gt;), so it is decoded first.
+func inlineImageDocIDs(content string) []int {
+	decoded := html.UnescapeString(content)
+	var ids []int
+	seen := map[int]bool{}
+	for _, m := range inlineImagePattern.FindAllStringSubmatch(decoded, -1) {
+		id, err := strconv.Atoi(m[1])
+		if err != nil || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// --- GetTicketDescription ---
+
+type GetTicketDescription struct {
+	glpi         *glpi.Client
+	sessionToken string
+	conv         *ai.Conversation
+}
+
+func NewGetTicketDescription(g *glpi.Client, token string, conv *ai.Conversation) *GetTicketDescription {
+	return &GetTicketDescription{glpi: g, sessionToken: token, conv: conv}
+}
+
+func (t *GetTicketDescription) Name() string   { return "get_ticket_description" }
+func (t *GetTicketDescription) ReadOnly() bool { return true }
+func (t *GetTicketDescription) Description() string {
+	return `Mostra a descricao completa de um chamado e envia ao usuario, como imagens no WhatsApp, os prints colados nela.
+Quando usar: quando o usuario quiser ver a descricao com os prints/imagens. Ex: "me mostra o print do chamado 123", "quero ver a descricao completa com as imagens".
+NAO usar: para um resumo do chamado — use get_ticket.
+As imagens sao enviadas automaticamente apos sua resposta; nao tente descreve-las nem enviar links.
+Retorna: {id, titulo, descricao, imagens_enviadas, imagens_ignoradas}.`
+}
+func (t *GetTicketDescription) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"ticket_id": {Type: "integer", Description: "ID do chamado"},
+		},
+		Required: []string{"ticket_id"},
+	}
+}
+
+func (t *GetTicketDescription) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	ticketID, err := intArg(args, "ticket_id")
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, err := t.glpi.GetTicket(t.sessionToken, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar chamado: %w", err)
+	}
+	result := map[string]any{
+		"id":               ticket.ID,
+		"titulo":           ticket.Name,
+		"descricao":        htmlToPlainText(ticket.Content),
+		"imagens_enviadas": 0,
+	}
+
+	docIDs := inlineImageDocIDs(ticket.Content)
+	if len(docIDs) == 0 {
+		result["mensagem"] = "A descrição não tem imagens."
+		return result, nil
+	}
+
+	sent, skipped := 0, len(docIDs)-min(len(docIDs), maxInlineImages)
+	for i, docID := range docIDs[:min(len(docIDs), maxInlineImages)] {
+		data, mimeType, ext, ok := downloadImage(t.glpi, t.sessionToken, docID)
+		if !ok {
+			skipped++
+			continue
+		}
+		t.conv.AttachImage(ai.Image{
+			Data:     data,
+			MIMEType: mimeType,
+			Filename: fmt.Sprintf("chamado-%d-%d.%s", ticket.ID, i+1, ext),
+			Caption:  fmt.Sprintf("Chamado #%d — imagem %d", ticket.ID, i+1),
+		})
+		sent++
+	}
+	result["imagens_enviadas"] = sent
+	if skipped > 0 {
+		result["imagens_ignoradas"] = skipped
+		result["mensagem"] = fmt.Sprintf("%d imagem(ns) não puderam ser enviadas (limite de %d ou formato/tamanho não suportado); o usuário pode vê-las no Nexus.", skipped, maxInlineImages)
+	}
+	return result, nil
+}
+
+var _ ai.Tool = (*GetTicketDescription)(nil)
@@ -3,7 +3,11 @@ package tools
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/lojasmm/laia/internal/ai"
@@ -129,18 +133,115 @@ func (t *GetTicket) Execute(_ context.Context, args map[string]any) (map[string]
 	if err != nil {
 		return nil, fmt.Errorf("erro ao buscar chamado: %w", err)
 	}
+	return ticketDetailResult(ticket), nil
+}
 
+func ticketDetailResult(ticket *glpi.TicketDetail) map[string]any {
 	return map[string]any{
 		"id":            ticket.ID,
 		"titulo":        ticket.Name,
 		"descricao":     ticket.Content,
 		"status":        ticketStatusLabel(ticket.Status),
 		"urgencia":      urgencyLabel(ticket.Urgency),
+		"impacto":       impactLabel(ticket.Impact),
 		"prioridade":    priorityLabel(ticket.Priority),
 		"categoria":     ticket.ITILCategoriesID,
 		"criado_em":     ticket.DateCreated,
 		"atualizado_em": ticket.DateMod,
-	}, nil
+	}
+}
+
+// --- GetTicketsBatch ---
+
+// maxBatchTickets caps one get_tickets_batch call; GLPI serves each ID as a
+// separate request, so this also bounds the parallel load per user.
+const maxBatchTickets = 10
+
+// GetTicketsBatch fetches several tickets in one tool call, so the agent
+// doesn't loop over get_ticket and trip the repeated-call detection.
+type GetTicketsBatch struct {
+	glpi         *glpi.Client
+	sessionToken string
+}
+
+func NewGetTicketsBatch(g *glpi.Client, token string) *GetTicketsBatch {
+	return &GetTicketsBatch{glpi: g, sessionToken: token}
+}
+
+func (t *GetTicketsBatch) Name() string   { return "get_tickets_batch" }
+func (t *GetTicketsBatch) ReadOnly() bool { return true }
+func (t *GetTicketsBatch) Description() string {
+	return `Retorna os detalhes de varios chamados de uma vez (ate 10).
+Quando usar: quando o usuario quiser detalhes de mais de um chamado. Ex: "detalhes dos chamados 120, 121 e 130", "me mostra os detalhes de todos os meus abertos" (apos list_my_tickets).
+NAO usar: para um unico chamado — use get_ticket. NUNCA chame get_ticket repetidamente no lugar desta.
+Chamados que falharem aparecem em erros; os demais sao retornados normalmente.
+Retorna: {total, chamados: [mesmo formato de get_ticket], erros: [{id, erro}]}.`
+}
+func (t *GetTicketsBatch) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"ticket_ids": {
+				Type:        "array",
+				Description: "IDs dos chamados (máx 10)",
+				Items:       &ai.ParamSchema{Type: "integer"},
+			},
+		},
+		Required: []string{"ticket_ids"},
+	}
+}
+
+func (t *GetTicketsBatch) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	ids, err := intSliceArg(args, "ticket_ids")
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("informe ao menos um ticket_id")
+	}
+	if len(ids) > maxBatchTickets {
+		return nil, fmt.Errorf("máximo de %d chamados por vez (recebidos %d)", maxBatchTickets, len(ids))
+	}
+
+	tickets := make([]map[string]any, len(ids))
+	failures := make([]error, len(ids))
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i, id int) {
+			defer wg.Done()
+			ticket, err := t.glpi.GetTicket(t.sessionToken, id)
+			if err != nil {
+				failures[i] = err
+				return
+			}
+			tickets[i] = ticketDetailResult(ticket)
+		}(i, id)
+	}
+	wg.Wait()
+
+	// Keep the requested order in both lists.
+	found := make([]map[string]any, 0, len(ids))
+	errs := []map[string]any{}
+	var firstErr error
+	for i, id := range ids {
+		if failures[i] != nil {
+			errs = append(errs, map[string]any{"id": id, "erro": ai.ClassifyError(failures[i]).Message})
+			if firstErr == nil {
+				firstErr = failures[i]
+			}
+			continue
+		}
+		found = append(found, tickets[i])
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf("erro ao buscar chamados: %w", firstErr)
+	}
+	result := map[string]any{"total": len(found), "chamados": found}
+	if len(errs) > 0 {
+		result["erros"] = errs
+	}
+	return result, nil
 }
 
 // --- CreateTicket ---
@@ -148,6 +249,12 @@ func (t *GetTicket) Execute(_ context.Context, args map[string]any) (map[string]
 type CreateTicket struct {
 	glpi   *glpi.Client
 	userID int
+	// conv, when set, is condensed into a transcript appended to the description.
+	conv *ai.Conversation
+	// customFields, when set, enables the custom_fields parameter.
+	customFields []CustomField
+	// urgency.Default replaces a missing urgency argument.
+	urgency UrgencyPolicy
 }
 
 func NewCreateTicket(g *glpi.Client, userID int) *CreateTicket {
@@ -165,7 +272,7 @@ Requer: title, description, category_id (de get_department_categories) e departm
 Retorna: {id, mensagem} com o numero do chamado criado.`
 }
 func (t *CreateTicket) Parameters() *ai.ParamSchema {
-	return &ai.ParamSchema{
+	schema := &ai.ParamSchema{
 		Type: "object",
 		Properties: map[string]*ai.ParamSchema{
 			"title":         {Type: "string", Description: "Título do chamado"},
@@ -173,54 +280,120 @@ func (t *CreateTicket) Parameters() *ai.ParamSchema {
 			"category_id":   {Type: "integer", Description: "ID da categoria ITIL (obrigatório, obtido via get_department_categories)"},
 			"department_id": {Type: "integer", Description: "ID do departamento/formulário (obtido via get_departments)"},
 			"urgency":       {Type: "integer", Description: "Urgência: 1=Muito baixa, 2=Baixa, 3=Média, 4=Alta, 5=Muito alta"},
+			"impact":        {Type: "integer", Description: "Impacto (quantos são afetados): 1=Muito baixo (só o usuário), 2=Baixo, 3=Médio (setor), 4=Alto, 5=Muito alto (loja/empresa inteira)"},
+			"location_id":   {Type: "integer", Description: "Localização da loja (obtida via set_branch)"},
+			"error_log":     {Type: "string", Description: "Log/mensagem de erro colada pelo usuário, sem alterações (veja analyze_error_log)"},
+			"type":          {Type: "string", Description: "incidente (algo parou/quebrou) ou requisicao (pedido de acesso, compra, instalação). Omita se a categoria já define", Enum: ticketTypeParam},
 		},
 		Required: []string{"title", "description", "category_id", "department_id"},
 	}
+	if len(t.customFields) > 0 {
+		schema.Properties["custom_fields"] = customFieldsSchema(t.customFields)
+	}
+	return schema
 }
 
 func (t *CreateTicket) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	input, formID, err := t.buildInput(args)
+	if err != nil {
+		return nil, err
+	}
+
+	// Usa admin session pois usuários self-service não têm permissão
+	// para criar tickets diretamente via API (só via FormCreator na web).
+	adminSession, err := t.glpi.AdminSession(glpi.AdminCreateTicket)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar sessão admin: %w", err)
+	}
+	defer t.glpi.KillSession(adminSession)
+
+	typ, clarify := resolveTicketType(t.glpi, adminSession, input.ITILCategoriesID, input.Type)
+	if clarify != nil {
+		return clarify, nil
+	}
+	input.Type = typ
+
+	// Aplica as mesmas regras de actors do FormCreator (observadores, grupos atribuídos)
+	if formID > 0 {
+		applyFormActors(t.glpi, adminSession, formID, t.userID, &input)
+	}
+
+	id, err := t.glpi.CreateTicket(adminSession, input)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar chamado: %w", err)
+	}
+	result := map[string]any{"id": id, "mensagem": fmt.Sprintf("Chamado #%d criado com sucesso", id)}
+
+	// The description only carries the start of long logs (logDescription).
+	// The ticket already exists, so a failed upload is only reported.
+	if errorLog := strings.TrimSpace(optionalStringArg(args, "error_log")); len(errorLog) > maxInlineLogLen {
+		filename := fmt.Sprintf("log-chamado-%d.txt", id)
+		if _, err := t.glpi.UploadDocument(adminSession, "Log do erro", filename, []byte(errorLog), "Ticket", id); err != nil {
+			slog.Warn("tools: failed to attach error log", "ticket_id", id, "error", err)
+			result["aviso"] = "O log completo não pôde ser anexado; só o início dele está na descrição."
+		} else {
+			result["anexo"] = filename
+		}
+	}
+	return result, nil
+}
+
+// buildInput validates args and builds the ticket without the form's actors,
+// which need a session (see applyFormActors). preview_ticket goes through the
+// same path so the confirmation shows what create_ticket will send.
+func (t *CreateTicket) buildInput(args map[string]any) (glpi.CreateTicketInput, int, error) {
 	title, _ := stringArg(args, "title")
 	description, _ := stringArg(args, "description")
 	if title == "" || description == "" {
-		return nil, fmt.Errorf("título e descrição são obrigatórios")
+		return glpi.CreateTicketInput{}, 0, fmt.Errorf("título e descrição são obrigatórios")
 	}
 
 	catID, err := intArg(args, "category_id")
 	if err != nil || catID <= 0 {
-		return nil, fmt.Errorf("category_id é obrigatório — use get_department_categories para obter o ID")
+		return glpi.CreateTicketInput{}, 0, fmt.Errorf("category_id é obrigatório — use get_department_categories para obter o ID")
 	}
 
 	formID, _ := intArg(args, "department_id")
-
-	// Usa admin session pois usuários self-service não têm permissão
-	// para criar tickets diretamente via API (só via FormCreator na web).
-	adminSession, err := t.glpi.AdminSession()
+	typ, err := ticketTypeFromArg(optionalStringArg(args, "type"))
 	if err != nil {
-		return nil, fmt.Errorf("erro ao criar sessão admin: %w", err)
+		return glpi.CreateTicketInput{}, 0, err
+	}
+
+	var custom map[string]any
+	if len(t.customFields) > 0 {
+		if custom, err = mapCustomFields(t.customFields, args["custom_fields"]); err != nil {
+			return glpi.CreateTicketInput{}, 0, err
+		}
+	}
+
+	if errorLog := optionalStringArg(args, "error_log"); strings.TrimSpace(errorLog) != "" {
+		description = logDescription(description, errorLog)
+	}
+	if t.conv != nil {
+		if transcript := buildTranscript(t.conv.Turns()); transcript != "" {
+			description += "\n\n" + transcript
+		}
 	}
-	defer t.glpi.KillSession(adminSession)
 
 	input := glpi.CreateTicketInput{
 		Name:             title,
 		Content:          description,
-		Type:             1, // Incidente
+		Type:             typ, // resolved against the category once there's a session (resolveTicketType)
 		ITILCategoriesID: catID,
 		UsersIDRequester: t.userID,
+		CustomFields:     custom,
 	}
 	if urgency, err := intArg(args, "urgency"); err == nil && urgency >= 1 && urgency <= 5 {
 		input.Urgency = urgency
+	} else {
+		input.Urgency = t.urgency.Default
 	}
-
-	// Aplica as mesmas regras de actors do FormCreator (observadores, grupos atribuídos)
-	if formID > 0 {
-		applyFormActors(t.glpi, adminSession, formID, t.userID, &input)
+	// GLPI derives priority from urgency and impact; omitted impact keeps GLPI's default (3).
+	if impact, err := intArg(args, "impact"); err == nil && impact >= 1 && impact <= 5 {
+		input.Impact = impact
 	}
-
-	id, err := t.glpi.CreateTicket(adminSession, input)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao criar chamado: %w", err)
-	}
-	return map[string]any{"id": id, "mensagem": fmt.Sprintf("Chamado #%d criado com sucesso", id)}, nil
+	input.LocationsID = optionalIntArg(args, "location_id")
+	return input, formID, nil
 }
 
 // applyFormActors reads the FormCreator target ticket config and applies the
@@ -266,21 +439,23 @@ type UpdateTicket struct {
 	glpi         *glpi.Client
 	sessionToken string
 	userID       int
+	workflow     StatusWorkflow
 }
 
-func NewUpdateTicket(g *glpi.Client, token string, userID int) *UpdateTicket {
-	return &UpdateTicket{glpi: g, sessionToken: token, userID: userID}
+func NewUpdateTicket(g *glpi.Client, token string, userID int, workflow StatusWorkflow) *UpdateTicket {
+	return &UpdateTicket{glpi: g, sessionToken: token, userID: userID, workflow: workflow}
 }
 
 func (t *UpdateTicket) Name() string    { return "update_ticket" }
 func (t *UpdateTicket) ReadOnly() bool   { return false }
 func (t *UpdateTicket) Description() string {
 	return `Atualiza campos de um chamado existente.
-Quando usar: quando o usuario quiser alterar status, urgencia, titulo, descricao ou categoria de um chamado. Ex: "fechar chamado 123", "mudar urgencia do chamado 456 para alta".
+Quando usar: quando o usuario quiser alterar status, urgencia, impacto, titulo, descricao, categoria ou tipo (incidente/requisicao) de um chamado. Ex: "fechar chamado 123", "mudar urgencia do chamado 456 para alta".
 SEMPRE confirme a alteracao com o usuario via respond_interactive antes de executar.
 O usuario precisa ter permissao de edicao no GLPI para o chamado.
 Passe apenas os campos que deseja alterar — campos omitidos nao serao modificados.
-Retorna: {mensagem, alteracoes: [lista de campos alterados]}.`
+Nem toda mudanca de status e permitida (ex: chamado novo nao pode ser fechado direto; fechado e definitivo). Se a mudanca for recusada, nada e alterado: explique ao usuario e ofereca os status em 'status_permitidos'.
+Retorna: {mensagem, alteracoes: [lista de campos alterados]} ou {atualizado: false, mensagem, status_atual, status_permitidos}.`
 }
 func (t *UpdateTicket) Parameters() *ai.ParamSchema {
 	return &ai.ParamSchema{
@@ -289,9 +464,11 @@ func (t *UpdateTicket) Parameters() *ai.ParamSchema {
 			"ticket_id":   {Type: "integer", Description: "ID do chamado"},
 			"status":      {Type: "integer", Description: "Novo status: 1=Novo, 2=Atribuído, 3=Planejado, 4=Pendente, 5=Solucionado, 6=Fechado"},
 			"urgency":     {Type: "integer", Description: "Urgência: 1=Muito baixa, 2=Baixa, 3=Média, 4=Alta, 5=Muito alta"},
+			"impact":      {Type: "integer", Description: "Impacto: 1=Muito baixo, 2=Baixo, 3=Médio, 4=Alto, 5=Muito alto"},
 			"title":       {Type: "string", Description: "Novo título do chamado"},
 			"description": {Type: "string", Description: "Nova descrição do chamado"},
 			"category_id": {Type: "integer", Description: "Nova categoria ITIL"},
+			"type":        {Type: "string", Description: "Novo tipo: incidente ou requisicao", Enum: ticketTypeParam},
 		},
 		Required: []string{"ticket_id"},
 	}
@@ -314,6 +491,10 @@ func (t *UpdateTicket) Execute(_ context.Context, args map[string]any) (map[stri
 		input.Urgency = u
 		changes = append(changes, "urgência → "+urgencyLabel(u))
 	}
+	if i, err := intArg(args, "impact"); err == nil && i >= 1 && i <= 5 {
+		input.Impact = i
+		changes = append(changes, "impacto → "+impactLabel(i))
+	}
 	if title, _ := args["title"].(string); title != "" {
 		input.Name = title
 		changes = append(changes, "título")
@@ -326,13 +507,61 @@ func (t *UpdateTicket) Execute(_ context.Context, args map[string]any) (map[stri
 		input.ITILCategoriesID = catID
 		changes = append(changes, "categoria")
 	}
+	typ, err := ticketTypeFromArg(optionalStringArg(args, "type"))
+	if err != nil {
+		return nil, err
+	}
+	if typ != 0 {
+		input.Type = typ
+		changes = append(changes, "tipo → "+ticketTypeLabel(typ))
+	}
 
 	if len(changes) == 0 {
 		return nil, fmt.Errorf("nenhum campo para atualizar")
 	}
 
-	err = t.glpi.UpdateTicket(t.sessionToken, ticketID, input)
+	if input.Status == 0 && input.ITILCategoriesID == 0 && input.Type == 0 {
+		return t.update(ticketID, input, changes)
+	}
+	ticket, err := t.glpi.GetTicket(t.sessionToken, ticketID)
 	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar chamado: %w", err)
+	}
+	if input.Status != 0 {
+		if refused := t.workflow.refusal(ticketID, ticket.Status, input.Status); refused != nil {
+			refused["atualizado"] = false
+			return refused, nil
+		}
+	}
+	if input.ITILCategoriesID != 0 || input.Type != 0 {
+		// The category or the type changes: check the pair the ticket ends up with.
+		categoryID, typ := input.ITILCategoriesID, input.Type
+		if categoryID == 0 {
+			if categoryID, err = t.glpi.GetTicketCategoryID(t.sessionToken, ticketID); err != nil {
+				return nil, fmt.Errorf("erro ao buscar categoria do chamado: %w", err)
+			}
+		}
+		if typ == 0 {
+			typ = ticket.Type
+		}
+		if categoryID > 0 {
+			// Categories are reference data self-service profiles may not read.
+			adminSession, err := t.glpi.AdminSession(glpi.AdminReadReference)
+			if err != nil {
+				return nil, fmt.Errorf("erro ao criar sessão admin: %w", err)
+			}
+			_, clarify := resolveTicketType(t.glpi, adminSession, categoryID, typ)
+			t.glpi.KillSession(adminSession)
+			if clarify != nil {
+				return clarify, nil
+			}
+		}
+	}
+	return t.update(ticketID, input, changes)
+}
+
+func (t *UpdateTicket) update(ticketID int, input glpi.UpdateTicketInput, changes []string) (map[string]any, error) {
+	if err := t.glpi.UpdateTicket(t.sessionToken, ticketID, input); err != nil {
 		return nil, fmt.Errorf("erro ao atualizar chamado: %w", err)
 	}
 	return map[string]any{
@@ -361,7 +590,8 @@ NAO usar: para listar apenas "meus chamados" sem filtros — use list_my_tickets
 O campo 'query' busca por substring no titulo E descricao simultaneamente (busca com AND entre criterios).
 Se nenhum criterio for informado, pedira esclarecimento ao usuario.
 Resultados limitados a 10 itens. Se houver mais, informe o total e sugira ao usuario refinar a busca.
-Retorna: {total, chamados: [{id, titulo, status, data_abertura, data_fechamento, urgencia, prioridade, categoria, tecnico, solicitante}]}.`
+Se 'query' nao encontrar nada, retorna need_clarification oferecendo list_my_tickets.
+Retorna: {total, chamados: [{id, titulo, status, data_abertura, data_fechamento, urgencia, impacto, prioridade, categoria, tecnico, solicitante}]}.`
 }
 func (t *SearchTicketsAdvanced) Parameters() *ai.ParamSchema {
 	return &ai.ParamSchema{
@@ -405,115 +635,613 @@ func (t *SearchTicketsAdvanced) Execute(_ context.Context, args map[string]any)
 	assignedTo := optionalStringArg(args, "assigned_to")
 	requester := optionalStringArg(args, "requester")
 
-	if query == "" && status == "" && period == "" && urgency == "" && assignedTo == "" && requester == "" {
-		return clarification(
-			"O que voce gostaria de buscar? Informe pelo menos um criterio.",
-			[]string{"texto (ex: VPN)", "status (aberto/pendente)", "periodo (hoje/semana/mes)", "urgencia", "tecnico atribuido"},
-			"Use search_tickets_advanced com pelo menos um parametro preenchido.",
-		), nil
+	if query == "" && status == "" && period == "" && urgency == "" && assignedTo == "" && requester == "" {
+		return clarification(
+			"O que voce gostaria de buscar? Informe pelo menos um criterio.",
+			[]string{"texto (ex: VPN)", "status (aberto/pendente)", "periodo (hoje/semana/mes)", "urgencia", "tecnico atribuido"},
+			"Use search_tickets_advanced com pelo menos um parametro preenchido.",
+		), nil
+	}
+
+	criteria := newSearchCriteria()
+	criteria.addText(query)
+	if status != "" && status != "todos" {
+		criteria.addStatuses(mapStatusToGLPI(status))
+	}
+	criteria.addPeriod(period)
+
+	if urgency != "" {
+		code := mapUrgencyToGLPI(urgency)
+		if code > 0 {
+			criteria.add("10", "equals", fmt.Sprintf("%d", code))
+		}
+	}
+
+	if assignedTo != "" {
+		criteria.add("5", "contains", assignedTo)
+	}
+	if requester != "" {
+		criteria.add("4", "contains", requester)
+	}
+
+	result, err := t.glpi.AdvancedSearchTickets(t.sessionToken, criteria.params)
+	if err != nil {
+		return nil, fmt.Errorf("erro na busca: %w", err)
+	}
+
+	// A typo in the query often yields zero rows, which users read as "I have
+	// no tickets". Offer the unfiltered list instead of an empty answer.
+	if result.TotalCount == 0 && query != "" {
+		return clarification(
+			fmt.Sprintf("Nenhum chamado com '%s' — quer ver todos os seus chamados?", query),
+			[]string{"Ver meus chamados", "Buscar outro termo"},
+			"Se o usuario aceitar, use list_my_tickets. Se quiser outro termo, repita search_tickets_advanced.",
+		), nil
+	}
+
+	// GLPI search field IDs:
+	// 1=Title, 2=ID, 3=Priority, 4=Requester, 5=Technician, 7=Category,
+	// 10=Urgency, 11=Impact, 12=Status, 15=Open date, 16=Close date, 21=Content
+	items := make([]map[string]any, len(result.Data))
+	for i, d := range result.Data {
+		items[i] = map[string]any{
+			"id":              d["2"],
+			"titulo":          d["1"],
+			"status":          searchLabel(d["12"], ticketStatusLabel),
+			"data_abertura":   d["15"],
+			"data_fechamento": d["16"],
+			"urgencia":        searchLabel(d["10"], urgencyLabel),
+			"impacto":         searchLabel(d["11"], impactLabel),
+			"prioridade":      searchLabel(d["3"], priorityLabel),
+			"categoria":       d["7"],
+			"tecnico":         d["5"],
+			"solicitante":     d["4"],
+		}
+	}
+	return map[string]any{"total": result.TotalCount, "chamados": items}, nil
+}
+
+// --- TicketCountByPeriod ---
+
+type TicketCountByPeriod struct {
+	glpi         *glpi.Client
+	sessionToken string
+}
+
+func NewTicketCountByPeriod(g *glpi.Client, token string) *TicketCountByPeriod {
+	return &TicketCountByPeriod{glpi: g, sessionToken: token}
+}
+
+func (t *TicketCountByPeriod) Name() string   { return "count_tickets_by_period" }
+func (t *TicketCountByPeriod) ReadOnly() bool { return true }
+func (t *TicketCountByPeriod) Description() string {
+	return `Conta chamados abertos em um periodo, opcionalmente por status, sem listar os chamados.
+Quando usar: perguntas de quantidade. Ex: "quantos chamados foram abertos essa semana?", "quantos chamados pendentes no mes?", "resumo por status do ano".
+NAO usar: quando o usuario quiser ver os chamados — use search_tickets_advanced.
+Retorna: {periodo, status, total} ou, com group_by_status, {periodo, total, por_status: [{status, total}]}.`
+}
+func (t *TicketCountByPeriod) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"period": {
+				Type:        "string",
+				Description: "Periodo de abertura: hoje, semana, mes, ano, ou intervalo YYYY-MM-DD..YYYY-MM-DD",
+			},
+			"status": {
+				Type:        "string",
+				Description: "Contar apenas este status: aberto (novo+atribuido+planejado), pendente, solucionado, fechado, todos",
+				Enum:        []string{"aberto", "pendente", "solucionado", "fechado", "todos"},
+			},
+			"group_by_status": {
+				Type:        "boolean",
+				Description: "Se true, retorna a contagem separada por status",
+			},
+		},
+		Required: []string{"period"},
+	}
+}
+
+func (t *TicketCountByPeriod) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	period, err := stringArg(args, "period")
+	if err != nil {
+		return nil, err
+	}
+	dateFrom, dateTo := parsePeriod(period)
+	if dateFrom == "" {
+		return clarification(
+			"De qual periodo voce quer a contagem?",
+			[]string{"hoje", "semana", "mes", "ano"},
+			"Use count_tickets_by_period com period=hoje|semana|mes|ano ou YYYY-MM-DD..YYYY-MM-DD.",
+		), nil
+	}
+	status := optionalStringArg(args, "status")
+	groupByStatus, _ := args["group_by_status"].(bool)
+
+	if !groupByStatus {
+		var codes []int
+		if status != "" && status != "todos" {
+			codes = mapStatusToGLPI(status)
+		}
+		total, err := t.count(dateFrom, dateTo, codes)
+		if err != nil {
+			return nil, err
+		}
+		if status == "" {
+			status = "todos"
+		}
+		return map[string]any{"periodo": period, "status": status, "total": total}, nil
+	}
+
+	// One range=0-0 query per status; GLPI has no GROUP BY in the search API.
+	codes := []int{1, 2, 3, 4, 5, 6}
+	if status != "" && status != "todos" {
+		codes = mapStatusToGLPI(status)
+	}
+	counts := make([]int, len(codes))
+	errs := make([]error, len(codes))
+	var wg sync.WaitGroup
+	for i, code := range codes {
+		wg.Add(1)
+		go func(i, code int) {
+			defer wg.Done()
+			counts[i], errs[i] = t.count(dateFrom, dateTo, []int{code})
+		}(i, code)
+	}
+	wg.Wait()
+
+	total := 0
+	byStatus := make([]map[string]any, 0, len(codes))
+	for i, code := range codes {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		total += counts[i]
+		byStatus = append(byStatus, map[string]any{"status": ticketStatusLabel(code), "total": counts[i]})
+	}
+	return map[string]any{"periodo": period, "total": total, "por_status": byStatus}, nil
+}
+
+// count asks GLPI for a single row so only totalcount is transferred.
+func (t *TicketCountByPeriod) count(dateFrom, dateTo string, statusCodes []int) (int, error) {
+	result, err := t.glpi.AdvancedSearchTickets(t.sessionToken, periodCountCriteria(dateFrom, dateTo, statusCodes))
+	if err != nil {
+		return 0, fmt.Errorf("erro ao contar chamados: %w", err)
+	}
+	return result.TotalCount, nil
+}
+
+// periodCountCriteria filters by opening date (see addPeriodCriteria) and, when
+// given, an OR group of status codes (field 12).
+func periodCountCriteria(dateFrom, dateTo string, statusCodes []int) map[string]string {
+	criteria := map[string]string{"range": "0-0"}
+	idx := addPeriodCriteria(criteria, 0, dateFrom, dateTo)
+	if len(statusCodes) > 0 {
+		criteria[fmt.Sprintf("criteria[%d][link]", idx)] = "AND"
+		for j, code := range statusCodes {
+			prefix := fmt.Sprintf("criteria[%d][criteria][%d]", idx, j)
+			if j > 0 {
+				criteria[prefix+"[link]"] = "OR"
+			}
+			criteria[prefix+"[field]"] = "12"
+			criteria[prefix+"[searchtype]"] = "equals"
+			criteria[prefix+"[value]"] = fmt.Sprintf("%d", code)
+		}
+	}
+	return criteria
+}
+
+// addPeriodCriteria filters criteria by opening date (field 15) from index idx
+// and returns the next free index. An open range ("2025-01-01..") has no upper
+// bound; a bare end date is extended to the end of that day, otherwise
+// "lessthan" would leave out tickets opened on it.
+func addPeriodCriteria(criteria map[string]string, idx int, dateFrom, dateTo string) int {
+	criterion := func(searchtype, value string) {
+		if idx > 0 {
+			criteria[fmt.Sprintf("criteria[%d][link]", idx)] = "AND"
+		}
+		criteria[fmt.Sprintf("criteria[%d][field]", idx)] = "15"
+		criteria[fmt.Sprintf("criteria[%d][searchtype]", idx)] = searchtype
+		criteria[fmt.Sprintf("criteria[%d][value]", idx)] = value
+		idx++
+	}
+	criterion("morethan", dateFrom)
+	if dateTo != "" {
+		if len(dateTo) == len("2006-01-02") {
+			dateTo += " 23:59:59"
+		}
+		criterion("lessthan", dateTo)
+	}
+	return idx
+}
+
+// --- MyTicketsByCategory ---
+
+const (
+	// maxCategoryStatsTickets bounds the search; older tickets beyond it don't
+	// change which categories come out on top.
+	maxCategoryStatsTickets = 200
+	topCategories           = 5
+	// Below this many tickets a ranking says nothing useful.
+	minCategoryStatsTickets = 3
+)
+
+type MyTicketsByCategory struct {
+	glpi         *glpi.Client
+	sessionToken string
+	userID       int
+}
+
+func NewMyTicketsByCategory(g *glpi.Client, token string, userID int) *MyTicketsByCategory {
+	return &MyTicketsByCategory{glpi: g, sessionToken: token, userID: userID}
+}
+
+func (t *MyTicketsByCategory) Name() string   { return "my_tickets_by_category" }
+func (t *MyTicketsByCategory) ReadOnly() bool { return true }
+func (t *MyTicketsByCategory) Description() string {
+	return `Conta os chamados abertos pelo usuario agrupados por categoria, com as categorias mais frequentes primeiro.
+Quando usar: "que tipos de problema eu mais abro?", "quais categorias eu mais uso?".
+NAO usar: para listar os chamados — use list_my_tickets.
+Retorna: {total, categorias: [{categoria, total, percentual}]} com as 5 principais, ou {total, mensagem} se houver poucos chamados.`
+}
+func (t *MyTicketsByCategory) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"period": {
+				Type:        "string",
+				Description: "Periodo de abertura: hoje, semana, mes, ano, ou intervalo YYYY-MM-DD..YYYY-MM-DD. Default: todos",
+			},
+		},
+	}
+}
+
+func (t *MyTicketsByCategory) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	criteria := actorTicketsCriteria("4", t.userID, "todos")
+	criteria["range"] = fmt.Sprintf("0-%d", maxCategoryStatsTickets-1)
+	if period := optionalStringArg(args, "period"); period != "" {
+		if dateFrom, dateTo := parsePeriod(period); dateFrom != "" {
+			// Status "todos" leaves criteria[0] as the only one.
+			addPeriodCriteria(criteria, 1, dateFrom, dateTo)
+		}
+	}
+
+	result, err := t.glpi.AdvancedSearchTickets(t.sessionToken, criteria)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar chamados: %w", err)
+	}
+
+	if len(result.Data) < minCategoryStatsTickets {
+		return map[string]any{
+			"total":    len(result.Data),
+			"mensagem": "Poucos chamados para identificar um padrão de categorias.",
+		}, nil
+	}
+
+	top := groupByCategory(result.Data)
+	if len(top) > topCategories {
+		top = top[:topCategories]
+	}
+	items := make([]map[string]any, len(top))
+	for i, c := range top {
+		items[i] = map[string]any{
+			"categoria":  c.name,
+			"total":      c.count,
+			"percentual": c.count * 100 / len(result.Data),
+		}
+	}
+	out := map[string]any{"total": len(result.Data), "categorias": items}
+	if result.TotalCount > len(result.Data) {
+		out["nota"] = fmt.Sprintf("Considerados os %d chamados mais recentes de %d.", len(result.Data), result.TotalCount)
+	}
+	return out, nil
+}
+
+type categoryCount struct {
+	name  string
+	count int
+}
+
+// groupByCategory counts search rows by category (field 7, which the search
+// API already returns as the full category name), most frequent first.
+func groupByCategory(rows []glpi.SearchResultItem) []categoryCount {
+	counts := map[string]int{}
+	for _, d := range rows {
+		name, _ := d["7"].(string)
+		if name == "" {
+			name = "Sem categoria"
+		}
+		counts[name]++
+	}
+	out := make([]categoryCount, 0, len(counts))
+	for name, n := range counts {
+		out = append(out, categoryCount{name: name, count: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].count != out[j].count {
+			return out[i].count > out[j].count
+		}
+		return out[i].name < out[j].name
+	})
+	return out
+}
+
+// --- MyAssignedTickets ---
+
+// maxAssignedTickets is how much of a technician's queue is fetched; the
+// client-side sort then orders it by priority, urgency and age.
+const maxAssignedTickets = 200
+
+type MyAssignedTickets struct {
+	technicianOnly
+	glpi         *glpi.Client
+	sessionToken string
+	userID       int
+}
+
+func NewMyAssignedTickets(g *glpi.Client, token string, userID int) *MyAssignedTickets {
+	return &MyAssignedTickets{glpi: g, sessionToken: token, userID: userID}
+}
+
+func (t *MyAssignedTickets) Name() string   { return "list_my_assigned_tickets" }
+func (t *MyAssignedTickets) ReadOnly() bool { return true }
+func (t *MyAssignedTickets) Description() string {
+	return `Lista a fila de chamados atribuidos ao usuario atual como TECNICO.
+Quando usar: quando um tecnico pedir sua fila de atendimento. Ex: "chamados atribuidos a mim", "minha fila", "o que tenho pra atender".
+NAO usar: para chamados que o usuario abriu/solicitou — use list_my_tickets.
+Por padrao traz apenas chamados em aberto (novo, atribuido, planejado, pendente); use status="todos" para incluir solucionados e fechados.
+Ordenado por prioridade e urgencia (maior primeiro) e depois pelo mais antigo.
+Retorna: {total, chamados: [{id, titulo, status, prioridade, urgencia, data_abertura, solicitante}]}.`
+}
+func (t *MyAssignedTickets) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"status": {
+				Type:        "string",
+				Description: "Filtrar por status: aberto, pendente, solucionado, fechado, todos. Default: aberto + pendente",
+				Enum:        []string{"aberto", "pendente", "solucionado", "fechado", "todos"},
+			},
+		},
+	}
+}
+
+func (t *MyAssignedTickets) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	status := optionalStringArg(args, "status")
+
+	criteria := actorTicketsCriteria("5", t.userID, status)
+	criteria["range"] = fmt.Sprintf("0-%d", maxAssignedTickets-1)
+	result, err := t.glpi.AdvancedSearchTickets(t.sessionToken, criteria)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar chamados atribuídos: %w", err)
+	}
+
+	if len(result.Data) == 0 {
+		return map[string]any{
+			"total":    0,
+			"chamados": []map[string]any{},
+			"mensagem": "Nenhum chamado atribuído a você no momento.",
+		}, nil
+	}
+
+	sortByPriorityThenAge(result.Data)
+
+	items := make([]map[string]any, len(result.Data))
+	for i, d := range result.Data {
+		items[i] = map[string]any{
+			"id":            d["2"],
+			"titulo":        d["1"],
+			"status":        searchLabel(d["12"], ticketStatusLabel),
+			"prioridade":    searchLabel(d["3"], priorityLabel),
+			"urgencia":      searchLabel(d["10"], urgencyLabel),
+			"data_abertura": d["15"],
+			"solicitante":   d["4"],
+		}
+	}
+	return map[string]any{"total": result.TotalCount, "chamados": items}, nil
+}
+
+// actorTicketsCriteria builds the search for tickets where userID is the actor
+// in field (4=requester, 5=assigned technician). An empty status means
+// everything not yet solved. Results come highest priority first, so when
+// there are more than the range holds the ones cut are the least urgent.
+func actorTicketsCriteria(field string, userID int, status string) map[string]string {
+	criteria := map[string]string{
+		"criteria[0][field]":      field,
+		"criteria[0][searchtype]": "equals",
+		"criteria[0][value]":      fmt.Sprintf("%d", userID),
+		"sort":                    "3", // priority
+		"order":                   "DESC",
+		"range":                   "0-49",
+	}
+
+	var codes []int
+	switch status {
+	case "todos":
+	case "":
+		codes = []int{1, 2, 3, 4}
+	default:
+		codes = mapStatusToGLPI(status)
+	}
+	if len(codes) > 0 {
+		criteria["criteria[1][link]"] = "AND"
+		for j, c := range codes {
+			prefix := fmt.Sprintf("criteria[1][criteria][%d]", j)
+			if j > 0 {
+				criteria[prefix+"[link]"] = "OR"
+			}
+			criteria[prefix+"[field]"] = "12"
+			criteria[prefix+"[searchtype]"] = "equals"
+			criteria[prefix+"[value]"] = fmt.Sprintf("%d", c)
+		}
+	}
+	return criteria
+}
+
+// sortByPriorityThenAge orders search rows by priority (3) and urgency (10)
+// descending, then by opening date (15) ascending so older tickets come first.
+func sortByPriorityThenAge(rows []glpi.SearchResultItem) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		pi, pj := searchInt(rows[i]["3"]), searchInt(rows[j]["3"])
+		if pi != pj {
+			return pi > pj
+		}
+		ui, uj := searchInt(rows[i]["10"]), searchInt(rows[j]["10"])
+		if ui != uj {
+			return ui > uj
+		}
+		di, _ := rows[i]["15"].(string)
+		dj, _ := rows[j]["15"].(string)
+		return di < dj
+	})
+}
+
+// --- ColleagueTickets ---
+
+// ColleagueTickets lets team leads follow a colleague's tickets. Access is
+// decided by the GLPI profile, not by us: self-service profiles never get the
+// tool, and GLPI still filters results by what the profile can see.
+type ColleagueTickets struct {
+	colleagueAccess
+	glpi         *glpi.Client
+	sessionToken string
+}
+
+func NewColleagueTickets(g *glpi.Client, token string) *ColleagueTickets {
+	return &ColleagueTickets{glpi: g, sessionToken: token}
+}
+
+func (t *ColleagueTickets) Name() string   { return "list_colleague_tickets" }
+func (t *ColleagueTickets) ReadOnly() bool { return true }
+func (t *ColleagueTickets) Description() string {
+	return `Lista os chamados abertos por um colega (para gestores/lideres de equipe).
+Quando usar: quando o usuario perguntar sobre chamados de outra pessoa. Ex: "como esta o chamado do Joao?", "chamados da Maria".
+Se varios colegas corresponderem ao nome, retorna opcoes com o ID de cada um: mostre-as com respond_interactive e chame novamente com o user_id escolhido.
+Retorna: {colega, total, chamados: [{id, titulo, status, prioridade, data_abertura, tecnico}]}.`
+}
+func (t *ColleagueTickets) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"colleague": {Type: "string", Description: "Nome (ou parte) do colega. Ex: 'João', 'Maria Souza'"},
+			"user_id":   {Type: "integer", Description: "ID do colega, vindo das opcoes de uma chamada anterior. Tem precedencia sobre colleague"},
+			"status": {
+				Type:        "string",
+				Description: "Filtrar por status. Default: chamados ainda nao solucionados",
+				Enum:        []string{"aberto", "pendente", "solucionado", "fechado", "todos"},
+			},
+		},
+	}
+}
+
+func (t *ColleagueTickets) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	colleague, clarify, err := t.resolveColleague(args)
+	if err != nil || clarify != nil {
+		return clarify, err
 	}
 
-	// GLPI search uses criteria groups: top-level criteria linked with AND,
-	// sub-criteria inside a group linked with OR.
-	// Reference: nexus_apirest.md — criteria[N][criteria][M] for sub-groups.
-	criteria := map[string]string{}
-	idx := 0
+	result, err := t.glpi.AdvancedSearchTickets(t.sessionToken, actorTicketsCriteria("4", colleague.ID, optionalStringArg(args, "status")))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar chamados do colega: %w", err)
+	}
 
-	// addTopCriteria adds a single top-level AND criterion.
-	addTopCriteria := func(field, searchType, value string) {
-		if idx > 0 {
-			criteria[fmt.Sprintf("criteria[%d][link]", idx)] = "AND"
+	items := make([]map[string]any, len(result.Data))
+	for i, d := range result.Data {
+		items[i] = map[string]any{
+			"id":            d["2"],
+			"titulo":        d["1"],
+			"status":        searchLabel(d["12"], ticketStatusLabel),
+			"prioridade":    searchLabel(d["3"], priorityLabel),
+			"data_abertura": d["15"],
+			"tecnico":       d["5"],
 		}
-		criteria[fmt.Sprintf("criteria[%d][field]", idx)] = field
-		criteria[fmt.Sprintf("criteria[%d][searchtype]", idx)] = searchType
-		criteria[fmt.Sprintf("criteria[%d][value]", idx)] = value
-		idx++
 	}
+	return map[string]any{"colega": userDisplayName(colleague), "total": result.TotalCount, "chamados": items}, nil
+}
 
-	// addORGroup adds a top-level AND group with multiple OR sub-criteria.
-	addORGroup := func(field, searchType string, values []string) {
-		if idx > 0 {
-			criteria[fmt.Sprintf("criteria[%d][link]", idx)] = "AND"
-		}
-		for j, v := range values {
-			prefix := fmt.Sprintf("criteria[%d][criteria][%d]", idx, j)
-			if j > 0 {
-				criteria[prefix+"[link]"] = "OR"
-			}
-			criteria[prefix+"[field]"] = field
-			criteria[prefix+"[searchtype]"] = searchType
-			criteria[prefix+"[value]"] = v
+// resolveColleague picks the colleague by user_id or by name. SearchUsers
+// matches login, first and last name separately, so homonyms (and full
+// names) can't be told apart by name; the options carry the ID instead.
+func (t *ColleagueTickets) resolveColleague(args map[string]any) (glpi.UserSummary, map[string]any, error) {
+	if id := optionalIntArg(args, "user_id"); id > 0 {
+		u, err := t.glpi.GetUser(t.sessionToken, id)
+		if err != nil {
+			return glpi.UserSummary{}, nil, fmt.Errorf("erro ao buscar colega: %w", err)
 		}
-		idx++
+		return glpi.UserSummary{ID: u.ID, Login: u.Name, FirstName: u.FirstName, RealName: u.RealName}, nil, nil
 	}
 
-	// query: title OR content (sub-group)
-	if query != "" {
-		addORGroup("1", "contains", []string{query, query})
-		// Override second sub-criterion to search field 21 (content)
-		prefix := fmt.Sprintf("criteria[%d][criteria][1]", idx-1)
-		criteria[prefix+"[field]"] = "21"
+	name, _ := stringArg(args, "colleague")
+	if strings.TrimSpace(name) == "" {
+		return glpi.UserSummary{}, nil, fmt.Errorf("informe colleague ou user_id")
 	}
-
-	// status: "aberto" = 1 OR 2 OR 3 (sub-group)
-	if status != "" && status != "todos" {
-		statusCodes := mapStatusToGLPI(status)
-		vals := make([]string, len(statusCodes))
-		for i, c := range statusCodes {
-			vals[i] = fmt.Sprintf("%d", c)
-		}
-		addORGroup("12", "equals", vals)
+	users, err := t.searchColleague(name)
+	if err != nil {
+		return glpi.UserSummary{}, nil, fmt.Errorf("erro ao buscar colega: %w", err)
 	}
-
-	// period: date range with AND
-	if period != "" {
-		dateFrom, dateTo := parsePeriod(period)
-		if dateFrom != "" {
-			addTopCriteria("15", "morethan", dateFrom)
-		}
-		if dateTo != "" {
-			addTopCriteria("15", "lessthan", dateTo)
-		}
+	switch len(users) {
+	case 0:
+		return glpi.UserSummary{}, map[string]any{"total": 0, "mensagem": fmt.Sprintf("Não encontrei nenhum colega com o nome %q.", name)}, nil
+	case 1:
+		return users[0], nil, nil
 	}
-
-	if urgency != "" {
-		code := mapUrgencyToGLPI(urgency)
-		if code > 0 {
-			addTopCriteria("10", "equals", fmt.Sprintf("%d", code))
-		}
+	options := make([]string, len(users))
+	for i, u := range users {
+		options[i] = fmt.Sprintf("%s (%s) — user_id %d", userDisplayName(u), u.Login, u.ID)
 	}
+	return glpi.UserSummary{}, clarification("Qual destes colegas?", options,
+		"Mostre as opções com respond_interactive e chame novamente com o user_id escolhido."), nil
+}
 
-	if assignedTo != "" {
-		addTopCriteria("5", "contains", assignedTo)
+// searchColleague searches users by name. A full name ("Maria Souza") is in
+// no single field, so it is searched by its longest word and narrowed to the
+// users whose name has every word.
+func (t *ColleagueTickets) searchColleague(name string) ([]glpi.UserSummary, error) {
+	words := strings.Fields(strings.ToLower(name))
+	if len(words) < 2 {
+		return t.glpi.SearchUsers(t.sessionToken, name)
 	}
-	if requester != "" {
-		addTopCriteria("4", "contains", requester)
+	longest := slices.MaxFunc(words, func(a, b string) int { return len(a) - len(b) })
+	users, err := t.glpi.SearchUsers(t.sessionToken, longest)
+	if err != nil {
+		return nil, err
 	}
+	return slices.DeleteFunc(users, func(u glpi.UserSummary) bool {
+		full := strings.ToLower(userDisplayName(u) + " " + u.Login)
+		for _, w := range words {
+			if !strings.Contains(full, w) {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
 
-	result, err := t.glpi.AdvancedSearchTickets(t.sessionToken, criteria)
-	if err != nil {
-		return nil, fmt.Errorf("erro na busca: %w", err)
+// colleagueAccess restricts a tool to profiles with "see all" or "see group"
+// ticket rights (ai.ProfileRestricted). Self-service (helpdesk) profiles
+// never qualify.
+type colleagueAccess struct{}
+
+func (colleagueAccess) AllowsProfile(p glpi.ActiveProfile) bool {
+	if p.Interface == "helpdesk" {
+		return false
 	}
+	return p.TicketRight&(glpi.TicketReadAll|glpi.TicketReadGroup) != 0
+}
 
-	// GLPI search field IDs:
-	// 1=Title, 2=ID, 3=Priority, 4=Requester, 5=Technician,
-	// 7=Category, 10=Urgency, 12=Status, 15=Open date, 16=Close date, 21=Content
-	items := make([]map[string]any, len(result.Data))
-	for i, d := range result.Data {
-		items[i] = map[string]any{
-			"id":              d["2"],
-			"titulo":          d["1"],
-			"status":          d["12"],
-			"data_abertura":   d["15"],
-			"data_fechamento": d["16"],
-			"urgencia":        d["10"],
-			"prioridade":      d["3"],
-			"categoria":       d["7"],
-			"tecnico":         d["5"],
-			"solicitante":     d["4"],
-		}
+// technicianOnly restricts a tool to central-interface profiles (ai.ProfileRestricted);
+// self-service users are never assigned tickets or tasks in GLPI.
+type technicianOnly struct{}
+
+func (technicianOnly) AllowsProfile(p glpi.ActiveProfile) bool {
+	return p.Interface != "helpdesk"
+}
+
+func userDisplayName(u glpi.UserSummary) string {
+	if name := strings.TrimSpace(u.FirstName + " " + u.RealName); name != "" {
+		return name
 	}
-	return map[string]any{"total": result.TotalCount, "chamados": items}, nil
+	return u.Login
 }
 
 // --- GetTicketTasks ---
@@ -533,7 +1261,8 @@ func (t *GetTicketTasks) ReadOnly() bool { return true }
 func (t *GetTicketTasks) Description() string {
 	return `Lista as tarefas/atividades de um chamado.
 Quando usar: quando o usuario quiser ver as tarefas de um chamado especifico. Ex: "tarefas do chamado 123", "o que precisa ser feito no chamado 456".
-Retorna: {total, tarefas: [{id, conteudo, estado, progresso, data}]}.
+Retorna: {total, tarefas: [{id, conteudo, estado, progresso, data, inicio_planejado, fim_planejado}]}; inicio/fim_planejado so aparecem em tarefas agendadas.
+Para "quando o tecnico vem?", prefira next_intervention.
 Estados possiveis: "A fazer" (1), "Em andamento" (2), "Feito" (3).
 Progresso e um percentual de 0 a 100.`
 }
@@ -567,6 +1296,10 @@ func (t *GetTicketTasks) Execute(_ context.Context, args map[string]any) (map[st
 			"progresso": task.PercentDone,
 			"data":     task.DateCreated,
 		}
+		if task.Begin != "" {
+			items[i]["inicio_planejado"] = task.Begin
+			items[i]["fim_planejado"] = task.End
+		}
 	}
 	return map[string]any{"total": len(tasks), "tarefas": items}, nil
 }
@@ -574,6 +1307,7 @@ func (t *GetTicketTasks) Execute(_ context.Context, args map[string]any) (map[st
 // --- AddTicketTask ---
 
 type AddTicketTask struct {
+	technicianOnly
 	glpi         *glpi.Client
 	sessionToken string
 	userID       int
@@ -701,6 +1435,127 @@ func (t *ApproveTicket) Execute(_ context.Context, args map[string]any) (map[str
 	}, nil
 }
 
+// --- ApprovalHistory ---
+
+type ApprovalHistory struct {
+	glpi         *glpi.Client
+	sessionToken string
+}
+
+func NewApprovalHistory(g *glpi.Client, token string) *ApprovalHistory {
+	return &ApprovalHistory{glpi: g, sessionToken: token}
+}
+
+func (t *ApprovalHistory) Name() string   { return "get_approval_history" }
+func (t *ApprovalHistory) ReadOnly() bool { return true }
+func (t *ApprovalHistory) Description() string {
+	return `Mostra todas as aprovacoes (validacoes) de um chamado: quem pediu, quem aprovou/recusou, quando e os comentarios.
+Quando usar: quando o usuario quiser saber quem aprovou, se ja foi aprovado ou o historico de aprovacoes. Ex: "quem aprovou o chamado 123?", "a aprovacao do chamado 456 ja saiu?".
+NAO usar: para aprovar/recusar — use approve_ticket.
+Retorna: {total, aprovacoes: [{status, solicitado_por, validador, solicitado_em, respondido_em, comentario_solicitacao, comentario_validacao}]}.`
+}
+func (t *ApprovalHistory) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"ticket_id": {Type: "integer", Description: "ID do chamado"},
+		},
+		Required: []string{"ticket_id"},
+	}
+}
+
+func (t *ApprovalHistory) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	ticketID, err := intArg(args, "ticket_id")
+	if err != nil {
+		return nil, err
+	}
+
+	validations, err := t.glpi.GetTicketValidations(t.sessionToken, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar validações: %w", err)
+	}
+	if len(validations) == 0 {
+		return map[string]any{
+			"total":      0,
+			"aprovacoes": []map[string]any{},
+			"mensagem":   fmt.Sprintf("O chamado #%d não possui aprovações.", ticketID),
+		}, nil
+	}
+
+	userName := userNamer(t.glpi, t.sessionToken)
+
+	items := make([]map[string]any, len(validations))
+	for i, v := range validations {
+		items[i] = map[string]any{
+			"status":                 validationStatusLabel(v.Status),
+			"solicitado_por":         keyName(v.KeysNames, "users_id", v.UsersID, userName),
+			"validador":              keyName(v.KeysNames, "users_id_validate", v.UsersIDValidate, userName),
+			"solicitado_em":          v.DateCreated,
+			"respondido_em":          v.ValidationDate,
+			"comentario_solicitacao": htmlToPlainText(v.CommentSubmission),
+			"comentario_validacao":   htmlToPlainText(v.CommentValidation),
+		}
+	}
+	return map[string]any{"total": len(items), "aprovacoes": items}, nil
+}
+
+// --- ExplainStatus ---
+
+type statusExplanation struct {
+	meaning  string
+	nextStep string
+}
+
+// statusExplanations answers "what does this status mean for me?" in the
+// requester's terms; users mostly confuse Solucionado with Fechado.
+var statusExplanations = map[int]statusExplanation{
+	1: {"O chamado foi registrado e aguarda um técnico assumir.", "Aguarde a atribuição. Se lembrar de algo, adicione um comentário."},
+	2: {"Um técnico já é responsável e está trabalhando no chamado.", "Acompanhe os comentários e responda se o técnico pedir alguma informação."},
+	3: {"O atendimento tem uma tarefa agendada para uma data específica.", "Aguarde a data planejada. Você pode ver as tarefas do chamado."},
+	4: {"O atendimento está pausado aguardando algo, geralmente uma resposta sua ou de um fornecedor.", "Veja os últimos comentários e responda para o atendimento continuar."},
+	5: {"O técnico registrou uma solução, mas o chamado ainda não foi encerrado.", "Se resolveu, você pode aprovar a solução. Se o problema continua, responda com um comentário para reabrir."},
+	6: {"O chamado foi encerrado definitivamente e não pode mais ser reaberto.", "Se o problema voltar, abra um novo chamado. Você também pode avaliar o atendimento."},
+}
+
+type ExplainStatus struct{}
+
+func NewExplainStatus() *ExplainStatus { return &ExplainStatus{} }
+
+func (t *ExplainStatus) Name() string   { return "explain_status" }
+func (t *ExplainStatus) ReadOnly() bool { return true }
+func (t *ExplainStatus) Description() string {
+	return `Explica o que significa um status de chamado e o que o usuario pode fazer em seguida.
+Quando usar: quando o usuario perguntar o que significa um status ou a diferenca entre eles. Ex: "o que e solucionado?", "qual a diferenca entre solucionado e fechado?".
+Para comparar dois status, chame uma vez para cada.
+Retorna: {status, nome, significado, proximos_passos}.`
+}
+func (t *ExplainStatus) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"status": {Type: "integer", Description: "Código do status: 1=Novo, 2=Em atendimento (atribuído), 3=Em atendimento (planejado), 4=Pendente, 5=Solucionado, 6=Fechado"},
+		},
+		Required: []string{"status"},
+	}
+}
+
+func (t *ExplainStatus) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	status, err := intArg(args, "status")
+	if err != nil {
+		return nil, err
+	}
+	e, ok := statusExplanations[status]
+	if !ok {
+		return nil, fmt.Errorf("status inválido: %d (use 1 a 6)", status)
+	}
+	return map[string]any{
+		"status":          status,
+		"nome":            ticketStatusLabel(status),
+		"significado":     e.meaning,
+		"proximos_passos": e.nextStep,
+	}, nil
+}
+
 // --- RateTicket ---
 
 type RateTicket struct {
@@ -765,6 +1620,95 @@ func (t *RateTicket) Execute(_ context.Context, args map[string]any) (map[string
 	}, nil
 }
 
+// --- CloseAndRate ---
+
+type CloseAndRate struct {
+	glpi         *glpi.Client
+	sessionToken string
+	workflow     StatusWorkflow
+}
+
+func NewCloseAndRate(g *glpi.Client, token string, workflow StatusWorkflow) *CloseAndRate {
+	return &CloseAndRate{glpi: g, sessionToken: token, workflow: workflow}
+}
+
+func (t *CloseAndRate) Name() string   { return "close_and_rate" }
+func (t *CloseAndRate) ReadOnly() bool { return false }
+func (t *CloseAndRate) Description() string {
+	return `Fecha um chamado solucionado e envia a avaliacao de satisfacao em uma unica chamada.
+Quando usar: quando o usuario aprovar a solucao e quiser fechar e avaliar. Ex: "pode fechar o 123, nota 5", "resolveu, fecha e avalia com 4".
+NAO usar: so para avaliar um chamado ja fechado (use rate_ticket) ou so para mudar status (use update_ticket).
+SEMPRE confirme via respond_interactive (chamado, nota e comentario) antes de executar.
+So chamados solucionados podem ser fechados; se o fechamento for recusado, nada e alterado: explique ao usuario e ofereca os status em 'status_permitidos'.
+O chamado e fechado primeiro; se nao houver pesquisa de satisfacao ou a avaliacao falhar, o chamado permanece fechado e a resposta indica a falha parcial.
+Retorna: {mensagem, fechado (bool), avaliado (bool), erro_avaliacao (se houver)} ou {fechado: false, mensagem, status_atual, status_permitidos}.`
+}
+func (t *CloseAndRate) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"ticket_id": {Type: "integer", Description: "ID do chamado"},
+			"rating":    {Type: "integer", Description: "Nota de 1 a 5 (1=Péssimo, 5=Excelente)"},
+			"comment":   {Type: "string", Description: "Comentário sobre o atendimento (opcional)"},
+		},
+		Required: []string{"ticket_id", "rating"},
+	}
+}
+
+func (t *CloseAndRate) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	ticketID, err := intArg(args, "ticket_id")
+	if err != nil {
+		return nil, err
+	}
+	rating, err := intArg(args, "rating")
+	if err != nil {
+		return nil, err
+	}
+	if rating < 1 || rating > 5 {
+		return nil, fmt.Errorf("nota deve ser de 1 a 5")
+	}
+	comment := optionalStringArg(args, "comment")
+
+	refused, err := t.workflow.checkTransition(t.glpi, t.sessionToken, ticketID, 6)
+	if err != nil {
+		return nil, err
+	}
+	if refused != nil {
+		refused["fechado"] = false
+		refused["avaliado"] = false
+		return refused, nil
+	}
+	if err := t.glpi.UpdateTicket(t.sessionToken, ticketID, glpi.UpdateTicketInput{Status: 6}); err != nil {
+		return nil, fmt.Errorf("erro ao fechar chamado: %w", err)
+	}
+	result := map[string]any{
+		"fechado":  true,
+		"avaliado": false,
+		"mensagem": fmt.Sprintf("Chamado #%d fechado", ticketID),
+	}
+
+	// The survey is created by GLPI when the ticket closes, so it is looked up
+	// only now. Like add_followup_and_update, a rating failure is a partial
+	// success: returning an error would make the agent retry the close.
+	satisfaction, err := t.glpi.GetTicketSatisfaction(t.sessionToken, ticketID)
+	switch {
+	case err != nil:
+		result["erro_avaliacao"] = ai.ClassifyError(err).Message
+	case satisfaction == nil:
+		result["erro_avaliacao"] = "não há pesquisa de satisfação disponível para este chamado"
+	default:
+		if err := t.glpi.RateTicketSatisfaction(t.sessionToken, satisfaction.ID, rating, comment); err != nil {
+			result["erro_avaliacao"] = ai.ClassifyError(err).Message
+		} else {
+			result["avaliado"] = true
+			result["mensagem"] = fmt.Sprintf("Chamado #%d fechado e avaliado com %d estrelas", ticketID, rating)
+			return result, nil
+		}
+	}
+	result["mensagem"] = fmt.Sprintf("Chamado #%d fechado, mas não foi possível enviar a avaliação", ticketID)
+	return result, nil
+}
+
 // --- GetTicketHistory ---
 
 type GetTicketHistory struct {
@@ -784,6 +1728,11 @@ func (t *GetTicketHistory) Description() string {
 Quando usar: quando o usuario quiser saber o que aconteceu com um chamado, quem alterou, quando mudou de status. Ex: "historico do chamado 123", "o que mudou no meu chamado".
 Retorna: lista com data, usuario, valor antigo e valor novo de cada alteracao.`
 }
+// OutputLimits: a history is read as a whole; cutting it at 10 entries hides
+// what happened last.
+func (t *GetTicketHistory) OutputLimits() ai.OutputLimits {
+	return ai.OutputLimits{MaxItems: 30, MaxBytes: 16 << 10}
+}
 func (t *GetTicketHistory) Parameters() *ai.ParamSchema {
 	return &ai.ParamSchema{
 		Type: "object",
@@ -869,6 +1818,129 @@ func (t *AddFollowup) Execute(_ context.Context, args map[string]any) (map[strin
 	}, nil
 }
 
+// --- FollowupAndUpdate ---
+
+type FollowupAndUpdate struct {
+	glpi         *glpi.Client
+	sessionToken string
+	workflow     StatusWorkflow
+}
+
+func NewFollowupAndUpdate(g *glpi.Client, token string, workflow StatusWorkflow) *FollowupAndUpdate {
+	return &FollowupAndUpdate{glpi: g, sessionToken: token, workflow: workflow}
+}
+
+func (t *FollowupAndUpdate) Name() string   { return "add_followup_and_update" }
+func (t *FollowupAndUpdate) ReadOnly() bool { return false }
+func (t *FollowupAndUpdate) Description() string {
+	return `Adiciona um comentario e altera o status de um chamado em uma unica chamada.
+Quando usar: quando o usuario pedir para comentar E mudar o status juntos. Ex: "comenta que resolvi e fecha o chamado 123", "responde e coloca como pendente".
+NAO usar: so para comentar (use add_followup) ou so para alterar campos (use update_ticket).
+SEMPRE confirme via respond_interactive antes de executar.
+Nem toda mudanca de status e permitida (as mesmas regras de update_ticket). Se a mudanca for recusada, nem o comentario e adicionado: explique ao usuario e ofereca os status em 'status_permitidos'.
+O comentario e adicionado primeiro; se a mudanca de status falhar, o comentario permanece e a resposta indica a falha parcial.
+Retorna: {mensagem, comentario_id, status_alterado (bool), erro_status (se houver)} ou {status_alterado: false, mensagem, status_atual, status_permitidos}.`
+}
+func (t *FollowupAndUpdate) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"ticket_id": {Type: "integer", Description: "ID do chamado"},
+			"content":   {Type: "string", Description: "Texto do comentário"},
+			"status":    {Type: "integer", Description: "Novo status (opcional): 1=Novo, 2=Atribuído, 3=Planejado, 4=Pendente, 5=Solucionado, 6=Fechado"},
+		},
+		Required: []string{"ticket_id", "content"},
+	}
+}
+
+func (t *FollowupAndUpdate) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	ticketID, err := intArg(args, "ticket_id")
+	if err != nil {
+		return nil, err
+	}
+	content, _ := stringArg(args, "content")
+	if content == "" {
+		return nil, fmt.Errorf("conteúdo do comentário é obrigatório")
+	}
+	status := optionalIntArg(args, "status")
+	if status != 0 && (status < 1 || status > 6) {
+		return nil, fmt.Errorf("status inválido: %d (deve ser de 1 a 6)", status)
+	}
+	if status != 0 {
+		// Checked before the followup so a refused change leaves nothing behind.
+		refused, err := t.workflow.checkTransition(t.glpi, t.sessionToken, ticketID, status)
+		if err != nil {
+			return nil, err
+		}
+		if refused != nil {
+			refused["status_alterado"] = false
+			return refused, nil
+		}
+	}
+
+	id, err := t.glpi.AddFollowup(t.sessionToken, ticketID, content)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao adicionar comentário: %w", err)
+	}
+	result := map[string]any{
+		"comentario_id":   id,
+		"status_alterado": false,
+		"mensagem":        fmt.Sprintf("Comentário adicionado ao chamado #%d", ticketID),
+	}
+	if status == 0 {
+		return result, nil
+	}
+
+	// GLPI has no transaction across endpoints, so a failed status change is
+	// reported as a partial success rather than an error the agent would retry
+	// (which would duplicate the followup).
+	if err := t.glpi.UpdateTicket(t.sessionToken, ticketID, glpi.UpdateTicketInput{Status: status}); err != nil {
+		result["erro_status"] = ai.ClassifyError(err).Message
+		result["mensagem"] = fmt.Sprintf("Comentário adicionado ao chamado #%d, mas não foi possível alterar o status", ticketID)
+		return result, nil
+	}
+	result["status_alterado"] = true
+	result["mensagem"] = fmt.Sprintf("Comentário adicionado e chamado #%d alterado para %s", ticketID, ticketStatusLabel(status))
+	return result, nil
+}
+
+// userNamer returns a lookup from user ID to display name that fetches each
+// user once. ID 0 (no user) gives "". Users purged from GLPI (404) show as
+// removed; any other failure falls back to the ID so lists never break.
+func userNamer(g *glpi.Client, session string) func(int) string {
+	names := map[int]string{}
+	return func(id int) string {
+		if id == 0 {
+			return ""
+		}
+		if n, ok := names[id]; ok {
+			return n
+		}
+		n := fmt.Sprintf("Usuário #%d", id)
+		u, err := g.GetUser(session, id)
+		switch {
+		case err != nil && strings.Contains(err.Error(), "status 404"):
+			n = fmt.Sprintf("Usuário removido (#%d)", id)
+		case err != nil:
+		case strings.TrimSpace(u.FirstName+" "+u.RealName) != "":
+			n = strings.TrimSpace(u.FirstName + " " + u.RealName)
+		case u.Name != "":
+			n = u.Name
+		}
+		names[id] = n
+		return n
+	}
+}
+
+// keyName prefers the name GLPI resolved through add_keys_names and only
+// looks the user up when it is missing (older GLPI, deleted user).
+func keyName(names glpi.KeysNames, field string, id int, lookup func(int) string) string {
+	if n := names.Get(field); n != "" {
+		return n
+	}
+	return lookup(id)
+}
+
 // --- GetFollowups ---
 
 type GetFollowups struct {
@@ -886,14 +1958,21 @@ func (t *GetFollowups) ReadOnly() bool { return true }
 func (t *GetFollowups) Description() string {
 	return `Lista os comentarios (followups) de um chamado.
 Quando usar: quando o usuario quiser ver as mensagens/respostas de um chamado. Ex: "comentarios do chamado 123", "respostas no meu chamado".
-Retorna: {total, comentarios: [{id, conteudo, data}]}.
-Nota: a resposta nao inclui o nome do autor de cada comentario.`
+autor_tipo diz quem escreveu: "voce" (o proprio usuario), "solicitante" (outro solicitante do chamado) ou "tecnico" (equipe de TI).
+Use 'since' quando o usuario so quiser as novidades. Ex: "tem resposta nova no 123 desde ontem?".
+Retorna: {total, comentarios: [{id, conteudo, data, autor, autor_tipo}], desde?}.`
+}
+// OutputLimits: the conversation on a ticket often runs past 10 comments,
+// and GLPI lists the newest last.
+func (t *GetFollowups) OutputLimits() ai.OutputLimits {
+	return ai.OutputLimits{MaxItems: 20, MaxBytes: 12 << 10}
 }
 func (t *GetFollowups) Parameters() *ai.ParamSchema {
 	return &ai.ParamSchema{
 		Type: "object",
 		Properties: map[string]*ai.ParamSchema{
 			"ticket_id": {Type: "integer", Description: "ID do chamado"},
+			"since":     {Type: "string", Description: "Só comentários depois desta data/hora (horário de Brasília): 'YYYY-MM-DD' ou 'YYYY-MM-DD HH:MM'"},
 		},
 		Required: []string{"ticket_id"},
 	}
@@ -905,20 +1984,73 @@ func (t *GetFollowups) Execute(_ context.Context, args map[string]any) (map[stri
 		return nil, err
 	}
 
-	followups, err := t.glpi.GetFollowups(t.sessionToken, ticketID)
-	if err != nil {
+	var followups []glpi.Followup
+	since := strings.TrimSpace(optionalStringArg(args, "since"))
+	if since != "" {
+		from, err := parseSince(since)
+		if err != nil {
+			return nil, err
+		}
+		since = from.Format(glpiDateTime)
+		followups, err = t.glpi.GetFollowupsSince(t.sessionToken, ticketID, since)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao buscar comentários: %w", err)
+		}
+	} else if followups, err = t.glpi.GetFollowups(t.sessionToken, ticketID); err != nil {
 		return nil, fmt.Errorf("erro ao buscar comentários: %w", err)
 	}
 
+	// Best effort: without the actor list every other author counts as
+	// technician, which is what most followups are anyway.
+	requesters := map[int]bool{}
+	if actors, err := t.glpi.GetTicketUsers(t.sessionToken, ticketID); err == nil {
+		for _, a := range actors {
+			if a.Type == glpi.ActorRequester {
+				requesters[a.UsersID] = true
+			}
+		}
+	}
+
+	userName := userNamer(t.glpi, t.sessionToken)
 	items := make([]map[string]any, len(followups))
 	for i, f := range followups {
-		items[i] = map[string]any{
+		item := map[string]any{
 			"id":       f.ID,
 			"conteudo": f.Content,
 			"data":     f.DateCreated,
 		}
+		switch {
+		case f.UsersID == 0:
+			// Followups added by mail from unknown senders or by automatic actions.
+			item["autor"] = "Sem autor identificado"
+		case f.UsersID == t.userID:
+			item["autor"] = "Você"
+			item["autor_tipo"] = "voce"
+		case requesters[f.UsersID]:
+			item["autor"] = keyName(f.KeysNames, "users_id", f.UsersID, userName)
+			item["autor_tipo"] = "solicitante"
+		default:
+			item["autor"] = keyName(f.KeysNames, "users_id", f.UsersID, userName)
+			item["autor_tipo"] = "tecnico"
+		}
+		items[i] = item
+	}
+	result := map[string]any{"total": len(followups), "comentarios": items}
+	if since != "" {
+		result["desde"] = since
+	}
+	return result, nil
+}
+
+// parseSince reads a date or date-time typed in Brasília time. The Nexus
+// server runs in the same zone, so the result compares with its dates.
+func parseSince(s string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, brLocation); err == nil {
+			return t, nil
+		}
 	}
-	return map[string]any{"total": len(followups), "comentarios": items}, nil
+	return time.Time{}, fmt.Errorf("data inválida em since: %q (use YYYY-MM-DD ou YYYY-MM-DD HH:MM)", s)
 }
 
 // --- search helpers ---
@@ -940,7 +2072,8 @@ func mapStatusToGLPI(status string) []int {
 	}
 }
 
-// parsePeriod converts friendly period names or date ranges to (from, to) date strings.
+// parsePeriod converts friendly period names or date ranges to (from, to) date
+// strings. An open range ("2025-01-01..") returns an empty to.
 func parsePeriod(period string) (string, string) {
 	now := time.Now()
 	today := now.Format("2006-01-02")
@@ -990,14 +2123,39 @@ var _ ai.Tool = (*GetTicket)(nil)
 var _ ai.Tool = (*CreateTicket)(nil)
 var _ ai.Tool = (*UpdateTicket)(nil)
 var _ ai.Tool = (*AddFollowup)(nil)
+var _ ai.Tool = (*FollowupAndUpdate)(nil)
 var _ ai.Tool = (*GetFollowups)(nil)
 var _ ai.Tool = (*SearchTicketsAdvanced)(nil)
+var _ ai.Tool = (*TicketCountByPeriod)(nil)
+var _ ai.Tool = (*MyTicketsByCategory)(nil)
+var _ ai.Tool = (*MyAssignedTickets)(nil)
+var _ ai.Tool = (*ColleagueTickets)(nil)
 var _ ai.Tool = (*GetTicketTasks)(nil)
 var _ ai.Tool = (*AddTicketTask)(nil)
 var _ ai.Tool = (*ApproveTicket)(nil)
+var _ ai.Tool = (*ApprovalHistory)(nil)
+var _ ai.Tool = (*ExplainStatus)(nil)
+var _ ai.Tool = (*GetTicketsBatch)(nil)
 var _ ai.Tool = (*RateTicket)(nil)
+var _ ai.Tool = (*CloseAndRate)(nil)
 var _ ai.Tool = (*GetTicketHistory)(nil)
 
+// validationStatusLabel maps CommonITILValidation statuses.
+func validationStatusLabel(s int) string {
+	switch s {
+	case 1:
+		return "Sem validação"
+	case 2:
+		return "Aguardando"
+	case 3:
+		return "Aprovado"
+	case 4:
+		return "Recusado"
+	default:
+		return fmt.Sprintf("Desconhecido (%d)", s)
+	}
+}
+
 func ticketStatusLabel(s int) string {
 	switch s {
 	case 1:
@@ -1034,6 +2192,23 @@ func urgencyLabel(u int) string {
 	}
 }
 
+func impactLabel(i int) string {
+	switch i {
+	case 1:
+		return "Muito baixo"
+	case 2:
+		return "Baixo"
+	case 3:
+		return "Médio"
+	case 4:
+		return "Alto"
+	case 5:
+		return "Muito alto"
+	default:
+		return fmt.Sprintf("Desconhecido (%d)", i)
+	}
+}
+
 func taskStateLabel(s int) string {
 	switch s {
 	case 1:
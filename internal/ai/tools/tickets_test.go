@@ -0,0 +1,233 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lojasmm/laia/internal/glpi"
+)
+
+// testGLPI serves h as the Nexus REST API.
+func testGLPI(t *testing.T, h http.HandlerFunc) *glpi.Client {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return glpi.NewClient(srv.URL, "app", "", 0, glpi.Timeouts{})
+}
+
+func TestParsePeriod(t *testing.T) {
+	now := time.Now()
+	day := func(tm time.Time) string { return tm.Format("2006-01-02") }
+	today := day(now)
+
+	tests := []struct {
+		period   string
+		wantFrom string
+		wantTo   string
+	}{
+		{"hoje", today, today},
+		{"semana", day(now.AddDate(0, 0, -7)), today},
+		{"mes", day(now.AddDate(0, -1, 0)), today},
+		{"ano", day(now.AddDate(-1, 0, 0)), today},
+		{"2025-01-01..2025-01-31", "2025-01-01", "2025-01-31"},
+		{"2025-01-01..", "2025-01-01", ""},
+		{"ontem", "", ""},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		from, to := parsePeriod(tt.period)
+		if from != tt.wantFrom || to != tt.wantTo {
+			t.Errorf("parsePeriod(%q) = (%q, %q), want (%q, %q)", tt.period, from, to, tt.wantFrom, tt.wantTo)
+		}
+	}
+}
+
+func TestMyTicketsByCategoryOpenRange(t *testing.T) {
+	criteria := actorTicketsCriteria("4", 7, "todos")
+	from, to := parsePeriod("2025-01-01..")
+	next := addPeriodCriteria(criteria, 1, from, to)
+
+	if next != 2 {
+		t.Errorf("next index = %d, want 2", next)
+	}
+	if got := criteria["criteria[1][value]"]; got != "2025-01-01" {
+		t.Errorf("lower bound = %q", got)
+	}
+	if _, ok := criteria["criteria[2][value]"]; ok {
+		t.Errorf("open range got an upper bound: %q", criteria["criteria[2][value]"])
+	}
+}
+
+// ticketsServer answers GET /Ticket/:id for the IDs in found and 404s the rest.
+func ticketsServer(found ...int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var id int
+		fmt.Sscanf(strings.TrimPrefix(r.URL.Path, "/apirest.php/Ticket/"), "%d", &id)
+		w.Header().Set("Content-Type", "application/json")
+		for _, f := range found {
+			if f == id {
+				fmt.Fprintf(w, `{"id": %d, "name": "Chamado %d", "status": 2}`, id, id)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`["ERROR_ITEM_NOT_FOUND", "item not found"]`))
+	}
+}
+
+func batchArgs(ids ...int) map[string]any {
+	list := make([]any, len(ids))
+	for i, id := range ids {
+		list[i] = float64(id)
+	}
+	return map[string]any{"ticket_ids": list}
+}
+
+func TestGetTicketsBatchPartialFailure(t *testing.T) {
+	tool := NewGetTicketsBatch(testGLPI(t, ticketsServer(10, 30, 50)), "session")
+
+	out, err := tool.Execute(context.Background(), batchArgs(50, 20, 10, 40, 30))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotFound []int
+	for _, c := range out["chamados"].([]map[string]any) {
+		gotFound = append(gotFound, c["id"].(int))
+	}
+	if fmt.Sprint(gotFound) != "[50 10 30]" {
+		t.Errorf("chamados = %v, want request order [50 10 30]", gotFound)
+	}
+	var gotErrs []int
+	for _, e := range out["erros"].([]map[string]any) {
+		gotErrs = append(gotErrs, e["id"].(int))
+	}
+	if fmt.Sprint(gotErrs) != "[20 40]" {
+		t.Errorf("erros = %v, want request order [20 40]", gotErrs)
+	}
+	if out["total"] != 3 {
+		t.Errorf("total = %v, want 3", out["total"])
+	}
+}
+
+func TestGetTicketsBatchAllFailed(t *testing.T) {
+	tool := NewGetTicketsBatch(testGLPI(t, ticketsServer()), "session")
+
+	_, err := tool.Execute(context.Background(), batchArgs(1, 2))
+	if err == nil || !strings.Contains(err.Error(), "erro ao buscar chamados") {
+		t.Fatalf("err = %v, want the batch error", err)
+	}
+}
+
+func TestGetTicketsBatchLimits(t *testing.T) {
+	calls := 0
+	tool := NewGetTicketsBatch(testGLPI(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		ticketsServer()(w, r)
+	}), "session")
+
+	ids := make([]int, maxBatchTickets+1)
+	for i := range ids {
+		ids[i] = i + 1
+	}
+	if _, err := tool.Execute(context.Background(), batchArgs(ids...)); err == nil {
+		t.Error("no error for more than maxBatchTickets IDs")
+	}
+	if _, err := tool.Execute(context.Background(), batchArgs()); err == nil {
+		t.Error("no error for an empty list")
+	}
+	if calls != 0 {
+		t.Errorf("rejected batches still made %d requests", calls)
+	}
+}
+
+func TestPeriodCountCriteria(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		codes    []int
+		want     map[string]string
+	}{
+		{
+			name: "bare end date extended to end of day",
+			from: "2025-01-01", to: "2025-01-31",
+			want: map[string]string{
+				"range":                   "0-0",
+				"criteria[0][field]":      "15",
+				"criteria[0][searchtype]": "morethan",
+				"criteria[0][value]":      "2025-01-01",
+				"criteria[1][link]":       "AND",
+				"criteria[1][field]":      "15",
+				"criteria[1][searchtype]": "lessthan",
+				"criteria[1][value]":      "2025-01-31 23:59:59",
+			},
+		},
+		{
+			name: "end with time kept",
+			from: "2025-01-01", to: "2025-01-31 12:00:00",
+			want: map[string]string{
+				"range":                   "0-0",
+				"criteria[0][field]":      "15",
+				"criteria[0][searchtype]": "morethan",
+				"criteria[0][value]":      "2025-01-01",
+				"criteria[1][link]":       "AND",
+				"criteria[1][field]":      "15",
+				"criteria[1][searchtype]": "lessthan",
+				"criteria[1][value]":      "2025-01-31 12:00:00",
+			},
+		},
+		{
+			name: "open range with status group",
+			from: "2025-01-01", codes: []int{1, 2},
+			want: map[string]string{
+				"range":                                "0-0",
+				"criteria[0][field]":                   "15",
+				"criteria[0][searchtype]":              "morethan",
+				"criteria[0][value]":                   "2025-01-01",
+				"criteria[1][link]":                    "AND",
+				"criteria[1][criteria][0][field]":      "12",
+				"criteria[1][criteria][0][searchtype]": "equals",
+				"criteria[1][criteria][0][value]":      "1",
+				"criteria[1][criteria][1][link]":       "OR",
+				"criteria[1][criteria][1][field]":      "12",
+				"criteria[1][criteria][1][searchtype]": "equals",
+				"criteria[1][criteria][1][value]":      "2",
+			},
+		},
+		{
+			name: "status group after both bounds",
+			from: "2025-01-01", to: "2025-01-31", codes: []int{5},
+			want: map[string]string{
+				"range":                                "0-0",
+				"criteria[0][field]":                   "15",
+				"criteria[0][searchtype]":              "morethan",
+				"criteria[0][value]":                   "2025-01-01",
+				"criteria[1][link]":                    "AND",
+				"criteria[1][field]":                   "15",
+				"criteria[1][searchtype]":              "lessthan",
+				"criteria[1][value]":                   "2025-01-31 23:59:59",
+				"criteria[2][link]":                    "AND",
+				"criteria[2][criteria][0][field]":      "12",
+				"criteria[2][criteria][0][searchtype]": "equals",
+				"criteria[2][criteria][0][value]":      "5",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := periodCountCriteria(tt.from, tt.to, tt.codes)
+			if len(got) != len(tt.want) {
+				t.Errorf("got %d keys, want %d: %v", len(got), len(tt.want), got)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("%s = %q, want %q", k, got[k], v)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/lojasmm/laia/internal/glpi"
+)
+
+// GLPI ticket types.
+const (
+	ticketTypeIncident = 1
+	ticketTypeRequest  = 2
+)
+
+// ticketTypeParam is the "type" parameter of create_ticket and update_ticket.
+var ticketTypeParam = []string{"incidente", "requisicao"}
+
+func ticketTypeFromArg(v string) (int, error) {
+	switch v {
+	case "":
+		return 0, nil
+	case "incidente":
+		return ticketTypeIncident, nil
+	case "requisicao":
+		return ticketTypeRequest, nil
+	default:
+		return 0, fmt.Errorf("tipo inválido: %s (use incidente ou requisicao)", v)
+	}
+}
+
+func ticketTypeLabel(t int) string {
+	switch t {
+	case ticketTypeIncident:
+		return "Incidente"
+	case ticketTypeRequest:
+		return "Requisição"
+	default:
+		return fmt.Sprintf("Tipo %d", t)
+	}
+}
+
+// categoryAllowsType reports whether tickets of type may use c. Categories
+// saved before the flags existed have neither set and accept both.
+func categoryAllowsType(c *glpi.ITILCategory, typ int) bool {
+	if c.IsIncident == 0 && c.IsRequest == 0 {
+		return true
+	}
+	switch typ {
+	case ticketTypeIncident:
+		return c.IsIncident == 1
+	case ticketTypeRequest:
+		return c.IsRequest == 1
+	default:
+		return true
+	}
+}
+
+// resolveTicketType picks the type of a ticket in categoryID. With no type
+// requested it's the one the category accepts, incident first; a requested
+// type the category doesn't accept returns a clarification instead. GLPI
+// itself doesn't enforce the flags, it only hides the category in the web
+// form, so a ticket created otherwise lands in a category technicians don't
+// expect for that type.
+func resolveTicketType(g *glpi.Client, session string, categoryID, requested int) (int, map[string]any) {
+	category, err := g.GetCategory(session, categoryID)
+	if err != nil {
+		// The flags are a consistency check; don't block the ticket on them.
+		slog.Warn("tools: could not read category type flags", "category_id", categoryID, "error", err)
+		if requested == 0 {
+			return ticketTypeIncident, nil
+		}
+		return requested, nil
+	}
+	if requested == 0 {
+		if categoryAllowsType(category, ticketTypeIncident) {
+			return ticketTypeIncident, nil
+		}
+		return ticketTypeRequest, nil
+	}
+	if categoryAllowsType(category, requested) {
+		return requested, nil
+	}
+	other := ticketTypeIncident
+	if requested == ticketTypeIncident {
+		other = ticketTypeRequest
+	}
+	name := category.Completename
+	if name == "" {
+		name = category.Name
+	}
+	return 0, clarification(
+		fmt.Sprintf("A categoria %s só aceita chamados do tipo %s. Quer seguir como %s ou escolher outra categoria?",
+			name, ticketTypeLabel(other), ticketTypeLabel(other)),
+		[]string{"Seguir como " + ticketTypeLabel(other), "Outra categoria"},
+		fmt.Sprintf("Use respond_interactive com botoes. Se o usuario aceitar, repita a chamada com type=%q; se nao, volte a Etapa 3.", ticketTypeParam[other-1]),
+	)
+}
@@ -0,0 +1,92 @@
+package tools
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/lojasmm/laia/internal/store"
+)
+
+const (
+	transcriptHeader = "--- Transcrição WhatsApp ---"
+	// Keeps the note readable for technicians and well below GLPI's content limits.
+	maxTranscriptLen  = 2000
+	maxTranscriptLine = 300
+)
+
+var (
+	emailPattern = regexp.MustCompile(`[\w.+-]+@[\w-]+(\.[\w-]+)+`)
+	cpfPattern   = regexp.MustCompile(`\b\d{3}\.?\d{3}\.?\d{3}-?\d{2}\b`)
+	phonePattern = regexp.MustCompile(`\+?\(?\d{2,3}\)?[\s-]?\d{4,5}[\s-]?\d{4}\b`)
+)
+
+// greetings are dropped from the transcript — they add nothing for the technician.
+var greetings = map[string]bool{
+	"oi": true, "oii": true, "ola": true, "olá": true, "opa": true, "e ai": true, "e aí": true,
+	"bom dia": true, "boa tarde": true, "boa noite": true, "tudo bem": true,
+	"obrigado": true, "obrigada": true, "valeu": true, "ok": true, "blz": true,
+}
+
+// buildTranscript condenses the turns since the last created ticket into a
+// plain-text note. Returns "" when there's nothing worth attaching.
+func buildTranscript(turns []store.ConversationTurn) string {
+	start := 0
+	for i, t := range turns {
+		for _, p := range t.Parts {
+			if p.FunctionResponse != nil && p.FunctionResponse.Name == "create_ticket" {
+				start = i + 1
+			}
+		}
+	}
+
+	var lines []string
+	for _, t := range turns[start:] {
+		speaker := ""
+		switch t.Role {
+		case "user":
+			speaker = "Usuário"
+		case "assistant":
+			speaker = "Laia"
+		default:
+			continue
+		}
+		for _, p := range t.Parts {
+			text := p.Text
+			// Questions asked through buttons/lists only exist as tool call args
+			if p.FunctionCall != nil && p.FunctionCall.Name == "respond_interactive" {
+				text, _ = p.FunctionCall.Args["text"].(string)
+			}
+			text = strings.Join(strings.Fields(text), " ")
+			if text == "" || isGreeting(text) {
+				continue
+			}
+			lines = append(lines, speaker+": "+truncateText(redactPII(text), maxTranscriptLine))
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(transcriptHeader)
+	for _, line := range lines {
+		if len([]rune(b.String()))+len([]rune(line))+1 > maxTranscriptLen {
+			b.WriteString("\n[…]")
+			break
+		}
+		b.WriteString("\n" + line)
+	}
+	return b.String()
+}
+
+func isGreeting(text string) bool {
+	normalized := strings.ToLower(strings.Trim(text, " !.,?"))
+	return greetings[normalized]
+}
+
+// redactPII masks e-mails, CPFs and phone numbers that users tend to paste in chat.
+func redactPII(text string) string {
+	text = emailPattern.ReplaceAllString(text, "[email]")
+	text = cpfPattern.ReplaceAllString(text, "[cpf]")
+	return phonePattern.ReplaceAllString(text, "[telefone]")
+}
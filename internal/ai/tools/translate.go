@@ -0,0 +1,146 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lojasmm/laia/internal/ai"
+	"github.com/lojasmm/laia/internal/glpi"
+)
+
+// maxCachedTranslations bounds memory; the cache is simply reset when full
+// since translations are cheap to redo compared to tracking recency.
+const maxCachedTranslations = 200
+
+// translationCache is shared by every registry, so a ticket translated for
+// one user isn't paid for again by the next.
+type translationCache struct {
+	mu      sync.Mutex
+	entries map[string]map[string]any
+}
+
+func newTranslationCache() *translationCache {
+	return &translationCache{entries: make(map[string]map[string]any)}
+}
+
+// translationKey includes date_mod so any edit to the ticket (new solution,
+// changed description) invalidates earlier translations.
+func translationKey(ticketID int, dateMod, lang string) string {
+	return fmt.Sprintf("%d|%s|%s", ticketID, dateMod, strings.ToLower(strings.TrimSpace(lang)))
+}
+
+func (c *translationCache) get(key string) (map[string]any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.entries[key]
+	return v, ok
+}
+
+func (c *translationCache) put(key string, v map[string]any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxCachedTranslations {
+		c.entries = make(map[string]map[string]any)
+	}
+	c.entries[key] = v
+}
+
+// --- TranslateTicket ---
+
+type TranslateTicket struct {
+	glpi         *glpi.Client
+	sessionToken string
+	completer    *ai.Completer
+	cache        *translationCache
+}
+
+func NewTranslateTicket(g *glpi.Client, token string, completer *ai.Completer, cache *translationCache) *TranslateTicket {
+	return &TranslateTicket{glpi: g, sessionToken: token, completer: completer, cache: cache}
+}
+
+func (t *TranslateTicket) Name() string   { return "translate_ticket" }
+func (t *TranslateTicket) ReadOnly() bool { return true }
+
+// HasSideEffects: each translation is a paid model call.
+func (t *TranslateTicket) HasSideEffects() bool { return true }
+func (t *TranslateTicket) Description() string {
+	return `Traduz titulo, descricao e solucao de um chamado para outro idioma.
+Quando usar: quando o usuario pedir o chamado em outro idioma ou escrever em outro idioma e quiser entender um chamado. Ex: "traduz o chamado 123 para espanhol".
+NAO usar: para responder ao usuario em outro idioma — isso voce faz direto.
+Retorna: {id, idioma, titulo, descricao, solucao}.`
+}
+
+// Timeout: translating a long description and solution is a full model
+// completion, which alone can take most of the default 30s.
+func (t *TranslateTicket) Timeout() time.Duration { return 90 * time.Second }
+func (t *TranslateTicket) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"ticket_id": {Type: "integer", Description: "ID do chamado"},
+			"language":  {Type: "string", Description: "Idioma de destino. Ex: 'espanhol', 'inglês'"},
+		},
+		Required: []string{"ticket_id", "language"},
+	}
+}
+
+func (t *TranslateTicket) Execute(ctx context.Context, args map[string]any) (map[string]any, error) {
+	ticketID, err := intArg(args, "ticket_id")
+	if err != nil {
+		return nil, err
+	}
+	lang, err := stringArg(args, "language")
+	if err != nil || strings.TrimSpace(lang) == "" {
+		return nil, fmt.Errorf("parâmetro obrigatório ausente: language")
+	}
+
+	ticket, err := t.glpi.GetTicket(t.sessionToken, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar chamado: %w", err)
+	}
+	key := translationKey(ticket.ID, ticket.DateMod, lang)
+	if cached, ok := t.cache.get(key); ok {
+		return cached, nil
+	}
+
+	source := map[string]string{
+		"titulo":    ticket.Name,
+		"descricao": htmlToPlainText(ticket.Content),
+		"solucao":   "",
+	}
+	if solutions, err := t.glpi.GetTicketSolutions(t.sessionToken, ticketID); err == nil && len(solutions) > 0 {
+		source["solucao"] = htmlToPlainText(solutions[len(solutions)-1].Content)
+	}
+	sourceJSON, _ := json.Marshal(source)
+
+	reply, err := t.completer.Complete(ctx,
+		fmt.Sprintf("Traduza os valores do JSON para %s. Mantenha as chaves, números de chamado e termos técnicos. "+
+			"Responda apenas com o JSON, sem comentários.", lang),
+		string(sourceJSON))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao traduzir chamado: %w", err)
+	}
+
+	var translated map[string]string
+	reply = strings.TrimSpace(reply)
+	reply = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(reply, "```json"), "```"), "```")
+	if err := json.Unmarshal([]byte(reply), &translated); err != nil {
+		return nil, fmt.Errorf("erro ao traduzir chamado: resposta inválida do modelo")
+	}
+
+	result := map[string]any{
+		"id":        ticket.ID,
+		"idioma":    lang,
+		"titulo":    translated["titulo"],
+		"descricao": translated["descricao"],
+		"solucao":   translated["solucao"],
+	}
+	t.cache.put(key, result)
+	return result, nil
+}
+
+var _ ai.Tool = (*TranslateTicket)(nil)
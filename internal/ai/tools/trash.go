@@ -0,0 +1,176 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/lojasmm/laia/internal/ai"
+	"github.com/lojasmm/laia/internal/glpi"
+)
+
+// maxDeletedTickets caps search_deleted_tickets; the trash is searched to find
+// one ticket, not to browse it.
+const maxDeletedTickets = 10
+
+// trashAccess restricts a tool to profiles that see every ticket and may put
+// tickets in the trash (ai.ProfileRestricted). GLPI only lets those restore
+// them, so showing the trash to anyone else would be a dead end.
+type trashAccess struct{}
+
+func (trashAccess) AllowsProfile(p glpi.ActiveProfile) bool {
+	return p.Interface != "helpdesk" &&
+		p.TicketRight&glpi.TicketReadAll != 0 &&
+		p.TicketRight&glpi.TicketDelete != 0
+}
+
+// --- SearchDeletedTickets ---
+
+type SearchDeletedTickets struct {
+	trashAccess
+	glpi         *glpi.Client
+	sessionToken string
+}
+
+func NewSearchDeletedTickets(g *glpi.Client, token string) *SearchDeletedTickets {
+	return &SearchDeletedTickets{glpi: g, sessionToken: token}
+}
+
+func (t *SearchDeletedTickets) Name() string   { return "search_deleted_tickets" }
+func (t *SearchDeletedTickets) ReadOnly() bool { return true }
+func (t *SearchDeletedTickets) Description() string {
+	return `Busca chamados que foram excluidos (estao na lixeira do Nexus). Disponivel apenas para administradores.
+Quando usar: quando o usuario procurar um chamado que "sumiu" ou foi apagado. Ex: "alguem excluiu o chamado 123", "chamados na lixeira sobre VPN".
+NAO usar: para chamados normais — use search_tickets_advanced ou get_ticket.
+Informe query (titulo) ou ticket_id. Para tirar da lixeira, use restore_ticket com confirmacao do usuario.
+Retorna: {total, chamados: [{id, titulo, status, data_abertura, data_modificacao, solicitante}]}.`
+}
+func (t *SearchDeletedTickets) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"query":     {Type: "string", Description: "Parte do titulo do chamado excluido. Ex: 'VPN'"},
+			"ticket_id": {Type: "integer", Description: "ID do chamado excluido, se conhecido"},
+		},
+	}
+}
+
+func (t *SearchDeletedTickets) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	query := optionalStringArg(args, "query")
+	ticketID := optionalIntArg(args, "ticket_id")
+	if query == "" && ticketID <= 0 {
+		return clarification(
+			"Qual chamado excluído você procura? Informe o número ou parte do título.",
+			nil,
+			"Use search_deleted_tickets com query ou ticket_id.",
+		), nil
+	}
+
+	// Field 19 (last update) is the closest the search has to a deletion date:
+	// moving a ticket to the trash updates it.
+	criteria := map[string]string{
+		"forcedisplay[12]": "19",
+		"range":            fmt.Sprintf("0-%d", maxDeletedTickets-1),
+		"sort":             "19",
+		"order":            "DESC",
+	}
+	if ticketID > 0 {
+		criteria["criteria[0][field]"] = "2"
+		criteria["criteria[0][searchtype]"] = "equals"
+		criteria["criteria[0][value]"] = fmt.Sprintf("%d", ticketID)
+	} else {
+		criteria["criteria[0][field]"] = "1"
+		criteria["criteria[0][searchtype]"] = "contains"
+		criteria["criteria[0][value]"] = query
+	}
+
+	result, err := t.glpi.SearchDeletedTickets(t.sessionToken, criteria)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar chamados excluídos: %w", err)
+	}
+
+	items := make([]map[string]any, len(result.Data))
+	for i, d := range result.Data {
+		items[i] = map[string]any{
+			"id":               d["2"],
+			"titulo":           d["1"],
+			"status":           searchLabel(d["12"], ticketStatusLabel),
+			"data_abertura":    d["15"],
+			"data_modificacao": d["19"],
+			"solicitante":      d["4"],
+		}
+	}
+	out := map[string]any{"total": result.TotalCount, "chamados": items}
+	if result.TotalCount == 0 {
+		out["mensagem"] = "Nenhum chamado excluído encontrado. Ele pode ter sido apagado definitivamente (purgado)."
+	}
+	return out, nil
+}
+
+var _ ai.Tool = (*SearchDeletedTickets)(nil)
+
+// --- RestoreTicket ---
+
+type RestoreTicket struct {
+	trashAccess
+	glpi         *glpi.Client
+	sessionToken string
+}
+
+func NewRestoreTicket(g *glpi.Client, token string) *RestoreTicket {
+	return &RestoreTicket{glpi: g, sessionToken: token}
+}
+
+func (t *RestoreTicket) Name() string   { return "restore_ticket" }
+func (t *RestoreTicket) ReadOnly() bool { return false }
+func (t *RestoreTicket) Description() string {
+	return `Restaura um chamado excluido, tirando-o da lixeira do Nexus. Disponivel apenas para administradores.
+Quando usar: depois de encontrar o chamado com search_deleted_tickets e o usuario confirmar que quer restaura-lo.
+O chamado volta com o mesmo numero, status e historico.
+Retorna: {restaurado, mensagem}.`
+}
+func (t *RestoreTicket) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"ticket_id": {Type: "integer", Description: "ID do chamado excluido"},
+		},
+		Required: []string{"ticket_id"},
+	}
+}
+
+func (t *RestoreTicket) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	ticketID, err := intArg(args, "ticket_id")
+	if err != nil {
+		return nil, err
+	}
+
+	// A ticket outside the trash would be "restored" without complaint, which
+	// reads as if it had been deleted.
+	found, err := t.glpi.SearchDeletedTickets(t.sessionToken, map[string]string{
+		"criteria[0][field]":      "2",
+		"criteria[0][searchtype]": "equals",
+		"criteria[0][value]":      fmt.Sprintf("%d", ticketID),
+		"range":                   "0-0",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar chamado excluído: %w", err)
+	}
+	if found.TotalCount == 0 {
+		return map[string]any{
+			"restaurado": false,
+			"mensagem":   fmt.Sprintf("O chamado #%d não está na lixeira.", ticketID),
+		}, nil
+	}
+
+	if err := t.glpi.RestoreTicket(t.sessionToken, ticketID); err != nil {
+		return nil, fmt.Errorf("erro ao restaurar chamado: %w", err)
+	}
+	slog.Info("tools: ticket restored from trash", "ticket_id", ticketID)
+	return map[string]any{
+		"restaurado": true,
+		"mensagem":   fmt.Sprintf("Chamado #%d restaurado", ticketID),
+	}, nil
+}
+
+var _ ai.Tool = (*RestoreTicket)(nil)
@@ -0,0 +1,142 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/lojasmm/laia/internal/ai"
+)
+
+// UrgencyPolicy lets simple requests skip the urgency question of the create
+// flow. Categories listed in SkipCategories, and their direct subcategories,
+// are flagged for the model; create_ticket fills in Default whenever no
+// urgency is passed, asked or not.
+type UrgencyPolicy struct {
+	// Default is 1-5; 0 leaves GLPI's default (medium).
+	Default        int
+	SkipCategories []int
+}
+
+// asks reports whether the flow should ask urgency for category, whose
+// parent is parentID.
+func (p UrgencyPolicy) asks(category, parentID int) bool {
+	return !slices.Contains(p.SkipCategories, category) && !slices.Contains(p.SkipCategories, parentID)
+}
+
+// UrgencyRule maps keywords to the urgency the problem usually deserves, so
+// the create flow proposes it instead of asking neutrally. Loaded from
+// URGENCY_HINTS_FILE:
+//
+//	[{"keywords": ["loja parada", "caixa parado"], "urgency": 5, "label": "Loja sem vender"}]
+type UrgencyRule struct {
+	Keywords []string `json:"keywords"`
+	Urgency  int      `json:"urgency"`
+	Label    string   `json:"label"`
+}
+
+// DefaultUrgencyRules cover the outages every store reports the same way.
+// A URGENCY_HINTS_FILE replaces them; "[]" turns suggestions off.
+var DefaultUrgencyRules = []UrgencyRule{
+	{Keywords: []string{"loja parada", "loja esta parada", "loja ta parada", "caixa parado", "nao consigo vender", "sem vender", "pdv parado"}, Urgency: 5, Label: "Loja sem vender"},
+	{Keywords: []string{"sistema caiu", "caiu o sistema", "fora do ar", "sistema parado", "ninguem consegue", "todos sem acesso", "sem internet"}, Urgency: 4, Label: "Sistema fora do ar"},
+	{Keywords: []string{"quando der", "sem pressa", "nao e urgente"}, Urgency: 2, Label: "Sem pressa"},
+}
+
+// LoadUrgencyRules reads the urgency hint file; an empty path keeps
+// DefaultUrgencyRules.
+func LoadUrgencyRules(path string) ([]UrgencyRule, error) {
+	if path == "" {
+		return DefaultUrgencyRules, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rules := []UrgencyRule{}
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	for i, r := range rules {
+		if len(r.Keywords) == 0 || r.Urgency < 1 || r.Urgency > 5 {
+			return nil, fmt.Errorf("%s: rule %d needs keywords and urgency 1-5", path, i)
+		}
+	}
+	return rules, nil
+}
+
+// matchUrgency returns the matching rule with the highest urgency, and the
+// keywords that hit. Unlike routing, severity wins over hit count: "sem
+// pressa, mas a loja esta parada" is still urgent. Keywords match as whole
+// words, accents ignored (see matchRouting).
+func matchUrgency(rules []UrgencyRule, text string) (*UrgencyRule, []string) {
+	words := routingWords(text)
+	var best *UrgencyRule
+	var bestHits []string
+	for i := range rules {
+		var hits []string
+		for _, kw := range rules[i].Keywords {
+			if kwWords := routingWords(kw); len(kwWords) > 0 && containsPhrase(words, kwWords) {
+				hits = append(hits, kw)
+			}
+		}
+		if len(hits) == 0 {
+			continue
+		}
+		if best == nil || rules[i].Urgency > best.Urgency || rules[i].Urgency == best.Urgency && len(hits) > len(bestHits) {
+			best, bestHits = &rules[i], hits
+		}
+	}
+	return best, bestHits
+}
+
+// --- SuggestUrgency ---
+
+type SuggestUrgency struct {
+	rules []UrgencyRule
+}
+
+func NewSuggestUrgency(rules []UrgencyRule) *SuggestUrgency {
+	return &SuggestUrgency{rules: rules}
+}
+
+func (t *SuggestUrgency) Name() string   { return "suggest_urgency" }
+func (t *SuggestUrgency) ReadOnly() bool { return true }
+func (t *SuggestUrgency) Description() string {
+	return `Sugere a urgencia do chamado a partir de palavras-chave do relato (ex: "loja parada" → Muito alta).
+Quando usar: na Etapa 4 do fluxo de criacao, antes de perguntar a urgencia, passando o relato do usuario com as palavras dele.
+Se encontrado=true, NAO pergunte a urgencia do zero: proponha a sugerida e peca confirmacao com botoes ("Confirmar", "Mudar urgencia"). So passe urgency ao create_ticket depois que o usuario confirmar ou escolher outra.
+Retorna: {encontrado, urgency, urgencia, motivo, palavras_chave}.`
+}
+func (t *SuggestUrgency) Parameters() *ai.ParamSchema {
+	return &ai.ParamSchema{
+		Type: "object",
+		Properties: map[string]*ai.ParamSchema{
+			"problem": {Type: "string", Description: "Relato do problema com as palavras do usuário"},
+		},
+		Required: []string{"problem"},
+	}
+}
+
+func (t *SuggestUrgency) Execute(_ context.Context, args map[string]any) (map[string]any, error) {
+	problem, err := stringArg(args, "problem")
+	if err != nil {
+		return nil, err
+	}
+
+	rule, hits := matchUrgency(t.rules, problem)
+	if rule == nil {
+		return map[string]any{"encontrado": false}, nil
+	}
+	return map[string]any{
+		"encontrado":     true,
+		"urgency":        rule.Urgency,
+		"urgencia":       urgencyLabel(rule.Urgency),
+		"motivo":         rule.Label,
+		"palavras_chave": hits,
+	}, nil
+}
+
+var _ ai.Tool = (*SuggestUrgency)(nil)
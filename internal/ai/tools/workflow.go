@@ -0,0 +1,112 @@
+package tools
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lojasmm/laia/internal/glpi"
+)
+
+// StatusWorkflow maps a ticket status to the statuses the ticket tools
+// (update_ticket, add_followup_and_update, close_and_rate) may move it to. GLPI itself accepts almost any change, but some make no sense from
+// the requester's side and the model used to try them (e.g. closing a ticket
+// nobody has looked at yet).
+type StatusWorkflow map[int][]int
+
+// DefaultStatusWorkflow follows GLPI's lifecycle: open statuses (new,
+// assigned, planned, pending) move among themselves or to solved; a solved
+// ticket is closed or reopened as assigned; closed is final.
+var DefaultStatusWorkflow = StatusWorkflow{
+	1: {2, 3, 4, 5},
+	2: {3, 4, 5},
+	3: {2, 4, 5},
+	4: {2, 3, 5},
+	5: {2, 6},
+	6: {},
+}
+
+// AllowedTransitions returns the statuses a ticket in current may move to.
+// A status the workflow doesn't list allows nothing.
+func (w StatusWorkflow) AllowedTransitions(current int) []int {
+	return w[current]
+}
+
+func (w StatusWorkflow) allows(from, to int) bool {
+	return slices.Contains(w.AllowedTransitions(from), to)
+}
+
+// checkTransition fetches the ticket and returns the fields explaining a
+// refused status change, or nil when the change is allowed. Keeping the
+// current status is always allowed. Tools merge the fields into their own
+// result so the model can offer the allowed statuses.
+func (w StatusWorkflow) checkTransition(g *glpi.Client, session string, ticketID, to int) (map[string]any, error) {
+	ticket, err := g.GetTicket(session, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar chamado: %w", err)
+	}
+	return w.refusal(ticketID, ticket.Status, to), nil
+}
+
+func (w StatusWorkflow) refusal(ticketID, from, to int) map[string]any {
+	if from == to || w.allows(from, to) {
+		return nil
+	}
+	return map[string]any{
+		"mensagem": fmt.Sprintf("O chamado #%d está %s e não pode passar para %s; nenhuma alteração foi feita.",
+			ticketID, ticketStatusLabel(from), ticketStatusLabel(to)),
+		"status_atual":      ticketStatusLabel(from),
+		"status_permitidos": statusLabels(w.AllowedTransitions(from)),
+	}
+}
+
+// ParseStatusWorkflow reads TICKET_STATUS_WORKFLOW, one "from:to,to" group per
+// status separated by ";" (e.g. "1:2,4,5;5:2,6"). Statuses left out allow no
+// change. Empty means DefaultStatusWorkflow.
+func ParseStatusWorkflow(s string) (StatusWorkflow, error) {
+	if strings.TrimSpace(s) == "" {
+		return DefaultStatusWorkflow, nil
+	}
+	status := func(v string) (int, error) {
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || n < 1 || n > 6 {
+			return 0, fmt.Errorf("invalid ticket status %q (use 1-6)", v)
+		}
+		return n, nil
+	}
+	w := StatusWorkflow{}
+	for _, group := range strings.Split(s, ";") {
+		if strings.TrimSpace(group) == "" {
+			continue
+		}
+		fromStr, toList, ok := strings.Cut(group, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid workflow entry %q (use e.g. 1:2,4,5)", group)
+		}
+		from, err := status(fromStr)
+		if err != nil {
+			return nil, err
+		}
+		w[from] = []int{}
+		for _, v := range strings.Split(toList, ",") {
+			if strings.TrimSpace(v) == "" {
+				continue
+			}
+			to, err := status(v)
+			if err != nil {
+				return nil, err
+			}
+			w[from] = append(w[from], to)
+		}
+	}
+	return w, nil
+}
+
+func statusLabels(statuses []int) []string {
+	labels := make([]string, len(statuses))
+	for i, s := range statuses {
+		labels[i] = ticketStatusLabel(s)
+	}
+	return labels
+}
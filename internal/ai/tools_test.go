@@ -0,0 +1,70 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lojasmm/laia/internal/glpi"
+)
+
+type technicianTool struct{ fakeTool }
+
+func (t *technicianTool) AllowsProfile(p glpi.ActiveProfile) bool { return p.Interface == "central" }
+
+func TestRegistryCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile *glpi.ActiveProfile
+		tool    string
+		want    ErrorType
+	}{
+		{"open tool", nil, "get_ticket", ""},
+		{"unknown tool", nil, "get_tickets", ErrValidation},
+		{"restricted without profile", nil, "log_time", ErrPermission},
+		{"restricted for helpdesk", &glpi.ActiveProfile{Interface: "helpdesk"}, "log_time", ErrPermission},
+		{"restricted for technician", &glpi.ActiveProfile{Interface: "central"}, "log_time", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			r.Register(&fakeTool{name: "get_ticket", readOnly: true})
+			r.Register(&technicianTool{fakeTool{name: "log_time"}})
+			if tt.profile != nil {
+				r.SetProfile(*tt.profile)
+			}
+			var got ErrorType
+			if te := r.check(tt.tool); te != nil {
+				got = te.Type
+			}
+			if got != tt.want {
+				t.Errorf("check(%s) = %q, want %q", tt.tool, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnknownToolListsAvailableTools(t *testing.T) {
+	r := NewRegistry()
+	r.Register(&fakeTool{name: "search_kb", readOnly: true})
+	r.Register(&fakeTool{name: "get_ticket", readOnly: true})
+	r.Register(&technicianTool{fakeTool{name: "log_time"}})
+
+	_, err := r.ExecuteTool(context.Background(), "get_tickets", nil)
+	var te *ToolError
+	if !errors.As(err, &te) {
+		t.Fatalf("ExecuteTool(get_tickets) = %v, want a *ToolError", err)
+	}
+	if te.Type != ErrValidation || te.Retryable {
+		t.Errorf("error = %+v, want a non-retryable validation error", te)
+	}
+	// Sorted, and without tools the profile can't call.
+	want := `a ferramenta "get_tickets" não existe. Use uma destas: get_ticket, search_kb`
+	if te.Message != want {
+		t.Errorf("message = %q, want %q", te.Message, want)
+	}
+	result := toolErrorResult(te)
+	if errMap, _ := result["error"].(map[string]any); errMap["message"] != want {
+		t.Errorf("tool result = %v, want the corrective message for the model", result)
+	}
+}
@@ -0,0 +1,77 @@
+package ai
+
+import (
+	"context"
+	"sync"
+)
+
+// ToolTrace collects the tool calls of a dry run.
+type ToolTrace struct {
+	mu    sync.Mutex
+	Calls []TracedCall
+}
+
+type TracedCall struct {
+	Tool   string         `json:"tool"`
+	Args   map[string]any `json:"args"`
+	Result map[string]any `json:"result"`
+}
+
+func (t *ToolTrace) record(tool string, args, result map[string]any) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Calls = append(t.Calls, TracedCall{Tool: tool, Args: args, Result: result})
+}
+
+type dryRunKey struct{}
+
+// WithDryRun marks ctx as a support reproduction: Handle records every tool
+// call in trace and leaves the user's stored history untouched. Sending the
+// reply is up to the caller, so nothing reaches WhatsApp. Read-only tools run
+// normally so the plan is realistic; mutating and side-effecting tools are
+// only previewed.
+func WithDryRun(ctx context.Context, trace *ToolTrace) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, trace)
+}
+
+// SetDryRun previews mutating tools for every conversation (AGENT_DRY_RUN),
+// e.g. to try prompt changes against production Nexus without changing it.
+// Unlike WithDryRun, history is still saved so multi-turn flows work.
+func (a *Agent) SetDryRun(on bool) {
+	a.dryRun = on
+}
+
+// IsDryRun reports whether ctx belongs to a WithDryRun reproduction, for tools
+// that must skip a side effect but still return their result.
+func IsDryRun(ctx context.Context) bool {
+	return dryRunTrace(ctx) != nil
+}
+
+func (a *Agent) previewMutations(ctx context.Context) bool {
+	return a.dryRun || dryRunTrace(ctx) != nil
+}
+
+// preview reports whether the call is replaced by dryRunPreview: mutating
+// tools in any dry run, side-effecting reads only in a support reproduction.
+func (a *Agent) preview(ctx context.Context, registry *Registry, name string) bool {
+	if !registry.IsReadOnly(name) {
+		return a.previewMutations(ctx)
+	}
+	return dryRunTrace(ctx) != nil && registry.HasSideEffects(name)
+}
+
+// dryRunPreview stands in for a mutating tool's result: the model sees what
+// would have been done and can tell the user, but Nexus is never called.
+func dryRunPreview(name string, args map[string]any) map[string]any {
+	return map[string]any{
+		"status":   "dry_run",
+		"mensagem": "Modo simulação: " + name + " NÃO foi executada. Diga ao usuário o que seria feito, com estes dados.",
+		"args":     args,
+	}
+}
+
+// dryRunTrace returns the trace of a dry run, or nil for a normal run.
+func dryRunTrace(ctx context.Context) *ToolTrace {
+	t, _ := ctx.Value(dryRunKey{}).(*ToolTrace)
+	return t
+}
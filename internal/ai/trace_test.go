@@ -0,0 +1,53 @@
+package ai
+
+import (
+	"context"
+	"testing"
+)
+
+type fakeTool struct {
+	name        string
+	readOnly    bool
+	sideEffects bool
+}
+
+func (t *fakeTool) Name() string             { return t.name }
+func (t *fakeTool) Description() string      { return "" }
+func (t *fakeTool) Parameters() *ParamSchema { return &ParamSchema{Type: "object"} }
+func (t *fakeTool) ReadOnly() bool           { return t.readOnly }
+func (t *fakeTool) HasSideEffects() bool     { return t.sideEffects }
+func (t *fakeTool) Execute(context.Context, map[string]any) (map[string]any, error) {
+	return map[string]any{}, nil
+}
+
+func TestPreview(t *testing.T) {
+	r := NewRegistry()
+	r.Register(&fakeTool{name: "read", readOnly: true})
+	r.Register(&fakeTool{name: "guide", readOnly: true, sideEffects: true})
+	r.Register(&fakeTool{name: "write"})
+
+	reproduction := WithDryRun(context.Background(), &ToolTrace{})
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		global bool
+		tool   string
+		want   bool
+	}{
+		{"normal run executes writes", context.Background(), false, "write", false},
+		{"normal run executes side effects", context.Background(), false, "guide", false},
+		{"reproduction runs reads", reproduction, false, "read", false},
+		{"reproduction previews writes", reproduction, false, "write", true},
+		{"reproduction previews side effects", reproduction, false, "guide", true},
+		{"global dry run previews writes", context.Background(), true, "write", true},
+		{"global dry run keeps side effects", context.Background(), true, "guide", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Agent{dryRun: tt.global}
+			if got := a.preview(tt.ctx, r, tt.tool); got != tt.want {
+				t.Errorf("preview(%s) = %v, want %v", tt.tool, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,13 +2,14 @@ package auth
 
 import (
 	"embed"
-	"fmt"
 	"html/template"
-	"log"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/lojasmm/laia/internal/glpi"
+	"github.com/lojasmm/laia/internal/logging"
 	"github.com/lojasmm/laia/internal/store"
 	"github.com/lojasmm/laia/internal/whatsapp"
 )
@@ -22,16 +23,25 @@ type pageData struct {
 	Phone   string
 	Message string
 	Success bool
+	// OAuthURL is the "Entrar com o Nexus" link; empty when OAuth is disabled.
+	OAuthURL string
 }
 
 type Handler struct {
-	glpi  *glpi.Client
-	store store.Store
-	wa    *whatsapp.Client
+	glpi   *glpi.Client
+	store  store.Store
+	wa     *whatsapp.Client
+	states *oauthStates
+	// onboarding is DefaultOnboarding unless SetOnboarding is called.
+	onboarding Onboarding
 }
 
 func NewHandler(g *glpi.Client, s store.Store, wa *whatsapp.Client) *Handler {
-	return &Handler{glpi: g, store: s, wa: wa}
+	h := &Handler{glpi: g, store: s, wa: wa, states: newOAuthStates(), onboarding: DefaultOnboarding}
+	if err := h.onboarding.compile(); err != nil {
+		panic(err) // DefaultOnboarding is a constant; this is a programming error
+	}
+	return h
 }
 
 func (h *Handler) HandleVerifyPage(w http.ResponseWriter, r *http.Request) {
@@ -40,7 +50,15 @@ func (h *Handler) HandleVerifyPage(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "parametro phone obrigatorio", http.StatusBadRequest)
 		return
 	}
-	pageTmpl.Execute(w, pageData{Phone: phone})
+	pageTmpl.Execute(w, h.page(phone, ""))
+}
+
+func (h *Handler) page(phone, message string) pageData {
+	d := pageData{Phone: phone, Message: message}
+	if h.glpi.OAuthEnabled() {
+		d.OAuthURL = "/auth/oauth/start?" + url.Values{"phone": {phone}}.Encode()
+	}
+	return d
 }
 
 func (h *Handler) HandleVerifySubmit(w http.ResponseWriter, r *http.Request) {
@@ -49,35 +67,26 @@ func (h *Handler) HandleVerifySubmit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	phone := r.FormValue("phone")
+	phone := store.PhoneDigits(r.FormValue("phone"))
 	userToken := r.FormValue("user_token")
 
 	if phone == "" || userToken == "" {
-		pageTmpl.Execute(w, pageData{
-			Phone:   phone,
-			Message: "Telefone e token são obrigatórios.",
-		})
+		pageTmpl.Execute(w, h.page(phone, "Telefone e token são obrigatórios."))
 		return
 	}
 
 	sessionToken, err := h.glpi.InitSession(userToken)
 	if err != nil {
-		log.Printf("auth: initSession failed for phone %s: %v", phone, err)
-		pageTmpl.Execute(w, pageData{
-			Phone:   phone,
-			Message: "Token inválido ou erro ao conectar ao Nexus. Verifique e tente novamente.",
-		})
+		slog.Warn("auth: initSession failed", "phone", logging.HashPhone(phone), "error", err)
+		pageTmpl.Execute(w, h.page(phone, "Token inválido ou erro ao conectar ao Nexus. Verifique e tente novamente."))
 		return
 	}
 
 	fullSession, err := h.glpi.GetFullSession(sessionToken)
 	if err != nil {
-		log.Printf("auth: getFullSession failed: %v", err)
+		slog.Error("auth: getFullSession failed", "phone", logging.HashPhone(phone), "error", err)
 		h.glpi.KillSession(sessionToken)
-		pageTmpl.Execute(w, pageData{
-			Phone:   phone,
-			Message: "Erro ao obter dados da sessão. Tente novamente.",
-		})
+		pageTmpl.Execute(w, h.page(phone, "Erro ao obter dados da sessão. Tente novamente."))
 		return
 	}
 
@@ -91,35 +100,11 @@ func (h *Handler) HandleVerifySubmit(w http.ResponseWriter, r *http.Request) {
 		AuthenticatedAt: time.Now(),
 	}
 	if err := h.store.SaveUser(u); err != nil {
-		log.Printf("auth: saveUser failed: %v", err)
-		pageTmpl.Execute(w, pageData{
-			Phone:   phone,
-			Message: "Erro interno ao salvar dados. Tente novamente.",
-		})
+		slog.Error("auth: saveUser failed", "phone", logging.HashPhone(phone), "error", err)
+		pageTmpl.Execute(w, h.page(phone, "Erro interno ao salvar dados. Tente novamente."))
 		return
 	}
 
-	log.Printf("auth: user %s (%d) linked to phone %s", u.Name, u.GLPIUserID, phone)
-
-	body := fmt.Sprintf(
-		"✅ *Pronto, %s!*\n\n"+
-			"Seu WhatsApp foi vinculado ao Nexus com sucesso.\n\n"+
-			"Aqui estão algumas coisas que posso fazer por você:\n\n"+
-			"📋 Abrir e acompanhar chamados\n"+
-			"💬 Adicionar comentários\n"+
-			"🔍 Buscar na base de conhecimento\n\n"+
-			"_É só me mandar uma mensagem!_",
-		u.Name,
-	)
-	buttons := []whatsapp.Button{
-		{Type: "reply", Reply: whatsapp.ButtonReply{ID: "action_new_ticket", Title: "Abrir chamado"}},
-		{Type: "reply", Reply: whatsapp.ButtonReply{ID: "action_my_tickets", Title: "Meus chamados"}},
-	}
-	if err := h.wa.SendInteractiveButtons(phone, body, buttons); err != nil {
-		log.Printf("auth: failed to send welcome message to %s: %v", phone, err)
-	}
-
-	// Redirecionar pro WhatsApp
-	waURL := fmt.Sprintf("https://wa.me/%s", phone)
-	http.Redirect(w, r, waURL, http.StatusSeeOther)
+	slog.Info("auth: user linked", "glpi_user_id", u.GLPIUserID, "phone", logging.HashPhone(phone))
+	h.welcome(w, r, u)
 }
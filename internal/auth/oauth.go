@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lojasmm/laia/internal/logging"
+	"github.com/lojasmm/laia/internal/store"
+)
+
+// oauthStateTTL bounds how long a user has to finish logging in on Nexus.
+const oauthStateTTL = 10 * time.Minute
+
+type pendingLogin struct {
+	phone     string
+	expiresAt time.Time
+}
+
+// oauthStates maps the OAuth "state" parameter to the phone that started the
+// login, so the callback knows which WhatsApp number to link (and CSRF is
+// prevented). Kept in memory: a restart only forces the user to click again.
+type oauthStates struct {
+	mu      sync.Mutex
+	pending map[string]pendingLogin
+}
+
+func newOAuthStates() *oauthStates {
+	return &oauthStates{pending: make(map[string]pendingLogin)}
+}
+
+func (s *oauthStates) create(phone string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	state := hex.EncodeToString(b)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for k, p := range s.pending {
+		if now.After(p.expiresAt) {
+			delete(s.pending, k)
+		}
+	}
+	s.pending[state] = pendingLogin{phone: phone, expiresAt: now.Add(oauthStateTTL)}
+	return state, nil
+}
+
+// consume returns the phone for state and forgets it; states are single-use.
+func (s *oauthStates) consume(state string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.pending[state]
+	delete(s.pending, state)
+	if !ok || time.Now().After(p.expiresAt) {
+		return "", false
+	}
+	return p.phone, true
+}
+
+// HandleOAuthStart redirects to the Nexus login page.
+func (h *Handler) HandleOAuthStart(w http.ResponseWriter, r *http.Request) {
+	phone := store.PhoneDigits(r.URL.Query().Get("phone"))
+	if phone == "" || !h.glpi.OAuthEnabled() {
+		http.Error(w, "parametro phone obrigatorio", http.StatusBadRequest)
+		return
+	}
+	state, err := h.states.create(phone)
+	if err != nil {
+		http.Error(w, "erro interno", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, h.glpi.OAuthAuthorizeURL(state), http.StatusFound)
+}
+
+// HandleOAuthCallback finishes the authorization-code flow and links the user.
+// Reference: RFC 6749 §4.1.2 — https://datatracker.ietf.org/doc/html/rfc6749#section-4.1.2
+func (h *Handler) HandleOAuthCallback(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	phone, ok := h.states.consume(q.Get("state"))
+	if !ok {
+		http.Error(w, "link expirado, solicite um novo pelo WhatsApp", http.StatusBadRequest)
+		return
+	}
+	if errCode := q.Get("error"); errCode != "" {
+		slog.Warn("auth: oauth denied", "phone", logging.HashPhone(phone), "error", errCode)
+		pageTmpl.Execute(w, h.page(phone, "Login no Nexus cancelado. Tente novamente."))
+		return
+	}
+
+	tok, err := h.glpi.ExchangeOAuthCode(q.Get("code"))
+	if err != nil {
+		slog.Warn("auth: oauth code exchange failed", "phone", logging.HashPhone(phone), "error", err)
+		pageTmpl.Execute(w, h.page(phone, "Não foi possível concluir o login no Nexus. Tente novamente."))
+		return
+	}
+
+	sessionToken, err := h.glpi.InitSessionWithAccessToken(tok.AccessToken)
+	if err != nil {
+		slog.Warn("auth: initSession with oauth failed", "phone", logging.HashPhone(phone), "error", err)
+		pageTmpl.Execute(w, h.page(phone, "Erro ao conectar ao Nexus. Tente novamente ou use seu token."))
+		return
+	}
+	fullSession, err := h.glpi.GetFullSession(sessionToken)
+	h.glpi.KillSession(sessionToken)
+	if err != nil {
+		slog.Error("auth: getFullSession failed", "phone", logging.HashPhone(phone), "error", err)
+		pageTmpl.Execute(w, h.page(phone, "Erro ao obter dados da sessão. Tente novamente."))
+		return
+	}
+
+	u := store.User{
+		Phone:             phone,
+		OAuthRefreshToken: tok.RefreshToken,
+		GLPIUserID:        fullSession.Session.GlpiID,
+		Name:              fullSession.Session.GlpiFriendlyName,
+		AuthenticatedAt:   time.Now(),
+	}
+	if err := h.store.SaveUser(u); err != nil {
+		slog.Error("auth: saveUser failed", "phone", logging.HashPhone(phone), "error", err)
+		pageTmpl.Execute(w, h.page(phone, "Erro interno ao salvar dados. Tente novamente."))
+		return
+	}
+
+	slog.Info("auth: user linked via oauth", "glpi_user_id", u.GLPIUserID, "phone", logging.HashPhone(phone))
+	h.welcome(w, r, u)
+}
@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/lojasmm/laia/internal/logging"
+	"github.com/lojasmm/laia/internal/store"
+	"github.com/lojasmm/laia/internal/whatsapp"
+)
+
+// Onboarding is the capability tour sent the first time a phone is linked.
+// Message and Relinked are text/templates over the linked store.User
+// (e.g. {{.Name}}); each button reply reaches the agent as a user message
+// with the button title as text.
+type Onboarding struct {
+	Message string             `json:"message"`
+	Buttons []OnboardingButton `json:"buttons"`
+	// Relinked replaces the tour when an already onboarded phone links again.
+	Relinked string `json:"relinked"`
+
+	message  *texttemplate.Template
+	relinked *texttemplate.Template
+}
+
+type OnboardingButton struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+var DefaultOnboarding = Onboarding{
+	Message: "✅ *Pronto, {{.Name}}!*\n\n" +
+		"Seu WhatsApp foi vinculado ao Nexus com sucesso.\n\n" +
+		"Aqui estão algumas coisas que posso fazer por você:\n\n" +
+		"📋 Abrir e acompanhar chamados\n" +
+		"💬 Adicionar comentários\n" +
+		"🔍 Buscar na base de conhecimento\n\n" +
+		"_Experimente um dos botões abaixo ou me mande uma mensagem!_",
+	Buttons: []OnboardingButton{
+		{ID: "action_my_tickets", Title: "Meus chamados"},
+		{ID: "action_new_ticket", Title: "Abrir chamado"},
+		{ID: "action_knowledge_base", Title: "Base de conhecimento"},
+	},
+	Relinked: "✅ *Pronto, {{.Name}}!* Seu WhatsApp foi vinculado novamente ao Nexus. É só me mandar uma mensagem!",
+}
+
+// LoadOnboarding reads ONBOARDING_FILE. An empty path, or fields left out of
+// the file, fall back to DefaultOnboarding.
+func LoadOnboarding(path string) (Onboarding, error) {
+	o := DefaultOnboarding
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Onboarding{}, err
+		}
+		var file Onboarding
+		if err := json.Unmarshal(data, &file); err != nil {
+			return Onboarding{}, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		if file.Message != "" {
+			o.Message = file.Message
+		}
+		if file.Buttons != nil {
+			o.Buttons = file.Buttons
+		}
+		if file.Relinked != "" {
+			o.Relinked = file.Relinked
+		}
+	}
+	if err := o.compile(); err != nil {
+		return Onboarding{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return o, nil
+}
+
+// compile parses the templates and checks the WhatsApp reply button limits:
+// at most 3 buttons, titles up to 20 characters.
+// Reference: https://developers.facebook.com/docs/whatsapp/cloud-api/messages/interactive-reply-buttons-messages
+func (o *Onboarding) compile() error {
+	if len(o.Buttons) > 3 {
+		return fmt.Errorf("onboarding allows at most 3 buttons, got %d", len(o.Buttons))
+	}
+	for i, b := range o.Buttons {
+		if b.ID == "" || b.Title == "" {
+			return fmt.Errorf("onboarding button %d needs id and title", i)
+		}
+		if len([]rune(b.Title)) > 20 {
+			return fmt.Errorf("onboarding button %q: title longer than 20 characters", b.Title)
+		}
+	}
+	var err error
+	if o.message, err = texttemplate.New("message").Parse(o.Message); err != nil {
+		return fmt.Errorf("onboarding message: %w", err)
+	}
+	if o.relinked, err = texttemplate.New("relinked").Parse(o.Relinked); err != nil {
+		return fmt.Errorf("onboarding relinked: %w", err)
+	}
+	return nil
+}
+
+func render(t *texttemplate.Template, u store.User) (string, error) {
+	var b strings.Builder
+	if err := t.Execute(&b, u); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// SetOnboarding replaces DefaultOnboarding; o must come from LoadOnboarding.
+func (h *Handler) SetOnboarding(o Onboarding) {
+	h.onboarding = o
+}
+
+// welcome greets a freshly linked user on WhatsApp and sends the browser back
+// there. The tour with buttons is sent once per phone; later re-links (e.g.
+// after an expired token) only get a short confirmation.
+func (h *Handler) welcome(w http.ResponseWriter, r *http.Request, u store.User) {
+	onboarded, err := h.store.IsOnboarded(u.Phone)
+	if err != nil {
+		slog.Warn("auth: failed to read onboarded flag", "phone", logging.HashPhone(u.Phone), "error", err)
+	}
+
+	if onboarded {
+		if body, err := render(h.onboarding.relinked, u); err != nil {
+			slog.Error("auth: failed to render relinked message", "phone", logging.HashPhone(u.Phone), "error", err)
+		} else if err := h.wa.SendText(u.Phone, body); err != nil {
+			slog.Error("auth: failed to send welcome message", "phone", logging.HashPhone(u.Phone), "error", err)
+		}
+	} else {
+		h.sendOnboarding(u)
+	}
+
+	// Redirecionar pro WhatsApp
+	waURL := fmt.Sprintf("https://wa.me/%s", u.Phone)
+	http.Redirect(w, r, waURL, http.StatusSeeOther)
+}
+
+func (h *Handler) sendOnboarding(u store.User) {
+	body, err := render(h.onboarding.message, u)
+	if err != nil {
+		slog.Error("auth: failed to render onboarding message", "phone", logging.HashPhone(u.Phone), "error", err)
+		return
+	}
+
+	if len(h.onboarding.Buttons) == 0 {
+		err = h.wa.SendText(u.Phone, body)
+	} else {
+		buttons := make([]whatsapp.Button, len(h.onboarding.Buttons))
+		for i, b := range h.onboarding.Buttons {
+			buttons[i] = whatsapp.Button{Type: "reply", Reply: whatsapp.ButtonReply{ID: b.ID, Title: b.Title}}
+		}
+		err = h.wa.SendInteractiveButtons(u.Phone, body, buttons)
+	}
+	if err != nil {
+		slog.Error("auth: failed to send welcome message", "phone", logging.HashPhone(u.Phone), "error", err)
+		return
+	}
+
+	// Only mark after a successful send, so a failed tour is retried on the next link.
+	if err := h.store.MarkOnboarded(u.Phone); err != nil {
+		slog.Warn("auth: failed to save onboarded flag", "phone", logging.HashPhone(u.Phone), "error", err)
+	}
+}
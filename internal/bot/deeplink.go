@@ -0,0 +1,70 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// deepLinkPrefix starts the prefilled message of store posters' QR codes, e.g.
+//
+//	https://wa.me/5547999999999?text=laia:chamado%20d=3%20c=42%20Impressora%20andar%202
+//
+// which the user sends as "laia:chamado d=3 c=42 Impressora andar 2":
+// d = department (form) ID, c = ITIL category ID, the rest is the title.
+const deepLinkPrefix = "laia:chamado"
+
+type deepLink struct {
+	DepartmentID int
+	CategoryID   int
+	Title        string
+}
+
+// parseDeepLink decodes a QR deep-link message. It returns nil, nil when text
+// isn't a deep link, and an error when it is one but can't be used.
+func parseDeepLink(text string) (*deepLink, error) {
+	text = strings.TrimSpace(text)
+	if len(text) < len(deepLinkPrefix) || !strings.EqualFold(text[:len(deepLinkPrefix)], deepLinkPrefix) {
+		return nil, nil
+	}
+	fields := strings.Fields(text[len(deepLinkPrefix):])
+
+	var link deepLink
+	i := 0
+	for ; i < len(fields); i++ {
+		key, value, ok := strings.Cut(fields[i], "=")
+		if !ok {
+			break // first token without "=" starts the title
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid %s=%q", key, value)
+		}
+		switch strings.ToLower(key) {
+		case "d":
+			link.DepartmentID = n
+		case "c":
+			link.CategoryID = n
+		default:
+			return nil, fmt.Errorf("unknown key %q", key)
+		}
+	}
+	link.Title = strings.Join(fields[i:], " ")
+
+	if link.DepartmentID == 0 || link.CategoryID == 0 {
+		return nil, fmt.Errorf("missing d= or c=")
+	}
+	if link.Title == "" {
+		return nil, fmt.Errorf("missing title")
+	}
+	return &link, nil
+}
+
+// agentPrompt rewrites the deep link as the user's request so the agent skips
+// straight to describing the problem, with department and category settled.
+func (l *deepLink) agentPrompt() string {
+	return fmt.Sprintf("Quero abrir um chamado: %s.\n"+
+		"(Enviado por QR code: department_id=%d e category_id=%d já definidos — "+
+		"pule as etapas de setor e categoria, pergunte só os detalhes do problema e siga para a confirmação.)",
+		l.Title, l.DepartmentID, l.CategoryID)
+}
@@ -2,34 +2,84 @@ package bot
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"strings"
+	"time"
 
 	"github.com/lojasmm/laia/internal/ai"
+	aitools "github.com/lojasmm/laia/internal/ai/tools"
+	"github.com/lojasmm/laia/internal/glpi"
+	"github.com/lojasmm/laia/internal/logging"
 	"github.com/lojasmm/laia/internal/session"
 	"github.com/lojasmm/laia/internal/store"
 	"github.com/lojasmm/laia/internal/whatsapp"
 )
 
+const (
+	// defaultMaxInboundChars caps a typed message; longer text would crowd
+	// out the history budget and GLPI's content limits.
+	defaultMaxInboundChars = 4000
+	// defaultMaxInboundLogChars caps a pasted log or stack trace instead. Logs
+	// get more room because create_ticket attaches them whole as a .txt
+	// rather than putting them in the description.
+	defaultMaxInboundLogChars = 12000
+	// lastMessageResolution is how stale the stored last message time may
+	// get. It only errs towards a closed 24h window, where reminders go out
+	// as a template instead of free text.
+	lastMessageResolution = 5 * time.Minute
+)
+
 type Handler struct {
 	wa         *whatsapp.Client
 	store      store.Store
 	authURL    string
 	agent      *ai.Agent
 	sessionMgr *session.Manager
+
+	maxInboundChars    int
+	maxInboundLogChars int
+	interim            bool
 }
 
 func NewHandler(wa *whatsapp.Client, s store.Store, authURL string, agent *ai.Agent, sm *session.Manager) *Handler {
-	return &Handler{wa: wa, store: s, authURL: authURL, agent: agent, sessionMgr: sm}
+	return &Handler{
+		wa: wa, store: s, authURL: authURL, agent: agent, sessionMgr: sm,
+		maxInboundChars: defaultMaxInboundChars, maxInboundLogChars: defaultMaxInboundLogChars,
+	}
 }
 
-func (h *Handler) HandleMessage(phone, messageID, text string) {
-	// Per-user lock prevents race conditions from concurrent messages
-	err := h.sessionMgr.WithLock(phone, func() error {
+// SetMaxInboundChars overrides defaultMaxInboundChars; n <= 0 keeps the default.
+func (h *Handler) SetMaxInboundChars(n int) {
+	if n > 0 {
+		h.maxInboundChars = n
+	}
+}
+
+// SetMaxInboundLogChars overrides defaultMaxInboundLogChars; n <= 0 keeps the default.
+func (h *Handler) SetMaxInboundLogChars(n int) {
+	if n > 0 {
+		h.maxInboundLogChars = n
+	}
+}
+
+// SetInterimMessages sends the model's text that comes with tool calls as its
+// own message while the tools run (AGENT_INTERIM_MESSAGES).
+func (h *Handler) SetInterimMessages(on bool) {
+	h.interim = on
+}
+
+func (h *Handler) HandleMessage(phone, messageID, text, replyID string) {
+	logger := logging.ForRequest(phone)
+	ctx := logging.WithLogger(context.Background(), logger)
+
+	// Per-user lock prevents race conditions from concurrent messages. Keyed
+	// like the store, so both spellings of one number share it.
+	err := h.sessionMgr.WithLock(store.NormalizePhone(phone), func() error {
 		user, err := h.store.GetUser(phone)
 		if err != nil {
-			log.Printf("bot: store error for %s: %v", phone, err)
+			logger.Error("bot: store error", "error", err)
 			return nil
 		}
 
@@ -38,16 +88,25 @@ func (h *Handler) HandleMessage(phone, messageID, text string) {
 			return nil
 		}
 
-		h.handleCommand(user, phone, messageID, text)
+		// Track the 24h customer service window for proactive messages
+		// (reminders). A burst of messages doesn't need a write each.
+		if now := time.Now(); now.Sub(user.LastMessageAt) >= lastMessageResolution {
+			user.LastMessageAt = now
+			if err := h.store.SaveUser(*user); err != nil {
+				logger.Warn("bot: failed to update last message time", "error", err)
+			}
+		}
+
+		h.handleCommand(ctx, user, phone, messageID, text, replyID)
 		return nil
 	})
 	if err != nil {
-		log.Printf("bot: session lock error for %s: %v", phone, err)
+		logger.Error("bot: session lock error", "error", err)
 	}
 }
 
 func (h *Handler) sendVerificationLink(phone string) {
-	link := fmt.Sprintf("%s/auth/verify?phone=%s", h.authURL, phone)
+	link := fmt.Sprintf("%s/auth/verify?phone=%s", h.authURL, store.PhoneDigits(phone))
 	body := "Olá! Eu sou a *Laia*, sua assistente virtual do *Nexus* aqui nas Lojas MM.\n\n" +
 		"Comigo você pode:\n" +
 		"• Abrir e acompanhar chamados\n" +
@@ -57,20 +116,111 @@ func (h *Handler) sendVerificationLink(phone string) {
 		"É rápido — basta clicar no botão abaixo!"
 
 	if err := h.wa.SendCTAButton(phone, body, "Vincular conta", link); err != nil {
-		log.Printf("bot: failed to send verification link to %s: %v", phone, err)
+		slog.Error("bot: failed to send verification link", "phone", logging.HashPhone(phone), "error", err)
+	}
+}
+
+// maxAuthFailures is how many failed re-links in a row (within 24h) before we
+// stop assuming an expired token and point the user to IT support instead.
+const maxAuthFailures = 3
+
+// handleAuthFailure unlinks the user and asks them to reconnect. Repeated
+// failures usually mean a disabled account or a token revoked by Nexus, which
+// a new link won't fix.
+func (h *Handler) handleAuthFailure(ctx context.Context, phone string) {
+	logger := logging.FromContext(ctx)
+	failures, err := h.store.RecordAuthFailure(phone)
+	if err != nil {
+		logger.Warn("bot: failed to record auth failure", "error", err)
+	}
+	h.store.DeleteUser(phone)
+
+	if failures >= maxAuthFailures {
+		logger.Error("bot: repeated auth failures", "failures", failures)
+		h.wa.SendText(phone, "Não consegui validar seu acesso ao Nexus após várias tentativas. "+
+			"Sua conta pode estar desativada ou sem permissão de API — procure o suporte de TI. "+
+			"Depois disso, use o link abaixo para vincular novamente.")
+	} else {
+		h.wa.SendText(phone, "Sua sessão com o Nexus expirou. Vou enviar um novo link para reconectar sua conta.")
 	}
+	h.sendVerificationLink(phone)
 }
 
-func (h *Handler) handleCommand(user *store.User, phone, messageID, text string) {
+func (h *Handler) handleCommand(ctx context.Context, user *store.User, phone, messageID, text, replyID string) {
+	logger := logging.FromContext(ctx)
+
+	// Blank text or unsupported media: answer without spending a model call.
+	if strings.TrimSpace(text) == "" && replyID == "" {
+		h.wa.SendText(phone, "Não entendi, pode escrever sua dúvida? Por enquanto só consigo ler mensagens de texto.")
+		return
+	}
+
+	if n := len([]rune(text)); n > h.maxInboundChars {
+		if !aitools.LooksLikeLog(text) {
+			logger.Info("bot: inbound text over limit", "chars", n, "max_chars", h.maxInboundChars)
+			h.wa.SendText(phone, fmt.Sprintf("Sua mensagem ficou muito longa (%d caracteres; o limite é %d). "+
+				"Pode resumir o problema em poucas linhas?", n, h.maxInboundChars))
+			return
+		}
+		if n > h.maxInboundLogChars {
+			logger.Info("bot: inbound log over limit", "chars", n, "max_chars", h.maxInboundLogChars)
+			h.wa.SendText(phone, fmt.Sprintf("Esse log ficou muito longo (%d caracteres; o limite para logs é %d). "+
+				"Mande só o trecho com o erro — as primeiras linhas do erro e do stack trace costumam bastar — "+
+				"que eu anexo ao chamado.", n, h.maxInboundLogChars))
+			return
+		}
+	}
+
+	link, err := parseDeepLink(text)
+	if err != nil {
+		logger.Warn("bot: malformed deep link", "error", err)
+		h.wa.SendText(phone, "Não consegui ler este QR code. Me conte qual é o problema que eu abro o chamado para você.")
+		return
+	}
+	if link != nil {
+		text = link.agentPrompt()
+	}
+
 	// Hourglass reaction: signal to user that we're processing
 	if messageID != "" {
 		if err := h.wa.ReactMessage(phone, messageID, "⏳"); err != nil {
-			log.Printf("bot: failed to send hourglass reaction: %v", err)
+			logger.Warn("bot: failed to send hourglass reaction", "error", err)
 		}
 	}
 
-	ctx := context.Background()
-	resp, err := h.agent.Handle(ctx, user, phone, text)
+	if h.interim {
+		ctx = ai.WithInterim(ctx, func(text string) {
+			if err := h.wa.SendText(phone, text); err != nil {
+				logger.Warn("bot: failed to send interim message", "error", err)
+			}
+		})
+	}
+
+	start := time.Now()
+	if replyID == ai.NewTopicReplyID || strings.EqualFold(strings.TrimSpace(text), "novo assunto") {
+		if err := h.agent.ClearHistory(phone); err != nil {
+			logger.Error("bot: failed to clear history", "error", err)
+		}
+		if messageID != "" {
+			h.wa.ReactMessage(phone, messageID, "")
+		}
+		h.wa.SendText(phone, "Pronto! Comecei uma conversa nova. Como posso ajudar?")
+		return
+	}
+
+	if prompt, ok := ai.HelpPrompt(replyID); ok {
+		text = prompt
+	}
+
+	var resp *ai.Response
+	if status, ok := ai.TicketFilterFromReply(replyID); ok {
+		resp, err = h.agent.HandleTicketFilter(ctx, user, phone, status)
+	} else if replyID == "" && ai.IsHelpRequest(text) {
+		resp, err = h.agent.HandleHelp(ctx, user, phone)
+	} else {
+		resp, err = h.agent.Handle(ctx, user, phone, text, replyID != "")
+	}
+	logger.Info("bot: message handled", "latency_ms", time.Since(start).Milliseconds(), "ok", err == nil)
 
 	// Remove hourglass reaction after processing
 	if messageID != "" {
@@ -79,17 +229,17 @@ func (h *Handler) handleCommand(user *store.User, phone, messageID, text string)
 	}
 
 	if err != nil {
-		log.Printf("bot: agent error for %s: %v", phone, err)
+		logger.Error("bot: agent error", "error", err)
 		errMsg := err.Error()
 		switch {
 		case strings.Contains(errMsg, "auth_error"):
-			h.wa.SendText(phone, "Sua sessão com o Nexus expirou. Vou enviar um novo link para reconectar sua conta.")
-			h.store.DeleteUser(phone)
-			h.sendVerificationLink(phone)
+			h.handleAuthFailure(ctx, phone)
+		case errors.Is(err, glpi.ErrMaintenance):
+			h.wa.SendText(phone, "O Nexus está em manutenção no momento. Tente novamente mais tarde.")
 		case strings.Contains(errMsg, "initSession"):
 			h.wa.SendText(phone, "O Nexus pode estar em manutenção no momento. Tente novamente em alguns minutos.")
 		case strings.Contains(errMsg, "context"):
-			h.store.ClearHistory(phone)
+			h.agent.ClearHistory(phone)
 			h.wa.SendText(phone, "Nossa conversa ficou muito longa. Comece uma nova pergunta, por favor.")
 		default:
 			h.wa.SendText(phone, "Desculpe, ocorreu um erro ao processar sua mensagem. Tente novamente mais tarde.")
@@ -108,20 +258,52 @@ func (h *Handler) handleCommand(user *store.User, phone, messageID, text string)
 	}
 
 	if sendErr != nil {
-		log.Printf("bot: failed to send reply to %s: %v", phone, sendErr)
+		logger.Error("bot: failed to send reply", "error", sendErr)
+	}
+	h.sendImages(ctx, phone, resp.Images)
+	h.sendDocuments(ctx, phone, resp.Documents)
+}
+
+// sendImages delivers the images tools attached to the reply. A failed image
+// is logged and skipped; the text reply already went out.
+func (h *Handler) sendImages(ctx context.Context, phone string, images []ai.Image) {
+	logger := logging.FromContext(ctx)
+	for _, img := range images {
+		mediaID, err := h.wa.UploadMedia(img.Data, img.MIMEType, img.Filename)
+		if err != nil {
+			logger.Error("bot: failed to upload image", "file", img.Filename, "error", err)
+			continue
+		}
+		if err := h.wa.SendImage(phone, mediaID, img.Caption); err != nil {
+			logger.Error("bot: failed to send image", "file", img.Filename, "error", err)
+		}
+	}
+}
+
+// sendDocuments delivers the documents tools attached, like sendImages.
+func (h *Handler) sendDocuments(ctx context.Context, phone string, docs []ai.Document) {
+	logger := logging.FromContext(ctx)
+	for _, doc := range docs {
+		mediaID, err := h.wa.UploadMedia(doc.Data, doc.MIMEType, doc.Filename)
+		if err != nil {
+			logger.Error("bot: failed to upload document", "file", doc.Filename, "error", err)
+			continue
+		}
+		if err := h.wa.SendDocument(phone, mediaID, doc.Filename, doc.Caption); err != nil {
+			logger.Error("bot: failed to send document", "file", doc.Filename, "error", err)
+		}
 	}
 }
 
 func toWAButtons(buttons []ai.ButtonOption) []whatsapp.Button {
-	// WhatsApp allows max 3 buttons
-	if len(buttons) > 3 {
-		buttons = buttons[:3]
+	if len(buttons) > ai.MaxButtons {
+		buttons = buttons[:ai.MaxButtons]
 	}
 	wa := make([]whatsapp.Button, len(buttons))
 	for i, b := range buttons {
 		wa[i] = whatsapp.Button{
 			Type:  "reply",
-			Reply: whatsapp.ButtonReply{ID: truncate(b.ID, 256), Title: truncate(b.Title, 20)},
+			Reply: whatsapp.ButtonReply{ID: truncate(b.ID, 256), Title: truncate(b.Title, ai.MaxButtonTitleLen)},
 		}
 	}
 	return wa
@@ -134,7 +316,7 @@ func toWASections(sections []ai.ListSection) []whatsapp.Section {
 		for j, r := range s.Rows {
 			rows[j] = whatsapp.SectionRow{
 				ID:          truncate(r.ID, 200),
-				Title:       truncate(r.Title, 24),
+				Title:       truncate(r.Title, ai.MaxRowTitleLen),
 				Description: truncate(r.Description, 72),
 			}
 		}
@@ -0,0 +1,109 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/lojasmm/laia/internal/session"
+	"github.com/lojasmm/laia/internal/store"
+	"github.com/lojasmm/laia/internal/whatsapp"
+)
+
+// outbox records the messages sent through a WhatsApp client.
+type outbox struct {
+	mu   sync.Mutex
+	sent []whatsapp.SendMessageRequest
+}
+
+func (o *outbox) texts() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var out []string
+	for _, m := range o.sent {
+		if m.Text != nil {
+			out = append(out, m.Text.Body)
+		}
+	}
+	return out
+}
+
+// newTestHandler returns a Handler with a real store and a WhatsApp client
+// whose messages land in the returned outbox. It has no agent: tests only
+// reach paths that must not call the model.
+func newTestHandler(t *testing.T) (*Handler, *outbox, store.Store) {
+	t.Helper()
+	box := &outbox{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg whatsapp.SendMessageRequest
+		json.NewDecoder(r.Body).Decode(&msg)
+		box.mu.Lock()
+		box.sent = append(box.sent, msg)
+		box.mu.Unlock()
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+	wa := whatsapp.NewClient("123", "token")
+	wa.SetAPIURL(srv.URL)
+
+	s, err := store.NewBoltStore(filepath.Join(t.TempDir(), "laia.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return NewHandler(wa, s, "https://laia.test", nil, session.NewManager()), box, s
+}
+
+func TestHandleAuthFailureEscalates(t *testing.T) {
+	h, box, s := newTestHandler(t)
+	const phone = "5511987654321"
+
+	for i := 1; i <= maxAuthFailures; i++ {
+		if err := s.SaveUser(store.User{Phone: phone, GLPIUserID: 7}); err != nil {
+			t.Fatal(err)
+		}
+		h.handleAuthFailure(context.Background(), phone)
+
+		if u, _ := s.GetUser(phone); u != nil {
+			t.Fatalf("failure %d: user still linked", i)
+		}
+		texts := box.texts()
+		if len(texts) != i {
+			t.Fatalf("failure %d: %d notices sent", i, len(texts))
+		}
+		// The verification link that follows is a CTA button, not text.
+		notice := texts[len(texts)-1]
+		escalated := strings.Contains(notice, "várias tentativas")
+		if want := i >= maxAuthFailures; escalated != want {
+			t.Errorf("failure %d: escalated = %v, want %v (%q)", i, escalated, want, notice)
+		}
+	}
+
+	// A session that works resets the count, so the next failure is a plain expiry again.
+	if err := s.ResetAuthFailures(phone); err != nil {
+		t.Fatal(err)
+	}
+	h.handleAuthFailure(context.Background(), phone)
+	texts := box.texts()
+	if notice := texts[len(texts)-1]; strings.Contains(notice, "várias tentativas") {
+		t.Errorf("after reset: escalated notice %q", notice)
+	}
+}
+
+func TestHandleCommandBlankText(t *testing.T) {
+	for _, text := range []string{"", "   ", "\n\t"} {
+		h, box, _ := newTestHandler(t)
+		// h has no agent, so reaching the model would panic.
+		h.handleCommand(context.Background(), &store.User{Phone: "5511987654321"}, "5511987654321", "m1", text, "")
+
+		texts := box.texts()
+		if len(texts) != 1 || !strings.HasPrefix(texts[0], "Não entendi") {
+			t.Errorf("text %q: sent %q, want the not-understood reply", text, texts)
+		}
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -15,16 +17,119 @@ type Config struct {
 	NexusAppToken     string
 	NexusAdminToken   string
 	NexusAdminProfile int
+	// Optional per-operation overrides of the admin token/profile
+	// (NEXUS_ADMIN_READ_*, NEXUS_ADMIN_CREATE_*); unset values use NEXUS_ADMIN_*.
+	NexusAdminReadToken     string
+	NexusAdminReadProfile   int
+	NexusAdminCreateToken   string
+	NexusAdminCreateProfile int
+	// GLPI request timeouts (NEXUS_HTTP_TIMEOUT, NEXUS_SEARCH_TIMEOUT for /search/
+	// endpoints); 0 keeps glpi.DefaultTimeouts.
+	NexusHTTPTimeout   time.Duration
+	NexusSearchTimeout time.Duration
+
+	// OAuth2 login (optional). When NexusOAuthClientID is set users log in with
+	// their Nexus credentials instead of pasting a user_token.
+	NexusOAuthClientID     string
+	NexusOAuthClientSecret string
+	NexusOAuthRedirectURL  string
+	// TokenEncryptionKey encrypts stored refresh tokens (TOKEN_ENCRYPTION_KEY, 64 hex chars).
+	TokenEncryptionKey []byte
 
 	WAPhoneNumberID string
 	WAAccessToken   string
 	WAVerifyToken   string
+	// WAReminderTemplate is the approved template used for reminders outside the 24h window.
+	WAReminderTemplate string
 
 	OpenAIAPIKey string
 
+	// History caps per user (HISTORY_MAX_TURNS, HISTORY_MAX_TOKENS) and how many
+	// recent turns keep full tool results (HISTORY_KEEP_RECENT); 0 keeps the store defaults.
+	HistoryMaxTurns   int
+	HistoryMaxTokens  int
+	HistoryKeepRecent int
+
+	// MaxInboundChars rejects longer user messages (MAX_INBOUND_CHARS), and
+	// MaxInboundLogChars longer pasted logs (MAX_INBOUND_LOG_CHARS); 0 keeps the default.
+	MaxInboundChars    int
+	MaxInboundLogChars int
+
+	// Retries for retryable tool errors (TOOL_MAX_RETRIES, TOOL_RETRY_BACKOFF e.g. "2s").
+	ToolMaxRetries   int
+	ToolRetryBackoff time.Duration
+	// ToolNonRetryableErrors are error substrings never retried, even as a 5xx
+	// (TOOL_NON_RETRYABLE_ERRORS, separated by ";" since GLPI messages have commas).
+	ToolNonRetryableErrors []string
+
+	// MaxParallelTools caps concurrent read-only tool calls (TOOL_MAX_PARALLEL).
+	MaxParallelTools int
+
+	// DailyTokenBudget caps model tokens per phone per day (DAILY_TOKEN_BUDGET); 0 disables it.
+	DailyTokenBudget int
+
+	// ConfirmLevel is which mutating tools require a confirmed user turn (CONFIRM_LEVEL: none, create, all).
+	ConfirmLevel string
+
+	// Repeated tool call limits (DOOM_LOOP_EXACT_THRESHOLD aborts, DOOM_LOOP_NAME_THRESHOLD nudges); 0 keeps the defaults.
+	DoomLoopExactThreshold int
+	DoomLoopNameThreshold  int
+
+	// AttachTranscript appends the WhatsApp conversation to new tickets (TICKET_ATTACH_TRANSCRIPT=true).
+	AttachTranscript bool
+	// TranslateTickets enables the translate_ticket tool (TICKET_TRANSLATION=true).
+	TranslateTickets bool
+	// RoutingHintsFile is a JSON file of keyword → department/category rules (ROUTING_HINTS_FILE).
+	RoutingHintsFile string
+	// UrgencyHintsFile is a JSON file of keyword → urgency rules replacing the
+	// built-in ones (URGENCY_HINTS_FILE).
+	UrgencyHintsFile string
+	// BranchesFile is a JSON list of stores and their GLPI locations (BRANCHES_FILE).
+	BranchesFile string
+	// ManagersFile is a JSON list of department managers notify_manager can
+	// message (MANAGERS_FILE).
+	ManagersFile string
+	// CustomFieldsFile maps create_ticket answers to GLPI plugin Fields fields (CUSTOM_FIELDS_FILE).
+	CustomFieldsFile string
+	// Human handoff target (HANDOFF_CATEGORY_ID, HANDOFF_GROUP_ID) and the team's
+	// weekday shift, e.g. "8-18" (HANDOFF_HOURS); no category disables human_handoff.
+	HandoffCategoryID int
+	HandoffGroupID    int
+	HandoffHours      string
+	// DefaultUrgency (1-5) is used when create_ticket gets no urgency
+	// (TICKET_DEFAULT_URGENCY); 0 leaves GLPI's default. UrgencySkipCategories
+	// lists categories whose tickets don't ask the user for urgency
+	// (TICKET_URGENCY_SKIP_CATEGORIES, comma-separated IDs).
+	DefaultUrgency        int
+	UrgencySkipCategories []int
+	// StatusWorkflow restricts ticket status changes made by the tools, e.g. "1:2,4,5;5:2,6"
+	// (TICKET_STATUS_WORKFLOW); empty uses GLPI's lifecycle.
+	StatusWorkflow string
+
+	// InterimMessages sends the model's text that comes with tool calls
+	// before running them (AGENT_INTERIM_MESSAGES=true).
+	InterimMessages bool
+
+	// DryRun previews mutating tools instead of running them (AGENT_DRY_RUN=true).
+	DryRun bool
+
+	// OnboardingFile customizes the tour sent to newly linked users (ONBOARDING_FILE).
+	OnboardingFile string
+
+	// AdminAPIToken enables the support endpoints under /admin (ADMIN_API_TOKEN).
+	AdminAPIToken string
+	// AdminAlertPhone receives WhatsApp alerts about Nexus misconfiguration
+	// users run into, e.g. forms without a category question (ADMIN_ALERT_PHONE).
+	AdminAlertPhone string
+
 	BaseURL string
 	Port    string
 	DataDir string
+	// LogFormat selects the slog handler: "json" in production, "text" otherwise.
+	LogFormat string
+	// LogPhoneKey keys the phone hashes in logs (LOG_PHONE_KEY); empty uses a
+	// random key per run, so hashes can't be correlated across restarts.
+	LogPhoneKey []byte
 }
 
 func Load() (*Config, error) {
@@ -32,19 +137,88 @@ func Load() (*Config, error) {
 	_ = godotenv.Load()
 
 	cfg := &Config{
-		NexusBaseURL:    os.Getenv("NEXUS_BASE_URL"),
-		NexusAppToken:   os.Getenv("NEXUS_APP_TOKEN"),
-		NexusAdminToken:   os.Getenv("NEXUS_ADMIN_TOKEN"),
-		NexusAdminProfile: parseIntEnv("NEXUS_ADMIN_PROFILE"),
-		WAPhoneNumberID:   os.Getenv("WA_PHONE_NUMBER_ID"),
-		WAAccessToken:   os.Getenv("WA_ACCESS_TOKEN"),
-		WAVerifyToken:   os.Getenv("WA_VERIFY_TOKEN"),
-		OpenAIAPIKey:    os.Getenv("OPENAI_API_KEY"),
-		BaseURL:         os.Getenv("BASE_URL"),
-		Port:            os.Getenv("PORT"),
-		DataDir:         os.Getenv("DATA_DIR"),
+		NexusBaseURL:            os.Getenv("NEXUS_BASE_URL"),
+		NexusAppToken:           os.Getenv("NEXUS_APP_TOKEN"),
+		NexusAdminToken:         os.Getenv("NEXUS_ADMIN_TOKEN"),
+		NexusAdminProfile:       parseIntEnv("NEXUS_ADMIN_PROFILE"),
+		NexusAdminReadToken:     os.Getenv("NEXUS_ADMIN_READ_TOKEN"),
+		NexusAdminReadProfile:   parseIntEnv("NEXUS_ADMIN_READ_PROFILE"),
+		NexusAdminCreateToken:   os.Getenv("NEXUS_ADMIN_CREATE_TOKEN"),
+		NexusAdminCreateProfile: parseIntEnv("NEXUS_ADMIN_CREATE_PROFILE"),
+		NexusOAuthClientID:      os.Getenv("NEXUS_OAUTH_CLIENT_ID"),
+		NexusOAuthClientSecret:  os.Getenv("NEXUS_OAUTH_CLIENT_SECRET"),
+		NexusOAuthRedirectURL:   os.Getenv("NEXUS_OAUTH_REDIRECT_URL"),
+		WAPhoneNumberID:         os.Getenv("WA_PHONE_NUMBER_ID"),
+		WAAccessToken:           os.Getenv("WA_ACCESS_TOKEN"),
+		WAVerifyToken:           os.Getenv("WA_VERIFY_TOKEN"),
+		WAReminderTemplate:      os.Getenv("WA_REMINDER_TEMPLATE"),
+		OpenAIAPIKey:            os.Getenv("OPENAI_API_KEY"),
+		BaseURL:                 os.Getenv("BASE_URL"),
+		Port:                    os.Getenv("PORT"),
+		DataDir:                 os.Getenv("DATA_DIR"),
+		HistoryMaxTurns:         parseIntEnv("HISTORY_MAX_TURNS"),
+		HistoryMaxTokens:        parseIntEnv("HISTORY_MAX_TOKENS"),
+		HistoryKeepRecent:       parseIntEnv("HISTORY_KEEP_RECENT"),
+		MaxInboundChars:         parseIntEnv("MAX_INBOUND_CHARS"),
+		MaxInboundLogChars:      parseIntEnv("MAX_INBOUND_LOG_CHARS"),
+		ToolMaxRetries:          parseIntEnvDefault("TOOL_MAX_RETRIES", 1),
+		ToolNonRetryableErrors:  parseListEnv("TOOL_NON_RETRYABLE_ERRORS", ";"),
+		MaxParallelTools:        parseIntEnv("TOOL_MAX_PARALLEL"),
+		DailyTokenBudget:        parseIntEnv("DAILY_TOKEN_BUDGET"),
+		ConfirmLevel:            os.Getenv("CONFIRM_LEVEL"),
+		DoomLoopExactThreshold:  parseIntEnv("DOOM_LOOP_EXACT_THRESHOLD"),
+		DoomLoopNameThreshold:   parseIntEnv("DOOM_LOOP_NAME_THRESHOLD"),
+		AttachTranscript:        parseBoolEnv("TICKET_ATTACH_TRANSCRIPT"),
+		RoutingHintsFile:        os.Getenv("ROUTING_HINTS_FILE"),
+		UrgencyHintsFile:        os.Getenv("URGENCY_HINTS_FILE"),
+		TranslateTickets:        parseBoolEnv("TICKET_TRANSLATION"),
+		BranchesFile:            os.Getenv("BRANCHES_FILE"),
+		ManagersFile:            os.Getenv("MANAGERS_FILE"),
+		CustomFieldsFile:        os.Getenv("CUSTOM_FIELDS_FILE"),
+		HandoffCategoryID:       parseIntEnv("HANDOFF_CATEGORY_ID"),
+		HandoffGroupID:          parseIntEnv("HANDOFF_GROUP_ID"),
+		HandoffHours:            os.Getenv("HANDOFF_HOURS"),
+		StatusWorkflow:          os.Getenv("TICKET_STATUS_WORKFLOW"),
+		DefaultUrgency:          parseIntEnv("TICKET_DEFAULT_URGENCY"),
+		LogFormat:               os.Getenv("LOG_FORMAT"),
+		LogPhoneKey:             []byte(os.Getenv("LOG_PHONE_KEY")),
+		AdminAPIToken:           os.Getenv("ADMIN_API_TOKEN"),
+		AdminAlertPhone:         os.Getenv("ADMIN_ALERT_PHONE"),
+		OnboardingFile:          os.Getenv("ONBOARDING_FILE"),
+		DryRun:                  parseBoolEnv("AGENT_DRY_RUN"),
+		InterimMessages:         parseBoolEnv("AGENT_INTERIM_MESSAGES"),
 	}
 
+	cfg.ToolRetryBackoff = 2 * time.Second
+	if v := os.Getenv("TOOL_RETRY_BACKOFF"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("TOOL_RETRY_BACKOFF: %w", err)
+		}
+		cfg.ToolRetryBackoff = d
+	}
+	for key, dst := range map[string]*time.Duration{
+		"NEXUS_HTTP_TIMEOUT":   &cfg.NexusHTTPTimeout,
+		"NEXUS_SEARCH_TIMEOUT": &cfg.NexusSearchTimeout,
+	} {
+		if v := os.Getenv(key); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			*dst = d
+		}
+	}
+
+	if cfg.DefaultUrgency < 0 || cfg.DefaultUrgency > 5 {
+		return nil, fmt.Errorf("TICKET_DEFAULT_URGENCY must be 1-5, got %d", cfg.DefaultUrgency)
+	}
+	skip, err := parseIntListEnv("TICKET_URGENCY_SKIP_CATEGORIES")
+	if err != nil {
+		return nil, err
+	}
+	cfg.UrgencySkipCategories = skip
+
 	if cfg.Port == "" {
 		cfg.Port = "8080"
 	}
@@ -52,11 +226,25 @@ func Load() (*Config, error) {
 	if cfg.DataDir == "" {
 		cfg.DataDir = "."
 	}
+	if err := ensureWritableDir(cfg.DataDir); err != nil {
+		return nil, fmt.Errorf("DATA_DIR %q: %w", cfg.DataDir, err)
+	}
 
 	if cfg.BaseURL == "" {
 		cfg.BaseURL = fmt.Sprintf("http://localhost:%s", cfg.Port)
 	}
 
+	if cfg.NexusOAuthClientID != "" {
+		if cfg.NexusOAuthRedirectURL == "" {
+			cfg.NexusOAuthRedirectURL = cfg.BaseURL + "/auth/oauth/callback"
+		}
+		key, err := hex.DecodeString(os.Getenv("TOKEN_ENCRYPTION_KEY"))
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("TOKEN_ENCRYPTION_KEY must be 64 hex chars when NEXUS_OAUTH_CLIENT_ID is set")
+		}
+		cfg.TokenEncryptionKey = key
+	}
+
 	if cfg.WAVerifyToken == "" {
 		token, err := randomHex(16)
 		if err != nil {
@@ -82,11 +270,66 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
+// ensureWritableDir creates dir (and parents) if missing and checks that files
+// can be created in it, so a bad DATA_DIR fails here instead of inside bbolt.
+func ensureWritableDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+	f, err := os.CreateTemp(dir, ".laia-write-check-*")
+	if err != nil {
+		return fmt.Errorf("directory is not writable: %w", err)
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
 func parseIntEnv(key string) int {
 	v, _ := strconv.Atoi(os.Getenv(key))
 	return v
 }
 
+// parseIntEnvDefault is like parseIntEnv but distinguishes unset from "0".
+func parseIntEnvDefault(key string, def int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// parseIntListEnv reads a comma-separated list of integers; unset is nil.
+func parseIntListEnv(key string) ([]int, error) {
+	var out []int
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %q is not a number", key, v)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+// parseListEnv reads a sep-separated list, dropping blank entries; unset is nil.
+func parseListEnv(key, sep string) []string {
+	var out []string
+	for _, v := range strings.Split(os.Getenv(key), sep) {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func parseBoolEnv(key string) bool {
+	v, _ := strconv.ParseBool(os.Getenv(key))
+	return v
+}
+
 func randomHex(n int) (string, error) {
 	b := make([]byte, n)
 	if _, err := rand.Read(b); err != nil {
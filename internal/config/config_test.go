@@ -0,0 +1,57 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setRequiredEnv sets the variables Load refuses to start without.
+func setRequiredEnv(t *testing.T) {
+	for _, key := range []string{"NEXUS_BASE_URL", "NEXUS_APP_TOKEN", "WA_PHONE_NUMBER_ID", "WA_ACCESS_TOKEN", "OPENAI_API_KEY"} {
+		t.Setenv(key, "test")
+	}
+}
+
+func TestLoadCreatesNestedDataDir(t *testing.T) {
+	setRequiredEnv(t)
+	dir := filepath.Join(t.TempDir(), "var", "lib", "laia")
+	t.Setenv("DATA_DIR", dir)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.DataDir != dir {
+		t.Errorf("DataDir = %q, want %q", cfg.DataDir, dir)
+	}
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		t.Fatalf("DATA_DIR not created: %v", err)
+	}
+	// The write check leaves nothing behind.
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("DATA_DIR has leftovers: %v", entries)
+	}
+}
+
+func TestLoadRejectsUnwritableDataDir(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root ignores directory permissions")
+	}
+	setRequiredEnv(t)
+	parent := filepath.Join(t.TempDir(), "ro")
+	if err := os.Mkdir(parent, 0o500); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(parent, 0o700) })
+
+	for _, dir := range []string{parent, filepath.Join(parent, "laia")} {
+		t.Setenv("DATA_DIR", dir)
+		_, err := Load()
+		if err == nil || !strings.Contains(err.Error(), "DATA_DIR") {
+			t.Errorf("Load with DATA_DIR=%s: err = %v, want a DATA_DIR error", dir, err)
+		}
+	}
+}
@@ -3,42 +3,122 @@ package glpi
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
+	"slices"
+	"strings"
 	"time"
 )
 
+// ErrMaintenance is returned when Nexus answers with an HTML page instead of
+// JSON, which is what GLPI's maintenance mode (and the proxy in front of it) does.
+var ErrMaintenance = errors.New("nexus em manutenção")
+
+// ErrInvalidCredentials is returned by initSession when Nexus rejects the
+// user's token (revoked, regenerated or user disabled).
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
 type Client struct {
 	baseURL      string
 	appToken     string
 	adminToken   string
 	adminProfile int
+	adminCreds   map[AdminOperation]AdminCredential
 	http         *http.Client
+	searchHTTP   *http.Client
+	oauth        *oauthState
+	profiles     *sessionProfiles
+}
+
+// Timeouts bounds GLPI requests. Search endpoints get their own limit since
+// they scan many rows on large instances; a zero field keeps the default.
+type Timeouts struct {
+	Default time.Duration
+	Search  time.Duration
+}
+
+var DefaultTimeouts = Timeouts{Default: 15 * time.Second, Search: 45 * time.Second}
+
+func (t Timeouts) withDefaults() Timeouts {
+	if t.Default <= 0 {
+		t.Default = DefaultTimeouts.Default
+	}
+	if t.Search <= 0 {
+		t.Search = DefaultTimeouts.Search
+	}
+	return t
+}
+
+// AdminOperation selects which admin credential AdminSession uses, so reading
+// reference data doesn't need the rights required to create tickets.
+type AdminOperation int
+
+const (
+	// AdminReadReference reads data self-service users can't (categories,
+	// form targets, other users' tickets for statistics).
+	AdminReadReference AdminOperation = iota
+	// AdminCreateTicket creates tickets on the user's behalf.
+	AdminCreateTicket
+)
+
+// AdminCredential overrides the default admin token/profile for one
+// operation; empty fields fall back to the defaults from NewClient.
+type AdminCredential struct {
+	Token   string
+	Profile int
+}
+
+func (c *Client) SetAdminCredential(op AdminOperation, cred AdminCredential) {
+	if c.adminCreds == nil {
+		c.adminCreds = make(map[AdminOperation]AdminCredential)
+	}
+	c.adminCreds[op] = cred
+}
+
+// adminCredential resolves the token and profile for op.
+func (c *Client) adminCredential(op AdminOperation) (string, int) {
+	token, profile := c.adminToken, c.adminProfile
+	if cred, ok := c.adminCreds[op]; ok {
+		if cred.Token != "" {
+			token = cred.Token
+		}
+		if cred.Profile > 0 {
+			profile = cred.Profile
+		}
+	}
+	return token, profile
 }
 
-func NewClient(baseURL, appToken, adminToken string, adminProfile int) *Client {
+func NewClient(baseURL, appToken, adminToken string, adminProfile int, timeouts Timeouts) *Client {
+	timeouts = timeouts.withDefaults()
 	return &Client{
 		baseURL:      baseURL,
 		appToken:     appToken,
 		adminToken:   adminToken,
 		adminProfile: adminProfile,
-		http:         &http.Client{Timeout: 15 * time.Second},
+		http:         &http.Client{Timeout: timeouts.Default},
+		searchHTTP:   &http.Client{Timeout: timeouts.Search},
+		profiles:     newSessionProfiles(),
 	}
 }
 
-// AdminSession creates a session with elevated profile for reading reference data
-// (e.g. ITILCategory) that regular self-service users can't access.
-func (c *Client) AdminSession() (string, error) {
-	if c.adminToken == "" {
+// AdminSession creates a session with elevated profile for operations regular
+// self-service users can't perform (e.g. reading ITILCategory, creating tickets).
+func (c *Client) AdminSession(op AdminOperation) (string, error) {
+	token, profile := c.adminCredential(op)
+	if token == "" {
 		return "", fmt.Errorf("admin token not configured")
 	}
-	session, err := c.InitSession(c.adminToken)
+	// Admin sessions never need the profile cached, so skip the full session.
+	session, _, err := c.initSession("user_token "+token, false)
 	if err != nil {
 		return "", err
 	}
-	if c.adminProfile > 0 {
-		if err := c.ChangeActiveProfile(session, c.adminProfile); err != nil {
+	if profile > 0 {
+		if err := c.ChangeActiveProfile(session, profile); err != nil {
 			c.KillSession(session)
 			return "", fmt.Errorf("changing to admin profile: %w", err)
 		}
@@ -59,7 +139,7 @@ func (c *Client) ChangeActiveProfile(sessionToken string, profileID int) error {
 	}
 	c.setSessionHeaders(req, sessionToken)
 
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return fmt.Errorf("changeActiveProfile request: %w", err)
 	}
@@ -69,36 +149,92 @@ func (c *Client) ChangeActiveProfile(sessionToken string, profileID int) error {
 		respBody, _ := io.ReadAll(resp.Body)
 		return fmt.Errorf("changeActiveProfile status %d: %s", resp.StatusCode, respBody)
 	}
+	c.profiles.forget(sessionToken)
 	return nil
 }
 
-// InitSession validates a user_token and returns a session_token.
+// InitSession validates a user_token and returns a session_token. The session
+// comes back in the same response (get_full_session), so the active profile
+// is cached without a getFullSession round trip (see ActiveProfile).
 // Reference: nexus_apirest.md — GET /apirest.php/initSession
 func (c *Client) InitSession(userToken string) (string, error) {
+	token, _, err := c.initSession("user_token "+userToken, true)
+	return token, err
+}
+
+// initSession opens a session with the given Authorization header. With full
+// set it also returns the session data and caches its active profile.
+func (c *Client) initSession(authorization string, full bool) (string, *SessionInfo, error) {
 	req, err := http.NewRequest(http.MethodGet, c.baseURL+"/apirest.php/initSession", nil)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
-	req.Header.Set("Authorization", "user_token "+userToken)
+	req.Header.Set("Authorization", authorization)
 	req.Header.Set("App-Token", c.appToken)
 	req.Header.Set("Content-Type", "application/json")
+	if full {
+		q := req.URL.Query()
+		q.Set("get_full_session", "true")
+		req.URL.RawQuery = q.Encode()
+	}
 
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
-		return "", fmt.Errorf("initSession request: %w", err)
+		return "", nil, fmt.Errorf("initSession request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("initSession status %d: %s", resp.StatusCode, body)
+		return "", nil, initSessionError(resp.StatusCode, body)
 	}
 
 	var result InitSessionResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", fmt.Errorf("decoding initSession response: %w", err)
+		return "", nil, fmt.Errorf("decoding initSession response: %w", err)
+	}
+	if result.Session != nil {
+		c.profiles.set(result.SessionToken, result.Session.GlpiActiveProfile)
+	}
+	return result.SessionToken, result.Session, nil
+}
+
+// initSessionError wraps credential rejections in ErrInvalidCredentials so
+// callers can tell them apart from Nexus being unreachable.
+// Reference: nexus_apirest.md — ERROR_GLPI_LOGIN_USER_TOKEN
+func initSessionError(status int, body []byte) error {
+	if status == http.StatusUnauthorized || bytes.Contains(body, []byte("ERROR_GLPI_LOGIN_USER_TOKEN")) {
+		return fmt.Errorf("%w: initSession status %d: %s", ErrInvalidCredentials, status, body)
+	}
+	return fmt.Errorf("initSession status %d: %s", status, body)
+}
+
+// APIError is a non-success GLPI response. GLPI answers errors with a JSON
+// array ["ERROR_CODE", "localized message"]; Code and Message are empty when
+// the body isn't in that shape.
+// Reference: nexus_apirest.md — Errors
+type APIError struct {
+	Status  int
+	Code    string
+	Message string
+	Body    []byte
+}
+
+// Error keeps the "status N: body" text callers and ClassifyError match on.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("status %d: %s", e.Status, e.Body)
+}
+
+func newAPIError(status int, body []byte) *APIError {
+	e := &APIError{Status: status, Body: body}
+	var payload []any
+	if json.Unmarshal(body, &payload) == nil && len(payload) > 0 {
+		e.Code, _ = payload[0].(string)
+		if len(payload) > 1 {
+			e.Message, _ = payload[1].(string)
+		}
 	}
-	return result.SessionToken, nil
+	return e
 }
 
 // GetFullSession returns the current session details including user info.
@@ -110,7 +246,7 @@ func (c *Client) GetFullSession(sessionToken string) (*FullSession, error) {
 	}
 	c.setSessionHeaders(req, sessionToken)
 
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("getFullSession request: %w", err)
 	}
@@ -125,19 +261,37 @@ func (c *Client) GetFullSession(sessionToken string) (*FullSession, error) {
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return nil, fmt.Errorf("decoding getFullSession response: %w", err)
 	}
+	c.profiles.set(sessionToken, result.Session.GlpiActiveProfile)
 	return &result, nil
 }
 
-// KillSession ends the current GLPI session.
-// Reference: nexus_apirest.md — GET /apirest.php/killSession
+// ActiveProfile returns the session's active profile. It is cached from
+// initSession and only changes with ChangeActiveProfile, so checking the
+// profile on every message doesn't cost a getFullSession each time.
+func (c *Client) ActiveProfile(sessionToken string) (ActiveProfile, error) {
+	if p, ok := c.profiles.get(sessionToken); ok {
+		return p, nil
+	}
+	fs, err := c.GetFullSession(sessionToken)
+	if err != nil {
+		return ActiveProfile{}, err
+	}
+	return fs.Session.GlpiActiveProfile, nil
+}
+
+// KillSession ends the current GLPI session. A session GLPI no longer knows
+// (already killed, or expired behind an auth error) counts as ended, so the
+// deferred kills don't report a failure for it.
+// Reference: nexus_apirest.md — GET /apirest.php/killSession, ERROR_SESSION_TOKEN_INVALID
 func (c *Client) KillSession(sessionToken string) error {
+	c.profiles.forget(sessionToken)
 	req, err := http.NewRequest(http.MethodGet, c.baseURL+"/apirest.php/killSession", nil)
 	if err != nil {
 		return err
 	}
 	c.setSessionHeaders(req, sessionToken)
 
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return fmt.Errorf("killSession request: %w", err)
 	}
@@ -145,6 +299,9 @@ func (c *Client) KillSession(sessionToken string) error {
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode == http.StatusUnauthorized && bytes.Contains(body, []byte("ERROR_SESSION_TOKEN_INVALID")) {
+			return nil
+		}
 		return fmt.Errorf("killSession status %d: %s", resp.StatusCode, body)
 	}
 	return nil
@@ -165,13 +322,13 @@ func (c *Client) GetMyTickets(sessionToken string) ([]Ticket, error) {
 	q.Set("as_map", "0")
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("getMyTickets request: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	if !listStatusOK(resp.StatusCode) {
 		body, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("getMyTickets status %d: %s", resp.StatusCode, body)
 	}
@@ -183,6 +340,25 @@ func (c *Client) GetMyTickets(sessionToken string) ([]Ticket, error) {
 	return tickets, nil
 }
 
+// listStatusOK reports whether a list or search response succeeded. GLPI
+// answers 206 Partial Content when the collection is larger than the range
+// requested (or the default 0-49), with the items in the body as usual.
+// Reference: nexus_apirest.md — Content-Range
+func listStatusOK(code int) bool {
+	return code == http.StatusOK || code == http.StatusPartialContent
+}
+
+// hasMoreItems reports whether a list response's Content-Range
+// ("start-end/total") leaves items past its end. Paging past the last item is
+// an error in GLPI (ERROR_RANGE_EXCEEDED_TOTAL), so callers stop on false.
+func hasMoreItems(resp *http.Response) bool {
+	var start, end, total int
+	if n, _ := fmt.Sscanf(resp.Header.Get("Content-Range"), "%d-%d/%d", &start, &end, &total); n != 3 {
+		return false
+	}
+	return end+1 < total
+}
+
 func (c *Client) setSessionHeaders(req *http.Request, sessionToken string) {
 	req.Header.Set("Session-Token", sessionToken)
 	req.Header.Set("App-Token", c.appToken)
@@ -190,13 +366,47 @@ func (c *Client) setSessionHeaders(req *http.Request, sessionToken string) {
 }
 
 // setWriteSessionHeaders adds session headers + session_write=true for POST/PUT.
+// It merges into the existing query, so params already in the URL are kept.
 func (c *Client) setWriteSessionHeaders(req *http.Request, sessionToken string) {
 	c.setSessionHeaders(req, sessionToken)
+	ensureSessionWrite(req)
+}
+
+// ensureSessionWrite adds session_write=true unless the query already has it.
+// Reference: nexus_apirest.md — "By default, sessions used in this API are read-only"
+func ensureSessionWrite(req *http.Request) {
 	q := req.URL.Query()
+	if q.Get("session_write") == "true" {
+		return
+	}
 	q.Set("session_write", "true")
 	req.URL.RawQuery = q.Encode()
 }
 
+// do sends req and rejects non-JSON responses with ErrMaintenance, so callers
+// never try to decode an HTML error page.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	// Re-apply session_write on mutating calls in case the query was rebuilt
+	// after setWriteSessionHeaders (q.Encode() on a fresh url.Values drops it).
+	if req.Method != http.MethodGet && req.Header.Get("Session-Token") != "" {
+		ensureSessionWrite(req)
+	}
+	client := c.http
+	if strings.Contains(req.URL.Path, "/apirest.php/search/") {
+		client = c.searchHTTP
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w (status %d)", ErrMaintenance, resp.StatusCode)
+	}
+	return resp, nil
+}
+
 // GetTicket returns detailed ticket info.
 // Reference: nexus_apirest.md — GET /apirest.php/Ticket/:id
 func (c *Client) GetTicket(sessionToken string, ticketID int) (*TicketDetail, error) {
@@ -207,7 +417,7 @@ func (c *Client) GetTicket(sessionToken string, ticketID int) (*TicketDetail, er
 	}
 	c.setSessionHeaders(req, sessionToken)
 
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("getTicket request: %w", err)
 	}
@@ -246,13 +456,13 @@ func (c *Client) SearchTickets(sessionToken, query string, userID int) (*SearchR
 	q.Set("range", "0-19")
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("searchTickets request: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+	if !listStatusOK(resp.StatusCode) {
 		body, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("searchTickets status %d: %s", resp.StatusCode, body)
 	}
@@ -278,7 +488,7 @@ func (c *Client) CreateTicket(sessionToken string, input CreateTicketInput) (int
 	}
 	c.setWriteSessionHeaders(req, sessionToken)
 
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return 0, fmt.Errorf("createTicket request: %w", err)
 	}
@@ -286,7 +496,7 @@ func (c *Client) CreateTicket(sessionToken string, input CreateTicketInput) (int
 
 	if resp.StatusCode != http.StatusCreated {
 		respBody, _ := io.ReadAll(resp.Body)
-		return 0, fmt.Errorf("createTicket status %d: %s", resp.StatusCode, respBody)
+		return 0, fmt.Errorf("createTicket %w", newAPIError(resp.StatusCode, respBody))
 	}
 
 	var result struct {
@@ -313,7 +523,7 @@ func (c *Client) UpdateTicket(sessionToken string, ticketID int, input UpdateTic
 	}
 	c.setWriteSessionHeaders(req, sessionToken)
 
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return fmt.Errorf("updateTicket request: %w", err)
 	}
@@ -326,6 +536,45 @@ func (c *Client) UpdateTicket(sessionToken string, ticketID int, input UpdateTic
 	return nil
 }
 
+// UpdateTicketUser updates a ticket actor link (notification settings).
+// Reference: nexus_apirest.md — PUT /apirest.php/Ticket_User/:id
+func (c *Client) UpdateTicketUser(sessionToken string, linkID int, input UpdateTicketUserInput) error {
+	return c.updateItem(sessionToken, "Ticket_User", linkID, input)
+}
+
+// UpdateUser updates a user's contact fields. Self-service profiles may only
+// update their own user, and only if the profile allows it.
+// Reference: nexus_apirest.md — PUT /apirest.php/User/:id
+func (c *Client) UpdateUser(sessionToken string, userID int, input UpdateUserInput) error {
+	return c.updateItem(sessionToken, "User", userID, input)
+}
+
+func (c *Client) updateItem(sessionToken, itemtype string, id int, input any) error {
+	body, err := json.Marshal(glpiInput[any]{Input: input})
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/apirest.php/%s/%d", c.baseURL, itemtype, id)
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	c.setWriteSessionHeaders(req, sessionToken)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return fmt.Errorf("update %s request: %w", itemtype, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("update %s status %d: %s", itemtype, resp.StatusCode, respBody)
+	}
+	return nil
+}
+
 // AddFollowup adds a followup comment to a ticket.
 // Reference: nexus_apirest.md — POST /apirest.php/Ticket/:id/ITILFollowup
 func (c *Client) AddFollowup(sessionToken string, ticketID int, content string) (int, error) {
@@ -345,7 +594,7 @@ func (c *Client) AddFollowup(sessionToken string, ticketID int, content string)
 	}
 	c.setWriteSessionHeaders(req, sessionToken)
 
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return 0, fmt.Errorf("addFollowup request: %w", err)
 	}
@@ -368,62 +617,161 @@ func (c *Client) AddFollowup(sessionToken string, ticketID int, content string)
 // GetFollowups returns followup comments for a ticket.
 // Reference: nexus_apirest.md — GET /apirest.php/Ticket/:id/ITILFollowup
 func (c *Client) GetFollowups(sessionToken string, ticketID int) ([]Followup, error) {
+	followups, _, err := c.getFollowups(sessionToken, ticketID, nil)
+	return followups, err
+}
+
+// followupsPageSize and followupsMaxPages bound GetFollowupsSince: pages are
+// read newest first until one reaches since, at most 500 followups back.
+const (
+	followupsPageSize = 50
+	followupsMaxPages = 10
+)
+
+// GetFollowupsSince returns the followups of a ticket created after since, a
+// GLPI datetime ("2006-01-02 15:04:05", server time), oldest first like
+// GetFollowups. Sub-item lists can't be filtered by date, so pages are read
+// newest first until one reaches since; GLPI's default range (the first 50,
+// oldest first) would miss the newest followups on long tickets.
+func (c *Client) GetFollowupsSince(sessionToken string, ticketID int, since string) ([]Followup, error) {
+	var recent []Followup
+	for page := range followupsMaxPages {
+		from := page * followupsPageSize
+		followups, more, err := c.getFollowups(sessionToken, ticketID, map[string]string{
+			"sort":  "date_creation",
+			"order": "DESC",
+			"range": fmt.Sprintf("%d-%d", from, from+followupsPageSize-1),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range followups {
+			// Same layout on both sides, so string order is time order.
+			if f.DateCreated <= since {
+				slices.Reverse(recent)
+				return recent, nil
+			}
+			recent = append(recent, f)
+		}
+		if !more {
+			break
+		}
+	}
+	slices.Reverse(recent)
+	return recent, nil
+}
+
+// getFollowups lists a ticket's followups with extra query params; more is
+// set when items remain past the requested range.
+func (c *Client) getFollowups(sessionToken string, ticketID int, params map[string]string) (followups []Followup, more bool, err error) {
 	url := fmt.Sprintf("%s/apirest.php/Ticket/%d/ITILFollowup", c.baseURL, ticketID)
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 	c.setSessionHeaders(req, sessionToken)
 
-	resp, err := c.http.Do(req)
+	// Author names in the same request instead of one GetUser per author.
+	q := req.URL.Query()
+	q.Add("add_keys_names[]", "users_id")
+	for k, v := range params {
+		q.Set(k, v)
+	}
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.do(req)
 	if err != nil {
-		return nil, fmt.Errorf("getFollowups request: %w", err)
+		return nil, false, fmt.Errorf("getFollowups request: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	if !listStatusOK(resp.StatusCode) {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("getFollowups status %d: %s", resp.StatusCode, body)
+		return nil, false, fmt.Errorf("getFollowups status %d: %s", resp.StatusCode, body)
 	}
 
-	var followups []Followup
 	if err := json.NewDecoder(resp.Body).Decode(&followups); err != nil {
-		return nil, fmt.Errorf("decoding followups: %w", err)
+		return nil, false, fmt.Errorf("decoding followups: %w", err)
+	}
+	return followups, hasMoreItems(resp), nil
+}
+
+// GetTicketUsers returns the users linked to a ticket (requesters, assigned
+// technicians and observers).
+// Reference: nexus_apirest.md — GET /apirest.php/Ticket/:id/Ticket_User
+func (c *Client) GetTicketUsers(sessionToken string, ticketID int) ([]TicketUser, error) {
+	url := fmt.Sprintf("%s/apirest.php/Ticket/%d/Ticket_User", c.baseURL, ticketID)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setSessionHeaders(req, sessionToken)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("getTicketUsers request: %w", err)
 	}
-	return followups, nil
+	defer resp.Body.Close()
+
+	if !listStatusOK(resp.StatusCode) {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("getTicketUsers status %d: %s", resp.StatusCode, body)
+	}
+
+	var users []TicketUser
+	if err := json.NewDecoder(resp.Body).Decode(&users); err != nil {
+		return nil, fmt.Errorf("decoding ticket users: %w", err)
+	}
+	return users, nil
 }
 
-// SearchKnowledgeBase searches the GLPI knowledge base.
+// SearchKnowledgeBase searches the GLPI knowledge base. categoryID > 0 limits
+// results to that KB category and its sub-categories.
 // Reference: nexus_apirest.md — GET /apirest.php/search/KnowbaseItem/
-func (c *Client) SearchKnowledgeBase(sessionToken, query string) (*SearchResponse, error) {
+func (c *Client) SearchKnowledgeBase(sessionToken, query string, categoryID int) (*SearchResponse, error) {
 	req, err := http.NewRequest(http.MethodGet, c.baseURL+"/apirest.php/search/KnowbaseItem/", nil)
 	if err != nil {
 		return nil, err
 	}
 	c.setSessionHeaders(req, sessionToken)
 
-	// KnowbaseItem search options: 2=ID, 6=Subject(name), 7=Content(answer)
+	// KnowbaseItem search options: 2=ID, 4=Category, 6=Subject(name), 7=Content(answer)
 	q := req.URL.Query()
-	q.Set("criteria[0][field]", "6")
-	q.Set("criteria[0][searchtype]", "contains")
-	q.Set("criteria[0][value]", query)
-	q.Set("criteria[1][link]", "OR")
-	q.Set("criteria[1][field]", "7")
-	q.Set("criteria[1][searchtype]", "contains")
-	q.Set("criteria[1][value]", query)
+	if categoryID > 0 {
+		// (subject OR content) AND category; "under" includes sub-categories.
+		q.Set("criteria[0][criteria][0][field]", "6")
+		q.Set("criteria[0][criteria][0][searchtype]", "contains")
+		q.Set("criteria[0][criteria][0][value]", query)
+		q.Set("criteria[0][criteria][1][link]", "OR")
+		q.Set("criteria[0][criteria][1][field]", "7")
+		q.Set("criteria[0][criteria][1][searchtype]", "contains")
+		q.Set("criteria[0][criteria][1][value]", query)
+		q.Set("criteria[1][link]", "AND")
+		q.Set("criteria[1][field]", "4")
+		q.Set("criteria[1][searchtype]", "under")
+		q.Set("criteria[1][value]", fmt.Sprintf("%d", categoryID))
+	} else {
+		q.Set("criteria[0][field]", "6")
+		q.Set("criteria[0][searchtype]", "contains")
+		q.Set("criteria[0][value]", query)
+		q.Set("criteria[1][link]", "OR")
+		q.Set("criteria[1][field]", "7")
+		q.Set("criteria[1][searchtype]", "contains")
+		q.Set("criteria[1][value]", query)
+	}
 	q.Set("forcedisplay[0]", "2")
 	q.Set("forcedisplay[1]", "6")
 	q.Set("forcedisplay[2]", "7")
 	q.Set("range", "0-9")
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("searchKnowledgeBase request: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+	if !listStatusOK(resp.StatusCode) {
 		body, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("searchKnowledgeBase status %d: %s", resp.StatusCode, body)
 	}
@@ -435,6 +783,37 @@ func (c *Client) SearchKnowledgeBase(sessionToken, query string) (*SearchRespons
 	return &result, nil
 }
 
+// GetKBCategories returns every knowledge base category visible to the session.
+// Reference: nexus_apirest.md — GET /apirest.php/KnowbaseItemCategory/
+func (c *Client) GetKBCategories(sessionToken string) ([]KBCategory, error) {
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+"/apirest.php/KnowbaseItemCategory/", nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setSessionHeaders(req, sessionToken)
+
+	q := req.URL.Query()
+	q.Set("range", "0-199")
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("getKBCategories request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if !listStatusOK(resp.StatusCode) {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("getKBCategories status %d: %s", resp.StatusCode, body)
+	}
+
+	var categories []KBCategory
+	if err := json.NewDecoder(resp.Body).Decode(&categories); err != nil {
+		return nil, fmt.Errorf("decoding KB categories: %w", err)
+	}
+	return categories, nil
+}
+
 // GetKBArticle returns a specific knowledge base article.
 // Reference: nexus_apirest.md — GET /apirest.php/KnowbaseItem/:id
 func (c *Client) GetKBArticle(sessionToken string, articleID int) (*KBArticle, error) {
@@ -445,7 +824,7 @@ func (c *Client) GetKBArticle(sessionToken string, articleID int) (*KBArticle, e
 	}
 	c.setSessionHeaders(req, sessionToken)
 
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("getKBArticle request: %w", err)
 	}
@@ -483,13 +862,13 @@ func (c *Client) SearchAssets(sessionToken, itemtype, query string) (*SearchResp
 	q.Set("range", "0-9")
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("searchAssets request: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+	if !listStatusOK(resp.StatusCode) {
 		body, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("searchAssets status %d: %s", resp.StatusCode, body)
 	}
@@ -515,13 +894,13 @@ func (c *Client) GetForms(sessionToken string) ([]Form, error) {
 	q.Set("searchText[is_active]", "1")
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("getForms request: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+	if !listStatusOK(resp.StatusCode) {
 		body, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("getForms status %d: %s", resp.StatusCode, body)
 	}
@@ -533,6 +912,77 @@ func (c *Client) GetForms(sessionToken string) ([]Form, error) {
 	return forms, nil
 }
 
+// GetFormCategories returns the FormCreator form categories.
+// Reference: GET /apirest.php/PluginFormcreatorCategory/
+func (c *Client) GetFormCategories(sessionToken string) ([]FormCategory, error) {
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+"/apirest.php/PluginFormcreatorCategory/", nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setSessionHeaders(req, sessionToken)
+
+	q := req.URL.Query()
+	q.Set("range", "0-199")
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("getFormCategories request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if !listStatusOK(resp.StatusCode) {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("getFormCategories status %d: %s", resp.StatusCode, body)
+	}
+
+	var categories []FormCategory
+	if err := json.NewDecoder(resp.Body).Decode(&categories); err != nil {
+		return nil, fmt.Errorf("decoding form categories: %w", err)
+	}
+	return categories, nil
+}
+
+// GetFormAccessList returns the allow-list of a restricted form. itemtype is
+// PluginFormcreatorForm_Profile or PluginFormcreatorForm_User.
+// Reference: GET /apirest.php/PluginFormcreatorForm_Profile/
+func (c *Client) GetFormAccessList(sessionToken, itemtype string, formID int) ([]FormAccess, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/apirest.php/%s/", c.baseURL, itemtype), nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setSessionHeaders(req, sessionToken)
+
+	q := req.URL.Query()
+	q.Set("searchText[plugin_formcreator_forms_id]", fmt.Sprintf("%d", formID))
+	q.Set("range", "0-199")
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("getFormAccessList request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if !listStatusOK(resp.StatusCode) {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("getFormAccessList status %d: %s", resp.StatusCode, body)
+	}
+
+	var list []FormAccess
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("decoding form access list: %w", err)
+	}
+	// searchText is a LIKE match, so 1 also matches 10, 11...
+	exact := list[:0]
+	for _, a := range list {
+		if a.FormsID == formID {
+			exact = append(exact, a)
+		}
+	}
+	return exact, nil
+}
+
 // GetFormSections returns the sections of a FormCreator form.
 // Reference: GET /apirest.php/PluginFormcreatorForm/:id/PluginFormcreatorSection
 func (c *Client) GetFormSections(sessionToken string, formID int) ([]FormSection, error) {
@@ -543,13 +993,13 @@ func (c *Client) GetFormSections(sessionToken string, formID int) ([]FormSection
 	}
 	c.setSessionHeaders(req, sessionToken)
 
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("getFormSections request: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+	if !listStatusOK(resp.StatusCode) {
 		body, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("getFormSections status %d: %s", resp.StatusCode, body)
 	}
@@ -571,13 +1021,13 @@ func (c *Client) GetSectionQuestions(sessionToken string, sectionID int) ([]Form
 	}
 	c.setSessionHeaders(req, sessionToken)
 
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("getSectionQuestions request: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+	if !listStatusOK(resp.StatusCode) {
 		body, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("getSectionQuestions status %d: %s", resp.StatusCode, body)
 	}
@@ -602,13 +1052,13 @@ func (c *Client) GetTargetTickets(sessionToken string, formID int) ([]TargetTick
 	q.Set("searchText[plugin_formcreator_forms_id]", fmt.Sprintf("%d", formID))
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("getTargetTickets request: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+	if !listStatusOK(resp.StatusCode) {
 		body, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("getTargetTickets status %d: %s", resp.StatusCode, body)
 	}
@@ -630,13 +1080,13 @@ func (c *Client) GetTargetActors(sessionToken string, targetID int) ([]TargetAct
 	}
 	c.setSessionHeaders(req, sessionToken)
 
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("getTargetActors request: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+	if !listStatusOK(resp.StatusCode) {
 		body, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("getTargetActors status %d: %s", resp.StatusCode, body)
 	}
@@ -658,13 +1108,13 @@ func (c *Client) GetTicketTasks(sessionToken string, ticketID int) ([]TicketTask
 	}
 	c.setSessionHeaders(req, sessionToken)
 
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("getTicketTasks request: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	if !listStatusOK(resp.StatusCode) {
 		body, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("getTicketTasks status %d: %s", resp.StatusCode, body)
 	}
@@ -695,7 +1145,7 @@ func (c *Client) AddTicketTask(sessionToken string, ticketID int, content string
 	}
 	c.setWriteSessionHeaders(req, sessionToken)
 
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return 0, fmt.Errorf("addTicketTask request: %w", err)
 	}
@@ -715,103 +1165,408 @@ func (c *Client) AddTicketTask(sessionToken string, ticketID int, content string
 	return result.ID, nil
 }
 
-// GetTicketValidations returns approval requests for a ticket.
-// Reference: GET /apirest.php/Ticket/:id/TicketValidation
-func (c *Client) GetTicketValidations(sessionToken string, ticketID int) ([]TicketValidation, error) {
-	url := fmt.Sprintf("%s/apirest.php/Ticket/%d/TicketValidation", c.baseURL, ticketID)
-	req, err := http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
-		return nil, err
-	}
-	c.setSessionHeaders(req, sessionToken)
-
-	resp, err := c.http.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("getTicketValidations request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("getTicketValidations status %d: %s", resp.StatusCode, body)
-	}
-
-	var validations []TicketValidation
-	if err := json.NewDecoder(resp.Body).Decode(&validations); err != nil {
-		return nil, fmt.Errorf("decoding ticket validations: %w", err)
-	}
-	return validations, nil
-}
-
-// RespondTicketValidation approves or refuses a validation request.
-// Reference: PUT /apirest.php/TicketValidation/:id
-func (c *Client) RespondTicketValidation(sessionToken string, validationID int, approve bool, comment string) error {
-	status := 3 // Refused
-	if approve {
-		status = 2 // Approved
-	}
+// LogTicketTaskTime creates a done task on a ticket recording seconds of work.
+// Reference: POST /apirest.php/TicketTask/ (actiontime is in seconds)
+func (c *Client) LogTicketTaskTime(sessionToken string, ticketID int, content string, seconds int) (int, error) {
 	input := map[string]any{
-		"status":             status,
-		"comment_validation": comment,
+		"tickets_id": ticketID,
+		"content":    content,
+		"state":      3,
+		"actiontime": seconds,
 	}
 	body, err := json.Marshal(glpiInput[map[string]any]{Input: input})
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	url := fmt.Sprintf("%s/apirest.php/TicketValidation/%d", c.baseURL, validationID)
-	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(body))
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+"/apirest.php/TicketTask/", bytes.NewReader(body))
 	if err != nil {
-		return err
+		return 0, err
 	}
 	c.setWriteSessionHeaders(req, sessionToken)
 
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
-		return fmt.Errorf("respondTicketValidation request: %w", err)
+		return 0, fmt.Errorf("logTicketTaskTime request: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	if resp.StatusCode != http.StatusCreated {
 		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("respondTicketValidation status %d: %s", resp.StatusCode, respBody)
+		return 0, fmt.Errorf("logTicketTaskTime %w", newAPIError(resp.StatusCode, respBody))
 	}
-	return nil
+
+	var result struct {
+		ID int `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("decoding logTicketTaskTime response: %w", err)
+	}
+	return result.ID, nil
 }
 
-// GetTicketSatisfaction returns the satisfaction survey for a ticket.
-// Reference: GET /apirest.php/Ticket/:id/TicketSatisfaction
-func (c *Client) GetTicketSatisfaction(sessionToken string, ticketID int) (*TicketSatisfaction, error) {
-	url := fmt.Sprintf("%s/apirest.php/Ticket/%d/TicketSatisfaction", c.baseURL, ticketID)
+// SetTicketTaskTime replaces the time spent recorded on a task.
+// Reference: PUT /apirest.php/TicketTask/:id
+func (c *Client) SetTicketTaskTime(sessionToken string, taskID, seconds int) error {
+	return c.updateItem(sessionToken, "TicketTask", taskID, map[string]any{"actiontime": seconds})
+}
+
+// GetTicketSolutions returns the solutions proposed on a ticket, oldest first.
+// Reference: GET /apirest.php/Ticket/:id/ITILSolution
+func (c *Client) GetTicketSolutions(sessionToken string, ticketID int) ([]ITILSolution, error) {
+	url := fmt.Sprintf("%s/apirest.php/Ticket/%d/ITILSolution", c.baseURL, ticketID)
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
 	c.setSessionHeaders(req, sessionToken)
 
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
-		return nil, fmt.Errorf("getTicketSatisfaction request: %w", err)
+		return nil, fmt.Errorf("getTicketSolutions request: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	if !listStatusOK(resp.StatusCode) {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("getTicketSatisfaction status %d: %s", resp.StatusCode, body)
+		return nil, fmt.Errorf("getTicketSolutions status %d: %s", resp.StatusCode, body)
 	}
 
-	var surveys []TicketSatisfaction
-	if err := json.NewDecoder(resp.Body).Decode(&surveys); err != nil {
-		return nil, fmt.Errorf("decoding ticket satisfaction: %w", err)
-	}
-	if len(surveys) == 0 {
-		return nil, nil
+	var solutions []ITILSolution
+	if err := json.NewDecoder(resp.Body).Decode(&solutions); err != nil {
+		return nil, fmt.Errorf("decoding ticket solutions: %w", err)
 	}
-	return &surveys[0], nil
+	return solutions, nil
 }
 
-// RateTicketSatisfaction submits a satisfaction rating for a ticket.
-// Reference: PUT /apirest.php/TicketSatisfaction/:id
+// GetTicketValidations returns approval requests for a ticket.
+// Reference: GET /apirest.php/Ticket/:id/TicketValidation
+func (c *Client) GetTicketValidations(sessionToken string, ticketID int) ([]TicketValidation, error) {
+	url := fmt.Sprintf("%s/apirest.php/Ticket/%d/TicketValidation", c.baseURL, ticketID)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setSessionHeaders(req, sessionToken)
+
+	// Author names in the same request instead of one GetUser per author.
+	q := req.URL.Query()
+	q.Add("add_keys_names[]", "users_id")
+	q.Add("add_keys_names[]", "users_id_validate")
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("getTicketValidations request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if !listStatusOK(resp.StatusCode) {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("getTicketValidations status %d: %s", resp.StatusCode, body)
+	}
+
+	var validations []TicketValidation
+	if err := json.NewDecoder(resp.Body).Decode(&validations); err != nil {
+		return nil, fmt.Errorf("decoding ticket validations: %w", err)
+	}
+	return validations, nil
+}
+
+// GetMyValidations returns the approval requests still waiting on userID,
+// newest first. searchText is a LIKE match (user 1 also matches 12), so the
+// result is filtered again here.
+// Reference: nexus_apirest.md — GET /apirest.php/TicketValidation/
+func (c *Client) GetMyValidations(sessionToken string, userID int) ([]TicketValidation, error) {
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+"/apirest.php/TicketValidation/", nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setSessionHeaders(req, sessionToken)
+
+	q := req.URL.Query()
+	q.Set("searchText[users_id_validate]", fmt.Sprintf("%d", userID))
+	q.Set("searchText[status]", fmt.Sprintf("%d", ValidationWaiting))
+	q.Set("sort", "submission_date")
+	q.Set("order", "DESC")
+	q.Set("range", "0-199")
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("getMyValidations request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if !listStatusOK(resp.StatusCode) {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("getMyValidations status %d: %s", resp.StatusCode, body)
+	}
+
+	var all []TicketValidation
+	if err := json.NewDecoder(resp.Body).Decode(&all); err != nil {
+		return nil, fmt.Errorf("decoding validations: %w", err)
+	}
+	pending := all[:0]
+	for _, v := range all {
+		if v.UsersIDValidate == userID && v.Status == ValidationWaiting {
+			pending = append(pending, v)
+		}
+	}
+	return pending, nil
+}
+
+// UploadDocument stores data as a Document named name and links it to the
+// given item (e.g. "Ticket", 123), returning the Document ID.
+// Reference: nexus_apirest.md — Upload a document file
+func (c *Client) UploadDocument(sessionToken, name, filename string, data []byte, itemtype string, itemsID int) (int, error) {
+	manifest, err := json.Marshal(glpiInput[map[string]any]{Input: map[string]any{
+		"name":      name,
+		"_filename": []string{filename},
+		// Document links itself to the item when these are set on creation.
+		"itemtype": itemtype,
+		"items_id": itemsID,
+	}})
+	if err != nil {
+		return 0, err
+	}
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	if err := w.WriteField("uploadManifest", string(manifest)); err != nil {
+		return 0, err
+	}
+	part, err := w.CreateFormFile("filename[0]", filename)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := part.Write(data); err != nil {
+		return 0, err
+	}
+	if err := w.Close(); err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+"/apirest.php/Document/", &body)
+	if err != nil {
+		return 0, err
+	}
+	c.setWriteSessionHeaders(req, sessionToken)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+
+	resp, err := c.do(req)
+	if err != nil {
+		return 0, fmt.Errorf("uploadDocument request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		respBody, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("uploadDocument %w", newAPIError(resp.StatusCode, respBody))
+	}
+
+	var result struct {
+		ID int `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("decoding uploadDocument response: %w", err)
+	}
+	return result.ID, nil
+}
+
+// DownloadDocument returns a Document's file and its MIME type, reading at
+// most maxBytes; larger files fail with an error instead of being truncated.
+// Reference: nexus_apirest.md — GET /apirest.php/Document/:id with Accept: application/octet-stream
+func (c *Client) DownloadDocument(sessionToken string, docID int, maxBytes int64) ([]byte, string, error) {
+	url := fmt.Sprintf("%s/apirest.php/Document/%d", c.baseURL, docID)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	c.setSessionHeaders(req, sessionToken)
+	req.Header.Set("Accept", "application/octet-stream")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("downloadDocument request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, "", fmt.Errorf("downloadDocument status %d: %s", resp.StatusCode, body)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("reading document %d: %w", docID, err)
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, "", fmt.Errorf("document %d larger than %d bytes", docID, maxBytes)
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+// GetTicketDocumentIDs returns the IDs of the documents linked to a ticket,
+// in the order they were linked; GetDocument reads each one.
+// Reference: nexus_apirest.md — GET /apirest.php/Ticket/:id/Document_Item
+func (c *Client) GetTicketDocumentIDs(sessionToken string, ticketID int) ([]int, error) {
+	url := fmt.Sprintf("%s/apirest.php/Ticket/%d/Document_Item", c.baseURL, ticketID)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setSessionHeaders(req, sessionToken)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("getTicketDocumentIDs request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if !listStatusOK(resp.StatusCode) {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("getTicketDocumentIDs status %d: %s", resp.StatusCode, body)
+	}
+
+	var links []struct {
+		DocumentsID int `json:"documents_id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&links); err != nil {
+		return nil, fmt.Errorf("decoding ticket documents: %w", err)
+	}
+	ids := make([]int, len(links))
+	for i, l := range links {
+		ids[i] = l.DocumentsID
+	}
+	return ids, nil
+}
+
+// GetDocument returns a Document's metadata; DownloadDocument returns its file.
+// Reference: nexus_apirest.md — GET /apirest.php/Document/:id
+func (c *Client) GetDocument(sessionToken string, docID int) (*Document, error) {
+	url := fmt.Sprintf("%s/apirest.php/Document/%d", c.baseURL, docID)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setSessionHeaders(req, sessionToken)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("getDocument request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("getDocument %w", newAPIError(resp.StatusCode, body))
+	}
+
+	var doc Document
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decoding document: %w", err)
+	}
+	return &doc, nil
+}
+
+// GetUser returns a user's basic identity.
+// Reference: nexus_apirest.md — GET /apirest.php/User/:id
+func (c *Client) GetUser(sessionToken string, userID int) (*GLPIUser, error) {
+	url := fmt.Sprintf("%s/apirest.php/User/%d", c.baseURL, userID)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setSessionHeaders(req, sessionToken)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("getUser request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("getUser status %d: %s", resp.StatusCode, body)
+	}
+
+	var user GLPIUser
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return nil, fmt.Errorf("decoding user: %w", err)
+	}
+	return &user, nil
+}
+
+// RespondTicketValidation approves or refuses a validation request.
+// Reference: PUT /apirest.php/TicketValidation/:id
+func (c *Client) RespondTicketValidation(sessionToken string, validationID int, approve bool, comment string) error {
+	status := ValidationRefused
+	if approve {
+		status = ValidationAccepted
+	}
+	input := map[string]any{
+		"status":             status,
+		"comment_validation": comment,
+	}
+	body, err := json.Marshal(glpiInput[map[string]any]{Input: input})
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/apirest.php/TicketValidation/%d", c.baseURL, validationID)
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	c.setWriteSessionHeaders(req, sessionToken)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return fmt.Errorf("respondTicketValidation request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("respondTicketValidation status %d: %s", resp.StatusCode, respBody)
+	}
+	return nil
+}
+
+// GetTicketSatisfaction returns the satisfaction survey for a ticket.
+// Reference: GET /apirest.php/Ticket/:id/TicketSatisfaction
+func (c *Client) GetTicketSatisfaction(sessionToken string, ticketID int) (*TicketSatisfaction, error) {
+	url := fmt.Sprintf("%s/apirest.php/Ticket/%d/TicketSatisfaction", c.baseURL, ticketID)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setSessionHeaders(req, sessionToken)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("getTicketSatisfaction request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if !listStatusOK(resp.StatusCode) {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("getTicketSatisfaction status %d: %s", resp.StatusCode, body)
+	}
+
+	var surveys []TicketSatisfaction
+	if err := json.NewDecoder(resp.Body).Decode(&surveys); err != nil {
+		return nil, fmt.Errorf("decoding ticket satisfaction: %w", err)
+	}
+	if len(surveys) == 0 {
+		return nil, nil
+	}
+	return &surveys[0], nil
+}
+
+// RateTicketSatisfaction submits a satisfaction rating for a ticket.
+// Reference: PUT /apirest.php/TicketSatisfaction/:id
 func (c *Client) RateTicketSatisfaction(sessionToken string, satisfactionID int, rating int, comment string) error {
 	input := map[string]any{
 		"satisfaction": rating,
@@ -829,7 +1584,7 @@ func (c *Client) RateTicketSatisfaction(sessionToken string, satisfactionID int,
 	}
 	c.setWriteSessionHeaders(req, sessionToken)
 
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return fmt.Errorf("rateTicketSatisfaction request: %w", err)
 	}
@@ -852,13 +1607,13 @@ func (c *Client) GetTicketLogs(sessionToken string, ticketID int) ([]LogEntry, e
 	}
 	c.setSessionHeaders(req, sessionToken)
 
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("getTicketLogs request: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	if !listStatusOK(resp.StatusCode) {
 		body, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("getTicketLogs status %d: %s", resp.StatusCode, body)
 	}
@@ -873,7 +1628,27 @@ func (c *Client) GetTicketLogs(sessionToken string, ticketID int) ([]LogEntry, e
 // AdvancedSearchTickets searches tickets with multiple criteria.
 // Reference: GET /apirest.php/search/Ticket/
 func (c *Client) AdvancedSearchTickets(sessionToken string, criteria map[string]string) (*SearchResponse, error) {
-	req, err := http.NewRequest(http.MethodGet, c.baseURL+"/apirest.php/search/Ticket/", nil)
+	return c.searchITIL(sessionToken, "Ticket", criteria)
+}
+
+// SearchProblems searches problems; criteria use the same field IDs as
+// tickets for what Problem shares with them (title, status, dates, actors).
+// Reference: GET /apirest.php/search/Problem/
+func (c *Client) SearchProblems(sessionToken string, criteria map[string]string) (*SearchResponse, error) {
+	return c.searchITIL(sessionToken, "Problem", criteria)
+}
+
+// SearchChanges searches changes, like SearchProblems.
+// Reference: GET /apirest.php/search/Change/
+func (c *Client) SearchChanges(sessionToken string, criteria map[string]string) (*SearchResponse, error) {
+	return c.searchITIL(sessionToken, "Change", criteria)
+}
+
+// searchITIL runs a search on an ITIL object (Ticket, Problem, Change). The
+// displayed columns are the search options they have in common
+// (CommonITILObject), plus the SLA ones only tickets have.
+func (c *Client) searchITIL(sessionToken, itemtype string, criteria map[string]string) (*SearchResponse, error) {
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+"/apirest.php/search/"+itemtype+"/", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -884,39 +1659,120 @@ func (c *Client) AdvancedSearchTickets(sessionToken string, criteria map[string]
 		q.Set(k, v)
 	}
 	// Always show useful fields
-	q.Set("forcedisplay[0]", "2")  // ID
-	q.Set("forcedisplay[1]", "1")  // Name
-	q.Set("forcedisplay[2]", "12") // Status
-	q.Set("forcedisplay[3]", "15") // Opening date
-	q.Set("forcedisplay[4]", "10") // Urgency
-	q.Set("forcedisplay[5]", "3")  // Priority
-	q.Set("forcedisplay[6]", "7")  // Category
-	q.Set("forcedisplay[7]", "5")  // Assigned technician
-	q.Set("forcedisplay[8]", "4")  // Requester
-	q.Set("forcedisplay[9]", "16") // Closing date
+	q.Set("forcedisplay[0]", "2")   // ID
+	q.Set("forcedisplay[1]", "1")   // Name
+	q.Set("forcedisplay[2]", "12")  // Status
+	q.Set("forcedisplay[3]", "15")  // Opening date
+	q.Set("forcedisplay[4]", "10")  // Urgency
+	q.Set("forcedisplay[5]", "3")   // Priority
+	q.Set("forcedisplay[6]", "7")   // Category
+	q.Set("forcedisplay[7]", "5")   // Assigned technician
+	q.Set("forcedisplay[8]", "4")   // Requester
+	q.Set("forcedisplay[9]", "16")  // Closing date
+	q.Set("forcedisplay[10]", "17") // Resolution date
+	q.Set("forcedisplay[11]", "11") // Impact
+	// [12] is left for callers' own columns.
+	if itemtype == "Ticket" {
+		q.Set("forcedisplay[13]", "18")  // Time to resolve (SLA)
+		q.Set("forcedisplay[14]", "155") // Time to own (SLA)
+	}
 	if _, ok := criteria["range"]; !ok {
 		q.Set("range", "0-19")
 	}
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
-		return nil, fmt.Errorf("advancedSearchTickets request: %w", err)
+		return nil, fmt.Errorf("search %s request: %w", itemtype, err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+	if !listStatusOK(resp.StatusCode) {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("advancedSearchTickets status %d: %s", resp.StatusCode, body)
+		return nil, fmt.Errorf("search %s status %d: %s", itemtype, resp.StatusCode, body)
 	}
 
 	var result SearchResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("decoding advanced search results: %w", err)
+		return nil, fmt.Errorf("decoding %s search results: %w", itemtype, err)
 	}
 	return &result, nil
 }
 
+// SearchDeletedTickets is AdvancedSearchTickets over the trash: GLPI's search
+// only returns deleted items when asked with is_deleted=1, and then only them.
+// The API doc doesn't list is_deleted for search, but it's passed on to GLPI's
+// Search::manageParams like the other query params.
+// Reference: nexus_apirest.md — GET /apirest.php/search/:itemtype/
+func (c *Client) SearchDeletedTickets(sessionToken string, criteria map[string]string) (*SearchResponse, error) {
+	withDeleted := make(map[string]string, len(criteria)+1)
+	for k, v := range criteria {
+		withDeleted[k] = v
+	}
+	withDeleted["is_deleted"] = "1"
+	return c.AdvancedSearchTickets(sessionToken, withDeleted)
+}
+
+// RestoreTicket takes a ticket out of the trash. UpdateTicketInput can't carry
+// is_deleted=0 (omitempty drops it), so the field is sent on its own.
+// Reference: nexus_apirest.md — PUT /apirest.php/Ticket/:id
+func (c *Client) RestoreTicket(sessionToken string, ticketID int) error {
+	return c.updateItem(sessionToken, "Ticket", ticketID, map[string]any{"is_deleted": 0})
+}
+
+// SearchUsers finds users whose login, first name or last name contains query.
+// Reference: nexus_apirest.md — GET /apirest.php/search/User/
+func (c *Client) SearchUsers(sessionToken, query string) ([]UserSummary, error) {
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+"/apirest.php/search/User/", nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setSessionHeaders(req, sessionToken)
+
+	// User search fields: 1=Login, 2=ID, 9=First name, 34=Last name
+	q := req.URL.Query()
+	for i, field := range []string{"1", "9", "34"} {
+		prefix := fmt.Sprintf("criteria[%d]", i)
+		if i > 0 {
+			q.Set(prefix+"[link]", "OR")
+		}
+		q.Set(prefix+"[field]", field)
+		q.Set(prefix+"[searchtype]", "contains")
+		q.Set(prefix+"[value]", query)
+	}
+	q.Set("forcedisplay[0]", "2")
+	q.Set("forcedisplay[1]", "1")
+	q.Set("forcedisplay[2]", "9")
+	q.Set("forcedisplay[3]", "34")
+	q.Set("range", "0-9")
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("searchUsers request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if !listStatusOK(resp.StatusCode) {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("searchUsers status %d: %s", resp.StatusCode, body)
+	}
+
+	var result SearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decoding user search results: %w", err)
+	}
+	users := make([]UserSummary, 0, len(result.Data))
+	for _, d := range result.Data {
+		id, _ := d["2"].(float64)
+		login, _ := d["1"].(string)
+		first, _ := d["9"].(string)
+		last, _ := d["34"].(string)
+		users = append(users, UserSummary{ID: int(id), Login: login, FirstName: first, RealName: last})
+	}
+	return users, nil
+}
+
 // GetCategories returns ITIL ticket categories filtered by parent.
 // parentID=0 returns root categories (departments), parentID>0 returns sub-categories.
 // Uses the list endpoint with searchText filter on itilcategories_id.
@@ -933,13 +1789,13 @@ func (c *Client) GetCategories(sessionToken string, parentID int) ([]ITILCategor
 	q.Set("range", "0-49")
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("getCategories request: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+	if !listStatusOK(resp.StatusCode) {
 		body, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("getCategories status %d: %s", resp.StatusCode, body)
 	}
@@ -950,3 +1806,404 @@ func (c *Client) GetCategories(sessionToken string, parentID int) ([]ITILCategor
 	}
 	return categories, nil
 }
+
+// GetSLAs returns every SLA.
+// Reference: nexus_apirest.md — GET /apirest.php/SLA
+func (c *Client) GetSLAs(sessionToken string) ([]SLA, error) {
+	var slas []SLA
+	return slas, c.listItems(sessionToken, "SLA", map[string]string{"range": "0-199"}, &slas)
+}
+
+// GetTicketRules returns the ticket business rules, where categories get
+// their SLA.
+// Reference: nexus_apirest.md — GET /apirest.php/RuleTicket
+func (c *Client) GetTicketRules(sessionToken string) ([]TicketRule, error) {
+	var rules []TicketRule
+	return rules, c.listItems(sessionToken, "RuleTicket", map[string]string{"range": "0-499"}, &rules)
+}
+
+// GetRuleCriteria returns the criteria of every rule testing field.
+// Reference: nexus_apirest.md — GET /apirest.php/RuleCriteria
+func (c *Client) GetRuleCriteria(sessionToken, field string) ([]RuleCriterion, error) {
+	var list []RuleCriterion
+	if err := c.listItems(sessionToken, "RuleCriteria", map[string]string{
+		"searchText[criteria]": field,
+		"range":                "0-999",
+	}, &list); err != nil {
+		return nil, err
+	}
+	// searchText is a LIKE match: itilcategories_id also matches _itilcategories_id_code.
+	exact := list[:0]
+	for _, cr := range list {
+		if cr.Criteria == field {
+			exact = append(exact, cr)
+		}
+	}
+	return exact, nil
+}
+
+// GetRuleActions returns the actions of every rule setting field.
+// Reference: nexus_apirest.md — GET /apirest.php/RuleAction
+func (c *Client) GetRuleActions(sessionToken, field string) ([]RuleAction, error) {
+	var list []RuleAction
+	if err := c.listItems(sessionToken, "RuleAction", map[string]string{
+		"searchText[field]": field,
+		"range":             "0-999",
+	}, &list); err != nil {
+		return nil, err
+	}
+	// searchText is a LIKE match, see GetRuleCriteria.
+	exact := list[:0]
+	for _, a := range list {
+		if a.Field == field {
+			exact = append(exact, a)
+		}
+	}
+	return exact, nil
+}
+
+func (c *Client) listItems(sessionToken, itemtype string, params map[string]string, out any) error {
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+"/apirest.php/"+itemtype+"/", nil)
+	if err != nil {
+		return err
+	}
+	c.setSessionHeaders(req, sessionToken)
+
+	q := req.URL.Query()
+	for k, v := range params {
+		q.Set(k, v)
+	}
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.do(req)
+	if err != nil {
+		return fmt.Errorf("list %s request: %w", itemtype, err)
+	}
+	defer resp.Body.Close()
+
+	if !listStatusOK(resp.StatusCode) {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("list %s status %d: %s", itemtype, resp.StatusCode, body)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding %s list: %w", itemtype, err)
+	}
+	return nil
+}
+
+// GetTicketCategoryID returns the category ID of a ticket, which GetTicket
+// only has as a name (expand_dropdowns).
+// Reference: nexus_apirest.md — GET /apirest.php/Ticket/:id
+func (c *Client) GetTicketCategoryID(sessionToken string, ticketID int) (int, error) {
+	url := fmt.Sprintf("%s/apirest.php/Ticket/%d", c.baseURL, ticketID)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	c.setSessionHeaders(req, sessionToken)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return 0, fmt.Errorf("getTicketCategoryID request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("getTicketCategoryID status %d: %s", resp.StatusCode, body)
+	}
+
+	var ticket struct {
+		ITILCategoriesID int `json:"itilcategories_id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&ticket); err != nil {
+		return 0, fmt.Errorf("decoding ticket: %w", err)
+	}
+	return ticket.ITILCategoriesID, nil
+}
+
+// GetCategory returns an ITIL category.
+// Reference: nexus_apirest.md — GET /apirest.php/ITILCategory/:id
+func (c *Client) GetCategory(sessionToken string, id int) (*ITILCategory, error) {
+	url := fmt.Sprintf("%s/apirest.php/ITILCategory/%d", c.baseURL, id)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setSessionHeaders(req, sessionToken)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("getCategory request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("getCategory status %d: %s", resp.StatusCode, body)
+	}
+
+	var category ITILCategory
+	if err := json.NewDecoder(resp.Body).Decode(&category); err != nil {
+		return nil, fmt.Errorf("decoding category: %w", err)
+	}
+	return &category, nil
+}
+
+// GetItemName returns the display name of a dropdown item (ITILCategory,
+// Group, Location...): the full path for tree dropdowns, else the name.
+// Reference: nexus_apirest.md — GET /apirest.php/:itemtype/:id
+func (c *Client) GetItemName(sessionToken, itemtype string, id int) (string, error) {
+	url := fmt.Sprintf("%s/apirest.php/%s/%d", c.baseURL, itemtype, id)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	c.setSessionHeaders(req, sessionToken)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return "", fmt.Errorf("getItemName request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("getItemName %s status %d: %s", itemtype, resp.StatusCode, body)
+	}
+
+	var item struct {
+		Name         string `json:"name"`
+		Completename string `json:"completename"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&item); err != nil {
+		return "", fmt.Errorf("decoding %s: %w", itemtype, err)
+	}
+	if item.Completename != "" {
+		return item.Completename, nil
+	}
+	return item.Name, nil
+}
+
+// GetTicketItems returns the items (assets) linked to a ticket.
+// Reference: GET /apirest.php/Ticket/:id/Item_Ticket
+func (c *Client) GetTicketItems(sessionToken string, ticketID int) ([]ItemTicket, error) {
+	url := fmt.Sprintf("%s/apirest.php/Ticket/%d/Item_Ticket", c.baseURL, ticketID)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setSessionHeaders(req, sessionToken)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("getTicketItems request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if !listStatusOK(resp.StatusCode) {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("getTicketItems status %d: %s", resp.StatusCode, body)
+	}
+
+	var items []ItemTicket
+	if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
+		return nil, fmt.Errorf("decoding ticket items: %w", err)
+	}
+	return items, nil
+}
+
+// GetAssetTickets returns the ticket links of an asset. Links are readable
+// with the asset, so they may point to tickets the session can't see.
+// Reference: GET /apirest.php/:itemtype/:id/Item_Ticket
+func (c *Client) GetAssetTickets(sessionToken, itemtype string, id int) ([]ItemTicket, error) {
+	url := fmt.Sprintf("%s/apirest.php/%s/%d/Item_Ticket?range=0-199", c.baseURL, itemtype, id)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setSessionHeaders(req, sessionToken)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("getAssetTickets request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if !listStatusOK(resp.StatusCode) {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("getAssetTickets status %d: %s", resp.StatusCode, body)
+	}
+
+	var links []ItemTicket
+	if err := json.NewDecoder(resp.Body).Decode(&links); err != nil {
+		return nil, fmt.Errorf("decoding asset tickets: %w", err)
+	}
+	return links, nil
+}
+
+// GetAsset returns an asset of any itemtype with dropdowns expanded.
+// Reference: nexus_apirest.md — GET /apirest.php/:itemtype/:id
+func (c *Client) GetAsset(sessionToken, itemtype string, id int) (*Asset, error) {
+	url := fmt.Sprintf("%s/apirest.php/%s/%d?expand_dropdowns=true", c.baseURL, itemtype, id)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setSessionHeaders(req, sessionToken)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("getAsset request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("getAsset status %d: %s", resp.StatusCode, body)
+	}
+
+	var asset Asset
+	if err := json.NewDecoder(resp.Body).Decode(&asset); err != nil {
+		return nil, fmt.Errorf("decoding asset: %w", err)
+	}
+	return &asset, nil
+}
+
+// GetReservationItem returns the reservation entry of an asset, or nil if the
+// asset isn't reservable.
+// Reference: GET /apirest.php/ReservationItem/
+func (c *Client) GetReservationItem(sessionToken, itemtype string, itemsID int) (*ReservationItem, error) {
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+"/apirest.php/ReservationItem/", nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setSessionHeaders(req, sessionToken)
+
+	q := req.URL.Query()
+	q.Set("searchText[itemtype]", itemtype)
+	q.Set("searchText[items_id]", fmt.Sprintf("%d", itemsID))
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("getReservationItem request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if !listStatusOK(resp.StatusCode) {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("getReservationItem status %d: %s", resp.StatusCode, body)
+	}
+
+	var items []ReservationItem
+	if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
+		return nil, fmt.Errorf("decoding reservation items: %w", err)
+	}
+	// searchText is a LIKE match; keep the exact asset only
+	for _, it := range items {
+		if it.ItemType == itemtype && it.ItemsID == itemsID {
+			return &it, nil
+		}
+	}
+	return nil, nil
+}
+
+// reservationsPageSize bounds each GetReservations request.
+const reservationsPageSize = 100
+
+// GetReservations returns the reservations of a reservable item that end
+// after from (a GLPI datetime), ordered by begin. Sub-item lists can't be
+// filtered by date, so pages are read latest end first until one reaches
+// past reservations; a single fixed range would miss future bookings on
+// items with a long history.
+// Reference: GET /apirest.php/ReservationItem/:id/Reservation
+func (c *Client) GetReservations(sessionToken string, reservationItemID int, from string) ([]Reservation, error) {
+	var upcoming []Reservation
+	for start := 0; ; start += reservationsPageSize {
+		page, more, err := c.getReservationsPage(sessionToken, reservationItemID, start)
+		if err != nil {
+			return nil, err
+		}
+		reachedPast := false
+		for _, r := range page {
+			// Same layout on both sides, so string order is time order.
+			if r.End <= from {
+				reachedPast = true
+				break
+			}
+			upcoming = append(upcoming, r)
+		}
+		if reachedPast || !more {
+			break
+		}
+	}
+	slices.SortFunc(upcoming, func(a, b Reservation) int { return strings.Compare(a.Begin, b.Begin) })
+	return upcoming, nil
+}
+
+func (c *Client) getReservationsPage(sessionToken string, reservationItemID, start int) ([]Reservation, bool, error) {
+	url := fmt.Sprintf("%s/apirest.php/ReservationItem/%d/Reservation", c.baseURL, reservationItemID)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	c.setSessionHeaders(req, sessionToken)
+
+	q := req.URL.Query()
+	q.Set("sort", "end")
+	q.Set("order", "DESC")
+	q.Set("range", fmt.Sprintf("%d-%d", start, start+reservationsPageSize-1))
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, false, fmt.Errorf("getReservations request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if !listStatusOK(resp.StatusCode) {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, false, fmt.Errorf("getReservations status %d: %s", resp.StatusCode, body)
+	}
+
+	var reservations []Reservation
+	if err := json.NewDecoder(resp.Body).Decode(&reservations); err != nil {
+		return nil, false, fmt.Errorf("decoding reservations: %w", err)
+	}
+	return reservations, hasMoreItems(resp), nil
+}
+
+// CreateReservation books a reservable item for a time range.
+// Reference: POST /apirest.php/Reservation/
+func (c *Client) CreateReservation(sessionToken string, input CreateReservationInput) (int, error) {
+	body, err := json.Marshal(glpiInput[CreateReservationInput]{Input: input})
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+"/apirest.php/Reservation/", bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	c.setWriteSessionHeaders(req, sessionToken)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return 0, fmt.Errorf("createReservation request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		respBody, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("createReservation status %d: %s", resp.StatusCode, respBody)
+	}
+
+	var result struct {
+		ID int `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("decoding createReservation response: %w", err)
+	}
+	return result.ID, nil
+}
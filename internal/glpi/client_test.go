@@ -0,0 +1,222 @@
+package glpi
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestClient(t *testing.T, h http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return NewClient(srv.URL, "app-token", "", 0, Timeouts{})
+}
+
+func TestMaintenancePage(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		status      int
+		body        string
+		maintenance bool
+	}{
+		{"html maintenance page", "text/html; charset=UTF-8", http.StatusServiceUnavailable, "<html>Em manutenção</html>", true},
+		{"html with 200", "text/html", http.StatusOK, "<html></html>", true},
+		{"json ticket", "application/json", http.StatusOK, `{"id": 42, "name": "Impressora"}`, false},
+		{"json error", "application/json", http.StatusNotFound, `["ERROR_ITEM_NOT_FOUND", "not found"]`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+			_, err := c.GetTicket("session", 42)
+			if got := errors.Is(err, ErrMaintenance); got != tt.maintenance {
+				t.Errorf("errors.Is(%v, ErrMaintenance) = %v, want %v", err, got, tt.maintenance)
+			}
+		})
+	}
+}
+
+func TestWriteCallsSendSessionWrite(t *testing.T) {
+	calls := map[string]func(c *Client) error{
+		"CreateTicket": func(c *Client) error {
+			_, err := c.CreateTicket("session", CreateTicketInput{Name: "Impressora"})
+			return err
+		},
+		"UpdateTicket": func(c *Client) error { return c.UpdateTicket("session", 1, UpdateTicketInput{Status: 5}) },
+		"UpdateTicketUser": func(c *Client) error {
+			return c.UpdateTicketUser("session", 1, UpdateTicketUserInput{})
+		},
+		"UpdateUser": func(c *Client) error { return c.UpdateUser("session", 1, UpdateUserInput{}) },
+		"AddFollowup": func(c *Client) error {
+			_, err := c.AddFollowup("session", 1, "oi")
+			return err
+		},
+		"AddTicketTask": func(c *Client) error {
+			_, err := c.AddTicketTask("session", 1, "tarefa", 1)
+			return err
+		},
+		"LogTicketTaskTime": func(c *Client) error {
+			_, err := c.LogTicketTaskTime("session", 1, "tarefa", 60)
+			return err
+		},
+		"SetTicketTaskTime": func(c *Client) error { return c.SetTicketTaskTime("session", 1, 60) },
+		"UploadDocument": func(c *Client) error {
+			_, err := c.UploadDocument("session", "log", "log.txt", []byte("x"), "Ticket", 1)
+			return err
+		},
+		"RespondTicketValidation": func(c *Client) error {
+			return c.RespondTicketValidation("session", 1, true, "")
+		},
+		"RateTicketSatisfaction": func(c *Client) error { return c.RateTicketSatisfaction("session", 1, 5, "") },
+		"RestoreTicket":          func(c *Client) error { return c.RestoreTicket("session", 1) },
+		"CreateReservation": func(c *Client) error {
+			_, err := c.CreateReservation("session", CreateReservationInput{ReservationItemsID: 1})
+			return err
+		},
+	}
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			var sessionWrite string
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				sessionWrite = r.URL.Query().Get("session_write")
+				w.Header().Set("Content-Type", "application/json")
+				if r.Method == http.MethodPost {
+					w.WriteHeader(http.StatusCreated)
+					w.Write([]byte(`{"id": 1}`))
+					return
+				}
+				w.Write([]byte(`[{"1": true}]`))
+			})
+			if err := call(c); err != nil {
+				t.Fatal(err)
+			}
+			if sessionWrite != "true" {
+				t.Errorf("session_write = %q, want \"true\"", sessionWrite)
+			}
+		})
+	}
+}
+
+func TestListCallsAcceptPartialContent(t *testing.T) {
+	const items = `[{"id": 1}]`
+	const search = `{"totalcount": 120, "count": 1, "data": [{"2": 1}]}`
+	tests := []struct {
+		name string
+		body string
+		call func(c *Client) (int, error)
+	}{
+		{"GetTicketTasks", items, func(c *Client) (int, error) {
+			l, err := c.GetTicketTasks("session", 1)
+			return len(l), err
+		}},
+		{"GetFollowups", items, func(c *Client) (int, error) {
+			l, err := c.GetFollowups("session", 1)
+			return len(l), err
+		}},
+		{"GetTicketLogs", items, func(c *Client) (int, error) {
+			l, err := c.GetTicketLogs("session", 1)
+			return len(l), err
+		}},
+		{"GetForms", items, func(c *Client) (int, error) {
+			l, err := c.GetForms("session")
+			return len(l), err
+		}},
+		{"SearchTickets", search, func(c *Client) (int, error) {
+			r, err := c.SearchTickets("session", "impressora", 7)
+			if err != nil {
+				return 0, err
+			}
+			return len(r.Data), nil
+		}},
+		{"AdvancedSearchTickets", search, func(c *Client) (int, error) {
+			r, err := c.AdvancedSearchTickets("session", map[string]string{})
+			if err != nil {
+				return 0, err
+			}
+			return len(r.Data), nil
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Content-Range", "0-0/1")
+				w.WriteHeader(http.StatusPartialContent)
+				w.Write([]byte(tt.body))
+			})
+			n, err := tt.call(c)
+			if err != nil {
+				t.Fatalf("206 rejected: %v", err)
+			}
+			if n != 1 {
+				t.Errorf("got %d items, want 1", n)
+			}
+		})
+	}
+}
+
+func TestListCallsRejectErrors(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`["ERROR_RANGE_EXCEEDED_TOTAL", "range exceeds total"]`))
+	})
+	if _, err := c.GetTicketTasks("session", 1); err == nil {
+		t.Error("GetTicketTasks accepted a 400")
+	}
+	if _, err := c.SearchTickets("session", "impressora", 7); err == nil {
+		t.Error("SearchTickets accepted a 400")
+	}
+}
+
+func TestKillSession(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr bool
+	}{
+		{"killed", http.StatusOK, `[]`, false},
+		{"already killed", http.StatusUnauthorized, `["ERROR_SESSION_TOKEN_INVALID", "session_token inválido"]`, false},
+		{"other auth error", http.StatusUnauthorized, `["ERROR_APP_TOKEN_PARAMETERS_MISSING", "app_token ausente"]`, true},
+		{"server error", http.StatusInternalServerError, `["ERROR", "falha"]`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+			if err := c.KillSession("session"); (err != nil) != tt.wantErr {
+				t.Errorf("KillSession = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKillSessionTwice(t *testing.T) {
+	live := map[string]bool{"session": true}
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		token := r.Header.Get("Session-Token")
+		if !live[token] {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`["ERROR_SESSION_TOKEN_INVALID", "session_token inválido"]`))
+			return
+		}
+		delete(live, token)
+		w.Write([]byte(`[]`))
+	})
+	for i := range 2 {
+		if err := c.KillSession("session"); err != nil {
+			t.Errorf("kill %d: %v", i+1, err)
+		}
+	}
+}
@@ -1,7 +1,14 @@
 package glpi
 
+import (
+	"encoding/json"
+	"strings"
+)
+
 type InitSessionResponse struct {
 	SessionToken string `json:"session_token"`
+	// Session is set when initSession was called with get_full_session.
+	Session *SessionInfo `json:"session"`
 }
 
 // FullSession is returned by GET /apirest.php/getFullSession
@@ -10,9 +17,38 @@ type FullSession struct {
 }
 
 type SessionInfo struct {
-	GlpiID           int    `json:"glpiID"`
-	GlpiName         string `json:"glpiname"`
-	GlpiFriendlyName string `json:"glpifriendlyname"`
+	GlpiID            int           `json:"glpiID"`
+	GlpiName          string        `json:"glpiname"`
+	GlpiFriendlyName  string        `json:"glpifriendlyname"`
+	GlpiActiveProfile ActiveProfile `json:"glpiactiveprofile"`
+}
+
+type ActiveProfile struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	// Interface is "helpdesk" for self-service profiles, "central" otherwise.
+	Interface string `json:"interface"`
+	// TicketRight is the profile's Ticket rights bitmask (see TicketRead* constants).
+	TicketRight int `json:"ticket"`
+}
+
+// Ticket rights bits from GLPI's Ticket class.
+// Reference: https://github.com/glpi-project/glpi/blob/main/src/Ticket.php (READALL, READGROUP)
+const (
+	TicketReadAll   = 1024
+	TicketReadGroup = 2048
+)
+
+// TicketDelete is the generic DELETE right (put in / restore from the trash).
+// Reference: https://github.com/glpi-project/glpi/blob/main/inc/define.php (DELETE)
+const TicketDelete = 8
+
+// UserSummary is a row from the User search.
+type UserSummary struct {
+	ID        int
+	Login     string
+	FirstName string
+	RealName  string
 }
 
 type Ticket struct {
@@ -30,6 +66,7 @@ type TicketDetail struct {
 	Content          string `json:"content"`
 	Status           int    `json:"status"`
 	Urgency          int    `json:"urgency"`
+	Impact           int    `json:"impact"`
 	Priority         int    `json:"priority"`
 	Type             int    `json:"type"`
 	UsersIDRecipient any    `json:"users_id_recipient"`
@@ -38,13 +75,73 @@ type TicketDetail struct {
 	SolveDate        string `json:"solvedate"`
 	CloseDate        string `json:"closedate"`
 	ITILCategoriesID any    `json:"itilcategories_id"`
+	// SLA due dates; empty when no SLA applies to the ticket.
+	TimeToOwn            string `json:"time_to_own"`
+	TimeToResolve        string `json:"time_to_resolve"`
+	TakeIntoAccountDelay int    `json:"takeintoaccount_delay_stat"`
+	// Current escalation level of the TTR SLA (name with expand_dropdowns, 0 when none).
+	SLALevelTTR any `json:"slalevels_id_ttr"`
 }
 
 type Followup struct {
-	ID          int    `json:"id"`
-	Content     string `json:"content"`
-	DateCreated string `json:"date"`
-	UsersID     int    `json:"users_id"`
+	ID          int       `json:"id"`
+	Content     string    `json:"content"`
+	DateCreated string    `json:"date"`
+	UsersID     int       `json:"users_id"`
+	KeysNames   KeysNames `json:"_keys_names"`
+}
+
+// KeysNames holds the friendly names GLPI adds for the foreign keys passed in
+// add_keys_names[], keyed by field (e.g. "users_id").
+// Reference: nexus_apirest.md — Get an item (add_keys_names)
+type KeysNames map[string]any
+
+// Get returns the name resolved for field, or "" when GLPI didn't resolve it
+// (deleted item, no read right, or field not requested).
+func (k KeysNames) Get(field string) string {
+	name, _ := k[field].(string)
+	return strings.TrimSpace(name)
+}
+
+// Document is a file stored in GLPI. Mime is what GLPI detected on upload and
+// may be empty for old or imported documents.
+type Document struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Filename string `json:"filename"`
+	Mime     string `json:"mime"`
+}
+
+// Ticket_User actor types.
+const (
+	ActorRequester = 1
+	ActorAssigned  = 2
+	ActorObserver  = 3
+)
+
+// TicketUser links a user to a ticket as requester, assigned technician or
+// observer.
+type TicketUser struct {
+	ID               int    `json:"id"`
+	TicketsID        int    `json:"tickets_id"`
+	UsersID          int    `json:"users_id"`
+	Type             int    `json:"type"`
+	UseNotification  int    `json:"use_notification"`
+	AlternativeEmail string `json:"alternative_email"`
+}
+
+// UpdateTicketUserInput changes how an actor is notified on one ticket:
+// AlternativeEmail replaces the user's address for this ticket only.
+type UpdateTicketUserInput struct {
+	UseNotification  int    `json:"use_notification,omitempty"`
+	AlternativeEmail string `json:"alternative_email,omitempty"`
+}
+
+// UpdateUserInput holds the contact fields of a GLPI user. Email addresses
+// live in UserEmail and are not updated through User.
+type UpdateUserInput struct {
+	Phone  string `json:"phone,omitempty"`
+	Mobile string `json:"mobile,omitempty"`
 }
 
 type CreateTicketInput struct {
@@ -52,12 +149,39 @@ type CreateTicketInput struct {
 	Content          string `json:"content"`
 	ITILCategoriesID int    `json:"itilcategories_id,omitempty"`
 	Urgency          int    `json:"urgency,omitempty"`
+	Impact           int    `json:"impact,omitempty"`
 	Type             int    `json:"type,omitempty"`
 	UsersIDRequester int    `json:"_users_id_requester,omitempty"`
 	UsersIDAssign    []int  `json:"_users_id_assign,omitempty"`
 	GroupsIDAssign   []int  `json:"_groups_id_assign,omitempty"`
 	UsersIDObserver  []int  `json:"_users_id_observer,omitempty"`
 	GroupsIDObserver []int  `json:"_groups_id_observer,omitempty"`
+	LocationsID      int    `json:"locations_id,omitempty"`
+	// CustomFields are plugin Fields values keyed by the plugin's field name
+	// (e.g. "matriculafield"); the plugin reads them from the same input.
+	// Keys that collide with the fields above are ignored.
+	CustomFields map[string]any `json:"-"`
+}
+
+// MarshalJSON flattens CustomFields into the ticket input object.
+func (in CreateTicketInput) MarshalJSON() ([]byte, error) {
+	type plain CreateTicketInput
+	base, err := json.Marshal(plain(in))
+	if err != nil || len(in.CustomFields) == 0 {
+		return base, err
+	}
+	merged := make(map[string]any, len(in.CustomFields))
+	for k, v := range in.CustomFields {
+		merged[k] = v
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(base, &fields); err != nil {
+		return nil, err
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return json.Marshal(merged)
 }
 
 // TargetTicket is a FormCreator target that defines how a ticket is created from a form.
@@ -79,9 +203,11 @@ type UpdateTicketInput struct {
 	Content          string `json:"content,omitempty"`
 	Status           int    `json:"status,omitempty"`
 	Urgency          int    `json:"urgency,omitempty"`
+	Impact           int    `json:"impact,omitempty"`
 	Priority         int    `json:"priority,omitempty"`
 	ITILCategoriesID int    `json:"itilcategories_id,omitempty"`
 	Type             int    `json:"type,omitempty"`
+	LocationsID      int    `json:"locations_id,omitempty"`
 }
 
 type TicketTask struct {
@@ -92,15 +218,48 @@ type TicketTask struct {
 	DateCreated string `json:"date"`
 	Actiontime  int    `json:"actiontime"`
 	PercentDone int    `json:"percent_done"`
+	// Begin/End are the planned intervention, empty when the task isn't
+	// scheduled; UsersIDTech is the technician it's planned for.
+	Begin       string `json:"begin"`
+	End         string `json:"end"`
+	UsersIDTech int    `json:"users_id_tech"`
 }
 
+// ITILSolution is a solution proposed on a ticket; rejected ones stay listed.
+type ITILSolution struct {
+	ID          int    `json:"id"`
+	Content     string `json:"content"`
+	Status      int    `json:"status"` // 2=waiting approval, 3=accepted, 4=refused
+	DateCreated string `json:"date_creation"`
+}
+
+// TicketValidation statuses (CommonITILValidation constants).
+const (
+	ValidationNone     = 1
+	ValidationWaiting  = 2
+	ValidationAccepted = 3
+	ValidationRefused  = 4
+)
+
 type TicketValidation struct {
 	ID                int    `json:"id"`
+	TicketsID         int    `json:"tickets_id"`
+	UsersID           int    `json:"users_id"` // who requested the approval
 	UsersIDValidate   int    `json:"users_id_validate"`
 	Status            int    `json:"status"`
 	CommentSubmission string `json:"comment_submission"`
 	CommentValidation string `json:"comment_validation"`
 	DateCreated       string `json:"submission_date"`
+	ValidationDate    string `json:"validation_date"`
+	// KeysNames resolves users_id and users_id_validate.
+	KeysNames KeysNames `json:"_keys_names"`
+}
+
+type GLPIUser struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"` // login
+	FirstName string `json:"firstname"`
+	RealName  string `json:"realname"`
 }
 
 type TicketSatisfaction struct {
@@ -137,19 +296,149 @@ type KBArticle struct {
 	DateMod string `json:"date_mod"`
 }
 
+type KBCategory struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	Completename string `json:"completename"`
+}
+
 type ITILCategory struct {
 	ID               int    `json:"id"`
 	Name             string `json:"name"`
 	Completename     string `json:"completename"`
 	ITILCategoriesID int    `json:"itilcategories_id"`
+	// Ticket types the category is visible for (1/0).
+	IsIncident int `json:"is_incident"`
+	IsRequest  int `json:"is_request"`
+}
+
+// SLA is a service level. Type is SLATypeTTR or SLATypeTTO; the target is
+// NumberTime units of DefinitionTime ("minute", "hour", "day", "month").
+type SLA struct {
+	ID             int    `json:"id"`
+	Name           string `json:"name"`
+	Type           int    `json:"type"`
+	NumberTime     int    `json:"number_time"`
+	DefinitionTime string `json:"definition_time"`
+	CalendarsID    int    `json:"calendars_id"`
+}
+
+// SLA types.
+const (
+	SLATypeTTR = 0
+	SLATypeTTO = 1
+)
+
+// TicketRule is a ticket business rule; Ranking is the order rules run in.
+type TicketRule struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	IsActive int    `json:"is_active"`
+	Ranking  int    `json:"ranking"`
+}
+
+// RuleCriterion is one condition of a rule (Condition RulePatternIs means
+// Criteria equals Pattern).
+type RuleCriterion struct {
+	ID        int    `json:"id"`
+	RulesID   int    `json:"rules_id"`
+	Criteria  string `json:"criteria"`
+	Condition int    `json:"condition"`
+	Pattern   string `json:"pattern"`
+}
+
+// RulePatternIs is the "is" condition of rule criteria.
+const RulePatternIs = 0
+
+// RuleAction is what a rule does when it matches, e.g. assign Value to Field.
+type RuleAction struct {
+	ID         int    `json:"id"`
+	RulesID    int    `json:"rules_id"`
+	ActionType string `json:"action_type"`
+	Field      string `json:"field"`
+	Value      string `json:"value"`
+}
+
+// ItemTicket links an asset (or any item) to a ticket.
+type ItemTicket struct {
+	ID        int    `json:"id"`
+	TicketsID int    `json:"tickets_id"`
+	ItemType  string `json:"itemtype"`
+	ItemsID   int    `json:"items_id"`
+}
+
+// Asset holds the fields shared by every asset itemtype (Computer, Monitor...).
+// Dropdowns are names when fetched with expand_dropdowns.
+type Asset struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Serial      string `json:"serial"`
+	OtherSerial string `json:"otherserial"` // inventory number (patrimonio)
+	States      any    `json:"states_id"`
+	Locations   any    `json:"locations_id"`
+}
+
+// ReservationItem marks an asset as reservable.
+type ReservationItem struct {
+	ID       int    `json:"id"`
+	ItemType string `json:"itemtype"`
+	ItemsID  int    `json:"items_id"`
+	IsActive int    `json:"is_active"`
+	Comment  string `json:"comment"`
+}
+
+type Reservation struct {
+	ID                 int    `json:"id"`
+	ReservationItemsID int    `json:"reservationitems_id"`
+	Begin              string `json:"begin"`
+	End                string `json:"end"`
+	UsersID            int    `json:"users_id"`
+	Comment            string `json:"comment"`
+}
+
+type CreateReservationInput struct {
+	ReservationItemsID int    `json:"reservationitems_id"`
+	Begin              string `json:"begin"`
+	End                string `json:"end"`
+	UsersID            int    `json:"users_id"`
+	Comment            string `json:"comment,omitempty"`
 }
 
 // FormCreator models — plugin PluginFormcreator
 // Reference: https://github.com/pluginsGLPI/formcreator
 
 type Form struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	AccessRights int    `json:"access_rights"`
+	Description  string `json:"description"`
+	// CategoryID is the form's PluginFormcreatorCategory, 0 when uncategorized.
+	CategoryID int `json:"plugin_formcreator_categories_id"`
+}
+
+// FormCategory groups forms in the FormCreator service catalog. It's a tree
+// dropdown; Completename has the whole path ("TI > Acessos").
+type FormCategory struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	Completename string `json:"completename"`
+}
+
+// Form access_rights values.
+// Reference: https://github.com/pluginsGLPI/formcreator/blob/2.13.x/inc/formaccesstype.class.php
+const (
+	FormAccessPublic     = 0
+	FormAccessPrivate    = 1 // any logged-in user
+	FormAccessRestricted = 2 // only listed profiles/users
+)
+
+// FormAccess is one entry of a restricted form's allow-list
+// (PluginFormcreatorForm_Profile or PluginFormcreatorForm_User).
+type FormAccess struct {
+	ID         int `json:"id"`
+	FormsID    int `json:"plugin_formcreator_forms_id"`
+	ProfilesID int `json:"profiles_id"`
+	UsersID    int `json:"users_id"`
 }
 
 type FormSection struct {
@@ -0,0 +1,178 @@
+package glpi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OAuthConfig holds the OAuth2 client registered in Nexus (Setup → OAuth clients).
+type OAuthConfig struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+}
+
+// ErrOAuthGrant means the code or refresh token was rejected (expired, revoked
+// or already used), so the user has to log in again.
+// Reference: RFC 6749 §5.2 — https://datatracker.ietf.org/doc/html/rfc6749#section-5.2
+var ErrOAuthGrant = errors.New("oauth grant rejected")
+
+type OAuthToken struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	TokenType    string `json:"token_type"`
+}
+
+type cachedAccessToken struct {
+	token     string
+	expiresAt time.Time
+}
+
+// oauthState keeps access tokens in memory, keyed by refresh token, so each
+// conversation doesn't cost a round-trip to the token endpoint.
+type oauthState struct {
+	cfg OAuthConfig
+
+	mu     sync.Mutex
+	access map[string]cachedAccessToken
+}
+
+// accessTokenSkew renews tokens slightly before they expire, so a token
+// doesn't lapse between being handed out and being used.
+const accessTokenSkew = time.Minute
+
+// EnableOAuth turns on the authorization-code login as an alternative to user tokens.
+func (c *Client) EnableOAuth(cfg OAuthConfig) {
+	c.oauth = &oauthState{cfg: cfg, access: make(map[string]cachedAccessToken)}
+}
+
+func (c *Client) OAuthEnabled() bool { return c.oauth != nil }
+
+// OAuthAuthorizeURL is where users are sent to log in with their Nexus credentials.
+// Reference: GLPI 11 OAuth2 server — GET /api.php/authorize
+func (c *Client) OAuthAuthorizeURL(state string) string {
+	q := url.Values{
+		"response_type": {"code"},
+		"client_id":     {c.oauth.cfg.ClientID},
+		"redirect_uri":  {c.oauth.cfg.RedirectURL},
+		"scope":         {"api"},
+		"state":         {state},
+	}
+	return c.baseURL + "/api.php/authorize?" + q.Encode()
+}
+
+// ExchangeOAuthCode trades the authorization code from the callback for tokens.
+// Reference: GLPI 11 OAuth2 server — POST /api.php/token (grant_type=authorization_code)
+func (c *Client) ExchangeOAuthCode(code string) (*OAuthToken, error) {
+	return c.requestOAuthToken(url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {c.oauth.cfg.RedirectURL},
+	})
+}
+
+// RefreshOAuthToken obtains a new access token. The returned refresh token may
+// differ from the one passed in (rotation) and must replace it in storage.
+// Reference: GLPI 11 OAuth2 server — POST /api.php/token (grant_type=refresh_token)
+func (c *Client) RefreshOAuthToken(refreshToken string) (*OAuthToken, error) {
+	tok, err := c.requestOAuthToken(url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if tok.RefreshToken == "" {
+		tok.RefreshToken = refreshToken
+	}
+	return tok, nil
+}
+
+func (c *Client) requestOAuthToken(form url.Values) (*OAuthToken, error) {
+	if c.oauth == nil {
+		return nil, fmt.Errorf("oauth not configured")
+	}
+	form.Set("client_id", c.oauth.cfg.ClientID)
+	form.Set("client_secret", c.oauth.cfg.ClientSecret)
+
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+"/api.php/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oauth token request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%w: status %d: %s", ErrOAuthGrant, resp.StatusCode, body)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("oauth token status %d: %s", resp.StatusCode, body)
+	}
+
+	var tok OAuthToken
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return nil, fmt.Errorf("decoding oauth token: %w", err)
+	}
+	return &tok, nil
+}
+
+// InitSessionOAuth opens a session for an OAuth-linked user, refreshing the
+// access token when the cached one is missing or about to expire. It returns
+// the refresh token to persist, which changes when Nexus rotates it.
+func (c *Client) InitSessionOAuth(refreshToken string) (sessionToken, newRefreshToken string, err error) {
+	if c.oauth == nil {
+		return "", "", fmt.Errorf("oauth not configured")
+	}
+
+	newRefreshToken = refreshToken
+	c.oauth.mu.Lock()
+	cached, ok := c.oauth.access[refreshToken]
+	c.oauth.mu.Unlock()
+
+	if !ok || time.Now().Add(accessTokenSkew).After(cached.expiresAt) {
+		tok, err := c.RefreshOAuthToken(refreshToken)
+		if err != nil {
+			return "", "", err
+		}
+		cached = cachedAccessToken{
+			token:     tok.AccessToken,
+			expiresAt: time.Now().Add(time.Duration(tok.ExpiresIn) * time.Second),
+		}
+		newRefreshToken = tok.RefreshToken
+
+		c.oauth.mu.Lock()
+		delete(c.oauth.access, refreshToken)
+		c.oauth.access[newRefreshToken] = cached
+		c.oauth.mu.Unlock()
+	}
+
+	sessionToken, err = c.InitSessionWithAccessToken(cached.token)
+	if err != nil {
+		return "", "", err
+	}
+	return sessionToken, newRefreshToken, nil
+}
+
+// InitSessionWithAccessToken opens a legacy API session with an OAuth access token.
+// TODO: confirm Nexus accepts Bearer tokens on apirest.php/initSession (GLPI 11+);
+// until then the user_token login stays the default.
+// Reference: nexus_apirest.md — GET /apirest.php/initSession
+func (c *Client) InitSessionWithAccessToken(accessToken string) (string, error) {
+	token, _, err := c.initSession("Bearer "+accessToken, true)
+	return token, err
+}
@@ -0,0 +1,54 @@
+package glpi
+
+import (
+	"sync"
+	"time"
+)
+
+// sessionProfileTTL drops profiles of sessions nobody killed. GLPI expires
+// idle sessions on its side long before this anyway.
+const sessionProfileTTL = time.Hour
+
+// sessionProfiles caches each open session's active profile, keyed by
+// session token.
+type sessionProfiles struct {
+	mu       sync.Mutex
+	profiles map[string]cachedProfile
+}
+
+type cachedProfile struct {
+	profile ActiveProfile
+	at      time.Time
+}
+
+func newSessionProfiles() *sessionProfiles {
+	return &sessionProfiles{profiles: make(map[string]cachedProfile)}
+}
+
+func (s *sessionProfiles) set(sessionToken string, p ActiveProfile) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for token, cp := range s.profiles {
+		if now.Sub(cp.at) >= sessionProfileTTL {
+			delete(s.profiles, token)
+		}
+	}
+	s.profiles[sessionToken] = cachedProfile{profile: p, at: now}
+}
+
+func (s *sessionProfiles) get(sessionToken string) (ActiveProfile, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cp, ok := s.profiles[sessionToken]
+	if !ok || time.Since(cp.at) >= sessionProfileTTL {
+		return ActiveProfile{}, false
+	}
+	return cp.profile, true
+}
+
+func (s *sessionProfiles) forget(sessionToken string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.profiles, sessionToken)
+}
@@ -0,0 +1,75 @@
+// Package logging configures slog and carries a per-request logger through context.
+package logging
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"os"
+)
+
+// Setup installs the default slog logger. format "json" is meant for production
+// (log aggregation); anything else uses the human-readable text handler.
+// Calls to the standard log package are routed through the same handler.
+// phoneKey keys HashPhone; when empty a random key is used, so hashes only
+// correlate within one process run.
+func Setup(format string, phoneKey []byte) {
+	if len(phoneKey) > 0 {
+		hashKey = phoneKey
+	}
+	var h slog.Handler
+	if format == "json" {
+		h = slog.NewJSONHandler(os.Stdout, nil)
+	} else {
+		h = slog.NewTextHandler(os.Stdout, nil)
+	}
+	slog.SetDefault(slog.New(h))
+}
+
+type ctxKey struct{}
+
+// WithLogger returns a context carrying l, retrieved later with FromContext.
+func WithLogger(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext returns the request logger stored in ctx, or the default logger.
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
+
+// ForRequest builds the logger for one inbound message, tagged with a fresh
+// request_id and the hashed phone.
+func ForRequest(phone string) *slog.Logger {
+	return slog.Default().With("request_id", NewRequestID(), "phone", HashPhone(phone))
+}
+
+// hashKey keys HashPhone. Phone numbers are a small keyspace, so a plain hash
+// could be reversed by enumerating them; without the key it can't.
+var hashKey = randomKey()
+
+func randomKey() []byte {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return b
+}
+
+// HashPhone pseudonymizes a phone number with an HMAC so logs can be
+// correlated per user without storing the number itself.
+func HashPhone(phone string) string {
+	mac := hmac.New(sha256.New, hashKey)
+	mac.Write([]byte(phone))
+	return hex.EncodeToString(mac.Sum(nil)[:6])
+}
+
+func NewRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
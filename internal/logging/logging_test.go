@@ -0,0 +1,49 @@
+package logging
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func TestHashPhone(t *testing.T) {
+	orig := hashKey
+	defer func() { hashKey = orig }()
+
+	hashKey = []byte("key-a")
+	a := HashPhone("5511987654321")
+	if len(a) != 12 {
+		t.Fatalf("HashPhone length = %d, want 12 hex chars", len(a))
+	}
+	if HashPhone("5511987654321") != a {
+		t.Error("HashPhone not stable for the same key")
+	}
+	if HashPhone("5511987654322") == a {
+		t.Error("different phones hashed alike")
+	}
+
+	// An unkeyed hash is what an attacker could enumerate.
+	plain := sha256.Sum256([]byte("5511987654321"))
+	if a == hex.EncodeToString(plain[:6]) {
+		t.Error("HashPhone matches the unkeyed SHA-256")
+	}
+
+	hashKey = []byte("key-b")
+	if HashPhone("5511987654321") == a {
+		t.Error("HashPhone ignores the key")
+	}
+}
+
+func TestSetupKeepsRandomKeyWhenEmpty(t *testing.T) {
+	orig := hashKey
+	defer func() { hashKey = orig }()
+
+	Setup("text", nil)
+	if len(hashKey) == 0 {
+		t.Fatal("empty phone key")
+	}
+	Setup("text", []byte("configured"))
+	if string(hashKey) != "configured" {
+		t.Errorf("hashKey = %q, want configured key", hashKey)
+	}
+}
@@ -0,0 +1,96 @@
+package reminder
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/lojasmm/laia/internal/logging"
+	"github.com/lojasmm/laia/internal/store"
+	"github.com/lojasmm/laia/internal/whatsapp"
+)
+
+// customerServiceWindow is how long after the user's last message WhatsApp
+// still accepts free-form messages from the business.
+// Reference: https://developers.facebook.com/docs/whatsapp/pricing#customer-service-windows
+const customerServiceWindow = 24 * time.Hour
+
+// Scheduler delivers due reminders over WhatsApp.
+type Scheduler struct {
+	store store.Store
+	wa    *whatsapp.Client
+	// template is sent instead of free text when the 24h window is closed.
+	// Expected body params: {{1}} = ticket ID, {{2}} = note.
+	template     string
+	templateLang string
+	now          func() time.Time
+}
+
+func NewScheduler(s store.Store, wa *whatsapp.Client, template string) *Scheduler {
+	return &Scheduler{store: s, wa: wa, template: template, templateLang: "pt_BR", now: time.Now}
+}
+
+// Run fires due reminders every interval until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.FireDue()
+		}
+	}
+}
+
+// FireDue sends every reminder whose time has come and removes it from the store.
+// Reminders that can't be delivered are dropped too, so they don't fire forever.
+func (s *Scheduler) FireDue() {
+	now := s.now()
+	due, err := s.store.DueReminders(now)
+	if err != nil {
+		slog.Error("reminder: failed to load due reminders", "error", err)
+		return
+	}
+
+	for _, r := range due {
+		if err := s.deliver(r, now); err != nil {
+			slog.Warn("reminder: delivery failed", "ticket_id", r.TicketID, "phone", logging.HashPhone(r.Phone), "error", err)
+		}
+		if err := s.store.DeleteReminder(r); err != nil {
+			slog.Error("reminder: failed to delete reminder", "phone", logging.HashPhone(r.Phone), "error", err)
+		}
+	}
+}
+
+func (s *Scheduler) deliver(r store.Reminder, now time.Time) error {
+	user, err := s.store.GetUser(r.Phone)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return fmt.Errorf("user no longer linked")
+	}
+
+	if now.Sub(user.LastMessageAt) <= customerServiceWindow {
+		return s.wa.SendText(r.Phone, reminderText(r))
+	}
+	if s.template == "" {
+		return fmt.Errorf("24h window closed and no reminder template configured")
+	}
+	note := r.Note
+	if note == "" {
+		note = "-"
+	}
+	return s.wa.SendTemplate(r.Phone, s.template, s.templateLang, whatsapp.BodyParams(fmt.Sprintf("%d", r.TicketID), note))
+}
+
+func reminderText(r store.Reminder) string {
+	text := fmt.Sprintf("⏰ *Lembrete:* chamado *#%d*", r.TicketID)
+	if r.Note != "" {
+		text += "\n\n" + r.Note
+	}
+	return text + "\n\n_Quer que eu verifique o status dele?_"
+}
@@ -12,8 +12,11 @@ type Manager struct {
 	mutexes map[string]*userLock
 }
 
+// userLock's refs and lastUsed are guarded by Manager.mu, not by the lock's
+// own mu, so Cleanup can inspect them without waiting on a running handler.
 type userLock struct {
 	mu       sync.Mutex
+	refs     int // WithLock calls holding or waiting for mu
 	lastUsed time.Time
 }
 
@@ -32,23 +35,34 @@ func (m *Manager) WithLock(phone string, fn func() error) error {
 		ul = &userLock{}
 		m.mutexes[phone] = ul
 	}
+	// Taking the ref before releasing m.mu keeps Cleanup from deleting ul
+	// while we wait for it; otherwise a later message would create a second
+	// lock for the same phone and run concurrently with this one.
+	ul.refs++
+	ul.lastUsed = time.Now()
 	m.mu.Unlock()
 
+	defer func() {
+		m.mu.Lock()
+		ul.refs--
+		ul.lastUsed = time.Now()
+		m.mu.Unlock()
+	}()
+
 	ul.mu.Lock()
 	defer ul.mu.Unlock()
-
-	ul.lastUsed = time.Now()
 	return fn()
 }
 
 // Cleanup removes locks not used within maxAge to prevent memory leaks.
+// Locks that are held or awaited are never removed, however old.
 func (m *Manager) Cleanup(maxAge time.Duration) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	now := time.Now()
 	for phone, ul := range m.mutexes {
-		if now.Sub(ul.lastUsed) > maxAge {
+		if ul.refs == 0 && now.Sub(ul.lastUsed) > maxAge {
 			delete(m.mutexes, phone)
 		}
 	}
@@ -0,0 +1,97 @@
+package session
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestWithLockSerializesUnderCleanup runs concurrent messages per phone while
+// Cleanup evicts aggressively. If Cleanup dropped a lock that a message was
+// waiting on, the next message would get a fresh lock and two handlers for
+// the same phone would overlap. Run with -race.
+func TestWithLockSerializesUnderCleanup(t *testing.T) {
+	m := NewManager()
+	phones := []string{"5511900000001", "5511900000002", "5511900000003"}
+	active := make(map[string]*atomic.Int32, len(phones))
+	for _, p := range phones {
+		active[p] = &atomic.Int32{}
+	}
+
+	stop := make(chan struct{})
+	var cleaner sync.WaitGroup
+	cleaner.Add(1)
+	go func() {
+		defer cleaner.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				m.Cleanup(0)
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := range 200 {
+		phone := phones[i%len(phones)]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := m.WithLock(phone, func() error {
+				if n := active[phone].Add(1); n != 1 {
+					t.Errorf("%d handlers running for %s", n, phone)
+				}
+				time.Sleep(50 * time.Microsecond)
+				active[phone].Add(-1)
+				return nil
+			})
+			if err != nil {
+				t.Errorf("WithLock: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	close(stop)
+	cleaner.Wait()
+}
+
+func TestCleanup(t *testing.T) {
+	m := NewManager()
+	m.WithLock("idle", func() error { return nil })
+
+	held := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.WithLock("busy", func() error {
+			close(held)
+			<-release
+			return nil
+		})
+	}()
+	<-held
+
+	m.Cleanup(time.Hour)
+	if len(m.mutexes) != 2 {
+		t.Fatalf("Cleanup(1h) left %d locks, want 2", len(m.mutexes))
+	}
+
+	m.Cleanup(0)
+	if _, ok := m.mutexes["idle"]; ok {
+		t.Error("idle lock not evicted")
+	}
+	if _, ok := m.mutexes["busy"]; !ok {
+		t.Error("held lock evicted")
+	}
+
+	close(release)
+	<-done
+	m.Cleanup(0)
+	if len(m.mutexes) != 0 {
+		t.Errorf("Cleanup(0) after release left %d locks, want 0", len(m.mutexes))
+	}
+}
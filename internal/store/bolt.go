@@ -1,8 +1,11 @@
 package store
 
 import (
+	"bytes"
+	"crypto/cipher"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"time"
 
 	bolt "go.etcd.io/bbolt"
@@ -11,14 +14,28 @@ import (
 var (
 	usersBucket         = []byte("users")
 	conversationsBucket = []byte("conversations")
+	remindersBucket     = []byte("reminders")
+	authFailuresBucket  = []byte("auth_failures")
+	onboardedBucket     = []byte("onboarded")
+	recentTicketsBucket = []byte("recent_tickets")
+	tokenUsageBucket    = []byte("token_usage")
 )
 
-const (
-	maxConversationTurns = 50
+// HistoryLimits caps the stored conversation per user.
+type HistoryLimits struct {
+	MaxTurns int
 	// Token budget for conversation history (leaves room for system prompt + output).
-	// Estimated via len(text)/3.5 heuristic for multilingual content.
-	maxHistoryTokens = 3500
-)
+	// Estimated via EstimateTokens.
+	MaxTokens int
+	// KeepRecent is how many trailing turns keep full tool responses; older
+	// ones are compressed (see KeepFullFrom for the create flow exception).
+	KeepRecent int
+}
+
+var DefaultHistoryLimits = HistoryLimits{MaxTurns: 50, MaxTokens: 3500, KeepRecent: 4}
+
+// Auth failures older than this no longer count towards escalation.
+const authFailureWindow = 24 * time.Hour
 
 // TurnPart represents a single part of a conversation turn (text or function call/response).
 type TurnPart struct {
@@ -51,6 +68,40 @@ type User struct {
 	GLPIUserID      int       `json:"glpi_user_id"`
 	Name            string    `json:"name"`
 	AuthenticatedAt time.Time `json:"authenticated_at"`
+	// LastMessageAt is when the user last wrote to us; WhatsApp only allows
+	// free-form outbound messages within 24h of it.
+	LastMessageAt time.Time `json:"last_message_at,omitempty"`
+	// OAuthRefreshToken is set instead of UserToken for users who logged in via
+	// OAuth. Kept in plaintext in memory; encrypted by the store at rest.
+	OAuthRefreshToken string `json:"oauth_refresh_token,omitempty"`
+}
+
+// Reminder is a scheduled "me lembra desse chamado" message.
+type Reminder struct {
+	Phone     string    `json:"phone"`
+	TicketID  int       `json:"ticket_id"`
+	FireAt    time.Time `json:"fire_at"`
+	Note      string    `json:"note,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// key orders reminders by fire time so due ones can be read with a cursor scan.
+func (r Reminder) key() []byte {
+	return []byte(fmt.Sprintf("%s|%s|%d", r.FireAt.UTC().Format(reminderKeyLayout), r.Phone, r.TicketID))
+}
+
+const reminderKeyLayout = "20060102T150405Z"
+
+// maxRecentTickets is how many recently discussed tickets are kept per phone.
+const maxRecentTickets = 5
+
+// RecentTicket is a ticket the user recently interacted with. Title and
+// Status are a snapshot from that interaction, not live GLPI data.
+type RecentTicket struct {
+	ID     int       `json:"id"`
+	Title  string    `json:"title,omitempty"`
+	Status string    `json:"status,omitempty"`
+	At     time.Time `json:"at"`
 }
 
 type Store interface {
@@ -60,11 +111,24 @@ type Store interface {
 	GetHistory(phone string) ([]ConversationTurn, error)
 	SaveHistory(phone string, turns []ConversationTurn) error
 	ClearHistory(phone string) error
+	SaveReminder(r Reminder) error
+	DueReminders(now time.Time) ([]Reminder, error)
+	DeleteReminder(r Reminder) error
+	RecordAuthFailure(phone string) (int, error)
+	ResetAuthFailures(phone string) error
+	MarkOnboarded(phone string) error
+	IsOnboarded(phone string) (bool, error)
+	RecordRecentTicket(phone string, t RecentTicket) error
+	RecentTickets(phone string) ([]RecentTicket, error)
+	AddTokenUsage(phone, day string, tokens int) (int, error)
+	TokenUsage(phone, day string) (int, error)
 	Close() error
 }
 
 type BoltStore struct {
-	db *bolt.DB
+	db     *bolt.DB
+	aead   cipher.AEAD
+	limits HistoryLimits
 }
 
 func NewBoltStore(path string) (*BoltStore, error) {
@@ -77,31 +141,119 @@ func NewBoltStore(path string) (*BoltStore, error) {
 		if _, err := tx.CreateBucketIfNotExists(usersBucket); err != nil {
 			return err
 		}
-		_, err := tx.CreateBucketIfNotExists(conversationsBucket)
+		if _, err := tx.CreateBucketIfNotExists(conversationsBucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(remindersBucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(authFailuresBucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(onboardedBucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(recentTicketsBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(tokenUsageBucket)
 		return err
 	})
 	if err != nil {
 		db.Close()
-		return nil, fmt.Errorf("creating users bucket: %w", err)
+		return nil, fmt.Errorf("creating buckets: %w", err)
+	}
+	if err := db.Update(migratePhoneKeys); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrating phone keys: %w", err)
+	}
+
+	return &BoltStore{db: db, limits: DefaultHistoryLimits}, nil
+}
+
+// phoneBuckets are keyed by phoneKey.
+var phoneBuckets = [][]byte{
+	usersBucket, conversationsBucket, authFailuresBucket,
+	onboardedBucket, recentTicketsBucket, tokenUsageBucket,
+}
+
+// migratePhoneKeys moves records stored before keys were normalized, under
+// the number as Meta sent it, to their phoneKey. A record already under the
+// normalized key was written since and wins. Runs on every open; once
+// migrated there is nothing left to move.
+func migratePhoneKeys(tx *bolt.Tx) error {
+	for _, name := range phoneBuckets {
+		b := tx.Bucket(name)
+		var legacy [][]byte
+		err := b.ForEach(func(k, _ []byte) error {
+			if !bytes.Equal(k, phoneKey(string(k))) {
+				legacy = append(legacy, bytes.Clone(k))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range legacy {
+			key := phoneKey(string(k))
+			if b.Get(key) == nil {
+				if err := b.Put(key, bytes.Clone(b.Get(k))); err != nil {
+					return err
+				}
+			}
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		if len(legacy) > 0 {
+			slog.Info("store: migrated phone keys", "bucket", string(name), "keys", len(legacy))
+		}
 	}
+	return nil
+}
+
+// SetHistoryLimits overrides DefaultHistoryLimits; zero fields keep the default.
+func (s *BoltStore) SetHistoryLimits(l HistoryLimits) {
+	s.limits = l.withDefaults()
+}
 
-	return &BoltStore{db: db}, nil
+// HistoryLimits returns the effective limits, defaults applied.
+func (s *BoltStore) HistoryLimits() HistoryLimits { return s.limits }
+
+func (l HistoryLimits) withDefaults() HistoryLimits {
+	if l.MaxTurns <= 0 {
+		l.MaxTurns = DefaultHistoryLimits.MaxTurns
+	}
+	if l.MaxTokens <= 0 {
+		l.MaxTokens = DefaultHistoryLimits.MaxTokens
+	}
+	if l.KeepRecent <= 0 {
+		l.KeepRecent = DefaultHistoryLimits.KeepRecent
+	}
+	return l
 }
 
 func (s *BoltStore) SaveUser(u User) error {
+	if u.OAuthRefreshToken != "" {
+		sealed, err := s.seal(u.OAuthRefreshToken)
+		if err != nil {
+			return fmt.Errorf("encrypting refresh token: %w", err)
+		}
+		u.OAuthRefreshToken = sealed
+	}
 	return s.db.Update(func(tx *bolt.Tx) error {
 		data, err := json.Marshal(u)
 		if err != nil {
 			return err
 		}
-		return tx.Bucket(usersBucket).Put([]byte(u.Phone), data)
+		return tx.Bucket(usersBucket).Put(phoneKey(u.Phone), data)
 	})
 }
 
 func (s *BoltStore) GetUser(phone string) (*User, error) {
 	var u User
 	err := s.db.View(func(tx *bolt.Tx) error {
-		v := tx.Bucket(usersBucket).Get([]byte(phone))
+		v := tx.Bucket(usersBucket).Get(phoneKey(phone))
 		if v == nil {
 			return nil
 		}
@@ -113,19 +265,24 @@ func (s *BoltStore) GetUser(phone string) (*User, error) {
 	if u.Phone == "" {
 		return nil, nil
 	}
+	if u.OAuthRefreshToken != "" {
+		if u.OAuthRefreshToken, err = s.open(u.OAuthRefreshToken); err != nil {
+			return nil, fmt.Errorf("decrypting refresh token: %w", err)
+		}
+	}
 	return &u, nil
 }
 
 func (s *BoltStore) DeleteUser(phone string) error {
 	return s.db.Update(func(tx *bolt.Tx) error {
-		return tx.Bucket(usersBucket).Delete([]byte(phone))
+		return tx.Bucket(usersBucket).Delete(phoneKey(phone))
 	})
 }
 
 func (s *BoltStore) GetHistory(phone string) ([]ConversationTurn, error) {
 	var turns []ConversationTurn
 	err := s.db.View(func(tx *bolt.Tx) error {
-		v := tx.Bucket(conversationsBucket).Get([]byte(phone))
+		v := tx.Bucket(conversationsBucket).Get(phoneKey(phone))
 		if v == nil {
 			return nil
 		}
@@ -136,20 +293,18 @@ func (s *BoltStore) GetHistory(phone string) ([]ConversationTurn, error) {
 
 func (s *BoltStore) SaveHistory(phone string, turns []ConversationTurn) error {
 	// Hard cap to prevent unbounded growth
-	if len(turns) > maxConversationTurns {
-		turns = turns[len(turns)-maxConversationTurns:]
+	if len(turns) > s.limits.MaxTurns {
+		turns = turns[len(turns)-s.limits.MaxTurns:]
 	}
 
 	// Compress old tool responses before token pruning
-	for i := range turns {
-		if i >= len(turns)-4 {
-			break // keep recent turns untouched
-		}
+	keepFrom := KeepFullFrom(turns, s.limits.KeepRecent)
+	for i := 0; i < keepFrom; i++ {
 		compressTurnToolResponses(&turns[i])
 	}
 
 	// Token-aware pruning: drop oldest turns until under budget
-	for len(turns) > 2 && estimateTokens(turns) > maxHistoryTokens {
+	for len(turns) > 2 && EstimateTokens(turns) > s.limits.MaxTokens {
 		turns = turns[1:]
 	}
 
@@ -164,13 +319,54 @@ func (s *BoltStore) SaveHistory(phone string, turns []ConversationTurn) error {
 		if err != nil {
 			return err
 		}
-		return tx.Bucket(conversationsBucket).Put([]byte(phone), data)
+		return tx.Bucket(conversationsBucket).Put(phoneKey(phone), data)
 	})
 }
 
-// estimateTokens approximates token count for multilingual text.
+// createFlowTools are the ticket creation steps before create_ticket (prompt
+// Etapas 1-4) whose results hold the department, category and location IDs
+// the final call needs.
+var createFlowTools = map[string]bool{
+	"get_departments":           true,
+	"get_department_categories": true,
+	"get_subcategories":         true,
+	"suggest_routing":           true,
+	"set_branch":                true,
+	"preview_ticket":            true,
+}
+
+// createFlowLookback bounds how far back an unfinished create flow is looked
+// for, so one the user abandoned doesn't keep old responses full forever.
+const createFlowLookback = 20
+
+// KeepFullFrom returns the index of the first turn whose tool responses must
+// stay uncompressed: the last keepRecent turns, extended back to the first
+// step of a create flow that hasn't reached create_ticket yet. Compressing
+// those drops the IDs the model confirmed with the user several turns ago.
+func KeepFullFrom(turns []ConversationTurn, keepRecent int) int {
+	from := max(len(turns)-keepRecent, 0)
+	for i := len(turns) - 1; i >= 0 && i >= len(turns)-createFlowLookback; i-- {
+		if turns[i].Role != "assistant" {
+			continue
+		}
+		for _, p := range turns[i].Parts {
+			if p.FunctionCall == nil {
+				continue
+			}
+			if p.FunctionCall.Name == "create_ticket" {
+				return from
+			}
+			if createFlowTools[p.FunctionCall.Name] && i < from {
+				from = i
+			}
+		}
+	}
+	return from
+}
+
+// EstimateTokens approximates token count for multilingual text.
 // Uses len/3.5 heuristic with 10% overhead for JSON structure.
-func estimateTokens(turns []ConversationTurn) int {
+func EstimateTokens(turns []ConversationTurn) int {
 	total := 0
 	for _, t := range turns {
 		for _, p := range t.Parts {
@@ -243,8 +439,205 @@ func compressTurnToolResponses(turn *ConversationTurn) {
 
 func (s *BoltStore) ClearHistory(phone string) error {
 	return s.db.Update(func(tx *bolt.Tx) error {
-		return tx.Bucket(conversationsBucket).Delete([]byte(phone))
+		return tx.Bucket(conversationsBucket).Delete(phoneKey(phone))
+	})
+}
+
+func (s *BoltStore) SaveReminder(r Reminder) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		data, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(remindersBucket).Put(r.key(), data)
+	})
+}
+
+// DueReminders returns all reminders with FireAt at or before now, oldest first.
+func (s *BoltStore) DueReminders(now time.Time) ([]Reminder, error) {
+	limit := []byte(now.UTC().Format(reminderKeyLayout) + "|\xff")
+	var due []Reminder
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(remindersBucket).Cursor()
+		for k, v := c.First(); k != nil && bytes.Compare(k, limit) <= 0; k, v = c.Next() {
+			var r Reminder
+			if err := json.Unmarshal(v, &r); err != nil {
+				return err
+			}
+			due = append(due, r)
+		}
+		return nil
+	})
+	return due, err
+}
+
+func (s *BoltStore) DeleteReminder(r Reminder) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(remindersBucket).Delete(r.key())
+	})
+}
+
+// authFailures is kept apart from User because the user record is deleted on
+// auth failure, and the count must survive re-linking attempts.
+type authFailures struct {
+	Count  int       `json:"count"`
+	LastAt time.Time `json:"last_at"`
+}
+
+// RecordAuthFailure increments and returns the consecutive auth failures for phone.
+func (s *BoltStore) RecordAuthFailure(phone string) (int, error) {
+	var f authFailures
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(authFailuresBucket)
+		if v := b.Get(phoneKey(phone)); v != nil {
+			if err := json.Unmarshal(v, &f); err != nil {
+				return err
+			}
+		}
+		if time.Since(f.LastAt) > authFailureWindow {
+			f.Count = 0
+		}
+		f.Count++
+		f.LastAt = time.Now()
+		data, err := json.Marshal(f)
+		if err != nil {
+			return err
+		}
+		return b.Put(phoneKey(phone), data)
+	})
+	return f.Count, err
+}
+
+// ResetAuthFailures clears the count for phone. It runs on every opened
+// session, so the common case of nothing recorded stays a read.
+func (s *BoltStore) ResetAuthFailures(phone string) error {
+	var recorded bool
+	if err := s.db.View(func(tx *bolt.Tx) error {
+		recorded = tx.Bucket(authFailuresBucket).Get(phoneKey(phone)) != nil
+		return nil
+	}); err != nil || !recorded {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(authFailuresBucket).Delete(phoneKey(phone))
+	})
+}
+
+// The onboarded flag lives apart from User for the same reason as
+// authFailures: re-linking after an expired token recreates the user record,
+// and the capability tour should not be shown again.
+
+// MarkOnboarded records that phone has been shown the onboarding tour.
+func (s *BoltStore) MarkOnboarded(phone string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(onboardedBucket).Put(phoneKey(phone), []byte(time.Now().UTC().Format(time.RFC3339)))
+	})
+}
+
+func (s *BoltStore) IsOnboarded(phone string) (bool, error) {
+	var onboarded bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		onboarded = tx.Bucket(onboardedBucket).Get(phoneKey(phone)) != nil
+		return nil
+	})
+	return onboarded, err
+}
+
+// RecordRecentTicket moves t to the front of phone's recent tickets, keeping
+// the previous title/status when t doesn't carry them, and drops the oldest
+// beyond maxRecentTickets.
+func (s *BoltStore) RecordRecentTicket(phone string, t RecentTicket) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(recentTicketsBucket)
+		var list []RecentTicket
+		if v := b.Get(phoneKey(phone)); v != nil {
+			if err := json.Unmarshal(v, &list); err != nil {
+				return err
+			}
+		}
+		updated := []RecentTicket{t}
+		for _, old := range list {
+			if old.ID != t.ID {
+				updated = append(updated, old)
+				continue
+			}
+			if updated[0].Title == "" {
+				updated[0].Title = old.Title
+			}
+			if updated[0].Status == "" {
+				updated[0].Status = old.Status
+			}
+		}
+		if len(updated) > maxRecentTickets {
+			updated = updated[:maxRecentTickets]
+		}
+		data, err := json.Marshal(updated)
+		if err != nil {
+			return err
+		}
+		return b.Put(phoneKey(phone), data)
+	})
+}
+
+// RecentTickets returns phone's recent tickets, most recent first.
+func (s *BoltStore) RecentTickets(phone string) ([]RecentTicket, error) {
+	var list []RecentTicket
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(recentTicketsBucket).Get(phoneKey(phone))
+		if v == nil {
+			return nil
+		}
+		return json.Unmarshal(v, &list)
+	})
+	return list, err
+}
+
+// tokenUsage is one phone's model token count for Day. Only the current day
+// is kept: a count from an earlier day is dropped on the next write, which is
+// the daily reset.
+type tokenUsage struct {
+	Day    string `json:"day"`
+	Tokens int    `json:"tokens"`
+}
+
+// AddTokenUsage adds tokens to phone's count for day (e.g. "2006-01-02") and
+// returns the new total.
+func (s *BoltStore) AddTokenUsage(phone, day string, tokens int) (int, error) {
+	var u tokenUsage
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(tokenUsageBucket)
+		if v := b.Get(phoneKey(phone)); v != nil {
+			if err := json.Unmarshal(v, &u); err != nil {
+				return err
+			}
+		}
+		if u.Day != day {
+			u = tokenUsage{Day: day}
+		}
+		u.Tokens += tokens
+		data, err := json.Marshal(u)
+		if err != nil {
+			return err
+		}
+		return b.Put(phoneKey(phone), data)
 	})
+	return u.Tokens, err
+}
+
+// TokenUsage returns phone's token count for day; 0 when nothing was recorded.
+func (s *BoltStore) TokenUsage(phone, day string) (int, error) {
+	var u tokenUsage
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(tokenUsageBucket).Get(phoneKey(phone))
+		if v == nil {
+			return nil
+		}
+		return json.Unmarshal(v, &u)
+	})
+	if err != nil || u.Day != day {
+		return 0, err
+	}
+	return u.Tokens, nil
 }
 
 func (s *BoltStore) Close() error {
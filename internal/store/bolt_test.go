@@ -0,0 +1,172 @@
+package store
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestMigratePhoneKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "laia.db")
+
+	// Records written before keys were normalized, under Meta's raw form
+	// without the ninth digit.
+	const raw = "551187654321"
+	const normalized = "5511987654321"
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy := map[string]string{
+		string(usersBucket):         `{"phone":"` + raw + `","glpi_user_id":7}`,
+		string(conversationsBucket): `[{"role":"user","parts":[{"text":"oi"}]}]`,
+		string(recentTicketsBucket): `[{"id":42}]`,
+		string(tokenUsageBucket):    `{"day":"2026-10-15","tokens":100}`,
+		string(onboardedBucket):     `2026-10-01T00:00:00Z`,
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		for name, v := range legacy {
+			b, err := tx.CreateBucketIfNotExists([]byte(name))
+			if err != nil {
+				return err
+			}
+			if err := b.Put([]byte(raw), []byte(v)); err != nil {
+				return err
+			}
+		}
+		// A newer record under the normalized key wins over the legacy one.
+		b, err := tx.CreateBucketIfNotExists(authFailuresBucket)
+		if err != nil {
+			return err
+		}
+		if err := b.Put([]byte(raw), []byte(`{"count":1}`)); err != nil {
+			return err
+		}
+		return b.Put([]byte(normalized), []byte(`{"count":2}`))
+	}); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	s, err := NewBoltStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if u, err := s.GetUser(raw); err != nil || u == nil || u.GLPIUserID != 7 {
+		t.Fatalf("GetUser = %v, %v; want migrated user", u, err)
+	}
+	if h, err := s.GetHistory(normalized); err != nil || len(h) != 1 {
+		t.Errorf("GetHistory = %v, %v; want migrated history", h, err)
+	}
+	if n, err := s.TokenUsage(normalized, "2026-10-15"); err != nil || n != 100 {
+		t.Errorf("TokenUsage = %d, %v; want 100", n, err)
+	}
+	if ok, err := s.IsOnboarded(raw); err != nil || !ok {
+		t.Errorf("IsOnboarded = %v, %v; want true", ok, err)
+	}
+	if err := s.db.View(func(tx *bolt.Tx) error {
+		for _, name := range phoneBuckets {
+			if tx.Bucket(name).Get([]byte(raw)) != nil {
+				t.Errorf("bucket %s still has the legacy key", name)
+			}
+		}
+		if v := string(tx.Bucket(authFailuresBucket).Get([]byte(normalized))); v != `{"count":2}` {
+			t.Errorf("auth failures = %s, want the normalized record", v)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.DeleteUser(raw); err != nil {
+		t.Fatal(err)
+	}
+	if u, err := s.GetUser(raw); err != nil || u != nil {
+		t.Fatalf("GetUser after delete = %v, %v; want nil", u, err)
+	}
+}
+
+func TestKeepFullFrom(t *testing.T) {
+	call := func(name string) ConversationTurn {
+		return ConversationTurn{Role: "assistant", Parts: []TurnPart{{FunctionCall: &FunctionCallPart{Name: name}}}}
+	}
+	text := func(role, s string) ConversationTurn {
+		return ConversationTurn{Role: role, Parts: []TurnPart{{Text: s}}}
+	}
+
+	tests := []struct {
+		name  string
+		turns []ConversationTurn
+		want  int
+	}{
+		{
+			name:  "no flow keeps only recent",
+			turns: []ConversationTurn{text("user", "oi"), call("list_my_tickets"), text("user", "ok"), text("assistant", "certo"), text("user", "valeu")},
+			want:  3,
+		},
+		{
+			name:  "subcategory lookup mid-flow",
+			turns: []ConversationTurn{text("user", "oi"), call("get_subcategories"), text("user", "a segunda"), text("assistant", "certo"), text("user", "isso")},
+			want:  1,
+		},
+		{
+			name:  "flow started earlier",
+			turns: []ConversationTurn{call("get_departments"), text("user", "TI"), call("get_subcategories"), text("user", "a segunda"), text("assistant", "certo"), text("user", "isso")},
+			want:  0,
+		},
+		{
+			name:  "finished flow",
+			turns: []ConversationTurn{call("get_subcategories"), text("user", "a segunda"), call("create_ticket"), text("assistant", "criado"), text("user", "valeu")},
+			want:  3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := KeepFullFrom(tt.turns, 2); got != tt.want {
+				t.Errorf("KeepFullFrom = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthFailureCount(t *testing.T) {
+	s, err := NewBoltStore(filepath.Join(t.TempDir(), "laia.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	const phone = "5511987654321"
+
+	for want := 1; want <= 3; want++ {
+		if n, err := s.RecordAuthFailure(phone); err != nil || n != want {
+			t.Fatalf("RecordAuthFailure = %d, %v; want %d", n, err, want)
+		}
+	}
+	if err := s.ResetAuthFailures(phone); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := s.RecordAuthFailure(phone); n != 1 {
+		t.Errorf("after reset = %d, want 1", n)
+	}
+
+	// Failures older than the window don't add up.
+	stale, _ := json.Marshal(authFailures{Count: 2, LastAt: time.Now().Add(-authFailureWindow - time.Minute)})
+	if err := s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(authFailuresBucket).Put(phoneKey(phone), stale)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := s.RecordAuthFailure(phone); n != 1 {
+		t.Errorf("after a stale failure = %d, want 1", n)
+	}
+
+	// Resetting a phone with nothing recorded is a no-op.
+	if err := s.ResetAuthFailures("5511900000000"); err != nil {
+		t.Error(err)
+	}
+}
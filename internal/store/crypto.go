@@ -0,0 +1,59 @@
+package store
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+)
+
+// SetEncryptionKey enables AES-256-GCM encryption of OAuth refresh tokens at
+// rest. Without it, saving a user with a refresh token fails.
+func (s *BoltStore) SetEncryptionKey(key []byte) error {
+	if len(key) != 32 {
+		return fmt.Errorf("encryption key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+	s.aead = aead
+	return nil
+}
+
+// seal returns base64(nonce || ciphertext).
+func (s *BoltStore) seal(plaintext string) (string, error) {
+	if s.aead == nil {
+		return "", fmt.Errorf("encryption key not configured")
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := s.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *BoltStore) open(encoded string) (string, error) {
+	if s.aead == nil {
+		return "", fmt.Errorf("encryption key not configured")
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	n := s.aead.NonceSize()
+	if len(data) < n {
+		return "", fmt.Errorf("ciphertext too short")
+	}
+	plaintext, err := s.aead.Open(nil, data[:n], data[n:], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,65 @@
+package store
+
+import "strings"
+
+const brazilCountryCode = "55"
+
+// brazilAreaCodes are the DDDs in use. A number without country code is only
+// taken as Brazilian when it starts with one of them.
+var brazilAreaCodes = map[string]bool{
+	"11": true, "12": true, "13": true, "14": true, "15": true, "16": true, "17": true, "18": true, "19": true,
+	"21": true, "22": true, "24": true, "27": true, "28": true,
+	"31": true, "32": true, "33": true, "34": true, "35": true, "37": true, "38": true,
+	"41": true, "42": true, "43": true, "44": true, "45": true, "46": true, "47": true, "48": true, "49": true,
+	"51": true, "53": true, "54": true, "55": true,
+	"61": true, "62": true, "63": true, "64": true, "65": true, "66": true, "67": true, "68": true, "69": true,
+	"71": true, "73": true, "74": true, "75": true, "77": true, "79": true,
+	"81": true, "82": true, "83": true, "84": true, "85": true, "86": true, "87": true, "88": true, "89": true,
+	"91": true, "92": true, "93": true, "94": true, "95": true, "96": true, "97": true, "98": true, "99": true,
+}
+
+// PhoneDigits strips everything but digits from raw. It is what we keep of a
+// number we reply to: Meta's form, without reinterpreting it.
+func PhoneDigits(raw string) string {
+	var b strings.Builder
+	for _, r := range raw {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// NormalizePhone returns the canonical form of a WhatsApp number used as store
+// key: digits only, with country code, no leading zeros. Brazilian numbers
+// are the ones that vary in practice:
+//   - mobiles without country code ("11987654321", "011987654321") get 55
+//     prepended. Only a valid area code + 9 + 8 digits qualifies, so foreign
+//     numbers with country code (Chile's 569..., NANP's 1...) are kept;
+//   - mobiles still in the old 8-digit format ("55 11 87654321") get the
+//     ninth digit, so accounts created before and after the 2016 migration
+//     map to the same user. Meta may deliver either form.
+//
+// Numbers that don't look Brazilian are kept as digits. Some country codes
+// are also area codes (Peru's 51 is Porto Alegre's), so a few foreign numbers
+// still get a Brazilian key; the key is stable for them, and messages always
+// go to the number as Meta sent it, never to the key.
+func NormalizePhone(raw string) string {
+	digits := strings.TrimLeft(PhoneDigits(raw), "0") // trunk (0) and international (00) prefixes
+
+	if len(digits) == 11 && digits[2] == '9' && brazilAreaCodes[digits[:2]] {
+		digits = brazilCountryCode + digits
+	}
+	// 55 + area code + 8 digits starting 6-9 is a mobile without the ninth
+	// digit; 2-5 are landlines, which never got it.
+	if len(digits) == 12 && strings.HasPrefix(digits, brazilCountryCode) &&
+		brazilAreaCodes[digits[2:4]] && digits[4] >= '6' {
+		digits = digits[:4] + "9" + digits[4:]
+	}
+	return digits
+}
+
+// phoneKey is the bucket key for phone.
+func phoneKey(phone string) []byte {
+	return []byte(NormalizePhone(phone))
+}
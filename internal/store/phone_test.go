@@ -0,0 +1,37 @@
+package store
+
+import "testing"
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"canonical mobile", "5511987654321", "5511987654321"},
+		{"formatted mobile", "+55 (11) 98765-4321", "5511987654321"},
+		{"mobile without country code", "11987654321", "5511987654321"},
+		{"mobile with trunk prefix", "011987654321", "5511987654321"},
+		{"international prefix", "005511987654321", "5511987654321"},
+		{"mobile without ninth digit", "551187654321", "5511987654321"},
+		{"landline kept", "551132654321", "551132654321"},
+		{"invalid area code", "20987654321", "20987654321"},
+		{"chile mobile kept", "56912345678", "56912345678"},
+		{"us number kept", "12125551234", "12125551234"},
+		{"uk mobile kept", "447911123456", "447911123456"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizePhone(tt.raw); got != tt.want {
+				t.Errorf("NormalizePhone(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPhoneDigits(t *testing.T) {
+	if got := PhoneDigits("+56 9 1234-5678"); got != "56912345678" {
+		t.Errorf("PhoneDigits = %q, want %q", got, "56912345678")
+	}
+}
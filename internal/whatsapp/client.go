@@ -3,17 +3,25 @@ package whatsapp
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
 	"time"
 )
 
-const apiURL = "https://graph.facebook.com/v21.0"
+const defaultAPIURL = "https://graph.facebook.com/v21.0"
+
+// ErrInvalidToken is returned by VerifyToken when Meta rejects the access
+// token (expired, revoked or missing the WhatsApp permissions).
+var ErrInvalidToken = errors.New("whatsapp access token invalid")
 
 type Client struct {
 	phoneNumberID string
 	accessToken   string
+	apiURL        string
 	http          *http.Client
 }
 
@@ -21,10 +29,17 @@ func NewClient(phoneNumberID, accessToken string) *Client {
 	return &Client{
 		phoneNumberID: phoneNumberID,
 		accessToken:   accessToken,
+		apiURL:        defaultAPIURL,
 		http:          &http.Client{Timeout: 15 * time.Second},
 	}
 }
 
+// SetAPIURL points the client at another Graph API base URL (e.g. a test
+// server) instead of defaultAPIURL.
+func (c *Client) SetAPIURL(url string) {
+	c.apiURL = url
+}
+
 func (c *Client) SendText(to, body string) error {
 	msg := SendMessageRequest{
 		MessagingProduct: "whatsapp",
@@ -89,6 +104,101 @@ func (c *Client) SendList(to, body, buttonText string, sections []Section) error
 	return c.send(msg)
 }
 
+// SendTemplate sends a pre-approved template. Templates must be created and
+// approved in Meta's WhatsApp Manager first; the name, language and number of
+// variables must match the approved version or Meta rejects the message.
+// Reference: https://developers.facebook.com/docs/whatsapp/cloud-api/guides/send-message-templates
+func (c *Client) SendTemplate(to, templateName, lang string, components []TemplateComponent) error {
+	msg := SendMessageRequest{
+		MessagingProduct: "whatsapp",
+		RecipientType:    "individual",
+		To:               to,
+		Type:             "template",
+		Template: &Template{
+			Name:       templateName,
+			Language:   TemplateLanguage{Code: lang},
+			Components: components,
+		},
+	}
+	return c.send(msg)
+}
+
+// SendImage sends an image previously uploaded with UploadMedia.
+// Reference: https://developers.facebook.com/docs/whatsapp/cloud-api/messages/image-messages
+func (c *Client) SendImage(to, mediaID, caption string) error {
+	msg := SendMessageRequest{
+		MessagingProduct: "whatsapp",
+		RecipientType:    "individual",
+		To:               to,
+		Type:             "image",
+		Image:            &Media{ID: mediaID, Caption: caption},
+	}
+	return c.send(msg)
+}
+
+// SendDocument sends a file previously uploaded with UploadMedia; filename is
+// what the user sees.
+// Reference: https://developers.facebook.com/docs/whatsapp/cloud-api/messages/document-messages
+func (c *Client) SendDocument(to, mediaID, filename, caption string) error {
+	msg := SendMessageRequest{
+		MessagingProduct: "whatsapp",
+		RecipientType:    "individual",
+		To:               to,
+		Type:             "document",
+		Document:         &Media{ID: mediaID, Caption: caption, Filename: filename},
+	}
+	return c.send(msg)
+}
+
+// UploadMedia uploads a file to Meta and returns its media ID, valid for 30
+// days. Images must be JPEG or PNG, up to 5 MB; documents up to 100 MB.
+// Reference: https://developers.facebook.com/docs/whatsapp/cloud-api/reference/media#upload-media
+func (c *Client) UploadMedia(data []byte, mimeType, filename string) (string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("messaging_product", "whatsapp")
+	form.WriteField("type", mimeType)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, filename))
+	header.Set("Content-Type", mimeType)
+	part, err := form.CreatePart(header)
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write(data); err != nil {
+		return "", err
+	}
+	if err := form.Close(); err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s/%s/media", c.apiURL, c.phoneNumberID)
+	req, err := http.NewRequest(http.MethodPost, url, &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("uploading media: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("whatsapp API media status %d: %s", resp.StatusCode, respBody)
+	}
+	var result struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("decoding media upload: %w", err)
+	}
+	return result.ID, nil
+}
+
 // ReactMessage sends or removes a reaction on a message.
 // Reference: https://developers.facebook.com/docs/whatsapp/cloud-api/messages/reaction-messages
 func (c *Client) ReactMessage(to, messageID, emoji string) error {
@@ -107,7 +217,7 @@ func (c *Client) ReactMessage(to, messageID, emoji string) error {
 		return fmt.Errorf("marshaling reaction: %w", err)
 	}
 
-	url := fmt.Sprintf("%s/%s/messages", apiURL, c.phoneNumberID)
+	url := fmt.Sprintf("%s/%s/messages", c.apiURL, c.phoneNumberID)
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
 	if err != nil {
 		return err
@@ -128,13 +238,50 @@ func (c *Client) ReactMessage(to, messageID, emoji string) error {
 	return nil
 }
 
+// VerifyToken checks the access token by reading the phone number it sends
+// from. Sends fail with a bare 401 once the token expires, so this is probed
+// at startup and periodically to make the cause obvious in the logs.
+// Reference: https://developers.facebook.com/docs/graph-api/guides/error-handling
+func (c *Client) VerifyToken() error {
+	url := fmt.Sprintf("%s/%s?fields=id", c.apiURL, c.phoneNumberID)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("verifying token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	respBody, _ := io.ReadAll(resp.Body)
+	var apiErr struct {
+		Error struct {
+			Message string `json:"message"`
+			Code    int    `json:"code"`
+		} `json:"error"`
+	}
+	json.Unmarshal(respBody, &apiErr)
+	// 190 is Graph's OAuthException for expired/invalid tokens; 401 covers
+	// the rest of the auth failures.
+	if resp.StatusCode == http.StatusUnauthorized || apiErr.Error.Code == 190 {
+		return fmt.Errorf("%w: %s", ErrInvalidToken, apiErr.Error.Message)
+	}
+	return fmt.Errorf("whatsapp API token check status %d: %s", resp.StatusCode, respBody)
+}
+
 func (c *Client) send(msg SendMessageRequest) error {
 	payload, err := json.Marshal(msg)
 	if err != nil {
 		return fmt.Errorf("marshaling message: %w", err)
 	}
 
-	url := fmt.Sprintf("%s/%s/messages", apiURL, c.phoneNumberID)
+	url := fmt.Sprintf("%s/%s/messages", c.apiURL, c.phoneNumberID)
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
 	if err != nil {
 		return err
@@ -1,5 +1,7 @@
 package whatsapp
 
+import "strconv"
+
 // --- Incoming webhook payload ---
 // Reference: https://developers.facebook.com/docs/whatsapp/cloud-api/webhooks/components
 
@@ -47,6 +49,7 @@ type Message struct {
 	Type        string              `json:"type"`
 	Text        *TextContent        `json:"text,omitempty"`
 	Interactive *InteractiveContent `json:"interactive,omitempty"`
+	Location    *LocationContent    `json:"location,omitempty"`
 }
 
 // InteractiveContent represents a user's reply to an interactive message (button or list).
@@ -68,6 +71,37 @@ type ListReplyMsg struct {
 	Description string `json:"description"`
 }
 
+// LocationContent is a shared location pin. Name and Address are only set
+// when the user picked a place instead of sending their current position.
+// Reference: https://developers.facebook.com/docs/whatsapp/cloud-api/webhooks/components#messages-object
+type LocationContent struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Name      string  `json:"name,omitempty"`
+	Address   string  `json:"address,omitempty"`
+	URL       string  `json:"url,omitempty"`
+}
+
+// LocationPrefix starts the text a location message is delivered as.
+const LocationPrefix = "📍 Localização compartilhada:"
+
+// Text renders the pin as a user message, so it flows through the same
+// handler as typed text.
+func (l *LocationContent) Text() string {
+	place := l.Name
+	if l.Address != "" {
+		if place != "" {
+			place += ", "
+		}
+		place += l.Address
+	}
+	coords := strconv.FormatFloat(l.Latitude, 'f', 6, 64) + "," + strconv.FormatFloat(l.Longitude, 'f', 6, 64)
+	if place == "" {
+		place = coords
+	}
+	return LocationPrefix + " " + place + " (https://maps.google.com/?q=" + coords + ")"
+}
+
 type TextContent struct {
 	Body string `json:"body"`
 }
@@ -83,12 +117,70 @@ type Status struct {
 // Reference: https://developers.facebook.com/docs/whatsapp/cloud-api/messages
 
 type SendMessageRequest struct {
-	MessagingProduct string      `json:"messaging_product"`
-	RecipientType    string      `json:"recipient_type"`
-	To               string      `json:"to"`
-	Type             string      `json:"type"`
-	Text             *SendText   `json:"text,omitempty"`
+	MessagingProduct string       `json:"messaging_product"`
+	RecipientType    string       `json:"recipient_type"`
+	To               string       `json:"to"`
+	Type             string       `json:"type"`
+	Text             *SendText    `json:"text,omitempty"`
 	Interactive      *Interactive `json:"interactive,omitempty"`
+	Template         *Template    `json:"template,omitempty"`
+	Image            *Media       `json:"image,omitempty"`
+	Document         *Media       `json:"document,omitempty"`
+}
+
+// Media references a file uploaded with Client.UploadMedia.
+type Media struct {
+	ID      string `json:"id"`
+	Caption string `json:"caption,omitempty"`
+	// Filename is only used by documents.
+	Filename string `json:"filename,omitempty"`
+}
+
+// Template is a pre-approved message template, the only kind of message Meta
+// accepts outside the 24h customer service window.
+// Reference: https://developers.facebook.com/docs/whatsapp/cloud-api/guides/send-message-templates
+type Template struct {
+	Name       string              `json:"name"`
+	Language   TemplateLanguage    `json:"language"`
+	Components []TemplateComponent `json:"components,omitempty"`
+}
+
+type TemplateLanguage struct {
+	Code string `json:"code"`
+}
+
+// TemplateComponent fills the variables of one template part: "header",
+// "body" or "button" (buttons also need SubType and Index).
+type TemplateComponent struct {
+	Type       string              `json:"type"`
+	SubType    string              `json:"sub_type,omitempty"`
+	Index      string              `json:"index,omitempty"`
+	Parameters []TemplateParameter `json:"parameters"`
+}
+
+type TemplateParameter struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// BodyParams builds the body component for a template whose body has
+// positional variables {{1}}, {{2}}, ... in the given order.
+func BodyParams(values ...string) []TemplateComponent {
+	params := make([]TemplateParameter, len(values))
+	for i, v := range values {
+		params[i] = TemplateParameter{Type: "text", Text: v}
+	}
+	return []TemplateComponent{{Type: "body", Parameters: params}}
+}
+
+// URLButtonParam fills the dynamic suffix of the template's URL button at index.
+func URLButtonParam(index int, suffix string) TemplateComponent {
+	return TemplateComponent{
+		Type:       "button",
+		SubType:    "url",
+		Index:      strconv.Itoa(index),
+		Parameters: []TemplateParameter{{Type: "text", Text: suffix}},
+	}
 }
 
 type SendText struct {
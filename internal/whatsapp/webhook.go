@@ -2,12 +2,15 @@ package whatsapp
 
 import (
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
 )
 
 // MessageHandler is called for each incoming message with (senderPhone, messageID, messageBody).
-type MessageHandler func(phone, messageID, text string)
+// replyID is the ID of the tapped button/list row, empty for typed messages.
+// Location pins arrive as text starting with LocationPrefix. Unsupported
+// message types (audio, image, sticker...) arrive with empty text and replyID.
+type MessageHandler func(phone, messageID, text, replyID string)
 
 type WebhookHandler struct {
 	verifyToken string
@@ -42,7 +45,7 @@ func (h *WebhookHandler) HandleVerify(w http.ResponseWriter, r *http.Request) {
 func (h *WebhookHandler) HandleIncoming(w http.ResponseWriter, r *http.Request) {
 	var payload WebhookPayload
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		log.Printf("webhook: failed to decode payload: %v", err)
+		slog.Warn("webhook: failed to decode payload", "error", err)
 		w.WriteHeader(http.StatusOK)
 		return
 	}
@@ -55,21 +58,29 @@ func (h *WebhookHandler) HandleIncoming(w http.ResponseWriter, r *http.Request)
 				switch msg.Type {
 				case "text":
 					if msg.Text != nil {
-						h.onMessage(msg.From, msg.ID, msg.Text.Body)
+						h.onMessage(msg.From, msg.ID, msg.Text.Body, "")
 					}
 				case "interactive":
 					if msg.Interactive != nil {
 						switch msg.Interactive.Type {
 						case "button_reply":
 							if msg.Interactive.ButtonReply != nil {
-								h.onMessage(msg.From, msg.ID, msg.Interactive.ButtonReply.Title)
+								h.onMessage(msg.From, msg.ID, msg.Interactive.ButtonReply.Title, msg.Interactive.ButtonReply.ID)
 							}
 						case "list_reply":
 							if msg.Interactive.ListReply != nil {
-								h.onMessage(msg.From, msg.ID, msg.Interactive.ListReply.Title)
+								h.onMessage(msg.From, msg.ID, msg.Interactive.ListReply.Title, msg.Interactive.ListReply.ID)
 							}
 						}
 					}
+				case "location":
+					if msg.Location != nil {
+						h.onMessage(msg.From, msg.ID, msg.Location.Text(), "")
+					}
+				case "reaction":
+					// Reactions to our messages need no reply.
+				default:
+					h.onMessage(msg.From, msg.ID, "", "")
 				}
 			}
 		}
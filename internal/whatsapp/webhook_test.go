@@ -0,0 +1,47 @@
+package whatsapp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleIncomingMessageTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		wantCall bool
+		wantText string
+	}{
+		{"text", `{"from":"5511","id":"m1","type":"text","text":{"body":"oi"}}`, true, "oi"},
+		{"audio", `{"from":"5511","id":"m1","type":"audio","audio":{"id":"a1"}}`, true, ""},
+		{"sticker", `{"from":"5511","id":"m1","type":"sticker","sticker":{"id":"s1"}}`, true, ""},
+		{"image", `{"from":"5511","id":"m1","type":"image","image":{"id":"i1"}}`, true, ""},
+		{"reaction", `{"from":"5511","id":"m1","type":"reaction","reaction":{"message_id":"m0","emoji":"👍"}}`, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			var gotText, gotReply string
+			h := NewWebhookHandler("token", func(phone, messageID, text, replyID string) {
+				calls++
+				gotText, gotReply = text, replyID
+			})
+
+			body := `{"object":"whatsapp_business_account","entry":[{"changes":[{"field":"messages","value":{"messages":[` + tt.message + `]}}]}]}`
+			rec := httptest.NewRecorder()
+			h.HandleIncoming(rec, httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body)))
+
+			if rec.Code != http.StatusOK {
+				t.Errorf("status = %d, want 200", rec.Code)
+			}
+			if got := calls == 1; got != tt.wantCall || calls > 1 {
+				t.Fatalf("onMessage called %d times, want call = %v", calls, tt.wantCall)
+			}
+			if gotText != tt.wantText || gotReply != "" {
+				t.Errorf("onMessage(text=%q, replyID=%q), want text %q and no replyID", gotText, gotReply, tt.wantText)
+			}
+		})
+	}
+}